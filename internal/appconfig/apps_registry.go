@@ -5,9 +5,10 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
-	"strings"
 
 	"gopkg.in/yaml.v3"
+
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
 )
 
 // AppDefinition represents a known application in the registry
@@ -49,29 +50,45 @@ func LoadAppsRegistry() (*AppsRegistry, error) {
 		return nil, fmt.Errorf("failed to load embedded registry: %w", err)
 	}
 
-	// Try to load custom apps from user config
-	configDir, err := os.UserConfigDir()
-	if err == nil {
-		// Check for custom apps.yaml to add/override apps
-		customAppsPath := filepath.Join(configDir, "zeroui", "apps.yaml")
-		if _, err := os.Stat(customAppsPath); err == nil {
-			if err := registry.MergeFromFile(customAppsPath); err != nil {
-				// Log error but don't fail - embedded registry is still valid
-				fmt.Fprintf(os.Stderr, "Warning: failed to load custom apps: %v\n", err)
-			}
+	// Check for custom apps.yaml to add/override apps
+	if customAppsPath := customRegistryFile("apps.yaml"); customAppsPath != "" {
+		if err := registry.MergeFromFile(customAppsPath); err != nil {
+			// Log error but don't fail - embedded registry is still valid
+			fmt.Fprintf(os.Stderr, "Warning: failed to load custom apps: %v\n", err)
 		}
+	}
 
-		// Also check for full registry override (advanced users)
-		customRegistryPath := filepath.Join(configDir, "zeroui", "apps_registry.yaml")
-		if _, err := os.Stat(customRegistryPath); err == nil {
-			// This completely replaces the embedded registry
-			return loadRegistryFromFile(customRegistryPath)
-		}
+	// Also check for full registry override (advanced users)
+	if customRegistryPath := customRegistryFile("apps_registry.yaml"); customRegistryPath != "" {
+		// This completely replaces the embedded registry
+		return loadRegistryFromFile(customRegistryPath)
 	}
 
 	return registry, nil
 }
 
+// customRegistryFile returns the user's copy of a registry file in the zeroui
+// config directory, or "" when there is none. A copy only found in the
+// platform config directory, where it was read from before, is still used,
+// with a warning to move it.
+func customRegistryFile(name string) string {
+	path := filepath.Join(paths.ConfigDir(), name)
+	if _, err := os.Stat(path); err == nil {
+		return path
+	}
+
+	legacyDir := paths.LegacyConfigDir()
+	if legacyDir == "" || filepath.Clean(legacyDir) == filepath.Clean(paths.ConfigDir()) {
+		return ""
+	}
+	legacyPath := filepath.Join(legacyDir, name)
+	if _, err := os.Stat(legacyPath); err != nil {
+		return ""
+	}
+	fmt.Fprintf(os.Stderr, "Warning: reading %s from its old location; move it to %s\n", legacyPath, path)
+	return legacyPath
+}
+
 // LoadAppsRegistryFromFile loads registry from a specific file
 func LoadAppsRegistryFromFile(path string) (*AppsRegistry, error) {
 	return loadRegistryFromFile(path)
@@ -146,13 +163,8 @@ func (r *AppsRegistry) FindConfigPath(appName string) (string, bool) {
 		return "", false
 	}
 
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return "", false
-	}
-
 	for _, path := range app.ConfigPaths {
-		expandedPath := strings.ReplaceAll(path, "~", home)
+		expandedPath := paths.Expand(path)
 		if _, err := os.Stat(expandedPath); err == nil {
 			return expandedPath, true
 		}
@@ -174,14 +186,13 @@ func (r *AppsRegistry) GetConfigPaths(appName string) []string {
 		return nil
 	}
 
-	home, _ := os.UserHomeDir()
-	paths := make([]string, len(app.ConfigPaths))
+	expanded := make([]string, len(app.ConfigPaths))
 
 	for i, path := range app.ConfigPaths {
-		paths[i] = strings.ReplaceAll(path, "~", home)
+		expanded[i] = paths.Expand(path)
 	}
 
-	return paths
+	return expanded
 }
 
 // MergeFromFile merges applications from another YAML file
@@ -0,0 +1,43 @@
+package appconfig
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoadAppsRegistry_LegacyLocation(t *testing.T) {
+	if runtime.GOOS != "linux" {
+		t.Skip("the platform config directory only follows XDG_CONFIG_HOME on Linux")
+	}
+
+	platformDir := t.TempDir()
+	configDir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", platformDir)
+	t.Setenv("ZEROUI_CONFIG_DIR", configDir)
+
+	writeApps := func(path, name string) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte("applications:\n  - name: "+name+"\n    config_paths: [\"~/."+name+"\"]\n"), 0o644))
+	}
+
+	// Only in the platform config directory: still read
+	writeApps(filepath.Join(platformDir, "zeroui", "apps.yaml"), "old-app")
+	registry, err := LoadAppsRegistry()
+	require.NoError(t, err)
+	_, ok := registry.GetApp("old-app")
+	assert.True(t, ok, "apps.yaml in the old location is still read")
+
+	// The zeroui config directory wins once the file is moved there
+	writeApps(filepath.Join(configDir, "apps.yaml"), "new-app")
+	registry, err = LoadAppsRegistry()
+	require.NoError(t, err)
+	_, ok = registry.GetApp("new-app")
+	assert.True(t, ok)
+	_, ok = registry.GetApp("old-app")
+	assert.False(t, ok, "the old copy is ignored when the new one exists")
+}
@@ -0,0 +1,95 @@
+package appconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/knadh/koanf/v2"
+
+	"github.com/mrtkrcm/ZeroUI/internal/audit"
+)
+
+// fileState is a config file as it was at one point of a save
+type fileState struct {
+	hash string
+	// values is nil when the file is missing or can't be parsed
+	values *koanf.Koanf
+}
+
+// captureFiles records the state of every file an app's config is stored in
+func (l *Loader) captureFiles(appConfig *AppConfig, configPath string) map[string]fileState {
+	states := make(map[string]fileState)
+	if graph, err := l.LoadIncludeGraph(appConfig); err == nil {
+		for _, f := range graph.Files {
+			states[f.Path] = fileState{hash: f.Hash, values: f.Values}
+		}
+		return states
+	}
+	// The hash alone still tells whether the file changed
+	hash, _ := fileHash(configPath)
+	states[filepath.Clean(configPath)] = fileState{hash: hash}
+	return states
+}
+
+// recordChanges adds an audit log entry for every file a save changed. The
+// backup taken of the config before the save goes with the entry for its main
+// file. The change is already on disk, so failing to record it only warns.
+func (l *Loader) recordChanges(appConfig *AppConfig, configPath string, before, after map[string]fileState) {
+	backup := audit.TakeBackup(configPath)
+
+	files := make([]string, 0, len(after))
+	for path := range after {
+		files = append(files, path)
+	}
+	sort.Strings(files)
+
+	for _, path := range files {
+		old, updated := before[path], after[path]
+		if old.hash == updated.hash {
+			continue
+		}
+		entry := audit.Entry{
+			App:    appConfig.Name,
+			File:   path,
+			Keys:   changedKeys(old, updated),
+			Before: old.hash,
+			After:  updated.hash,
+		}
+		if path == filepath.Clean(configPath) {
+			entry.Backup = backup
+		}
+		if err := audit.Record(entry); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: failed to record change to %s in the audit log: %v\n", path, err)
+		}
+	}
+}
+
+// changedKeys lists the keys added, removed or changed between two versions
+// of a file, or none when either can't be parsed. Every key of a file the
+// change created is new.
+func changedKeys(before, after fileState) []string {
+	if after.values == nil || (before.values == nil && before.hash != "") {
+		return nil
+	}
+	old := map[string]interface{}{}
+	if before.values != nil {
+		old = before.values.All()
+	}
+	updated := after.values.All()
+
+	var keys []string
+	for key, value := range updated {
+		if prev, ok := old[key]; !ok || fmt.Sprintf("%v", prev) != fmt.Sprintf("%v", value) {
+			keys = append(keys, key)
+		}
+	}
+	for key := range old {
+		if _, ok := updated[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -24,9 +24,15 @@ func ParseGhosttyConfig(configPath string) (*koanf.Koanf, error) {
 		return nil, fmt.Errorf("failed to load Ghostty config: %w", err)
 	}
 
-	// Normalize values for friendlier access in tests and callers:
-	// - Collapse single-item arrays (e.g., ["value"]) to plain strings
-	// - Leave true arrays intact
+	collapseSingleItemLists(k)
+
+	return k, nil
+}
+
+// collapseSingleItemLists normalizes values for friendlier access in tests and
+// callers: single-item arrays (e.g., ["value"]) become plain strings, true
+// arrays are left intact
+func collapseSingleItemLists(k *koanf.Koanf) {
 	all := k.All()
 	for key, value := range all {
 		switch v := value.(type) {
@@ -40,15 +46,24 @@ func ParseGhosttyConfig(configPath string) (*koanf.Koanf, error) {
 			}
 		}
 	}
-
-	return k, nil
 }
 
 // WriteGhosttyConfig writes config back in Ghostty's format using koanf providers
 func WriteGhosttyConfig(configPath string, k *koanf.Koanf, originalPath string) error {
+	return WriteGhosttyConfigRemoving(configPath, k, originalPath, nil)
+}
+
+// WriteGhosttyConfigRemoving writes config like WriteGhosttyConfig and drops
+// the original lines of the removed keys instead of preserving them
+func WriteGhosttyConfigRemoving(configPath string, k *koanf.Koanf, originalPath string, removed []string) error {
+	removedKeys := make(map[string]bool, len(removed))
+	for _, key := range removed {
+		removedKeys[key] = true
+	}
+
 	// Prefer legacy writer to preserve comments and structure expected by tests
 	// Falls back to provider-based marshal on failure
-	if err := writeGhosttyConfigLegacy(configPath, k, originalPath); err == nil {
+	if err := writeGhosttyConfigLegacy(configPath, k, originalPath, removedKeys); err == nil {
 		return nil
 	}
 
@@ -78,9 +93,10 @@ func writeConfigToFile(configPath string, data []byte) error {
 }
 
 // Deprecated: Use WriteGhosttyConfig with koanf providers instead
-func writeGhosttyConfigLegacy(configPath string, k *koanf.Koanf, originalPath string) error {
+func writeGhosttyConfigLegacy(configPath string, k *koanf.Koanf, originalPath string, removed map[string]bool) error {
 	// Read original file to preserve structure and comments
-	originalLines, comments, err := readGhosttyConfigWithComments(originalPath)
+	// Comment lines are copied as they are met, so the per-line comment map isn't needed
+	originalLines, _, err := readGhosttyConfigWithComments(originalPath)
 	if err != nil {
 		// If original doesn't exist, write new file
 		return writeNewGhosttyConfig(configPath, k)
@@ -96,7 +112,7 @@ func writeGhosttyConfigLegacy(configPath string, k *koanf.Koanf, originalPath st
 	originalKeys := performance.GetStringBoolMap()
 	defer performance.PutStringBoolMap(originalKeys)
 
-	for i, line := range originalLines {
+	for _, line := range originalLines {
 		trimmed := strings.TrimSpace(line)
 
 		// Preserve comments and empty lines
@@ -121,11 +137,6 @@ func writeGhosttyConfigLegacy(configPath string, k *koanf.Koanf, originalPath st
 		if k.Exists(key) && !processedKeys[key] {
 			value := k.Get(key)
 
-			// Add any comments that were before this line
-			if comment, exists := comments[i]; exists {
-				output = append(output, comment)
-			}
-
 			// Write the updated value(s)
 			switch v := value.(type) {
 			case []string:
@@ -153,6 +164,9 @@ func writeGhosttyConfigLegacy(configPath string, k *koanf.Koanf, originalPath st
 		} else if k.Exists(key) && processedKeys[key] {
 			// Skip this line as we already processed this key
 			continue
+		} else if removed[key] && !k.Exists(key) {
+			// Drop lines of keys removed explicitly
+			continue
 		} else {
 			// Keep original line if key not in new config
 			output = append(output, line)
@@ -228,8 +242,12 @@ func sanitizeGhosttyKV(key string, value string) (bool, string) {
 		return false, ""
 	}
 
-	// keybind lines: expect something like "<combo>=<action>[:arg]".
+	// keybind lines: expect something like "<combo>=<action>[:arg]", or
+	// nothing to clear the keybinds set before
 	if strings.HasPrefix(k, "keybind") || k == "keybind" {
+		if v == "" {
+			return true, v
+		}
 		if strings.Count(v, "=") >= 1 {
 			left := strings.TrimSpace(v[:strings.Index(v, "=")])
 			right := strings.TrimSpace(v[strings.Index(v, "=")+1:])
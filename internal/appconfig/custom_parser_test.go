@@ -511,6 +511,36 @@ keybind-33 = super+ctrl+up=resize_split:up
 	assert.NotContains(t, text, "keybind-33 = 10")
 }
 
+func TestWriteGhosttyConfigRemoving(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalPath := filepath.Join(tmpDir, "ghostty.conf")
+	outputPath := filepath.Join(tmpDir, "ghostty_out.conf")
+
+	original := `# Appearance
+background-blur-radius = 20
+theme = dark
+font-family = Iosevka
+`
+	require.NoError(t, os.WriteFile(originalPath, []byte(original), 0o644))
+
+	k := koanf.New(".")
+	k.Set("theme", "light")
+	k.Set("background-blur", "20")
+
+	require.NoError(t, WriteGhosttyConfigRemoving(outputPath, k, originalPath, []string{"background-blur-radius"}))
+
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	text := string(data)
+
+	assert.Contains(t, text, "# Appearance")
+	assert.NotContains(t, text, "background-blur-radius")
+	assert.Contains(t, text, "background-blur = 20")
+	assert.Contains(t, text, "theme = light")
+	// Keys missing from the config but not removed explicitly are preserved
+	assert.Contains(t, text, "font-family = Iosevka")
+}
+
 // TestGhosttyConfigRoundTrip tests parsing and writing back preserves data
 func TestGhosttyConfigRoundTrip(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "ghostty-roundtrip-test")
@@ -672,3 +702,19 @@ func BenchmarkWriteGhosttyConfig(b *testing.B) {
 		}
 	}
 }
+
+func TestWriteGhosttyConfig_CommentsNotDuplicated(t *testing.T) {
+	tmpDir := t.TempDir()
+	originalPath := filepath.Join(tmpDir, "ghostty.conf")
+	outputPath := filepath.Join(tmpDir, "ghostty_out.conf")
+
+	require.NoError(t, os.WriteFile(originalPath, []byte("# Theme\ntheme = dark\n"), 0o644))
+
+	k := koanf.New(".")
+	k.Set("theme", "light")
+	require.NoError(t, WriteGhosttyConfig(outputPath, k, originalPath))
+
+	data, err := os.ReadFile(outputPath)
+	require.NoError(t, err)
+	assert.Equal(t, "# Theme\ntheme = light\n", string(data))
+}
@@ -0,0 +1,41 @@
+package appconfig
+
+import (
+	"fmt"
+	"sort"
+)
+
+// DefaultsComparison classifies an app's fields by how their configured value
+// relates to the field default. Keys are sorted.
+type DefaultsComparison struct {
+	Changed   []string // Set to a value other than the default
+	AtDefault []string // Explicitly set to the default value
+}
+
+// CompareWithDefaults compares configured values with the defaults of the
+// app's fields. Fields missing from the config use their default and are in
+// neither list. Values are compared by their string form so "14" and 14 match.
+// An empty default is treated as unknown: generated reference data uses it
+// for settings whose default could not be extracted.
+func CompareWithDefaults(appConfig *AppConfig, current map[string]interface{}) *DefaultsComparison {
+	result := &DefaultsComparison{}
+
+	for key, field := range appConfig.Fields {
+		currentValue, hasCurrent := current[key]
+		if !hasCurrent {
+			continue
+		}
+
+		defaultStr := fmt.Sprintf("%v", field.Default)
+		switch currentStr := fmt.Sprintf("%v", currentValue); {
+		case field.Default != nil && defaultStr != "" && currentStr == defaultStr:
+			result.AtDefault = append(result.AtDefault, key)
+		case currentStr != defaultStr:
+			result.Changed = append(result.Changed, key)
+		}
+	}
+
+	sort.Strings(result.Changed)
+	sort.Strings(result.AtDefault)
+	return result
+}
@@ -0,0 +1,244 @@
+package appconfig
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	lru "github.com/hashicorp/golang-lru/v2"
+	"github.com/knadh/koanf/v2"
+
+	"github.com/mrtkrcm/ZeroUI/internal/errors"
+	"github.com/mrtkrcm/ZeroUI/internal/secrets"
+	"github.com/mrtkrcm/ZeroUI/pkg/configmerge"
+)
+
+// ExternalEditPolicy decides what a save does when the config changed on disk
+// after it was loaded, for example because it was edited in an editor
+type ExternalEditPolicy int
+
+const (
+	// MergeExternalEdits keeps the other program's changes and applies this
+	// save's changes on top, key by key. Keys changed differently on both
+	// sides are reported as conflicts and nothing is written.
+	MergeExternalEdits ExternalEditPolicy = iota
+	// AbortOnExternalEdits refuses to save over any external change
+	AbortOnExternalEdits
+)
+
+// maxReadSnapshots bounds how many loaded configs are remembered for
+// detecting external edits
+const maxReadSnapshots = 64
+
+// readSnapshot is what LoadTargetConfig returned and the hash of every file
+// it was read from, so a later save of the same values can tell whether the
+// files changed underneath it
+type readSnapshot struct {
+	values *koanf.Koanf
+	hashes map[string]string
+}
+
+// recordRead remembers the values returned for a load along with the hashes
+// of the files taken before they were parsed
+func (l *Loader) recordRead(k *koanf.Koanf, hashes map[string]string) {
+	l.readCache().Add(k, &readSnapshot{values: k.Copy(), hashes: hashes})
+}
+
+// readFor returns the snapshot taken when k was loaded, if any
+func (l *Loader) readFor(k *koanf.Koanf) *readSnapshot {
+	snapshot, _ := l.readCache().Get(k)
+	return snapshot
+}
+
+func (l *Loader) readCache() *lru.Cache[*koanf.Koanf, *readSnapshot] {
+	l.readsOnce.Do(func() {
+		l.reads, _ = lru.New[*koanf.Koanf, *readSnapshot](maxReadSnapshots)
+	})
+	return l.reads
+}
+
+// hashFiles hashes the files a config is stored in
+func hashFiles(paths []string) (map[string]string, error) {
+	hashes := make(map[string]string, len(paths))
+	for _, path := range paths {
+		hash, err := fileHash(path)
+		if err != nil {
+			return nil, err
+		}
+		hashes[path] = hash
+	}
+	return hashes, nil
+}
+
+// reconcileExternalEdits checks the files k was loaded from. When another
+// program changed them since, the change in k is merged onto the current
+// contents (or refused, per opts.ExternalEdits). It returns the values to
+// write and the options to write them with, carrying the hashes the files must
+// still have when the write is committed.
+func (l *Loader) reconcileExternalEdits(appConfig *AppConfig, configPath string, graph *IncludeGraph, k *koanf.Koanf, opts SaveOptions) (*koanf.Koanf, SaveOptions, error) {
+	snapshot := l.readFor(k)
+	if snapshot == nil {
+		// Not loaded through this loader; only the commit-time check applies
+		return k, opts, nil
+	}
+
+	current := make(map[string]string)
+	if graph != nil {
+		for _, f := range graph.Files {
+			current[f.Path] = f.Hash
+		}
+	} else {
+		hash, err := fileHash(configPath)
+		if err != nil {
+			return nil, opts, fmt.Errorf("failed to check %s: %w", configPath, err)
+		}
+		current[configPath] = hash
+	}
+	opts.expected = current
+
+	changed := changedFiles(snapshot.hashes, current)
+	if len(changed) == 0 {
+		return k, opts, nil
+	}
+	if opts.ExternalEdits == AbortOnExternalEdits {
+		return nil, opts, externalEditError(appConfig, changed)
+	}
+
+	var theirs *koanf.Koanf
+	if graph != nil {
+		theirs = graph.Merged()
+	} else {
+		var err error
+		if theirs, err = loadConfigFile(configPath, appConfig.Format); err != nil {
+			return nil, opts, fmt.Errorf("failed to reload %s: %w", configPath, err)
+		}
+	}
+
+	merged, conflicts := mergeThreeWay(snapshot.values, theirs, k)
+	if len(conflicts) > 0 {
+		return nil, opts, mergeConflictError(appConfig, changed, conflicts)
+	}
+
+	// Keys dropped by the merge must be removed explicitly from writers that
+	// keep lines they don't know about
+	removed := append([]string(nil), opts.RemoveKeys...)
+	for _, key := range theirs.Keys() {
+		if !merged.Exists(key) {
+			removed = append(removed, key)
+		}
+	}
+	opts.RemoveKeys = removed
+
+	return merged, opts, nil
+}
+
+// mergeThreeWay combines the changes from base to theirs and from base to
+// ours, key by key
+func mergeThreeWay(base, theirs, ours *koanf.Koanf) (*koanf.Koanf, []configmerge.Conflict) {
+	result := configmerge.Merge(base.All(), ours.All(), theirs.All(), configmerge.Options{ListIdentity: configmerge.KnownLists})
+
+	merged := koanf.New(".")
+	for key, value := range result.Merged {
+		_ = merged.Set(key, value)
+	}
+	return merged, result.Conflicts
+}
+
+// changedFiles lists the files whose hash differs between two snapshots,
+// including files that appeared or disappeared
+func changedFiles(before, after map[string]string) []string {
+	var changed []string
+	for path, hash := range after {
+		if old, ok := before[path]; !ok || old != hash {
+			changed = append(changed, path)
+		}
+	}
+	for path := range before {
+		if _, ok := after[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// fileHash returns the SHA-256 of a file, or "" when it doesn't exist
+func fileHash(path string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
+
+// checkExpectedHash refuses a write whose temporary copy was taken from a
+// different version of the file than the one the save was based on
+func checkExpectedHash(appConfig *AppConfig, opts SaveOptions, tempFile *TempFile) error {
+	want, ok := opts.expected[tempFile.OriginalPath]
+	if !ok || want == tempFile.OriginalHash {
+		return nil
+	}
+	return externalEditError(appConfig, []string{tempFile.OriginalPath})
+}
+
+// commitError reports a failed commit, as an external edit when the file was
+// changed between copying and committing it
+func commitError(appConfig *AppConfig, configPath string, err error) error {
+	if IsOriginalChanged(err) {
+		return externalEditError(appConfig, []string{configPath})
+	}
+	return fmt.Errorf("failed to commit changes: %w", err)
+}
+
+func externalEditError(appConfig *AppConfig, paths []string) error {
+	return errors.New(errors.ConfigConflict,
+		fmt.Sprintf("%s was changed by another program since it was read", strings.Join(paths, ", "))).
+		WithApp(appConfig.Name).
+		WithSuggestions(
+			"Nothing was written; the other program's changes are intact",
+			"Run the command again to apply the change to the current file",
+		)
+}
+
+func mergeConflictError(appConfig *AppConfig, paths []string, conflicts []configmerge.Conflict) error {
+	keys := (&configmerge.Result{Conflicts: conflicts}).ConflictKeys()
+	suggestions := make([]string, 0, len(conflicts)+1)
+	for _, c := range conflicts {
+		name := c.Key
+		if c.Entry != "" {
+			name += " " + c.Entry
+		}
+		suggestions = append(suggestions, fmt.Sprintf("%s: file now has %s, this change sets %s (was %s)",
+			name, describeEntry(c.Key, c.Theirs), describeEntry(c.Key, c.Ours), describeEntry(c.Key, c.Base)))
+	}
+	suggestions = append(suggestions, "Nothing was written; resolve the keys in the file or run the command again")
+
+	return errors.New(errors.ConfigConflict,
+		fmt.Sprintf("%s was changed by another program and the changes conflict on %s",
+			strings.Join(paths, ", "), strings.Join(keys, ", "))).
+		WithApp(appConfig.Name).
+		WithContext("conflicts", strings.Join(keys, ",")).
+		WithSuggestions(suggestions...)
+}
+
+// describeEntry prints a key's merge value, distinguishing unset keys and
+// masking secrets
+func describeEntry(key string, value interface{}) string {
+	if value == nil {
+		return "<unset>"
+	}
+	return fmt.Sprintf("%q", fmt.Sprintf("%v", secrets.Redact(key, value)))
+}
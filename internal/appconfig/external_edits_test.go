@@ -0,0 +1,179 @@
+package appconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrtkrcm/ZeroUI/internal/errors"
+)
+
+func TestSaveTargetConfig_ExternalEdits(t *testing.T) {
+	setup := func(t *testing.T, name, format, content string) (*Loader, *AppConfig, string) {
+		path := filepath.Join(t.TempDir(), name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return &Loader{}, &AppConfig{Name: "app", Path: path, Format: format}, path
+	}
+
+	t.Run("edits to other keys are kept", func(t *testing.T) {
+		loader, appConfig, path := setup(t, "config.yaml", "yaml", "size: 12\ncolor: red\n")
+		k, err := loader.LoadTargetConfig(appConfig)
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(path, []byte("size: 12\ncolor: blue\nshape: round\n"), 0o644))
+
+		require.NoError(t, k.Set("size", 14))
+		require.NoError(t, loader.SaveTargetConfig(appConfig, k))
+
+		saved, err := loadConfigFile(path, "yaml")
+		require.NoError(t, err)
+		assert.Equal(t, 14, saved.Int("size"))
+		assert.Equal(t, "blue", saved.String("color"))
+		assert.Equal(t, "round", saved.String("shape"))
+
+		// Saving the same values again builds on the merged file
+		require.NoError(t, k.Set("size", 16))
+		require.NoError(t, loader.SaveTargetConfig(appConfig, k))
+		saved, err = loadConfigFile(path, "yaml")
+		require.NoError(t, err)
+		assert.Equal(t, 16, saved.Int("size"))
+		assert.Equal(t, "blue", saved.String("color"), "the earlier external edit survives")
+	})
+
+	t.Run("line-preserving formats drop externally removed keys", func(t *testing.T) {
+		loader, appConfig, path := setup(t, "config", "custom", "font-size = 13\ntheme = light\n")
+		k, err := loader.LoadTargetConfig(appConfig)
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(path, []byte("font-size = 13\ncursor-style = block\n"), 0o644))
+
+		require.NoError(t, k.Set("font-size", "16"))
+		require.NoError(t, loader.SaveTargetConfig(appConfig, k))
+
+		content := readIncludeFile(t, path)
+		assert.Contains(t, content, "font-size = 16")
+		assert.Contains(t, content, "cursor-style = block")
+		assert.NotContains(t, content, "theme")
+	})
+
+	t.Run("conflicting edits are reported and nothing is written", func(t *testing.T) {
+		loader, appConfig, path := setup(t, "config.yaml", "yaml", "size: 12\ncolor: red\n")
+		k, err := loader.LoadTargetConfig(appConfig)
+		require.NoError(t, err)
+
+		external := "size: 13\ncolor: blue\n"
+		require.NoError(t, os.WriteFile(path, []byte(external), 0o644))
+
+		require.NoError(t, k.Set("size", 14))
+		require.NoError(t, k.Set("color", "blue"))
+		err = loader.SaveTargetConfig(appConfig, k)
+		require.Error(t, err)
+
+		zerr, ok := errors.GetZeroUIError(err)
+		require.True(t, ok, "expected a ZeroUIError, got %v", err)
+		assert.Equal(t, errors.ConfigConflict, zerr.Type)
+		assert.Equal(t, "size", zerr.Context["conflicts"], "identical changes on both sides don't conflict")
+		assert.Contains(t, zerr.Suggestions[0], `file now has "13", this change sets "14" (was "12")`)
+		assert.Equal(t, external, readIncludeFile(t, path))
+	})
+
+	t.Run("conflicting secrets are masked", func(t *testing.T) {
+		loader, appConfig, path := setup(t, "config.yaml", "yaml", "api_token: old-token-value\n")
+		k, err := loader.LoadTargetConfig(appConfig)
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(path, []byte("api_token: their-token-value\n"), 0o644))
+
+		require.NoError(t, k.Set("api_token", "our-token-value"))
+		err = loader.SaveTargetConfig(appConfig, k)
+		zerr, ok := errors.GetZeroUIError(err)
+		require.True(t, ok, "expected a ZeroUIError, got %v", err)
+		assert.Contains(t, zerr.Suggestions[0], `file now has "********", this change sets "********" (was "********")`)
+		assert.NotContains(t, zerr.Error(), "token-value")
+	})
+
+	t.Run("abort policy refuses any external edit", func(t *testing.T) {
+		loader, appConfig, path := setup(t, "config.yaml", "yaml", "size: 12\ncolor: red\n")
+		k, err := loader.LoadTargetConfig(appConfig)
+		require.NoError(t, err)
+
+		external := "size: 12\ncolor: blue\n"
+		require.NoError(t, os.WriteFile(path, []byte(external), 0o644))
+
+		require.NoError(t, k.Set("size", 14))
+		err = loader.SaveTargetConfigWithOptions(appConfig, k, SaveOptions{ExternalEdits: AbortOnExternalEdits})
+		zerr, ok := errors.GetZeroUIError(err)
+		require.True(t, ok, "expected a ZeroUIError, got %v", err)
+		assert.Equal(t, errors.ConfigConflict, zerr.Type)
+		assert.Equal(t, external, readIncludeFile(t, path))
+	})
+
+	t.Run("included files are checked too", func(t *testing.T) {
+		dir := t.TempDir()
+		writeIncludeFiles(t, dir, map[string]string{
+			"config":     "font-size = 13\nconfig-file = extra.conf\n",
+			"extra.conf": "theme = light\n",
+		})
+		loader := &Loader{}
+		appConfig := &AppConfig{Name: "ghostty", Path: filepath.Join(dir, "config"), Format: "custom"}
+
+		k, err := loader.LoadTargetConfig(appConfig)
+		require.NoError(t, err)
+
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "extra.conf"), []byte("theme = dark\n"), 0o644))
+
+		require.NoError(t, k.Set("theme", "solarized"))
+		err = loader.SaveTargetConfig(appConfig, k)
+		zerr, ok := errors.GetZeroUIError(err)
+		require.True(t, ok, "expected a ZeroUIError, got %v", err)
+		assert.Equal(t, "theme", zerr.Context["conflicts"])
+		assert.Equal(t, "theme = dark\n", readIncludeFile(t, filepath.Join(dir, "extra.conf")))
+	})
+}
+
+func TestCommitTemp_RefusesChangedOriginal(t *testing.T) {
+	manager, err := NewTempFileManagerWithOptions(&TempFileOptions{TempDir: t.TempDir(), MaxBackups: 1, BufferSize: 1024})
+	require.NoError(t, err)
+
+	path := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(path, []byte("a = 1\n"), 0o644))
+
+	tempFile, err := manager.CreateTempCopy(path)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(tempFile.TempPath, []byte("a = 2\n"), 0o644))
+
+	require.NoError(t, os.WriteFile(path, []byte("a = 3\n"), 0o644))
+
+	err = manager.CommitTemp(tempFile)
+	require.Error(t, err)
+	assert.True(t, IsOriginalChanged(err))
+	assert.Equal(t, "a = 3\n", readIncludeFile(t, path))
+}
+
+func TestSaveTargetFile_ChangedBeforeCopyIsKept(t *testing.T) {
+	loader := &Loader{}
+	path := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(path, []byte("font-size = 12\n"), 0o644))
+	appConfig := &AppConfig{Name: "app", Path: path, Format: "custom"}
+
+	// A successful save leaves an older copy behind as <config>.backup
+	k, err := loader.LoadTargetConfig(appConfig)
+	require.NoError(t, err)
+	require.NoError(t, k.Set("font-size", "13"))
+	require.NoError(t, loader.SaveTargetConfig(appConfig, k))
+	stale, err := fileHash(path)
+	require.NoError(t, err)
+
+	// The file changes after the save checked it but before it copied it
+	require.NoError(t, os.WriteFile(path, []byte("font-size = 20\n"), 0o644))
+
+	require.NoError(t, k.Set("font-size", "14"))
+	err = loader.saveTargetFile(appConfig, k, SaveOptions{expected: map[string]string{path: stale}})
+	zerr, ok := errors.GetZeroUIError(err)
+	require.True(t, ok, "expected a ZeroUI error, got %v", err)
+	assert.Equal(t, errors.ConfigConflict, zerr.Type)
+	assert.Equal(t, "font-size = 20\n", readIncludeFile(t, path), "the other program's edit is intact")
+}
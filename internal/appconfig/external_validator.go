@@ -0,0 +1,135 @@
+package appconfig
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/mrtkrcm/ZeroUI/internal/errors"
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
+	"github.com/mrtkrcm/ZeroUI/internal/secrets"
+)
+
+// defaultValidatorTimeout bounds an external validator without a timeout
+const defaultValidatorTimeout = 10 * time.Second
+
+// maxValidatorOutput limits how much of a validator's output is reported
+const maxValidatorOutput = 20
+
+// ExternalValidator is an app's own config checker, run against a changed
+// config before it replaces the real file. Args may use {file} for the file
+// to check and {original} for the real config path; without {file} the path
+// is appended. A non-zero exit rejects the change.
+//
+//	validator:
+//	  command: ghostty
+//	  args: ["+validate-config", "--config-file={file}"]
+type ExternalValidator struct {
+	Command string   `yaml:"command"`
+	Args    []string `yaml:"args,omitempty"`
+	Timeout string   `yaml:"timeout,omitempty"` // Go duration, default 10s
+}
+
+// runExternalValidator checks the pending contents of configPath, stored in
+// tempPath, with the app's validator. The file is checked from a copy next to
+// the real config so relative includes resolve, with the same extension for
+// tools that pick a parser from it.
+func runExternalValidator(appConfig *AppConfig, tempPath, configPath string) error {
+	v := appConfig.Validator
+	if v == nil || v.Command == "" {
+		return nil
+	}
+
+	timeout := defaultValidatorTimeout
+	if v.Timeout != "" {
+		parsed, err := time.ParseDuration(v.Timeout)
+		if err != nil {
+			return errors.Wrap(errors.ValidationError, "invalid validator timeout", err).
+				WithApp(appConfig.Name).
+				WithValue(v.Timeout)
+		}
+		timeout = parsed
+	}
+
+	checkPath, cleanup := validationCopy(tempPath, configPath)
+	defer cleanup()
+
+	args := make([]string, 0, len(v.Args)+1)
+	hasFile := false
+	for _, arg := range v.Args {
+		if strings.Contains(arg, "{file}") {
+			hasFile = true
+		}
+		arg = strings.ReplaceAll(arg, "{file}", checkPath)
+		args = append(args, strings.ReplaceAll(arg, "{original}", configPath))
+	}
+	if !hasFile {
+		args = append(args, checkPath)
+	}
+
+	// The validator runs on this machine, so a root doesn't apply to it
+	command := paths.ExpandHome(v.Command)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, command, args...)
+	cmd.Dir = filepath.Dir(configPath)
+	// Don't wait on children that outlive a killed validator and keep its output open
+	cmd.WaitDelay = time.Second
+	var output bytes.Buffer
+	cmd.Stdout = &output
+	cmd.Stderr = &output
+
+	if err := cmd.Run(); err != nil {
+		message := validatorOutput(output.String(), checkPath, configPath)
+		if ctx.Err() == context.DeadlineExceeded {
+			message = fmt.Sprintf("timed out after %s", timeout)
+		} else if message == "" {
+			message = err.Error()
+		}
+		return errors.New(errors.ValidationError, fmt.Sprintf("%s rejected the new config:\n%s", v.Command, message)).
+			WithApp(appConfig.Name).
+			WithSuggestions(
+				"The config file was not changed",
+				fmt.Sprintf("The validator is declared in the app definition: %s", strings.Join(append([]string{v.Command}, v.Args...), " ")),
+			)
+	}
+
+	return nil
+}
+
+// validationCopy copies the pending file next to the real config, falling back
+// to the temp file itself when that directory isn't writable
+func validationCopy(tempPath, configPath string) (string, func()) {
+	data, err := os.ReadFile(tempPath)
+	if err != nil {
+		return tempPath, func() {}
+	}
+
+	name := fmt.Sprintf(".%s.zeroui-validate-%d%s",
+		strings.TrimSuffix(filepath.Base(configPath), filepath.Ext(configPath)), os.Getpid(), filepath.Ext(configPath))
+	path := filepath.Join(filepath.Dir(configPath), name)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return tempPath, func() {}
+	}
+	return path, func() { os.Remove(path) }
+}
+
+// validatorOutput trims a validator's output, naming the real config instead
+// of the copy that was checked. Validators often quote the offending lines,
+// so secrets in them are masked.
+func validatorOutput(output, checkPath, configPath string) string {
+	output = strings.TrimSpace(strings.ReplaceAll(output, checkPath, configPath))
+	output = secrets.RedactText(output)
+	lines := strings.Split(output, "\n")
+	if len(lines) > maxValidatorOutput {
+		lines = append(lines[:maxValidatorOutput], fmt.Sprintf("... (%d more lines)", len(lines)-maxValidatorOutput))
+	}
+	return strings.Join(lines, "\n")
+}
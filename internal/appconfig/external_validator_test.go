@@ -0,0 +1,130 @@
+package appconfig
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+
+	"github.com/knadh/koanf/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// writeStubValidator writes an executable shell script standing in for an
+// app's validator
+func writeStubValidator(t *testing.T, script string) string {
+	t.Helper()
+	if runtime.GOOS == "windows" {
+		t.Skip("stub validators are shell scripts")
+	}
+	path := filepath.Join(t.TempDir(), "validator")
+	require.NoError(t, os.WriteFile(path, []byte("#!/bin/sh\n"+script), 0o755))
+	return path
+}
+
+func TestSaveTargetConfig_ExternalValidator(t *testing.T) {
+	loader := &Loader{}
+
+	setup := func(t *testing.T, name, content string, validator *ExternalValidator) (*AppConfig, string) {
+		path := filepath.Join(t.TempDir(), name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+		return &AppConfig{Name: "app", Path: path, Format: "custom", Validator: validator}, path
+	}
+
+	t.Run("accepted changes are committed", func(t *testing.T) {
+		stub := writeStubValidator(t, `case "$1" in --config-file=*) ;; *) exit 2 ;; esac
+grep -q "font-size = 16" "${1#--config-file=}" || { echo "font-size not updated"; exit 1; }
+`)
+		appConfig, path := setup(t, "config", "font-size = 13\n", &ExternalValidator{Command: stub, Args: []string{"--config-file={file}"}})
+
+		k := koanf.New(".")
+		require.NoError(t, k.Set("font-size", "16"))
+		require.NoError(t, loader.SaveTargetConfig(appConfig, k))
+
+		assert.Contains(t, readIncludeFile(t, path), "font-size = 16")
+		leftovers, _ := filepath.Glob(filepath.Join(filepath.Dir(path), ".*zeroui-validate*"))
+		assert.Empty(t, leftovers, "the validation copy is removed")
+	})
+
+	t.Run("rejected changes leave the file untouched", func(t *testing.T) {
+		stub := writeStubValidator(t, `echo "$1: font-size must be positive" >&2
+exit 1
+`)
+		appConfig, path := setup(t, "config", "font-size = 13\n", &ExternalValidator{Command: stub})
+
+		k := koanf.New(".")
+		require.NoError(t, k.Set("font-size", "-1"))
+		err := loader.SaveTargetConfig(appConfig, k)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), path+": font-size must be positive", "output names the real config")
+		assert.Equal(t, "font-size = 13\n", readIncludeFile(t, path))
+	})
+
+	t.Run("secrets in the output are masked", func(t *testing.T) {
+		stub := writeStubValidator(t, `grep api-key "$1"; exit 1
+`)
+		appConfig, _ := setup(t, "config", "api-key = hunter2-hunter2\n", &ExternalValidator{Command: stub})
+
+		k := koanf.New(".")
+		require.NoError(t, k.Set("font-size", "14"))
+		err := loader.SaveTargetConfig(appConfig, k)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "api-key = ********")
+		assert.NotContains(t, err.Error(), "hunter2")
+	})
+
+	t.Run("rejected changes keep the last good write", func(t *testing.T) {
+		// Rejects any value but 14, so the first save passes and the second fails
+		stub := writeStubValidator(t, `grep -q "font-size = 14" "$1" || { echo "font-size must be 14"; exit 1; }
+`)
+		appConfig, path := setup(t, "config", "font-size = 13\n", &ExternalValidator{Command: stub})
+
+		k := koanf.New(".")
+		require.NoError(t, k.Set("font-size", "14"))
+		require.NoError(t, loader.SaveTargetConfig(appConfig, k))
+		require.Contains(t, readIncludeFile(t, path), "font-size = 14")
+
+		require.NoError(t, k.Set("font-size", "15"))
+		require.Error(t, loader.SaveTargetConfig(appConfig, k))
+		assert.Contains(t, readIncludeFile(t, path), "font-size = 14", "not reverted to an earlier version")
+
+		require.Error(t, loader.SaveTargetData(appConfig, []byte("font-size = 15\n")))
+		assert.Contains(t, readIncludeFile(t, path), "font-size = 14", "not reverted to an earlier version")
+	})
+
+	t.Run("checked copy keeps the config's extension and directory", func(t *testing.T) {
+		stub := writeStubValidator(t, `case "$1" in *.yaml) ;; *) echo "not yaml: $1"; exit 1 ;; esac
+[ "$(dirname "$1")" = "$(dirname "$2")" ] || { echo "wrong dir"; exit 1; }
+`)
+		appConfig, path := setup(t, "config.yaml", "size: 12\n", &ExternalValidator{Command: stub, Args: []string{"{file}", "{original}"}})
+		appConfig.Format = "yaml"
+
+		k := koanf.New(".")
+		require.NoError(t, k.Set("size", 14))
+		require.NoError(t, loader.SaveTargetConfig(appConfig, k))
+		assert.Contains(t, readIncludeFile(t, path), "size: 14")
+	})
+
+	t.Run("raw writes are validated too", func(t *testing.T) {
+		stub := writeStubValidator(t, "echo rejected; exit 1\n")
+		appConfig, path := setup(t, "config", "font-size = 13\n", &ExternalValidator{Command: stub})
+
+		err := loader.SaveTargetData(appConfig, []byte("font-size = 20\n"))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "rejected")
+		assert.Equal(t, "font-size = 13\n", readIncludeFile(t, path))
+	})
+
+	t.Run("slow validators time out", func(t *testing.T) {
+		stub := writeStubValidator(t, "sleep 5\n")
+		appConfig, path := setup(t, "config", "font-size = 13\n", &ExternalValidator{Command: stub, Timeout: "100ms"})
+
+		k := koanf.New(".")
+		require.NoError(t, k.Set("font-size", "14"))
+		err := loader.SaveTargetConfig(appConfig, k)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "timed out")
+		assert.Equal(t, "font-size = 13\n", readIncludeFile(t, path))
+	})
+}
@@ -0,0 +1,384 @@
+package appconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// FormatConfigData normalizes the layout of a config file. Formatting is
+// idempotent and keeps comments:
+//   - custom (Ghostty-style key = value): one space around "=", no indentation
+//     or trailing whitespace, single blank lines, and redundant repeats of a
+//     key's current value dropped
+//   - json: two-space indentation, key order kept; comments (JSONC) and
+//     trailing commas are kept
+//   - yaml: two-space indentation via a comment-preserving node round trip
+//   - toml: one space around "=" for bare keys, trailing whitespace removed
+//     and single blank lines; multi-line strings and arrays are left as written
+func FormatConfigData(format string, data []byte) ([]byte, error) {
+	if len(bytes.TrimSpace(data)) == 0 {
+		return []byte{}, nil
+	}
+
+	switch strings.ToLower(format) {
+	case "custom":
+		return formatKeyValueData(data), nil
+	case "json":
+		return formatJSONData(data)
+	case "yaml", "yml":
+		return formatYAMLData(data)
+	case "toml":
+		return formatTOMLData(data), nil
+	default:
+		return nil, fmt.Errorf("formatting is not supported for %q configs", format)
+	}
+}
+
+// formatKeyValueData formats Ghostty-style key = value files. A repeated line
+// is only dropped when it sets the value the key already has, so the result is
+// the same whether the app treats the key as a list or as last-one-wins.
+func formatKeyValueData(data []byte) []byte {
+	latest := make(map[string]string)
+	var out []string
+	pendingBlank := false
+
+	for _, raw := range splitLines(data) {
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			pendingBlank = len(out) > 0
+			continue
+		}
+
+		if !strings.HasPrefix(line, "#") {
+			if key, value, ok := strings.Cut(line, "="); ok && strings.TrimSpace(key) != "" {
+				key, value = strings.TrimSpace(key), strings.TrimSpace(value)
+				if prev, seen := latest[key]; seen && prev == value {
+					continue
+				}
+				latest[key] = value
+				line = key + " = " + value
+				if value == "" {
+					// "key =" resets a setting to its default
+					line = key + " ="
+				}
+			}
+		}
+
+		if pendingBlank {
+			out = append(out, "")
+			pendingBlank = false
+		}
+		out = append(out, line)
+	}
+
+	return joinLines(out)
+}
+
+// jsonToken is a token of a JSON file with comments (JSONC)
+type jsonToken struct {
+	text string
+	// newlines counts the line breaks between the previous token and this one
+	newlines int
+}
+
+func (t jsonToken) isComment() bool {
+	return strings.HasPrefix(t.text, "//") || strings.HasPrefix(t.text, "/*")
+}
+
+// tokenizeJSONC splits JSON into strings, scalars, punctuation and comments
+func tokenizeJSONC(data []byte) ([]jsonToken, error) {
+	var tokens []jsonToken
+	newlines := 0
+	for i := 0; i < len(data); {
+		c := data[i]
+		start := i
+		switch {
+		case c == '\n':
+			newlines++
+			i++
+			continue
+		case c == ' ' || c == '\t' || c == '\r':
+			i++
+			continue
+		case c == '"':
+			for i++; i < len(data) && data[i] != '"'; i++ {
+				if data[i] == '\\' {
+					i++
+				}
+			}
+			if i >= len(data) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			i++
+		case bytes.HasPrefix(data[i:], []byte("//")):
+			end := bytes.IndexByte(data[i:], '\n')
+			if end < 0 {
+				end = len(data) - i
+			}
+			i += end
+		case bytes.HasPrefix(data[i:], []byte("/*")):
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end < 0 {
+				return nil, fmt.Errorf("unterminated comment")
+			}
+			i += end + 4
+		case strings.IndexByte("{}[],:", c) >= 0:
+			i++
+		default:
+			for i < len(data) && strings.IndexByte("{}[],:\" \t\r\n/", data[i]) < 0 {
+				i++
+			}
+			if i == start {
+				return nil, fmt.Errorf("invalid character %q", c)
+			}
+		}
+		text := string(data[start:i])
+		if strings.HasPrefix(text, "//") {
+			text = strings.TrimRight(text, " \t\r")
+		}
+		tokens = append(tokens, jsonToken{text: text, newlines: newlines})
+		newlines = 0
+	}
+	return tokens, nil
+}
+
+// formatJSONData re-indents JSON without reordering keys. Comments, trailing
+// commas and single blank lines between members are kept, as editors like Zed
+// write settings as JSON with comments.
+func formatJSONData(data []byte) ([]byte, error) {
+	tokens, err := tokenizeJSONC(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to format JSON: %w", err)
+	}
+
+	// Check the document is valid once comments and trailing commas are gone
+	var plain []string
+	for i, tok := range tokens {
+		if tok.isComment() {
+			continue
+		}
+		if tok.text == "," {
+			if next := nextJSONToken(tokens, i); next == "}" || next == "]" {
+				continue
+			}
+		}
+		plain = append(plain, tok.text)
+	}
+	var v interface{}
+	if err := json.Unmarshal([]byte(strings.Join(plain, " ")), &v); err != nil {
+		return nil, fmt.Errorf("failed to format JSON: %w", err)
+	}
+
+	var buf bytes.Buffer
+	depth := 0
+	// breakLine holds the line break owed before the next token
+	breakLine := false
+	newline := func(blank bool) {
+		if buf.Len() > 0 {
+			buf.WriteByte('\n')
+			if blank {
+				buf.WriteByte('\n')
+			}
+		}
+		buf.WriteString(strings.Repeat("  ", depth))
+		breakLine = false
+	}
+
+	for i, tok := range tokens {
+		blank := tok.newlines > 1 && i > 0 && tokens[i-1].text != "{" && tokens[i-1].text != "["
+		switch {
+		case tok.isComment() && tok.newlines == 0 && i > 0:
+			// A comment on the line of the token before it stays there
+			buf.WriteString(" " + tok.text)
+			breakLine = breakLine || strings.HasPrefix(tok.text, "//")
+		case tok.isComment():
+			newline(blank)
+			buf.WriteString(tok.text)
+			breakLine = true
+		case tok.text == "{" || tok.text == "[":
+			if breakLine {
+				newline(blank)
+			}
+			buf.WriteString(tok.text)
+			if close := nextJSONToken(tokens, i); (close == "}" || close == "]") && !tokens[i+1].isComment() {
+				continue
+			}
+			depth++
+			breakLine = true
+		case tok.text == "}" || tok.text == "]":
+			if prev := tokens[i-1]; prev.text == "{" || prev.text == "[" {
+				buf.WriteString(tok.text)
+				continue
+			}
+			depth--
+			newline(false)
+			buf.WriteString(tok.text)
+		case tok.text == ",":
+			buf.WriteString(",")
+			breakLine = true
+		case tok.text == ":":
+			buf.WriteString(": ")
+		default:
+			if breakLine {
+				newline(blank)
+			}
+			buf.WriteString(tok.text)
+		}
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// nextJSONToken returns the text of the first token after i that isn't a
+// comment, or "" at the end
+func nextJSONToken(tokens []jsonToken, i int) string {
+	for _, tok := range tokens[i+1:] {
+		if !tok.isComment() {
+			return tok.text
+		}
+	}
+	return ""
+}
+
+// formatYAMLData re-encodes every document through yaml.Node, which keeps
+// comments, key order and scalar styles
+func formatYAMLData(data []byte) ([]byte, error) {
+	dec := yamlv3.NewDecoder(bytes.NewReader(data))
+
+	var buf bytes.Buffer
+	enc := yamlv3.NewEncoder(&buf)
+	enc.SetIndent(2)
+
+	for {
+		var doc yamlv3.Node
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to format YAML: %w", err)
+		}
+		if err := enc.Encode(&doc); err != nil {
+			return nil, fmt.Errorf("failed to format YAML: %w", err)
+		}
+	}
+
+	if err := enc.Close(); err != nil {
+		return nil, fmt.Errorf("failed to format YAML: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// tomlBareKey matches bare and dotted TOML keys
+var tomlBareKey = regexp.MustCompile(`^[A-Za-z0-9_.-]+$`)
+
+// formatTOMLData normalizes TOML line by line. Lines inside multi-line strings
+// are copied verbatim; continuation lines of multi-line arrays only lose
+// trailing whitespace.
+func formatTOMLData(data []byte) []byte {
+	var out []string
+	pendingBlank := false
+	stringDelim := "" // delimiter of the open multi-line string, if any
+	arrayDepth := 0
+
+	for _, raw := range splitLines(data) {
+		if stringDelim != "" {
+			out = append(out, raw)
+			if strings.Count(raw, stringDelim)%2 == 1 {
+				stringDelim = ""
+			}
+			continue
+		}
+
+		if arrayDepth > 0 {
+			line := strings.TrimRight(raw, " \t")
+			out = append(out, line)
+			arrayDepth += tomlBracketDepth(line)
+			continue
+		}
+
+		line := strings.TrimSpace(raw)
+		if line == "" {
+			pendingBlank = len(out) > 0
+			continue
+		}
+
+		if !strings.HasPrefix(line, "#") && !strings.HasPrefix(line, "[") {
+			if key, value, ok := strings.Cut(line, "="); ok && tomlBareKey.MatchString(strings.TrimSpace(key)) {
+				value = strings.TrimSpace(value)
+				line = strings.TrimSpace(key) + " = " + value
+
+				for _, delim := range []string{`"""`, `'''`} {
+					if strings.Count(value, delim)%2 == 1 {
+						stringDelim = delim
+						break
+					}
+				}
+				if stringDelim == "" {
+					arrayDepth = tomlBracketDepth(value)
+					if arrayDepth < 0 {
+						arrayDepth = 0
+					}
+				}
+			}
+		}
+
+		if pendingBlank {
+			out = append(out, "")
+			pendingBlank = false
+		}
+		out = append(out, line)
+	}
+
+	return joinLines(out)
+}
+
+// tomlBracketDepth returns the change in array nesting on a line, ignoring
+// brackets inside strings and comments
+func tomlBracketDepth(line string) int {
+	depth := 0
+	var quote rune
+	escaped := false
+
+	for _, r := range line {
+		switch {
+		case escaped:
+			escaped = false
+		case quote != 0:
+			if r == '\\' && quote == '"' {
+				escaped = true
+			} else if r == quote {
+				quote = 0
+			}
+		case r == '"' || r == '\'':
+			quote = r
+		case r == '#':
+			return depth
+		case r == '[':
+			depth++
+		case r == ']':
+			depth--
+		}
+	}
+	return depth
+}
+
+// splitLines splits file contents into lines, accepting CRLF line endings
+func splitLines(data []byte) []string {
+	text := strings.ReplaceAll(string(data), "\r\n", "\n")
+	return strings.Split(strings.TrimRight(text, "\n"), "\n")
+}
+
+// joinLines joins lines with a trailing newline
+func joinLines(lines []string) []byte {
+	if len(lines) == 0 {
+		return []byte{}
+	}
+	return []byte(strings.Join(lines, "\n") + "\n")
+}
@@ -0,0 +1,105 @@
+package appconfig
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormatConfigData(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		input  string
+		want   string
+	}{
+		{
+			name:   "key value spacing, blank lines and redundant repeats",
+			format: "custom",
+			input:  "# Font\n\n\n   font-family=Iosevka   \nfont-size   =13\nfont-size = 13\nkeybind = ctrl+a=copy\ntheme =\n\n",
+			want:   "# Font\n\nfont-family = Iosevka\nfont-size = 13\nkeybind = ctrl+a=copy\ntheme =\n",
+		},
+		{
+			name:   "repeats that change the value are kept",
+			format: "custom",
+			input:  "font-size = 12\nfont-size = 14\nfont-size = 12\n",
+			want:   "font-size = 12\nfont-size = 14\nfont-size = 12\n",
+		},
+		{
+			name:   "json indentation keeps key order",
+			format: "json",
+			input:  `{"b": 1,   "a": {"c": [1,2]}}`,
+			want:   "{\n  \"b\": 1,\n  \"a\": {\n    \"c\": [\n      1,\n      2\n    ]\n  }\n}\n",
+		},
+		{
+			name:   "json with comments and trailing commas",
+			format: "json",
+			input:  "// Zed settings\n{\n    \"theme\": \"One Dark\", // dark\n\n\n  /* fonts */\n  \"buffer_font_size\":15,\n  \"languages\": {},\n\"url\": \"http://x\",\n}\n",
+			want:   "// Zed settings\n{\n  \"theme\": \"One Dark\", // dark\n\n  /* fonts */\n  \"buffer_font_size\": 15,\n  \"languages\": {},\n  \"url\": \"http://x\",\n}\n",
+		},
+		{
+			name:   "yaml keeps comments",
+			format: "yaml",
+			input:  "# top\nroot:\n    a: 1 # inline\n    # before b\n    b: two\nlist:\n- x\n",
+			want:   "# top\nroot:\n  a: 1 # inline\n  # before b\n  b: two\nlist:\n  - x\n",
+		},
+		{
+			name:   "toml leaves multi-line values alone",
+			format: "toml",
+			input:  "# c\ntitle=\"x\"   \n\n\n[server]\n  port=80\nhosts = [\n  \"a\",  \n  \"b\" ]\ndesc = \"\"\"\n  keep   \n\"\"\"\n\"quoted key\"=1\n",
+			want:   "# c\ntitle = \"x\"\n\n[server]\nport = 80\nhosts = [\n  \"a\",\n  \"b\" ]\ndesc = \"\"\"\n  keep   \n\"\"\"\n\"quoted key\"=1\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := FormatConfigData(tt.format, []byte(tt.input))
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, string(got))
+
+			again, err := FormatConfigData(tt.format, got)
+			require.NoError(t, err)
+			assert.Equal(t, string(got), string(again), "formatting should be idempotent")
+		})
+	}
+
+	_, err := FormatConfigData("ini", []byte("a=1"))
+	assert.Error(t, err)
+	_, err = FormatConfigData("json", []byte("{"))
+	assert.Error(t, err)
+}
+
+func TestLineDiff(t *testing.T) {
+	diff := LineDiff([]byte("a\nb\nc\nd\ne\n"), []byte("a\nB\nc\nd\ne\nf\n"))
+
+	var ops strings.Builder
+	for _, line := range diff {
+		ops.WriteByte(byte(line.Op))
+	}
+	assert.Equal(t, " -+   +", ops.String())
+
+	assert.Equal(t, "  a\n- b\n+ B\n  c\n@@\n  e\n+ f\n", FormatLineDiff(diff, 1))
+}
+
+func TestCompareWithDefaults(t *testing.T) {
+	appConfig := &AppConfig{Fields: map[string]FieldConfig{
+		"font-size": {Default: 14},
+		"theme":     {Default: "dark"},
+		"opacity":   {Default: ""},
+		"cursor":    {},
+		"unset":     {Default: "block"},
+	}}
+
+	result := CompareWithDefaults(appConfig, map[string]interface{}{
+		"font-size": "14",
+		"theme":     "light",
+		"opacity":   "0.9",
+		"cursor":    "bar",
+		"other":     "x",
+	})
+
+	assert.Equal(t, []string{"cursor", "opacity", "theme"}, result.Changed)
+	assert.Equal(t, []string{"font-size"}, result.AtDefault)
+}
@@ -0,0 +1,736 @@
+package appconfig
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/knadh/koanf/v2"
+	"github.com/mrtkrcm/ZeroUI/internal/errors"
+	"github.com/mrtkrcm/ZeroUI/internal/safefile"
+)
+
+// Include dialects understood by LoadIncludeGraph
+const (
+	IncludeGhostty   = "ghostty"   // config-file = path, "?" marks optional files
+	IncludeAlacritty = "alacritty" // import = [...] or [general] import = [...]
+	IncludeKitty     = "kitty"     // include path, globinclude pattern
+	IncludeTmux      = "tmux"      // source-file [-q] path...
+	IncludeGit       = "git"       // [include] / [includeIf "..."] path = ...
+)
+
+// Include write targets
+const (
+	WriteToDefining = "defining"
+	WriteToRoot     = "root"
+)
+
+// IncludeConfig controls how an app's config files include each other
+type IncludeConfig struct {
+	// Dialect selects the include directive syntax. Defaults to the app name
+	// when it is a known dialect.
+	Dialect string `yaml:"dialect,omitempty"`
+	// WriteTo selects where changed keys are written: "defining" (default)
+	// writes each key to the file that sets it and new keys to the main
+	// config, "root" writes everything to the main config, and any other
+	// value is a file of the include graph, relative to the main config.
+	WriteTo string `yaml:"write_to,omitempty"`
+	// Disabled reads and writes only the main config file
+	Disabled bool `yaml:"disabled,omitempty"`
+}
+
+// IncludeDialect returns the include dialect of the app's config, or "" when
+// includes are not followed
+func (a *AppConfig) IncludeDialect() string {
+	if a.Includes != nil {
+		if a.Includes.Disabled {
+			return ""
+		}
+		if a.Includes.Dialect != "" {
+			return strings.ToLower(a.Includes.Dialect)
+		}
+	}
+	switch name := strings.ToLower(a.Name); name {
+	case IncludeGhostty, IncludeAlacritty, IncludeKitty, IncludeTmux, IncludeGit:
+		return name
+	}
+	return ""
+}
+
+// IncludeFile is one config file of an include graph
+type IncludeFile struct {
+	Path   string
+	Format string
+	// Parent is the including file and Line the directive's line in it; both
+	// are empty for the main config
+	Parent string
+	Line   int
+	// Optional files may be missing; Conditional files (git includeIf) depend
+	// on runtime state and are not part of the merged view
+	Optional    bool
+	Conditional bool
+
+	Values  *koanf.Koanf
+	Sources SourceMap
+	// Hash is the SHA-256 of the file as it was read
+	Hash string
+
+	children []*IncludeFile
+}
+
+// IncludeOccurrence is a value a file sets for a key
+type IncludeOccurrence struct {
+	File  *IncludeFile
+	Line  int
+	Value interface{}
+}
+
+// IncludeGraph is a main config file and every file it includes
+type IncludeGraph struct {
+	Root *IncludeFile
+	// Files lists every loaded file in discovery order, the main config first
+	Files []*IncludeFile
+	// Warnings reports includes that could not be followed
+	Warnings []string
+
+	dialect     string
+	occurrences map[string][]IncludeOccurrence // load order
+}
+
+// includeDirective is an include found in a config file
+type includeDirective struct {
+	target      string
+	line        int
+	optional    bool
+	conditional bool
+	glob        bool
+}
+
+// LoadIncludeGraph loads an app's main config file and, following its
+// include dialect, every file it includes
+func (l *Loader) LoadIncludeGraph(appConfig *AppConfig) (*IncludeGraph, error) {
+	rootPath, err := ExpandTargetPath(appConfig.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	root, err := loadIncludeFile(rootPath, appConfig.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	graph := &IncludeGraph{Root: root, Files: []*IncludeFile{root}, dialect: appConfig.IncludeDialect()}
+	switch graph.dialect {
+	case "":
+	case IncludeGhostty, IncludeAlacritty, IncludeKitty, IncludeTmux, IncludeGit:
+		graph.follow(root, appConfig.Format, map[string]bool{rootPath: true})
+	default:
+		return nil, fmt.Errorf("unsupported include dialect: %s", graph.dialect)
+	}
+
+	graph.occurrences = make(map[string][]IncludeOccurrence)
+	graph.index(root)
+	return graph, nil
+}
+
+// ghosttyListKeys are the Ghostty keys whose lines add to a list rather than
+// replace the previous value; "key =" with no value clears the list
+var ghosttyListKeys = map[string]bool{
+	"font-codepoint-map":      true,
+	"font-family":             true,
+	"font-family-bold":        true,
+	"font-family-bold-italic": true,
+	"font-family-italic":      true,
+	"font-feature":            true,
+	"font-variation":          true,
+	"keybind":                 true,
+	"palette":                 true,
+}
+
+// Merged returns the effective configuration across all files. A key set in
+// several files takes the last value, except Ghostty list keys, whose entries
+// are concatenated in load order.
+func (g *IncludeGraph) Merged() *koanf.Koanf {
+	if len(g.Files) == 1 {
+		return g.Root.Values
+	}
+
+	k := koanf.New(".")
+	for _, key := range g.Keys() {
+		occurrences := g.occurrences[key]
+		if !g.isListKey(key) {
+			_ = k.Set(key, occurrences[len(occurrences)-1].Value)
+			continue
+		}
+		if entries := listValue(occurrences); len(entries) > 0 {
+			_ = k.Set(key, collapseList(entries))
+		}
+	}
+	return k
+}
+
+// isListKey reports whether the files' values for key add up to a list
+func (g *IncludeGraph) isListKey(key string) bool {
+	return g.dialect == IncludeGhostty && ghosttyListKeys[key]
+}
+
+// listValue concatenates the entries the occurrences set for a list key,
+// starting over at an empty entry
+func listValue(occurrences []IncludeOccurrence) []interface{} {
+	var list []interface{}
+	for _, o := range occurrences {
+		for _, entry := range listEntries(o.Value) {
+			if strings.TrimSpace(fmt.Sprint(entry)) == "" {
+				list = nil
+				continue
+			}
+			list = append(list, entry)
+		}
+	}
+	return list
+}
+
+// listContribution returns what f must set for a list key so the merged list
+// becomes value: the entries after those the files loading before it set, or
+// an empty entry clearing those followed by the whole list when some of them
+// were dropped. It is nil when f adds nothing.
+func (g *IncludeGraph) listContribution(key string, f *IncludeFile, value interface{}) interface{} {
+	occurrences := g.occurrences[key]
+	at := len(occurrences)
+	for i, o := range occurrences {
+		if o.File == f {
+			at = i
+			break
+		}
+	}
+	before := listValue(occurrences[:at])
+	var after []interface{}
+	if at < len(occurrences) {
+		after = listValue(occurrences[at+1:])
+	}
+
+	entries := listEntries(value)
+	if n := len(entries) - len(after); n >= 0 && sameEntries(entries[n:], after) {
+		entries = entries[:n]
+	}
+	if len(entries) >= len(before) && sameEntries(entries[:len(before)], before) {
+		entries = entries[len(before):]
+	} else {
+		entries = append([]interface{}{""}, entries...)
+	}
+	if len(entries) == 0 {
+		return nil
+	}
+	return collapseList(entries)
+}
+
+// listEntries returns the entries of a list value; other values are a list of one
+func listEntries(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		return v
+	case []string:
+		entries := make([]interface{}, len(v))
+		for i, entry := range v {
+			entries[i] = entry
+		}
+		return entries
+	}
+	return []interface{}{value}
+}
+
+// collapseList returns a single entry on its own, as the parser does
+func collapseList(entries []interface{}) interface{} {
+	if len(entries) == 1 {
+		return entries[0]
+	}
+	return entries
+}
+
+func sameEntries(a, b []interface{}) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if fmt.Sprint(a[i]) != fmt.Sprint(b[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// Keys returns every key set by the merged files, sorted
+func (g *IncludeGraph) Keys() []string {
+	keys := make([]string, 0, len(g.occurrences))
+	for key := range g.occurrences {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// Occurrences returns the values files set for key, highest precedence first
+func (g *IncludeGraph) Occurrences(key string) []IncludeOccurrence {
+	occurrences := g.occurrences[key]
+	result := make([]IncludeOccurrence, 0, len(occurrences))
+	for i := len(occurrences) - 1; i >= 0; i-- {
+		result = append(result, occurrences[i])
+	}
+	return result
+}
+
+// DefiningFile returns the file whose value for key takes effect, or nil
+func (g *IncludeGraph) DefiningFile(key string) *IncludeFile {
+	occurrences := g.occurrences[key]
+	if len(occurrences) == 0 {
+		return nil
+	}
+	return occurrences[len(occurrences)-1].File
+}
+
+// File returns the loaded file at path, or nil
+func (g *IncludeGraph) File(path string) *IncludeFile {
+	path = filepath.Clean(path)
+	for _, f := range g.Files {
+		if f.Path == path {
+			return f
+		}
+	}
+	return nil
+}
+
+// follow loads the files included by file, depth first
+func (g *IncludeGraph) follow(file *IncludeFile, format string, stack map[string]bool) {
+	directives, err := g.findIncludes(file)
+	if err != nil {
+		g.Warnings = append(g.Warnings, fmt.Sprintf("%s: cannot read includes: %v", file.Path, err))
+		return
+	}
+
+	for _, d := range directives {
+		for _, path := range resolveIncludePaths(file.Path, d.target, d.glob) {
+			if stack[path] {
+				g.Warnings = append(g.Warnings, fmt.Sprintf("%s:%d: %s is already being loaded (include cycle)", file.Path, d.line, path))
+				continue
+			}
+			if g.File(path) != nil {
+				g.Warnings = append(g.Warnings, fmt.Sprintf("%s:%d: %s is included more than once, later includes are ignored", file.Path, d.line, path))
+				continue
+			}
+
+			if _, err := os.Stat(path); err != nil {
+				if !d.optional || !os.IsNotExist(err) {
+					g.Warnings = append(g.Warnings, fmt.Sprintf("%s:%d: cannot load %s: %v", file.Path, d.line, path, err))
+				}
+				continue
+			}
+
+			included, err := loadIncludeFile(path, includeFormat(path, format))
+			if err != nil {
+				g.Warnings = append(g.Warnings, fmt.Sprintf("%s:%d: cannot load %s: %v", file.Path, d.line, path, err))
+				continue
+			}
+			included.Parent = file.Path
+			included.Line = d.line
+			included.Optional = d.optional
+			included.Conditional = d.conditional || file.Conditional
+			if included.Conditional {
+				g.Warnings = append(g.Warnings, fmt.Sprintf("%s:%d: %s is included conditionally and not applied", file.Path, d.line, path))
+			}
+
+			g.Files = append(g.Files, included)
+			file.children = append(file.children, included)
+
+			stack[path] = true
+			g.follow(included, format, stack)
+			delete(stack, path)
+		}
+	}
+}
+
+// index records the values of file and its includes in load order. Ghostty
+// loads included files after the including one, Alacritty before it and the
+// other dialects at the directive's line.
+func (g *IncludeGraph) index(file *IncludeFile) {
+	if file.Conditional {
+		return
+	}
+
+	values := file.Values.All()
+	line := func(key string) int {
+		if pos, ok := file.Sources.Last(key); ok {
+			return pos.Line
+		}
+		return 0
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if li, lj := line(keys[i]), line(keys[j]); li != lj {
+			return li < lj
+		}
+		return keys[i] < keys[j]
+	})
+
+	next := 0
+	for _, key := range keys {
+		for next < len(file.children) && g.loadsBefore(file.children[next], line(key)) {
+			g.index(file.children[next])
+			next++
+		}
+		g.occurrences[key] = append(g.occurrences[key], IncludeOccurrence{File: file, Line: line(key), Value: values[key]})
+	}
+	for ; next < len(file.children); next++ {
+		g.index(file.children[next])
+	}
+}
+
+// loadsBefore reports whether an included file loads before a key set on
+// line of the including file
+func (g *IncludeGraph) loadsBefore(child *IncludeFile, line int) bool {
+	switch g.dialect {
+	case IncludeGhostty:
+		return false
+	case IncludeAlacritty:
+		return true
+	default:
+		return child.Line < line
+	}
+}
+
+// writeTarget returns the file configured by IncludeConfig.WriteTo, or nil
+// to write keys to the file that defines them
+func (g *IncludeGraph) writeTarget(appConfig *AppConfig) (*IncludeFile, error) {
+	writeTo := ""
+	if appConfig.Includes != nil {
+		writeTo = appConfig.Includes.WriteTo
+	}
+
+	switch writeTo {
+	case "", WriteToDefining:
+		return nil, nil
+	case WriteToRoot:
+		return g.Root, nil
+	}
+
+	path, err := ExpandTargetPath(writeTo)
+	if err != nil {
+		return nil, err
+	}
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(g.Root.Path), path)
+	}
+	target := g.File(path)
+	if target == nil || target.Conditional {
+		return nil, fmt.Errorf("include write target %s is not loaded by %s", path, g.Root.Path)
+	}
+	return target, nil
+}
+
+// saveIncludeGraph writes the changes between the graph's merged view and k
+// back to the files of the graph
+func (l *Loader) saveIncludeGraph(appConfig *AppConfig, graph *IncludeGraph, k *koanf.Koanf, opts SaveOptions) error {
+	target, err := graph.writeTarget(appConfig)
+	if err != nil {
+		return err
+	}
+
+	current := graph.Merged().All()
+	updated := k.All()
+
+	edits := make(map[*IncludeFile]*koanf.Koanf)
+	removed := make(map[*IncludeFile][]string)
+	edit := func(f *IncludeFile) *koanf.Koanf {
+		if edits[f] == nil {
+			edits[f] = f.Values.Copy()
+		}
+		return edits[f]
+	}
+
+	for key, value := range updated {
+		if old, ok := current[key]; ok && fmt.Sprintf("%v", old) == fmt.Sprintf("%v", value) {
+			continue
+		}
+		f := target
+		if f == nil {
+			if f = graph.DefiningFile(key); f == nil {
+				f = graph.Root
+			}
+		}
+		if graph.isListKey(key) {
+			// Entries the other files set stay in those files
+			if value = graph.listContribution(key, f, value); value == nil {
+				edit(f).Delete(key)
+				removed[f] = append(removed[f], key)
+				continue
+			}
+		}
+		if err := edit(f).Set(key, value); err != nil {
+			return fmt.Errorf("failed to set %s in %s: %w", key, f.Path, err)
+		}
+	}
+
+	removals := append([]string(nil), opts.RemoveKeys...)
+	for key := range current {
+		if _, ok := updated[key]; !ok {
+			removals = append(removals, key)
+		}
+	}
+	for _, key := range removals {
+		for _, occurrence := range graph.occurrences[key] {
+			f := occurrence.File
+			edit(f).Delete(key)
+			removed[f] = append(removed[f], key)
+		}
+	}
+
+	// Every file being changed stays locked until all are written, taken in
+	// load order so concurrent writers can't deadlock
+	for _, f := range graph.Files {
+		if _, ok := edits[f]; !ok {
+			continue
+		}
+		lock, err := lockTarget(appConfig, f.Path)
+		if err != nil {
+			return err
+		}
+		defer lock.Release()
+	}
+
+	// Files already written are restored if a later one fails; any that
+	// can't be are named in the returned error
+	originals := make(map[string][]byte)
+	fail := func(err error) error {
+		var unrestored []string
+		for path, data := range originals {
+			if werr := safefile.WriteFile(path, data, 0o644); werr != nil {
+				unrestored = append(unrestored, fmt.Sprintf("%s: %v", path, werr))
+			}
+		}
+		if len(unrestored) == 0 {
+			return err
+		}
+		sort.Strings(unrestored)
+		msg := "failed to restore " + strings.Join(unrestored, "; ")
+		if zerr, ok := errors.GetZeroUIError(err); ok {
+			return zerr.WithContext("restore", msg).
+				WithSuggestions(append(zerr.Suggestions, "Files listed under restore keep the new values; restore them from a backup")...)
+		}
+		return fmt.Errorf("%w; %s", err, msg)
+	}
+
+	for _, f := range graph.Files {
+		fileK, ok := edits[f]
+		if !ok {
+			continue
+		}
+		data, err := os.ReadFile(f.Path)
+		if err != nil {
+			return fail(fmt.Errorf("failed to read %s: %w", f.Path, err))
+		}
+
+		fileConfig := *appConfig
+		fileConfig.Path = f.Path
+		fileConfig.Format = f.Format
+		if err := l.saveTargetFile(&fileConfig, fileK, SaveOptions{RemoveKeys: removed[f], expected: opts.expected}); err != nil {
+			if _, ok := errors.GetZeroUIError(err); ok {
+				return fail(err)
+			}
+			return fail(fmt.Errorf("failed to save %s: %w", f.Path, err))
+		}
+		originals[f.Path] = data
+	}
+
+	return nil
+}
+
+// findIncludes returns the include directives of a file in file order
+func (g *IncludeGraph) findIncludes(file *IncludeFile) ([]includeDirective, error) {
+	if g.dialect == IncludeAlacritty {
+		return alacrittyIncludes(file), nil
+	}
+
+	data, err := os.ReadFile(file.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	var directives []includeDirective
+	section := ""
+	for i, raw := range splitLines(data) {
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		switch g.dialect {
+		case IncludeGhostty:
+			key, value, ok := strings.Cut(line, "=")
+			if !ok || strings.TrimSpace(key) != "config-file" {
+				continue
+			}
+			value = unquoteInclude(value)
+			optional := strings.HasPrefix(value, "?")
+			value = strings.TrimPrefix(value, "?")
+			if value != "" {
+				directives = append(directives, includeDirective{target: value, line: i + 1, optional: optional})
+			}
+
+		case IncludeKitty:
+			// kitty separates the command from its argument by any whitespace
+			command, value := line, ""
+			if j := strings.IndexAny(line, " \t"); j >= 0 {
+				command, value = line[:j], line[j+1:]
+			}
+			if command != "include" && command != "globinclude" {
+				continue
+			}
+			if value = unquoteInclude(value); value != "" {
+				directives = append(directives, includeDirective{target: os.ExpandEnv(value), line: i + 1, glob: command == "globinclude"})
+			}
+
+		case IncludeTmux:
+			fields := strings.Fields(line)
+			if fields[0] != "source-file" && fields[0] != "source" {
+				continue
+			}
+			optional := false
+			for j := 1; j < len(fields); j++ {
+				switch arg := fields[j]; {
+				case arg == "-t":
+					j++
+				case strings.HasPrefix(arg, "-"):
+					optional = optional || strings.Contains(arg, "q")
+				default:
+					directives = append(directives, includeDirective{target: unquoteInclude(arg), line: i + 1, optional: optional, glob: true})
+				}
+			}
+
+		case IncludeGit:
+			if strings.HasPrefix(line, "[") {
+				section = strings.ToLower(strings.TrimSpace(strings.Trim(line, "[]")))
+				continue
+			}
+			key, value, ok := strings.Cut(line, "=")
+			if !ok || !strings.EqualFold(strings.TrimSpace(key), "path") {
+				continue
+			}
+			if section != "include" && !strings.HasPrefix(section, "includeif") {
+				continue
+			}
+			if value = unquoteInclude(value); value != "" {
+				directives = append(directives, includeDirective{target: value, line: i + 1, conditional: section != "include"})
+			}
+		}
+	}
+
+	return directives, nil
+}
+
+// alacrittyIncludes reads the import list of an Alacritty config. Alacritty
+// skips missing imports, so every import is optional.
+func alacrittyIncludes(file *IncludeFile) []includeDirective {
+	var directives []includeDirective
+	for _, key := range []string{"import", "general.import"} {
+		line := 0
+		if pos, ok := file.Sources.Last(key); ok {
+			line = pos.Line
+		}
+		for _, target := range includeList(file.Values.Get(key)) {
+			directives = append(directives, includeDirective{target: target, line: line, optional: true})
+		}
+	}
+	return directives
+}
+
+// loadIncludeFile parses a config file and locates its keys
+func loadIncludeFile(path, format string) (*IncludeFile, error) {
+	hash, err := fileHash(path)
+	if err != nil {
+		return nil, err
+	}
+	k, err := loadConfigFile(path, format)
+	if err != nil {
+		return nil, err
+	}
+
+	f := &IncludeFile{Path: filepath.Clean(path), Format: format, Values: k, Sources: SourceMap{}, Hash: hash}
+
+	locateFormat := format
+	if locateFormat == "" {
+		locateFormat = strings.TrimPrefix(strings.ToLower(filepath.Ext(path)), ".")
+	}
+	if data, err := os.ReadFile(path); err == nil {
+		if sources, err := LocateKeys(locateFormat, f.Path, data); err == nil {
+			f.Sources = sources
+		}
+	}
+
+	return f, nil
+}
+
+// includeFormat picks the format of an included file from its extension,
+// falling back to the main config's format
+func includeFormat(path, format string) string {
+	switch ext := strings.ToLower(filepath.Ext(path)); ext {
+	case ".toml", ".json", ".yaml", ".yml":
+		return strings.TrimPrefix(ext, ".")
+	}
+	return format
+}
+
+// resolveIncludePaths resolves an include target relative to the including
+// file, expanding globs in sorted order
+func resolveIncludePaths(from, target string, glob bool) []string {
+	if expanded, err := ExpandTargetPath(target); err == nil {
+		target = expanded
+	}
+	if !filepath.IsAbs(target) {
+		target = filepath.Join(filepath.Dir(from), target)
+	}
+	target = filepath.Clean(target)
+
+	if !glob || !strings.ContainsAny(target, "*?[") {
+		return []string{target}
+	}
+	matches, err := filepath.Glob(target)
+	if err != nil {
+		return nil
+	}
+	sort.Strings(matches)
+	return matches
+}
+
+// unquoteInclude trims whitespace and surrounding quotes from a path
+func unquoteInclude(value string) string {
+	value = strings.TrimSpace(value)
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		value = value[1 : len(value)-1]
+	}
+	return value
+}
+
+// includeList returns a setting's values as strings
+func includeList(value interface{}) []string {
+	switch v := value.(type) {
+	case nil:
+		return nil
+	case string:
+		return []string{v}
+	case []string:
+		return v
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			list = append(list, fmt.Sprintf("%v", item))
+		}
+		return list
+	default:
+		return []string{fmt.Sprintf("%v", v)}
+	}
+}
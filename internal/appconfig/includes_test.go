@@ -0,0 +1,283 @@
+package appconfig
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mrtkrcm/ZeroUI/internal/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func writeIncludeFiles(t *testing.T, dir string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o644))
+	}
+}
+
+func readIncludeFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	return string(data)
+}
+
+func TestLoadIncludeGraph(t *testing.T) {
+	loader := &Loader{}
+
+	t.Run("ghostty includes load after the including file", func(t *testing.T) {
+		dir := t.TempDir()
+		writeIncludeFiles(t, dir, map[string]string{
+			"config":           "font-size = 13\nconfig-file = extra.conf\nconfig-file = ?missing.conf\nbackground = #000000\n",
+			"extra.conf":       "font-size = 15\nconfig-file = config\nconfig-file = nested/more.conf\n",
+			"nested/more.conf": "cursor-style = bar\n",
+		})
+
+		graph, err := loader.LoadIncludeGraph(&AppConfig{Name: "ghostty", Path: filepath.Join(dir, "config"), Format: "custom"})
+		require.NoError(t, err)
+
+		require.Len(t, graph.Files, 3)
+		assert.Equal(t, filepath.Join(dir, "config"), graph.Files[1].Parent)
+		assert.Equal(t, 2, graph.Files[1].Line)
+
+		merged := graph.Merged()
+		assert.Equal(t, "15", merged.String("font-size"))
+		assert.Equal(t, "bar", merged.String("cursor-style"))
+		assert.Equal(t, filepath.Join(dir, "extra.conf"), graph.DefiningFile("font-size").Path)
+
+		occurrences := graph.Occurrences("font-size")
+		require.Len(t, occurrences, 2)
+		assert.Equal(t, graph.Root, occurrences[1].File)
+		assert.Equal(t, 1, occurrences[1].Line)
+
+		require.Len(t, graph.Warnings, 1)
+		assert.Contains(t, graph.Warnings[0], "include cycle")
+	})
+
+	t.Run("ghostty list keys add up across files", func(t *testing.T) {
+		dir := t.TempDir()
+		writeIncludeFiles(t, dir, map[string]string{
+			"config":     "keybind = ctrl+a=copy\nfont-family = Inter\nconfig-file = keys.conf\nconfig-file = reset.conf\n",
+			"keys.conf":  "keybind = ctrl+b=paste\nkeybind = ctrl+c=quit\nfont-family = Noto Color Emoji\n",
+			"reset.conf": "font-family =\nfont-family = JetBrains Mono\n",
+		})
+
+		graph, err := loader.LoadIncludeGraph(&AppConfig{Name: "ghostty", Path: filepath.Join(dir, "config"), Format: "custom"})
+		require.NoError(t, err)
+
+		merged := graph.Merged()
+		assert.Equal(t, []interface{}{"ctrl+a=copy", "ctrl+b=paste", "ctrl+c=quit"}, merged.Get("keybind"))
+		assert.Equal(t, "JetBrains Mono", merged.Get("font-family"), "an empty value clears the entries before it")
+	})
+
+	t.Run("alacritty imports load before the importing file", func(t *testing.T) {
+		dir := t.TempDir()
+		writeIncludeFiles(t, dir, map[string]string{
+			"alacritty.toml": "[general]\nimport = [\"colors.toml\", \"missing.toml\"]\n\n[font]\nsize = 12\n",
+			"colors.toml":    "[colors.primary]\nbackground = \"#000000\"\n\n[font]\nsize = 10\n",
+		})
+
+		graph, err := loader.LoadIncludeGraph(&AppConfig{Name: "alacritty", Path: filepath.Join(dir, "alacritty.toml"), Format: "toml"})
+		require.NoError(t, err)
+
+		merged := graph.Merged()
+		assert.EqualValues(t, 12, merged.Int64("font.size"))
+		assert.Equal(t, "#000000", merged.String("colors.primary.background"))
+		assert.Empty(t, graph.Warnings, "missing imports are skipped silently")
+	})
+
+	t.Run("kitty includes apply at the directive line", func(t *testing.T) {
+		dir := t.TempDir()
+		writeIncludeFiles(t, dir, map[string]string{
+			"kitty.conf": "before = root\ninclude theme.conf\nafter = root\ninclude\tlate.conf\n",
+			"theme.conf": "before = theme\nafter = theme\n",
+			"late.conf":  "late = yes\n",
+		})
+
+		graph, err := loader.LoadIncludeGraph(&AppConfig{Name: "kitty", Path: filepath.Join(dir, "kitty.conf"), Format: "custom"})
+		require.NoError(t, err)
+
+		merged := graph.Merged()
+		assert.Equal(t, "theme", merged.String("before"))
+		assert.Equal(t, "root", merged.String("after"))
+		assert.Equal(t, "yes", merged.String("late"), "include may be followed by a tab")
+	})
+
+	t.Run("tmux source-file expands globs", func(t *testing.T) {
+		dir := t.TempDir()
+		writeIncludeFiles(t, dir, map[string]string{
+			"tmux.conf":     "source-file -q conf.d/*.conf\nsource-file -q absent.conf\n",
+			"conf.d/a.conf": "a = 1\n",
+			"conf.d/b.conf": "b = 2\n",
+		})
+
+		graph, err := loader.LoadIncludeGraph(&AppConfig{Name: "tmux", Path: filepath.Join(dir, "tmux.conf"), Format: "custom"})
+		require.NoError(t, err)
+
+		require.Len(t, graph.Files, 3)
+		assert.Equal(t, []string{"a", "b"}, graph.Keys())
+		assert.Empty(t, graph.Warnings)
+	})
+
+	t.Run("git includeIf is conditional", func(t *testing.T) {
+		dir := t.TempDir()
+		writeIncludeFiles(t, dir, map[string]string{
+			"gitconfig":  "[include]\n\tpath = common.inc\n[includeIf \"gitdir:~/work/\"]\n\tpath = work.inc\n",
+			"common.inc": "editor = vim\n",
+			"work.inc":   "email = work@example.com\n",
+		})
+
+		graph, err := loader.LoadIncludeGraph(&AppConfig{Name: "git", Path: filepath.Join(dir, "gitconfig"), Format: "custom"})
+		require.NoError(t, err)
+
+		require.Len(t, graph.Files, 3)
+		assert.False(t, graph.Files[1].Conditional)
+		assert.True(t, graph.Files[2].Conditional)
+		assert.True(t, graph.Merged().Exists("editor"))
+		assert.False(t, graph.Merged().Exists("email"))
+		require.Len(t, graph.Warnings, 1)
+		assert.Contains(t, graph.Warnings[0], "conditionally")
+	})
+
+	t.Run("disabled and unknown dialects", func(t *testing.T) {
+		dir := t.TempDir()
+		writeIncludeFiles(t, dir, map[string]string{
+			"config":     "config-file = extra.conf\n",
+			"extra.conf": "font-size = 15\n",
+		})
+		path := filepath.Join(dir, "config")
+
+		graph, err := loader.LoadIncludeGraph(&AppConfig{Name: "ghostty", Path: path, Format: "custom", Includes: &IncludeConfig{Disabled: true}})
+		require.NoError(t, err)
+		assert.Len(t, graph.Files, 1)
+
+		_, err = loader.LoadIncludeGraph(&AppConfig{Name: "x", Path: path, Format: "custom", Includes: &IncludeConfig{Dialect: "nope"}})
+		assert.Error(t, err)
+	})
+}
+
+func TestSaveTargetConfigWithIncludes(t *testing.T) {
+	loader := &Loader{}
+
+	setup := func(t *testing.T, includes *IncludeConfig) (*AppConfig, string, string) {
+		dir := t.TempDir()
+		writeIncludeFiles(t, dir, map[string]string{
+			"config":     "# main\nfont-size = 13\nconfig-file = local.conf\n",
+			"local.conf": "font-size = 15\ncursor-style = bar\n",
+		})
+		appConfig := &AppConfig{Name: "ghostty", Path: filepath.Join(dir, "config"), Format: "custom", Includes: includes}
+		return appConfig, filepath.Join(dir, "config"), filepath.Join(dir, "local.conf")
+	}
+
+	t.Run("changed keys go to the defining file", func(t *testing.T) {
+		appConfig, rootPath, localPath := setup(t, nil)
+
+		k, err := loader.LoadTargetConfig(appConfig)
+		require.NoError(t, err)
+		require.NoError(t, k.Set("font-size", "16"))
+		require.NoError(t, k.Set("background", "#101010"))
+		require.NoError(t, loader.SaveTargetConfig(appConfig, k))
+
+		root := readIncludeFile(t, rootPath)
+		local := readIncludeFile(t, localPath)
+		assert.Contains(t, local, "font-size = 16")
+		assert.NotContains(t, root, "font-size = 16", "no duplicate in the main config")
+		assert.Contains(t, root, "font-size = 13")
+		assert.Contains(t, root, "background = #101010")
+		assert.Contains(t, root, "# main")
+
+		k, err = loader.LoadTargetConfig(appConfig)
+		require.NoError(t, err)
+		assert.Equal(t, "16", k.String("font-size"))
+	})
+
+	t.Run("list entries stay in the file that sets them", func(t *testing.T) {
+		dir := t.TempDir()
+		writeIncludeFiles(t, dir, map[string]string{
+			"config":    "keybind = ctrl+a=copy\nconfig-file = keys.conf\n",
+			"keys.conf": "keybind = ctrl+b=paste\n",
+		})
+		appConfig := &AppConfig{Name: "ghostty", Path: filepath.Join(dir, "config"), Format: "custom"}
+
+		k, err := loader.LoadTargetConfig(appConfig)
+		require.NoError(t, err)
+		require.NoError(t, k.Set("keybind", []interface{}{"ctrl+a=copy", "ctrl+b=paste", "ctrl+d=close"}))
+		require.NoError(t, loader.SaveTargetConfig(appConfig, k))
+
+		assert.Equal(t, "keybind = ctrl+a=copy\nconfig-file = keys.conf\n", readIncludeFile(t, filepath.Join(dir, "config")))
+		keys := readIncludeFile(t, filepath.Join(dir, "keys.conf"))
+		assert.Contains(t, keys, "keybind = ctrl+b=paste\nkeybind = ctrl+d=close")
+		assert.NotContains(t, keys, "ctrl+a=copy", "entries of the main config aren't copied")
+
+		// Dropping an entry of the main config clears the list before the defining file's
+		require.NoError(t, k.Set("keybind", []interface{}{"ctrl+b=paste", "ctrl+d=close"}))
+		require.NoError(t, loader.SaveTargetConfig(appConfig, k))
+		k, err = loader.LoadTargetConfig(appConfig)
+		require.NoError(t, err)
+		assert.Equal(t, []interface{}{"ctrl+b=paste", "ctrl+d=close"}, k.Get("keybind"))
+	})
+
+	t.Run("removed keys are deleted from every file", func(t *testing.T) {
+		appConfig, rootPath, localPath := setup(t, nil)
+
+		k, err := loader.LoadTargetConfig(appConfig)
+		require.NoError(t, err)
+		require.NoError(t, loader.SaveTargetConfigWithOptions(appConfig, k, SaveOptions{RemoveKeys: []string{"font-size"}}))
+
+		assert.NotContains(t, readIncludeFile(t, rootPath), "font-size")
+		assert.NotContains(t, readIncludeFile(t, localPath), "font-size")
+		assert.Contains(t, readIncludeFile(t, localPath), "cursor-style = bar")
+	})
+
+	t.Run("write_to selects the target file", func(t *testing.T) {
+		appConfig, rootPath, localPath := setup(t, &IncludeConfig{WriteTo: "local.conf"})
+
+		k, err := loader.LoadTargetConfig(appConfig)
+		require.NoError(t, err)
+		require.NoError(t, k.Set("background", "#101010"))
+		require.NoError(t, loader.SaveTargetConfig(appConfig, k))
+
+		assert.NotContains(t, readIncludeFile(t, rootPath), "background")
+		assert.Contains(t, readIncludeFile(t, localPath), "background = #101010")
+
+		appConfig.Includes.WriteTo = "elsewhere.conf"
+		err = loader.SaveTargetConfig(appConfig, k)
+		require.Error(t, err)
+		assert.True(t, strings.Contains(err.Error(), "not loaded"))
+	})
+
+	t.Run("files that can't be restored are reported", func(t *testing.T) {
+		dir := t.TempDir()
+		rootDir := filepath.Join(dir, "main")
+		writeIncludeFiles(t, dir, map[string]string{
+			"main/config":      "font-size = 13\nconfig-file = ../local/local.conf\n",
+			"local/local.conf": "cursor-style = bar\n",
+		})
+		// Rejecting the include after the main config was written, with the
+		// main config's directory gone, leaves nowhere to restore it to
+		stub := writeStubValidator(t, `case "$1" in */local.conf) rm -rf "`+rootDir+`"; exit 1 ;; esac
+`)
+		appConfig := &AppConfig{
+			Name:      "ghostty",
+			Path:      filepath.Join(rootDir, "config"),
+			Format:    "custom",
+			Validator: &ExternalValidator{Command: stub, Args: []string{"{original}"}},
+		}
+
+		k, err := loader.LoadTargetConfig(appConfig)
+		require.NoError(t, err)
+		require.NoError(t, k.Set("font-size", "16"))
+		require.NoError(t, k.Set("cursor-style", "block"))
+
+		err = loader.SaveTargetConfig(appConfig, k)
+		require.Error(t, err)
+		zerr, ok := errors.GetZeroUIError(err)
+		require.True(t, ok, "the validator's error is kept: %v", err)
+		assert.Contains(t, zerr.Context["restore"], filepath.Join(rootDir, "config"))
+	})
+}
@@ -0,0 +1,116 @@
+package appconfig
+
+import (
+	"fmt"
+	"strings"
+)
+
+// maxDiffCells bounds the LCS table; larger inputs are shown as a full replacement
+const maxDiffCells = 4_000_000
+
+// DiffOp marks how a line changed
+type DiffOp byte
+
+const (
+	DiffEqual  DiffOp = ' '
+	DiffDelete DiffOp = '-'
+	DiffInsert DiffOp = '+'
+)
+
+// DiffLine is a single line of a line-based diff
+type DiffLine struct {
+	Op   DiffOp
+	Text string
+}
+
+// LineDiff computes a line-based diff between two file contents
+func LineDiff(oldData, newData []byte) []DiffLine {
+	a, b := diffSplit(oldData), diffSplit(newData)
+
+	if len(a)*len(b) > maxDiffCells {
+		diff := make([]DiffLine, 0, len(a)+len(b))
+		for _, line := range a {
+			diff = append(diff, DiffLine{Op: DiffDelete, Text: line})
+		}
+		for _, line := range b {
+			diff = append(diff, DiffLine{Op: DiffInsert, Text: line})
+		}
+		return diff
+	}
+
+	// lcs[i][j] holds the LCS length of a[i:] and b[j:]
+	lcs := make([][]int, len(a)+1)
+	for i := range lcs {
+		lcs[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	diff := make([]DiffLine, 0, max(len(a), len(b)))
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			diff = append(diff, DiffLine{Op: DiffEqual, Text: a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			diff = append(diff, DiffLine{Op: DiffDelete, Text: a[i]})
+			i++
+		default:
+			diff = append(diff, DiffLine{Op: DiffInsert, Text: b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		diff = append(diff, DiffLine{Op: DiffDelete, Text: a[i]})
+	}
+	for ; j < len(b); j++ {
+		diff = append(diff, DiffLine{Op: DiffInsert, Text: b[j]})
+	}
+
+	return diff
+}
+
+// FormatLineDiff renders changed lines with up to context unchanged lines
+// around them, separating distant hunks with "@@"
+func FormatLineDiff(diff []DiffLine, context int) string {
+	keep := make([]bool, len(diff))
+	for i, line := range diff {
+		if line.Op == DiffEqual {
+			continue
+		}
+		for j := max(0, i-context); j <= min(len(diff)-1, i+context); j++ {
+			keep[j] = true
+		}
+	}
+
+	var b strings.Builder
+	last := -1
+	for i, line := range diff {
+		if !keep[i] {
+			continue
+		}
+		if last >= 0 && i != last+1 {
+			b.WriteString("@@\n")
+		}
+		fmt.Fprintf(&b, "%c %s\n", line.Op, line.Text)
+		last = i
+	}
+	return b.String()
+}
+
+// diffSplit splits contents into lines without a trailing empty line
+func diffSplit(data []byte) []string {
+	if len(data) == 0 {
+		return nil
+	}
+	return splitLines(data)
+}
@@ -15,7 +15,9 @@ import (
 	"github.com/knadh/koanf/v2"
 	yamlv3 "gopkg.in/yaml.v3"
 
-	"github.com/mrtkrcm/ZeroUI/internal/performance"
+	"github.com/mrtkrcm/ZeroUI/internal/errors"
+	"github.com/mrtkrcm/ZeroUI/internal/filelock"
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
 	"github.com/mrtkrcm/ZeroUI/internal/security"
 )
 
@@ -28,6 +30,11 @@ type Loader struct {
 	appConfigCache *lru.Cache[string, *AppConfig]
 	cacheMutex     sync.RWMutex
 
+	// What each returned target config was read from, for detecting
+	// external edits when it is saved
+	reads     *lru.Cache[*koanf.Koanf, *readSnapshot]
+	readsOnce sync.Once
+
 	// File watching for cache invalidation with debouncing
 	fileWatcher     *DebouncedWatcher
 	watcherInitOnce sync.Once
@@ -46,7 +53,8 @@ type Loader struct {
 // Behavior:
 //   - If the environment variable ZEROUI_CONFIG_DIR is set, it is used as the
 //     config directory (useful for tests/CI).
-//   - Otherwise the loader uses $HOME/.config/zeroui as the default path.
+//   - Otherwise the loader uses $XDG_CONFIG_HOME/zeroui, by default
+//     $HOME/.config/zeroui.
 //   - The directory is created if it does not exist.
 func NewLoader() (*Loader, error) {
 	// Honor explicit override (useful for tests/CI)
@@ -74,12 +82,7 @@ func NewLoader() (*Loader, error) {
 		}, nil
 	}
 
-	home, err := performance.GetHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get user home directory: %w", err)
-	}
-
-	configDir := filepath.Join(home, ".config", "zeroui")
+	configDir := paths.ConfigDir()
 	if err := os.MkdirAll(configDir, 0o755); err != nil {
 		return nil, fmt.Errorf("failed to create config directory: %w", err)
 	}
@@ -122,6 +125,11 @@ func (l *Loader) SetConfigDir(dir string) {
 	}
 }
 
+// ConfigDir returns the directory holding app definitions and schemas.
+func (l *Loader) ConfigDir() string {
+	return l.configDir
+}
+
 // AppConfig represents the configuration for a single application.
 type AppConfig struct {
 	Name        string                  `yaml:"name"`
@@ -132,6 +140,8 @@ type AppConfig struct {
 	Presets     map[string]PresetConfig `yaml:"presets"`
 	Hooks       map[string]string       `yaml:"hooks,omitempty"`
 	Env         map[string]string       `yaml:"env,omitempty"`
+	Includes    *IncludeConfig          `yaml:"includes,omitempty"`
+	Validator   *ExternalValidator      `yaml:"validator,omitempty"`
 }
 
 // FieldConfig represents a configurable field.
@@ -140,7 +150,9 @@ type FieldConfig struct {
 	Values      []string    `yaml:"values,omitempty"`
 	Default     interface{} `yaml:"default,omitempty"`
 	Description string      `yaml:"description,omitempty"`
-	Path        string      `yaml:"path,omitempty"` // JSON path for nested values
+	Path        string      `yaml:"path,omitempty"`         // JSON path for nested values
+	Required    bool        `yaml:"required,omitempty"`     // Must stay present; reset writes the default
+	EnvOverride string      `yaml:"env_override,omitempty"` // Environment variable that overrides the value at runtime
 }
 
 // PresetConfig represents a preset configuration.
@@ -222,28 +234,55 @@ func (l *Loader) ListApps() ([]string, error) {
 }
 
 // LoadTargetConfig loads the actual configuration file that the app uses.
+// When the file includes others (see IncludeConfig), the merged view of the
+// whole include graph is returned.
 func (l *Loader) LoadTargetConfig(appConfig *AppConfig) (*koanf.Koanf, error) {
-	// Expand home directory in path
-	configPath := appConfig.Path
-	if strings.HasPrefix(configPath, "~") {
-		home, err := os.UserHomeDir()
+	if appConfig.IncludeDialect() != "" {
+		graph, err := l.LoadIncludeGraph(appConfig)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get home directory: %w", err)
+			return nil, err
 		}
-		// support both "~" and "~/..." usage
-		trimmed := strings.TrimPrefix(configPath, "~")
-		if strings.HasPrefix(trimmed, string(os.PathSeparator)) {
-			configPath = filepath.Join(home, trimmed[1:])
-		} else {
-			configPath = filepath.Join(home, trimmed)
+		k := graph.Merged()
+		hashes := make(map[string]string, len(graph.Files))
+		for _, f := range graph.Files {
+			hashes[f.Path] = f.Hash
 		}
+		l.recordRead(k, hashes)
+		return k, nil
+	}
+
+	configPath, err := ExpandTargetPath(appConfig.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	// Hash before parsing: a change racing the parse then shows up as an
+	// external edit instead of going unnoticed
+	hash, err := fileHash(configPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read target config: %w", err)
 	}
+	k, err := loadConfigFile(configPath, appConfig.Format)
+	if err != nil {
+		return nil, err
+	}
+	l.recordRead(k, map[string]string{configPath: hash})
+	return k, nil
+}
+
+// LoadConfigFile parses a config file that isn't an app's target, such as a
+// backup or a shared baseline, in the app's format
+func LoadConfigFile(configPath, format string) (*koanf.Koanf, error) {
+	return loadConfigFile(configPath, format)
+}
 
+// loadConfigFile parses a single config file in the given format, falling
+// back to the file extension when no format is set
+func loadConfigFile(configPath, format string) (*koanf.Koanf, error) {
 	k := koanf.New(".")
 
 	var parser koanf.Parser
-	format := strings.ToLower(appConfig.Format)
-	switch format {
+	switch strings.ToLower(format) {
 	case "":
 		ext := strings.ToLower(filepath.Ext(configPath))
 		switch ext {
@@ -254,7 +293,7 @@ func (l *Loader) LoadTargetConfig(appConfig *AppConfig) (*koanf.Koanf, error) {
 		case ".toml":
 			parser = toml.Parser()
 		default:
-			return nil, fmt.Errorf("unsupported config format: %s", appConfig.Format)
+			return nil, fmt.Errorf("unsupported config format: %s", format)
 		}
 	case "json":
 		parser = json.Parser()
@@ -263,9 +302,9 @@ func (l *Loader) LoadTargetConfig(appConfig *AppConfig) (*koanf.Koanf, error) {
 	case "toml":
 		parser = toml.Parser()
 	case "custom":
-		return l.loadCustomFormat(configPath)
+		return ParseGhosttyConfig(configPath)
 	default:
-		return nil, fmt.Errorf("unsupported config format: %s", appConfig.Format)
+		return nil, fmt.Errorf("unsupported config format: %s", format)
 	}
 
 	if err := k.Load(file.Provider(configPath), parser); err != nil {
@@ -275,22 +314,106 @@ func (l *Loader) LoadTargetConfig(appConfig *AppConfig) (*koanf.Koanf, error) {
 	return k, nil
 }
 
+// SaveOptions adjusts how SaveTargetConfigWithOptions writes a config file
+type SaveOptions struct {
+	// RemoveKeys lists keys deleted from the config. Writers that preserve
+	// the original file's lines (Ghostty-style formats) keep lines for keys
+	// they don't know about unless the key is listed here.
+	RemoveKeys []string
+	// ExternalEdits decides what happens when the files were changed by
+	// another program since the config being saved was loaded
+	ExternalEdits ExternalEditPolicy
+	// NoAudit keeps the write out of the audit log. It is for files that
+	// aren't the config itself, such as a copy renamed over it afterwards,
+	// whose change the caller records.
+	NoAudit bool
+
+	// expected holds the hash each file must still have when it is committed
+	expected map[string]string
+}
+
 // SaveTargetConfig saves the configuration back to the target file using temporary files for safety.
 func (l *Loader) SaveTargetConfig(appConfig *AppConfig, k *koanf.Koanf) error {
-	configPath := appConfig.Path
-	if strings.HasPrefix(configPath, "~") {
-		home, err := os.UserHomeDir()
-		if err != nil {
-			return fmt.Errorf("failed to get home directory: %w", err)
-		}
-		trimmed := strings.TrimPrefix(configPath, "~")
-		if strings.HasPrefix(trimmed, string(os.PathSeparator)) {
-			configPath = filepath.Join(home, trimmed[1:])
-		} else {
-			configPath = filepath.Join(home, trimmed)
+	return l.SaveTargetConfigWithOptions(appConfig, k, SaveOptions{})
+}
+
+// SaveTargetConfigWithOptions saves the configuration like SaveTargetConfig,
+// applying the given save options. When the config includes other files,
+// each changed key is written to the file selected by IncludeConfig.WriteTo.
+//
+// A config returned by LoadTargetConfig is compared against the files it was
+// read from; changes made by another program since are kept, and the save
+// fails with a ConfigConflict error when both touched the same key.
+func (l *Loader) SaveTargetConfigWithOptions(appConfig *AppConfig, k *koanf.Koanf, opts SaveOptions) error {
+	configPath, err := ExpandTargetPath(appConfig.Path)
+	if err != nil {
+		return err
+	}
+
+	lock, err := lockTarget(appConfig, configPath)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	var before map[string]fileState
+	if !opts.NoAudit {
+		before = l.captureFiles(appConfig, configPath)
+	}
+
+	var graph *IncludeGraph
+	paths := []string{configPath}
+	if appConfig.IncludeDialect() != "" {
+		if g, err := l.LoadIncludeGraph(appConfig); err == nil {
+			graph = g
+			paths = paths[:0]
+			for _, f := range graph.Files {
+				paths = append(paths, f.Path)
+			}
 		}
 	}
 
+	merged, opts, err := l.reconcileExternalEdits(appConfig, configPath, graph, k, opts)
+	if err != nil {
+		return err
+	}
+
+	if graph != nil && len(graph.Files) > 1 {
+		err = l.saveIncludeGraph(appConfig, graph, merged, opts)
+	} else {
+		err = l.saveTargetFile(appConfig, merged, opts)
+	}
+	if err != nil {
+		return err
+	}
+	if !opts.NoAudit {
+		l.recordChanges(appConfig, configPath, before, l.captureFiles(appConfig, configPath))
+	}
+
+	// Later saves of k are based on what it held. When other changes were
+	// merged in, k no longer matches the disk, so the pre-save hashes are
+	// kept: the next save merges again instead of reverting them.
+	if merged != k {
+		l.recordRead(k, opts.expected)
+	} else if hashes, err := hashFiles(paths); err == nil {
+		l.recordRead(k, hashes)
+	}
+	return nil
+}
+
+// saveTargetFile writes a config to the single file at appConfig.Path
+func (l *Loader) saveTargetFile(appConfig *AppConfig, k *koanf.Koanf, opts SaveOptions) error {
+	configPath, err := ExpandTargetPath(appConfig.Path)
+	if err != nil {
+		return err
+	}
+
+	lock, err := lockTarget(appConfig, configPath)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
 	// Initialize temp file manager and integrity checker
 	tempManager, err := NewTempFileManager()
 	if err != nil {
@@ -305,6 +428,12 @@ func (l *Loader) SaveTargetConfig(appConfig *AppConfig, k *koanf.Koanf) error {
 	if err != nil {
 		return fmt.Errorf("failed to create temporary copy: %w", err)
 	}
+	if err := checkExpectedHash(appConfig, opts, tempFile); err != nil {
+		tempManager.Discard(tempFile)
+		return err
+	}
+	// Removing the last settings empties the file
+	tempFile.AllowEmpty = len(k.Keys()) == 0
 
 	// Marshal configuration data
 	var data []byte
@@ -318,16 +447,23 @@ func (l *Loader) SaveTargetConfig(appConfig *AppConfig, k *koanf.Koanf) error {
 		data, err = k.Marshal(toml.Parser())
 	case "custom":
 		// For custom formats, handle separately
-		if err := l.saveCustomFormatWithTemp(tempFile.TempPath, k, configPath); err != nil {
-			tempManager.Rollback(tempFile)
+		if err := l.saveCustomFormatWithTemp(tempFile.TempPath, k, configPath, opts.RemoveKeys); err != nil {
+			tempManager.Discard(tempFile)
 			return fmt.Errorf("failed to save custom format: %w", err)
 		}
 		// Validate and commit
 		if err := integrityChecker.ValidateFormat(tempFile.TempPath); err != nil {
-			tempManager.Rollback(tempFile)
+			tempManager.Discard(tempFile)
 			return fmt.Errorf("validation failed: %w", err)
 		}
-		return tempManager.CommitTemp(tempFile)
+		if err := runExternalValidator(appConfig, tempFile.TempPath, configPath); err != nil {
+			tempManager.Discard(tempFile)
+			return err
+		}
+		if err := tempManager.CommitTemp(tempFile); err != nil {
+			return commitError(appConfig, configPath, err)
+		}
+		return nil
 	default:
 		ext := strings.ToLower(filepath.Ext(configPath))
 		switch ext {
@@ -338,69 +474,135 @@ func (l *Loader) SaveTargetConfig(appConfig *AppConfig, k *koanf.Koanf) error {
 		case ".toml":
 			data, err = k.Marshal(toml.Parser())
 		default:
-			tempManager.Rollback(tempFile)
+			tempManager.Discard(tempFile)
 			return fmt.Errorf("unsupported config format: %s", appConfig.Format)
 		}
 	}
 
 	if err != nil {
-		tempManager.Rollback(tempFile)
+		tempManager.Discard(tempFile)
 		return fmt.Errorf("failed to marshal config: %w", err)
 	}
 
 	// Write to temporary file
 	if err := os.WriteFile(tempFile.TempPath, data, 0o644); err != nil {
-		tempManager.Rollback(tempFile)
+		tempManager.Discard(tempFile)
 		return fmt.Errorf("failed to write temp file: %w", err)
 	}
 
 	// Validate config values against AppConfig constraints
 	validator := NewFieldValidator()
 	if err := validator.ValidateConfig(appConfig, k); err != nil {
-		tempManager.Rollback(tempFile)
+		tempManager.Discard(tempFile)
 		return fmt.Errorf("config values validation failed: %w", err)
 	}
 
 	// Validate the temporary file before committing
 	if err := integrityChecker.ValidateFormat(tempFile.TempPath); err != nil {
-		tempManager.Rollback(tempFile)
+		tempManager.Discard(tempFile)
 		return fmt.Errorf("config validation failed: %w", err)
 	}
 
 	// Verify content integrity
 	if err := integrityChecker.ValidateContent(tempFile.TempPath, nil); err != nil {
-		tempManager.Rollback(tempFile)
+		tempManager.Discard(tempFile)
 		return fmt.Errorf("content validation failed: %w", err)
 	}
 
+	// Let the app's own checker reject the file before it goes live
+	if err := runExternalValidator(appConfig, tempFile.TempPath, configPath); err != nil {
+		tempManager.Discard(tempFile)
+		return err
+	}
+
 	// Commit the temporary file to the actual location
 	if err := tempManager.CommitTemp(tempFile); err != nil {
-		return fmt.Errorf("failed to commit changes: %w", err)
+		return commitError(appConfig, configPath, err)
 	}
 
 	// Verify final file integrity
-	finalChecksum, err := integrityChecker.CalculateChecksum(configPath)
-	if err != nil {
+	if _, err := integrityChecker.CalculateChecksum(configPath); err != nil {
 		return fmt.Errorf("failed to verify saved file: %w", err)
 	}
 
-	// Log success with checksum for audit
-	if finalChecksum != "" {
-		// Could log this for audit purposes
-		_ = finalChecksum
+	return nil
+}
+
+// SaveTargetData writes raw file contents to the target file through the same
+// temporary file, validation and atomic commit steps as SaveTargetConfig.
+func (l *Loader) SaveTargetData(appConfig *AppConfig, data []byte) error {
+	configPath, err := ExpandTargetPath(appConfig.Path)
+	if err != nil {
+		return err
+	}
+
+	lock, err := lockTarget(appConfig, configPath)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
+	before := l.captureFiles(appConfig, configPath)
+
+	tempManager, err := NewTempFileManager()
+	if err != nil {
+		return fmt.Errorf("failed to initialize temp manager: %w", err)
+	}
+	defer tempManager.CleanupAll()
+
+	integrityChecker := NewIntegrityChecker()
+
+	tempFile, err := tempManager.CreateTempCopy(configPath)
+	if err != nil {
+		return fmt.Errorf("failed to create temporary copy: %w", err)
+	}
+
+	if err := os.WriteFile(tempFile.TempPath, data, 0o644); err != nil {
+		tempManager.Discard(tempFile)
+		return fmt.Errorf("failed to write temp file: %w", err)
+	}
+
+	if err := integrityChecker.ValidateFormat(tempFile.TempPath); err != nil {
+		tempManager.Discard(tempFile)
+		return fmt.Errorf("config validation failed: %w", err)
+	}
+
+	if err := runExternalValidator(appConfig, tempFile.TempPath, configPath); err != nil {
+		tempManager.Discard(tempFile)
+		return err
+	}
+
+	if err := tempManager.CommitTemp(tempFile); err != nil {
+		return commitError(appConfig, configPath, err)
 	}
+	l.recordChanges(appConfig, configPath, before, l.captureFiles(appConfig, configPath))
 
 	return nil
 }
 
-// loadCustomFormat handles custom formats (like Ghostty).
-func (l *Loader) loadCustomFormat(configPath string) (*koanf.Koanf, error) {
-	return ParseGhosttyConfig(configPath)
+// lockTarget takes the cross-process lock on a config file. Callers that
+// read before writing should hold it from the read; it is reentrant, so the
+// save paths taking it again is harmless.
+func lockTarget(appConfig *AppConfig, configPath string) (*filelock.Lock, error) {
+	lock, err := filelock.Acquire(configPath, filelock.Options{})
+	if err != nil {
+		if zerr, ok := errors.GetZeroUIError(err); ok {
+			return nil, zerr.WithApp(appConfig.Name)
+		}
+		return nil, err
+	}
+	return lock, nil
+}
+
+// ExpandTargetPath expands a leading ~ in a target config path and, when
+// zeroui runs under --root, rebases it into the root
+func ExpandTargetPath(configPath string) (string, error) {
+	return paths.Expand(configPath), nil
 }
 
 // saveCustomFormatWithTemp handles saving custom formats to a temporary file.
-func (l *Loader) saveCustomFormatWithTemp(tempPath string, k *koanf.Koanf, originalPath string) error {
-	return WriteGhosttyConfig(tempPath, k, originalPath)
+func (l *Loader) saveCustomFormatWithTemp(tempPath string, k *koanf.Koanf, originalPath string, removed []string) error {
+	return WriteGhosttyConfigRemoving(tempPath, k, originalPath, removed)
 }
 
 // initFileWatcher initializes the file watcher for cache invalidation.
@@ -10,6 +10,8 @@ import (
 	"testing"
 
 	"github.com/knadh/koanf/v2"
+	"github.com/mrtkrcm/ZeroUI/internal/audit"
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
 )
 
 // TestLoader_LoadAppConfig tests loading application configurations
@@ -618,3 +620,159 @@ func TestLoader_SaveTargetConfig(t *testing.T) {
 		}
 	})
 }
+
+// TestLoader_SaveTargetConfigThroughSymlink tests that saves update the file a
+// dotfiles symlink points to and keep its permissions
+func TestLoader_SaveTargetConfigThroughSymlink(t *testing.T) {
+	dir := t.TempDir()
+	real := filepath.Join(dir, "dotfiles", "config")
+	link := filepath.Join(dir, "home", "config")
+	for _, d := range []string{filepath.Dir(real), filepath.Dir(link)} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(real, []byte("font-size = 13\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(real, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("../dotfiles/config", link); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := &Loader{}
+	appConfig := &AppConfig{Name: "ghostty", Path: link, Format: "custom"}
+	k, err := loader.LoadTargetConfig(appConfig)
+	if err != nil {
+		t.Fatalf("LoadTargetConfig failed: %v", err)
+	}
+	if err := k.Set("font-size", "16"); err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.SaveTargetConfig(appConfig, k); err != nil {
+		t.Fatalf("SaveTargetConfig failed: %v", err)
+	}
+
+	if info, err := os.Lstat(link); err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("Expected %s to still be a symlink (err %v)", link, err)
+	}
+	data, err := os.ReadFile(real)
+	if err != nil || !strings.Contains(string(data), "font-size = 16") {
+		t.Errorf("Expected the link target to be updated, got %q (err %v)", data, err)
+	}
+	if info, _ := os.Stat(real); info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected mode 0600 to be kept, got %v", info.Mode().Perm())
+	}
+}
+
+func TestLoader_SaveTargetConfigRemovingLastKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	if err := os.WriteFile(path, []byte("font-size = 13\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := &Loader{}
+	appConfig := &AppConfig{Name: "ghostty", Path: path, Format: "custom"}
+	k, err := loader.LoadTargetConfig(appConfig)
+	if err != nil {
+		t.Fatalf("LoadTargetConfig failed: %v", err)
+	}
+	k.Delete("font-size")
+	if err := loader.SaveTargetConfigWithOptions(appConfig, k, SaveOptions{RemoveKeys: []string{"font-size"}}); err != nil {
+		t.Fatalf("Expected removing the only setting to succeed, got %v", err)
+	}
+
+	if data, err := os.ReadFile(path); err != nil || len(data) != 0 {
+		t.Errorf("Expected an empty config, got %q (err %v)", data, err)
+	}
+}
+
+func TestLoader_TargetConfigUnderRoot(t *testing.T) {
+	root := t.TempDir()
+	if err := paths.SetRoot(root); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = paths.SetRoot("") })
+
+	sandboxed := filepath.Join(root, ".config", "ghostty", "config")
+	if err := os.MkdirAll(filepath.Dir(sandboxed), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(sandboxed, []byte("font-size = 13\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	loader := &Loader{}
+	appConfig := &AppConfig{Name: "ghostty", Path: "~/.config/ghostty/config", Format: "custom"}
+	k, err := loader.LoadTargetConfig(appConfig)
+	if err != nil {
+		t.Fatalf("LoadTargetConfig failed: %v", err)
+	}
+	if got := k.String("font-size"); got != "13" {
+		t.Errorf("Expected the config under the root to be read, got font-size %q", got)
+	}
+	if err := k.Set("font-size", "16"); err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.SaveTargetConfig(appConfig, k); err != nil {
+		t.Fatalf("SaveTargetConfig failed: %v", err)
+	}
+	if data, _ := os.ReadFile(sandboxed); !strings.Contains(string(data), "font-size = 16") {
+		t.Errorf("Expected the config under the root to be written, got %q", data)
+	}
+
+	// Absolute paths outside the home directory are rebased as well
+	if got, _ := ExpandTargetPath("/etc/app.conf"); got != filepath.Join(root, "etc", "app.conf") {
+		t.Errorf("Expected /etc/app.conf to be rebased, got %s", got)
+	}
+}
+
+func TestLoader_SaveTargetConfigRecordsAudit(t *testing.T) {
+	state := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", state)
+
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte("theme: dark\nfont-size: 13\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	before, _ := fileHash(path)
+
+	loader := &Loader{}
+	appConfig := &AppConfig{Name: "myapp", Path: path, Format: "yaml"}
+	k, err := loader.LoadTargetConfig(appConfig)
+	if err != nil {
+		t.Fatalf("LoadTargetConfig failed: %v", err)
+	}
+	if err := k.Set("theme", "light"); err != nil {
+		t.Fatal(err)
+	}
+	if err := loader.SaveTargetConfig(appConfig, k); err != nil {
+		t.Fatalf("SaveTargetConfig failed: %v", err)
+	}
+	// Saving the same values again changes nothing and isn't recorded
+	if err := loader.SaveTargetConfig(appConfig, k); err != nil {
+		t.Fatalf("SaveTargetConfig failed: %v", err)
+	}
+	after, _ := fileHash(path)
+
+	log := audit.NewLog(filepath.Join(state, "zeroui", "audit.jsonl"))
+	if summary, err := log.Verify(); err != nil || summary.Entries != 1 {
+		t.Fatalf("Expected one audit entry, got %+v, %v", summary, err)
+	}
+	data, _ := os.ReadFile(log.Path())
+	var entry audit.Entry
+	if err := json.Unmarshal(data, &entry); err != nil {
+		t.Fatalf("Invalid audit entry %s: %v", data, err)
+	}
+	if entry.App != "myapp" || entry.File != path {
+		t.Errorf("Unexpected app or file in %+v", entry)
+	}
+	if !reflect.DeepEqual(entry.Keys, []string{"theme"}) {
+		t.Errorf("Expected keys [theme], got %v", entry.Keys)
+	}
+	if entry.Before != before || entry.After != after {
+		t.Errorf("Expected checksums %s -> %s, got %s -> %s", before, after, entry.Before, entry.After)
+	}
+}
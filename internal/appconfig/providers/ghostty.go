@@ -13,7 +13,8 @@ import (
 // GhosttyProvider is a koanf provider for Ghostty's custom configuration format.
 // It implements the Provider interface from github.com/knadh/koanf/providers
 type GhosttyProvider struct {
-	path string
+	path      string
+	positions map[string][]int
 }
 
 // NewGhosttyProvider creates a new Ghostty config provider.
@@ -35,6 +36,12 @@ func (p *GhosttyProvider) Read() ([]byte, error) {
 	return p.convertGhosttyToProperties(file)
 }
 
+// Positions returns the 1-based line numbers of each key seen by the last
+// Read or ReadBytes, in file order. Repeated keys have one line per occurrence.
+func (p *GhosttyProvider) Positions() map[string][]int {
+	return p.positions
+}
+
 // ReadBytes reads Ghostty config from a byte slice.
 func (p *GhosttyProvider) ReadBytes(b []byte) ([]byte, error) {
 	reader := strings.NewReader(string(b))
@@ -48,8 +55,11 @@ func (p *GhosttyProvider) convertGhosttyToProperties(r io.Reader) ([]byte, error
 
 	// Track multiple values for the same key
 	keyValues := make(map[string][]string)
+	p.positions = make(map[string][]int)
+	lineNo := 0
 
 	for scanner.Scan() {
+		lineNo++
 		line := strings.TrimSpace(scanner.Text())
 
 		// Skip comments and empty lines
@@ -79,6 +89,7 @@ func (p *GhosttyProvider) convertGhosttyToProperties(r io.Reader) ([]byte, error
 
 		// Collect values for each key
 		keyValues[key] = append(keyValues[key], value)
+		p.positions[key] = append(p.positions[key], lineNo)
 	}
 
 	if err := scanner.Err(); err != nil {
@@ -197,6 +208,11 @@ func NewGhosttyProviderWithParser(path string) *GhosttyProviderWithParser {
 	}
 }
 
+// Positions returns the line numbers recorded by the last LoadIntoKoanf
+func (p *GhosttyProviderWithParser) Positions() map[string][]int {
+	return p.provider.Positions()
+}
+
 // LoadIntoKoanf loads Ghostty config directly into a koanf instance.
 func (p *GhosttyProviderWithParser) LoadIntoKoanf(k *koanf.Koanf) error {
 	// Read the config file using the provider
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"sync"
 
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
 	"github.com/mrtkrcm/ZeroUI/pkg/reference"
 )
 
@@ -44,11 +45,11 @@ func NewReferenceEnhancedLoader() (*ReferenceEnhancedLoader, error) {
 		}
 
 		if !found {
-			// Create a basic configs directory as fallback
-			if err := os.MkdirAll("configs", 0o755); err != nil {
+			// Fall back to the cache directory rather than the working directory
+			configsDir = paths.ReferenceCacheDir()
+			if err := os.MkdirAll(configsDir, 0o755); err != nil {
 				return nil, fmt.Errorf("failed to create configs directory: %w", err)
 			}
-			configsDir = "configs"
 		}
 	}
 
@@ -81,6 +82,8 @@ func convertReferenceAppConfig(refConfig *reference.AppConfig) *AppConfig {
 			Default:     refField.Default,
 			Description: refField.Description,
 			Path:        refField.Path,
+			Required:    refField.Required,
+			EnvOverride: refField.EnvOverride,
 		}
 	}
 
@@ -117,6 +120,8 @@ func convertToReferenceAppConfig(config *AppConfig) *reference.AppConfig {
 			Default:     field.Default,
 			Description: field.Description,
 			Path:        field.Path,
+			Required:    field.Required,
+			EnvOverride: field.EnvOverride,
 		}
 	}
 
@@ -161,8 +166,12 @@ func (l *ReferenceEnhancedLoader) LoadAppConfigWithReference(appName string) (*A
 		return appConfig, nil
 	}
 
-	// Convert merged config back to config package format
-	return convertReferenceAppConfig(mergedRefConfig), nil
+	// Convert merged config back to config package format; include and
+	// validator settings only exist in app definitions
+	merged := convertReferenceAppConfig(mergedRefConfig)
+	merged.Includes = appConfig.Includes
+	merged.Validator = appConfig.Validator
+	return merged, nil
 }
 
 // LoadAppConfig overrides base method to use reference-enhanced loading
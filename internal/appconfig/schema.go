@@ -0,0 +1,61 @@
+package appconfig
+
+import (
+	"reflect"
+
+	"github.com/mrtkrcm/ZeroUI/pkg/reference"
+)
+
+// supportedFormats lists the config formats LoadTargetConfig understands
+var supportedFormats = []interface{}{"json", "yaml", "toml", "custom"}
+
+// supportedFieldTypes lists the field types the toggle engine understands
+var supportedFieldTypes = []interface{}{"choice", "string", "number", "boolean"}
+
+// AppJSONSchema describes an application's target config file as JSON Schema,
+// combining reference settings with the app definition's fields. Either may be nil.
+func AppJSONSchema(ref *reference.ConfigReference, app *AppConfig) map[string]interface{} {
+	var refApp *reference.AppConfig
+	if app != nil {
+		refApp = convertToReferenceAppConfig(app)
+	}
+	return reference.ExportJSONSchema(ref, refApp)
+}
+
+// AppDefinitionJSONSchema describes the app-definition YAML files in <config dir>/apps
+func AppDefinitionJSONSchema() map[string]interface{} {
+	schema := reference.SchemaForType(reflect.TypeOf(AppConfig{}))
+	schema["$schema"] = reference.JSONSchemaDraft
+	schema["title"] = "ZeroUI app definition"
+	schema["required"] = []interface{}{"name", "path", "format"}
+
+	props := schema["properties"].(map[string]interface{})
+	props["format"].(map[string]interface{})["enum"] = supportedFormats
+
+	field := props["fields"].(map[string]interface{})["additionalProperties"].(map[string]interface{})
+	field["required"] = []interface{}{"type"}
+	field["properties"].(map[string]interface{})["type"].(map[string]interface{})["enum"] = supportedFieldTypes
+
+	preset := props["presets"].(map[string]interface{})["additionalProperties"].(map[string]interface{})
+	preset["required"] = []interface{}{"values"}
+
+	return schema
+}
+
+// AppsRegistryJSONSchema describes apps_registry.yaml and the user's apps.yaml
+func AppsRegistryJSONSchema() map[string]interface{} {
+	schema := reference.SchemaForType(reflect.TypeOf(AppsRegistry{}))
+	schema["$schema"] = reference.JSONSchemaDraft
+	schema["title"] = "ZeroUI apps registry"
+
+	props := schema["properties"].(map[string]interface{})
+
+	app := props["applications"].(map[string]interface{})["items"].(map[string]interface{})
+	app["required"] = []interface{}{"name"}
+	app["properties"].(map[string]interface{})["config_format"].(map[string]interface{})["enum"] = supportedFormats
+
+	category := props["categories"].(map[string]interface{})["items"].(map[string]interface{})
+	category["required"] = []interface{}{"name"}
+
+	return schema
+}
@@ -0,0 +1,55 @@
+package appconfig
+
+import (
+	"reflect"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+func TestAppDefinitionJSONSchema(t *testing.T) {
+	schema := AppDefinitionJSONSchema()
+
+	if !reflect.DeepEqual(schema["required"], []interface{}{"name", "path", "format"}) {
+		t.Errorf("unexpected required fields: %v", schema["required"])
+	}
+
+	props := schema["properties"].(map[string]interface{})
+	for _, key := range []string{"name", "path", "format", "fields", "presets", "hooks", "env"} {
+		if _, ok := props[key]; !ok {
+			t.Errorf("expected property %q in app definition schema", key)
+		}
+	}
+
+	format := props["format"].(map[string]interface{})
+	if !reflect.DeepEqual(format["enum"], supportedFormats) {
+		t.Errorf("expected format enum, got %v", format["enum"])
+	}
+}
+
+func TestAppsRegistryJSONSchema(t *testing.T) {
+	schema := AppsRegistryJSONSchema()
+	props := schema["properties"].(map[string]interface{})
+
+	app := props["applications"].(map[string]interface{})["items"].(map[string]interface{})
+	appProps := app["properties"].(map[string]interface{})
+
+	// Every key used by the embedded registry must be described by the schema
+	var raw struct {
+		Applications []map[string]interface{} `yaml:"applications"`
+	}
+	if err := yaml.Unmarshal([]byte(defaultRegistry), &raw); err != nil {
+		t.Fatalf("failed to parse embedded registry: %v", err)
+	}
+	for _, entry := range raw.Applications {
+		for key := range entry {
+			if _, ok := appProps[key]; !ok {
+				t.Errorf("registry key %q missing from schema", key)
+			}
+		}
+	}
+
+	if _, ok := props["appsByName"]; ok {
+		t.Error("expected unexported fields to be excluded")
+	}
+}
@@ -0,0 +1,301 @@
+package appconfig
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/knadh/koanf/v2"
+	"github.com/mrtkrcm/ZeroUI/internal/appconfig/providers"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+// SourcePosition locates a setting in a config file
+type SourcePosition struct {
+	File string
+	Line int // 1-based
+}
+
+// String formats the position as file:line
+func (p SourcePosition) String() string {
+	return fmt.Sprintf("%s:%d", p.File, p.Line)
+}
+
+// SourceMap records where each key of a parsed config was set. Repeated keys
+// (Ghostty lists) have one position per occurrence, in file order. Nested keys
+// use koanf's dotted paths.
+type SourceMap map[string][]SourcePosition
+
+// Last returns the position of the last occurrence of key, which is the one
+// that takes effect for single-valued settings
+func (m SourceMap) Last(key string) (SourcePosition, bool) {
+	positions := m[key]
+	if len(positions) == 0 {
+		return SourcePosition{}, false
+	}
+	return positions[len(positions)-1], true
+}
+
+// LoadTargetConfigWithSources loads the target config like LoadTargetConfig
+// and records the line each key was set on
+func (l *Loader) LoadTargetConfigWithSources(appConfig *AppConfig) (*koanf.Koanf, SourceMap, error) {
+	configPath, err := ExpandTargetPath(appConfig.Path)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if strings.ToLower(appConfig.Format) == "custom" {
+		return ParseGhosttyConfigWithSources(configPath)
+	}
+
+	// Only the main file, so positions match values when it has includes
+	k, err := loadConfigFile(configPath, appConfig.Format)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read target config: %w", err)
+	}
+
+	format := appConfig.Format
+	if format == "" {
+		format = strings.TrimPrefix(strings.ToLower(filepath.Ext(configPath)), ".")
+	}
+	sources, err := LocateKeys(format, configPath, data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return k, sources, nil
+}
+
+// ParseGhosttyConfigWithSources parses a Ghostty-style config like
+// ParseGhosttyConfig and records the line of every key occurrence
+func ParseGhosttyConfigWithSources(configPath string) (*koanf.Koanf, SourceMap, error) {
+	provider := providers.NewGhosttyProviderWithParser(configPath)
+
+	k := koanf.New(".")
+	if err := provider.LoadIntoKoanf(k); err != nil {
+		return nil, nil, fmt.Errorf("failed to load Ghostty config: %w", err)
+	}
+	collapseSingleItemLists(k)
+
+	return k, newSourceMap(configPath, provider.Positions()), nil
+}
+
+// LocateKeys finds the line each key is set on in config data of the given
+// format (custom, json, yaml or toml). Only keys koanf exposes are recorded:
+// objects inside arrays are treated as part of the array value.
+func LocateKeys(format, file string, data []byte) (SourceMap, error) {
+	switch strings.ToLower(format) {
+	case "custom":
+		provider := providers.NewGhosttyProvider(file)
+		if _, err := provider.ReadBytes(data); err != nil {
+			return nil, err
+		}
+		return newSourceMap(file, provider.Positions()), nil
+	case "json":
+		return locateJSONKeys(file, data)
+	case "yaml", "yml":
+		return locateYAMLKeys(file, data)
+	case "toml":
+		return locateTOMLKeys(file, data), nil
+	default:
+		return nil, fmt.Errorf("unsupported config format: %s", format)
+	}
+}
+
+func newSourceMap(file string, lines map[string][]int) SourceMap {
+	sources := make(SourceMap, len(lines))
+	for key, keyLines := range lines {
+		for _, line := range keyLines {
+			sources[key] = append(sources[key], SourcePosition{File: file, Line: line})
+		}
+	}
+	return sources
+}
+
+// locateJSONKeys walks the JSON token stream, recording the line of every
+// object key outside arrays
+func locateJSONKeys(file string, data []byte) (SourceMap, error) {
+	type frame struct {
+		object    bool
+		inArray   bool // inside an array at any depth
+		prefix    string
+		key       string
+		expectKey bool
+	}
+
+	sources := make(SourceMap)
+	dec := json.NewDecoder(bytes.NewReader(data))
+	var stack []*frame
+
+	// valueDone marks the current object member as complete
+	valueDone := func() {
+		if len(stack) > 0 && stack[len(stack)-1].object {
+			stack[len(stack)-1].expectKey = true
+		}
+	}
+
+	for {
+		tok, err := dec.Token()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse JSON: %w", err)
+		}
+
+		var top *frame
+		if len(stack) > 0 {
+			top = stack[len(stack)-1]
+		}
+
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			next := &frame{object: tok == json.Delim('{'), expectKey: true}
+			if top != nil {
+				next.inArray = top.inArray || !top.object
+				if top.object {
+					next.prefix = top.prefix + top.key + "."
+				}
+			}
+			stack = append(stack, next)
+			continue
+		case json.Delim('}'), json.Delim(']'):
+			stack = stack[:len(stack)-1]
+			valueDone()
+			continue
+		}
+
+		if top != nil && top.object && top.expectKey {
+			top.key = fmt.Sprint(tok)
+			top.expectKey = false
+			if !top.inArray {
+				line := bytes.Count(data[:dec.InputOffset()], []byte("\n")) + 1
+				path := top.prefix + top.key
+				sources[path] = append(sources[path], SourcePosition{File: file, Line: line})
+			}
+			continue
+		}
+		valueDone()
+	}
+
+	return sources, nil
+}
+
+// locateYAMLKeys records the line of every mapping key outside sequences
+func locateYAMLKeys(file string, data []byte) (SourceMap, error) {
+	sources := make(SourceMap)
+
+	var walk func(node *yamlv3.Node, prefix string)
+	walk = func(node *yamlv3.Node, prefix string) {
+		switch node.Kind {
+		case yamlv3.DocumentNode:
+			for _, child := range node.Content {
+				walk(child, prefix)
+			}
+		case yamlv3.MappingNode:
+			for i := 0; i+1 < len(node.Content); i += 2 {
+				keyNode, valueNode := node.Content[i], node.Content[i+1]
+				path := prefix + keyNode.Value
+				sources[path] = append(sources[path], SourcePosition{File: file, Line: keyNode.Line})
+				walk(valueNode, path+".")
+			}
+		}
+	}
+
+	dec := yamlv3.NewDecoder(bytes.NewReader(data))
+	for {
+		var doc yamlv3.Node
+		if err := dec.Decode(&doc); err != nil {
+			if errors.Is(err, io.EOF) {
+				break
+			}
+			return nil, fmt.Errorf("failed to parse YAML: %w", err)
+		}
+		walk(&doc, "")
+	}
+
+	return sources, nil
+}
+
+// locateTOMLKeys records the line of every key in TOML data, prefixed by its
+// table. Keys of array tables ([[name]]) are part of the array value.
+func locateTOMLKeys(file string, data []byte) SourceMap {
+	sources := make(SourceMap)
+	prefix := ""
+	inArrayTable := false
+	stringDelim := ""
+	arrayDepth := 0
+
+	for i, raw := range splitLines(data) {
+		if stringDelim != "" {
+			if strings.Count(raw, stringDelim)%2 == 1 {
+				stringDelim = ""
+			}
+			continue
+		}
+		if arrayDepth > 0 {
+			arrayDepth += tomlBracketDepth(raw)
+			continue
+		}
+
+		line := strings.TrimSpace(raw)
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+			continue
+		case strings.HasPrefix(line, "[["):
+			inArrayTable = true
+			continue
+		case strings.HasPrefix(line, "["):
+			inArrayTable = false
+			header := strings.TrimPrefix(line, "[")
+			if end := strings.Index(header, "]"); end >= 0 {
+				header = header[:end]
+			}
+			prefix = tomlKeyPath(header) + "."
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		for _, delim := range []string{`"""`, `'''`} {
+			if strings.Count(value, delim)%2 == 1 {
+				stringDelim = delim
+				break
+			}
+		}
+		if stringDelim == "" {
+			arrayDepth = max(tomlBracketDepth(value), 0)
+		}
+
+		if inArrayTable {
+			continue
+		}
+		path := prefix + tomlKeyPath(key)
+		sources[path] = append(sources[path], SourcePosition{File: file, Line: i + 1})
+	}
+
+	return sources
+}
+
+// tomlKeyPath normalizes a dotted TOML key, removing quotes and whitespace
+// around each part
+func tomlKeyPath(key string) string {
+	parts := strings.Split(key, ".")
+	for i, part := range parts {
+		parts[i] = strings.Trim(strings.TrimSpace(part), `"'`)
+	}
+	return strings.Join(parts, ".")
+}
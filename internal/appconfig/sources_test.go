@@ -0,0 +1,74 @@
+package appconfig
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLocateKeys(t *testing.T) {
+	tests := []struct {
+		name   string
+		format string
+		input  string
+		want   map[string][]int
+	}{
+		{
+			name:   "custom with repeated keys",
+			format: "custom",
+			input:  "# comment\nfont-size = 13\n\nkeybind = ctrl+a=copy\nkeybind = ctrl+v=paste\n",
+			want:   map[string][]int{"font-size": {2}, "keybind": {4, 5}},
+		},
+		{
+			name:   "json nested objects, arrays are values",
+			format: "json",
+			input:  "{\n  \"theme\": \"One\",\n  \"terminal\": {\n    \"font_size\": 14\n  },\n  \"list\": [{\"inner\": 1}]\n}\n",
+			want:   map[string][]int{"theme": {2}, "terminal": {3}, "terminal.font_size": {4}, "list": {6}},
+		},
+		{
+			name:   "yaml nested mappings",
+			format: "yaml",
+			input:  "font:\n  size: 12\n  normal:\n    family: Iosevka\nitems:\n  - name: x\n",
+			want:   map[string][]int{"font": {1}, "font.size": {2}, "font.normal": {3}, "font.normal.family": {4}, "items": {5}},
+		},
+		{
+			name:   "toml tables, multi-line values and array tables",
+			format: "toml",
+			input:  "title = \"x\"\nlist = [\n  1,\n]\n[font]\nsize = 12\n\"quoted key\" = 1\n[[hints]]\nregex = \"a\"\n[window]\nopacity = 0.9\n",
+			want:   map[string][]int{"title": {1}, "list": {2}, "font.size": {6}, "font.quoted key": {7}, "window.opacity": {11}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sources, err := LocateKeys(tt.format, "cfg", []byte(tt.input))
+			require.NoError(t, err)
+
+			got := make(map[string][]int, len(sources))
+			for key, positions := range sources {
+				for _, pos := range positions {
+					assert.Equal(t, "cfg", pos.File)
+					got[key] = append(got[key], pos.Line)
+				}
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestLoadTargetConfigWithSources(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config")
+	require.NoError(t, os.WriteFile(path, []byte("font-size = 13\nfont-size = 14\n"), 0o644))
+
+	loader := &Loader{}
+	k, sources, err := loader.LoadTargetConfigWithSources(&AppConfig{Path: path, Format: "custom"})
+	require.NoError(t, err)
+
+	assert.NotNil(t, k.Get("font-size"))
+	pos, ok := sources.Last("font-size")
+	require.True(t, ok)
+	assert.Equal(t, path+":2", pos.String())
+}
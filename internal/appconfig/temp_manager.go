@@ -4,6 +4,7 @@ import (
 	"context"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -12,8 +13,22 @@ import (
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
+	"github.com/mrtkrcm/ZeroUI/internal/safefile"
+	"github.com/mrtkrcm/ZeroUI/internal/security"
 )
 
+// ErrOriginalChanged is returned by CommitTemp when the original file no
+// longer matches the contents the temporary copy was made from
+var ErrOriginalChanged = errors.New("file was changed by another program since it was read")
+
+// IsOriginalChanged reports whether err came from a refused commit because
+// the original file changed underneath it
+func IsOriginalChanged(err error) bool {
+	return errors.Is(err, ErrOriginalChanged)
+}
+
 // TempFileManager manages temporary files for safe configuration editing
 type TempFileManager struct {
 	tempDir   string
@@ -38,6 +53,9 @@ type TempFile struct {
 	OriginalHash string
 	CreatedAt    time.Time
 	LockFile     string
+	// AllowEmpty lets an empty file be committed, for saves that leave no
+	// settings; otherwise an empty file is taken for a failed write
+	AllowEmpty bool
 }
 
 // TempFileOptions configures the temporary file manager
@@ -51,7 +69,7 @@ type TempFileOptions struct {
 // DefaultTempFileOptions returns sensible defaults
 func DefaultTempFileOptions() *TempFileOptions {
 	return &TempFileOptions{
-		TempDir:    filepath.Join(os.TempDir(), fmt.Sprintf("zeroui-temp-%d", os.Getpid())),
+		TempDir:    filepath.Join(paths.TempDir(), fmt.Sprintf("zeroui-temp-%d", os.Getpid())),
 		MaxBackups: 5,
 		MaxTempAge: 24 * time.Hour,
 		BufferSize: 32 * 1024, // 32KB buffer for file operations
@@ -185,7 +203,7 @@ func (m *TempFileManager) ValidateTemp(tempFile *TempFile) error {
 	if err != nil {
 		return fmt.Errorf("failed to stat temp file: %w", err)
 	}
-	if info.Size() == 0 {
+	if info.Size() == 0 && !tempFile.AllowEmpty {
 		return fmt.Errorf("temporary file is empty")
 	}
 
@@ -222,9 +240,28 @@ func (m *TempFileManager) CommitTempWithContext(ctx context.Context, tempFile *T
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
+	// Compare-and-swap: don't replace edits made after the copy was taken
+	currentHash, err := m.calculateFileHashWithContext(ctx, tempFile.OriginalPath)
+	if err != nil {
+		atomic.AddUint64(&m.errors, 1)
+		return fmt.Errorf("failed to check original file: %w", err)
+	}
+	if currentHash != tempFile.OriginalHash {
+		atomic.AddUint64(&m.errors, 1)
+		return fmt.Errorf("%w: %s", ErrOriginalChanged, tempFile.OriginalPath)
+	}
+
+	// Write through symlinks (dotfiles managers link configs into a repo) to
+	// the real file, so the link itself is kept
+	target, err := security.ResolvePath(tempFile.OriginalPath)
+	if err != nil {
+		atomic.AddUint64(&m.errors, 1)
+		return err
+	}
+
 	// Create backup of original if it exists
 	originalExists := false
-	if info, err := os.Stat(tempFile.OriginalPath); err == nil {
+	if info, err := os.Stat(target); err == nil {
 		originalExists = true
 		// Ensure we're not overwriting a directory
 		if info.IsDir() {
@@ -232,25 +269,26 @@ func (m *TempFileManager) CommitTempWithContext(ctx context.Context, tempFile *T
 			return fmt.Errorf("cannot overwrite directory %q", tempFile.OriginalPath)
 		}
 
-		if err := m.createBackupWithRotation(tempFile.OriginalPath, tempFile.BackupPath); err != nil {
+		if err := m.createBackupWithRotation(target, tempFile.BackupPath); err != nil {
 			atomic.AddUint64(&m.errors, 1)
 			return fmt.Errorf("failed to create backup: %w", err)
 		}
 	}
 
 	// Ensure target directory exists
-	targetDir := filepath.Dir(tempFile.OriginalPath)
+	targetDir := filepath.Dir(target)
 	if err := os.MkdirAll(targetDir, 0o755); err != nil {
 		atomic.AddUint64(&m.errors, 1)
 		return fmt.Errorf("failed to create target directory: %w", err)
 	}
 
-	// Platform-specific atomic rename
-	commitErr := m.atomicRename(tempFile.TempPath, tempFile.OriginalPath)
+	// Replace the file atomically in its own directory, keeping its mode and
+	// ownership
+	commitErr := safefile.Replace(tempFile.TempPath, target)
 	if commitErr != nil {
-		// Try to restore backup if rename failed and we had an original
+		// Try to restore backup if the replace failed and we had an original
 		if originalExists {
-			if restoreErr := m.atomicRename(tempFile.BackupPath, tempFile.OriginalPath); restoreErr != nil {
+			if restoreErr := safefile.Replace(tempFile.BackupPath, target); restoreErr != nil {
 				// Critical error: couldn't restore backup
 				atomic.AddUint64(&m.errors, 1)
 				return fmt.Errorf("commit failed and backup restore failed: commit=%v, restore=%v", commitErr, restoreErr)
@@ -261,10 +299,10 @@ func (m *TempFileManager) CommitTempWithContext(ctx context.Context, tempFile *T
 	}
 
 	// Verify the committed file
-	if _, err := os.Stat(tempFile.OriginalPath); err != nil {
+	if _, err := os.Stat(target); err != nil {
 		// Try to restore from backup
 		if originalExists {
-			m.atomicRename(tempFile.BackupPath, tempFile.OriginalPath)
+			safefile.Replace(tempFile.BackupPath, target)
 		}
 		atomic.AddUint64(&m.errors, 1)
 		return fmt.Errorf("commit verification failed: %w", err)
@@ -277,6 +315,17 @@ func (m *TempFileManager) CommitTempWithContext(ctx context.Context, tempFile *T
 	return nil
 }
 
+// Discard drops a temporary copy that is not going to be committed. The
+// original was never replaced, so it is left exactly as it is; use it for
+// failures before CommitTemp, where Rollback would put an older backup back.
+func (m *TempFileManager) Discard(tempFile *TempFile) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.cleanup(tempFile)
+	delete(m.tempFiles, tempFile.OriginalPath)
+}
+
 // Rollback discards changes and restores from backup if needed
 func (m *TempFileManager) Rollback(tempFile *TempFile) error {
 	m.mu.Lock()
@@ -288,7 +337,7 @@ func (m *TempFileManager) Rollback(tempFile *TempFile) error {
 
 	// Restore from backup if it exists
 	if _, err := os.Stat(tempFile.BackupPath); err == nil {
-		if err := os.Rename(tempFile.BackupPath, tempFile.OriginalPath); err != nil {
+		if err := safefile.Replace(tempFile.BackupPath, tempFile.OriginalPath); err != nil {
 			return fmt.Errorf("failed to restore from backup: %w", err)
 		}
 	}
@@ -477,35 +526,6 @@ func (m *TempFileManager) createBackupWithRotation(src, dst string) error {
 	return m.copyFile(src, dst)
 }
 
-// atomicRename performs an atomic rename operation with platform-specific handling
-func (m *TempFileManager) atomicRename(src, dst string) error {
-	// On Windows, we need to remove the destination first
-	if runtime.GOOS == "windows" {
-		// Try to remove destination if it exists
-		if _, err := os.Stat(dst); err == nil {
-			if err := os.Remove(dst); err != nil {
-				// If we can't remove, try to move it aside
-				tempDst := dst + ".old"
-				os.Rename(dst, tempDst)
-				defer os.Remove(tempDst)
-			}
-		}
-	}
-
-	// Perform the rename
-	if err := os.Rename(src, dst); err != nil {
-		// If rename fails, try copy and delete
-		if copyErr := m.copyFile(src, dst); copyErr != nil {
-			return fmt.Errorf("rename failed, copy also failed: rename=%v, copy=%v", err, copyErr)
-		}
-		// Remove source after successful copy
-		os.Remove(src)
-		return nil
-	}
-
-	return nil
-}
-
 // StartPeriodicCleanup starts a goroutine that periodically cleans up stale files
 func (m *TempFileManager) StartPeriodicCleanup(ctx context.Context, interval time.Duration) {
 	go m.periodicCleanup(ctx, interval)
@@ -6,6 +6,8 @@ import (
 	"path/filepath"
 	"regexp"
 	"strings"
+
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
 )
 
 // Validator provides configuration validation
@@ -55,11 +57,8 @@ func (v *Validator) addDefaultRules() {
 			return fmt.Errorf("value must be a string")
 		}
 
-		// Expand home directory
-		if strings.HasPrefix(path, "~") {
-			home, _ := os.UserHomeDir()
-			path = strings.Replace(path, "~", home, 1)
-		}
+		// Expand home directory, rebased under --root
+		path = paths.Expand(path)
 
 		if _, err := os.Stat(path); err != nil {
 			return fmt.Errorf("path does not exist: %s", path)
@@ -1,6 +1,8 @@
 package atomic
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -9,6 +11,7 @@ import (
 
 	"github.com/knadh/koanf/v2"
 	"github.com/mrtkrcm/ZeroUI/internal/appconfig"
+	"github.com/mrtkrcm/ZeroUI/internal/audit"
 	"github.com/mrtkrcm/ZeroUI/internal/recovery"
 )
 
@@ -128,10 +131,17 @@ func (op *Operation) WriteConfig(appConfig *appconfig.AppConfig, configData map[
 		k.Set(key, value)
 	}
 
-	if err := loader.SaveTargetConfig(&appconfig.AppConfig{
+	before, err := fileChecksum(op.filePath)
+	if err != nil {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+
+	// The temporary file isn't the config; the change is recorded once it
+	// replaces it
+	if err := loader.SaveTargetConfigWithOptions(&appconfig.AppConfig{
 		Path:   tempPath,
 		Format: appConfig.Format,
-	}, k); err != nil {
+	}, k, appconfig.SaveOptions{NoAudit: true}); err != nil {
 		// Clean up temp file
 		os.Remove(tempPath)
 		return fmt.Errorf("failed to write temporary config: %w", err)
@@ -144,9 +154,34 @@ func (op *Operation) WriteConfig(appConfig *appconfig.AppConfig, configData map[
 		return fmt.Errorf("failed to atomically replace config file: %w", err)
 	}
 
+	after, _ := fileChecksum(op.filePath)
+	err = audit.Record(audit.Entry{
+		App:    appConfig.Name,
+		File:   op.filePath,
+		Before: before,
+		After:  after,
+		Backup: audit.TakeBackup(op.filePath),
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record change to %s in the audit log: %v\n", op.filePath, err)
+	}
+
 	return nil
 }
 
+// fileChecksum returns the SHA-256 of a file, or "" when it doesn't exist
+func fileChecksum(path string) (string, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
 // Commit completes the operation successfully
 func (op *Operation) Commit() {
 	if op.lock != nil {
@@ -10,8 +10,13 @@ import (
 	"time"
 
 	"github.com/mrtkrcm/ZeroUI/internal/appconfig"
+	"github.com/mrtkrcm/ZeroUI/test/helpers"
 )
 
+func TestMain(m *testing.M) {
+	helpers.RunTestMainWithCleanup(m, "internal/atomic", "zeroui-internal-atomic-test-home-", nil)
+}
+
 // setupAtomicTest creates a test environment for atomic operations
 func setupAtomicTest(t *testing.T) (string, func()) {
 	tmpDir, err := os.MkdirTemp("", "atomic-test")
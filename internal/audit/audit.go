@@ -0,0 +1,211 @@
+// Package audit keeps an append-only log of configuration changes. Each
+// change is one JSON line recording who changed which keys of which file, the
+// file's checksums before and after, and the backup taken first.
+//
+// Entries are hash-chained: every entry carries the hash of the one before it
+// and a hash over its own content, so editing, reordering or deleting an entry
+// breaks the chain at that point, which Verify reports. Removing entries from
+// the end can't be detected from the log alone; compare the head hash Verify
+// returns with one noted earlier.
+package audit
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/mrtkrcm/ZeroUI/internal/errors"
+	"github.com/mrtkrcm/ZeroUI/internal/filelock"
+)
+
+// Entry is one configuration change
+type Entry struct {
+	// Seq numbers entries from 1
+	Seq     int       `json:"seq"`
+	Time    time.Time `json:"time"`
+	User    string    `json:"user"`
+	Command string    `json:"command"`
+	App     string    `json:"app"`
+	File    string    `json:"file"`
+	// Keys lists the keys whose values changed; it is empty when they can't
+	// be told apart, as for a restore that replaces the whole file
+	Keys []string `json:"keys,omitempty"`
+	// Before and After are the SHA-256 of the file; Before is empty when the
+	// change created it
+	Before string `json:"before"`
+	After  string `json:"after"`
+	// Backup is the backup taken before the change, if any
+	Backup string `json:"backup,omitempty"`
+	// Restored is the backup the file was restored from, for restores
+	Restored string `json:"restored,omitempty"`
+
+	// Prev is the hash of the previous entry, empty for the first
+	Prev string `json:"prev"`
+	// Hash covers every other field
+	Hash string `json:"hash"`
+}
+
+// hash computes the entry's hash from every field but Hash
+func (e Entry) hash() (string, error) {
+	e.Hash = ""
+	data, err := json.Marshal(e)
+	if err != nil {
+		return "", err
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), nil
+}
+
+// Log is an audit log file
+type Log struct {
+	path string
+}
+
+// NewLog returns the audit log stored at path
+func NewLog(path string) *Log {
+	return &Log{path: path}
+}
+
+// Path returns the log's file
+func (l *Log) Path() string {
+	return l.path
+}
+
+// Append chains an entry to the end of the log, filling in its sequence
+// number and hashes, and returns it as written
+func (l *Log) Append(e Entry) (Entry, error) {
+	if err := os.MkdirAll(filepath.Dir(l.path), 0o700); err != nil {
+		return e, fmt.Errorf("failed to create audit log directory: %w", err)
+	}
+
+	// Other zeroui processes append to the same log
+	lock, err := filelock.Acquire(l.path, filelock.Options{})
+	if err != nil {
+		return e, err
+	}
+	defer lock.Release()
+
+	f, err := os.OpenFile(l.path, os.O_RDWR|os.O_CREATE|os.O_APPEND, 0o600)
+	if err != nil {
+		return e, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	last, err := lastLine(f)
+	if err != nil {
+		return e, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	e.Seq, e.Prev = 1, ""
+	if len(last) > 0 {
+		var prev Entry
+		if err := json.Unmarshal(last, &prev); err != nil {
+			return e, errors.Wrap(errors.AuditTampered, "the last audit log entry is damaged", err).
+				WithContext("log", l.path).
+				WithSuggestions("Check the log with: zeroui audit verify")
+		}
+		e.Seq, e.Prev = prev.Seq+1, prev.Hash
+	}
+	if e.Hash, err = e.hash(); err != nil {
+		return e, err
+	}
+
+	line, err := json.Marshal(e)
+	if err != nil {
+		return e, err
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		return e, fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return e, f.Sync()
+}
+
+// Summary describes an intact log
+type Summary struct {
+	Entries int
+	// Head is the hash of the last entry, empty for an empty log
+	Head string
+}
+
+// Verify checks every entry's hash and its link to the previous entry. A
+// missing log is an empty one. A broken chain is an AuditTampered error
+// naming the first line that doesn't check out.
+func (l *Log) Verify() (*Summary, error) {
+	f, err := os.Open(l.path)
+	if os.IsNotExist(err) {
+		return &Summary{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer f.Close()
+
+	summary := &Summary{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for line := 1; scanner.Scan(); line++ {
+		var e Entry
+		dec := json.NewDecoder(bytes.NewReader(scanner.Bytes()))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(&e); err != nil {
+			return nil, l.broken(line, "entry is not valid", err)
+		}
+		if e.Seq != summary.Entries+1 {
+			return nil, l.broken(line, fmt.Sprintf("entry %d follows entry %d", e.Seq, summary.Entries), nil)
+		}
+		if e.Prev != summary.Head {
+			return nil, l.broken(line, "entry does not follow the one before it", nil)
+		}
+		if want, err := e.hash(); err != nil || want != e.Hash {
+			return nil, l.broken(line, "entry was modified", err)
+		}
+		summary.Entries++
+		summary.Head = e.Hash
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read audit log: %w", err)
+	}
+	return summary, nil
+}
+
+func (l *Log) broken(line int, reason string, err error) error {
+	msg := fmt.Sprintf("audit log chain is broken at line %d: %s", line, reason)
+	var zerr *errors.ZeroUIError
+	if err != nil {
+		zerr = errors.Wrap(errors.AuditTampered, msg, err)
+	} else {
+		zerr = errors.New(errors.AuditTampered, msg)
+	}
+	return zerr.WithContext("log", l.path).
+		WithSuggestions("Entries from this line on may have been edited, reordered or removed")
+}
+
+// lastLine returns the last line of f, without its newline
+func lastLine(f *os.File) ([]byte, error) {
+	info, err := f.Stat()
+	if err != nil {
+		return nil, err
+	}
+	size := info.Size()
+	for window := int64(4096); ; window *= 2 {
+		if window > size {
+			window = size
+		}
+		buf := make([]byte, window)
+		if _, err := f.ReadAt(buf, size-window); err != nil {
+			return nil, err
+		}
+		buf = bytes.TrimRight(buf, "\n")
+		if i := bytes.LastIndexByte(buf, '\n'); i >= 0 {
+			return buf[i+1:], nil
+		}
+		if window == size {
+			return buf, nil
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package audit
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mrtkrcm/ZeroUI/internal/errors"
+)
+
+func writeEntries(t *testing.T, log *Log, n int) []Entry {
+	t.Helper()
+	var entries []Entry
+	for i := 0; i < n; i++ {
+		e, err := log.Append(Entry{
+			App:    "ghostty",
+			File:   "/home/octo/.config/ghostty/config",
+			Keys:   []string{"font-size"},
+			Before: strings.Repeat("a", 64),
+			After:  strings.Repeat("b", 64),
+		})
+		if err != nil {
+			t.Fatalf("Append failed: %v", err)
+		}
+		entries = append(entries, e)
+	}
+	return entries
+}
+
+func TestLogAppendAndVerify(t *testing.T) {
+	log := NewLog(filepath.Join(t.TempDir(), "state", "audit.jsonl"))
+
+	summary, err := log.Verify()
+	if err != nil || summary.Entries != 0 {
+		t.Fatalf("Expected a missing log to be empty, got %+v, %v", summary, err)
+	}
+
+	entries := writeEntries(t, log, 3)
+	if entries[0].Seq != 1 || entries[0].Prev != "" {
+		t.Errorf("Expected the first entry to start the chain, got %+v", entries[0])
+	}
+	if entries[2].Seq != 3 || entries[2].Prev != entries[1].Hash {
+		t.Errorf("Expected entry 3 to follow entry 2, got %+v", entries[2])
+	}
+
+	summary, err = log.Verify()
+	if err != nil {
+		t.Fatalf("Verify failed: %v", err)
+	}
+	if summary.Entries != 3 || summary.Head != entries[2].Hash {
+		t.Errorf("Unexpected summary %+v", summary)
+	}
+	if info, _ := os.Stat(log.Path()); info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected the log to be private, got %v", info.Mode().Perm())
+	}
+}
+
+func TestLogVerifyDetectsTampering(t *testing.T) {
+	tests := map[string]struct {
+		tamper func(lines [][]byte) [][]byte
+		line   string
+	}{
+		"modified entry": {
+			tamper: func(lines [][]byte) [][]byte {
+				lines[1] = bytes.Replace(lines[1], []byte("font-size"), []byte("theme"), 1)
+				return lines
+			},
+			line: "line 2",
+		},
+		"deleted entry": {
+			tamper: func(lines [][]byte) [][]byte {
+				return append(lines[:1], lines[2:]...)
+			},
+			line: "line 2",
+		},
+		"reordered entries": {
+			tamper: func(lines [][]byte) [][]byte {
+				lines[0], lines[1] = lines[1], lines[0]
+				return lines
+			},
+			line: "line 1",
+		},
+		"added field": {
+			tamper: func(lines [][]byte) [][]byte {
+				lines[2] = bytes.Replace(lines[2], []byte(`{"seq"`), []byte(`{"note":"x","seq"`), 1)
+				return lines
+			},
+			line: "line 3",
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			log := NewLog(filepath.Join(t.TempDir(), "audit.jsonl"))
+			writeEntries(t, log, 3)
+
+			data, err := os.ReadFile(log.Path())
+			if err != nil {
+				t.Fatal(err)
+			}
+			lines := tt.tamper(bytes.Split(bytes.TrimSuffix(data, []byte("\n")), []byte("\n")))
+			if err := os.WriteFile(log.Path(), append(bytes.Join(lines, []byte("\n")), '\n'), 0o600); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = log.Verify()
+			zerr, ok := errors.GetZeroUIError(err)
+			if !ok || zerr.Type != errors.AuditTampered {
+				t.Fatalf("Expected an AuditTampered error, got %v", err)
+			}
+			if !strings.Contains(zerr.Message, tt.line) {
+				t.Errorf("Expected the error to name %s, got %q", tt.line, zerr.Message)
+			}
+		})
+	}
+}
+
+func TestRecord(t *testing.T) {
+	state := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", state)
+	SetCommand("zeroui toggle")
+	t.Cleanup(func() { SetCommand("") })
+
+	file := filepath.Join(t.TempDir(), "config")
+	NoteBackup(file, "/backups/ghostty_20240101_120000.backup")
+	backup := TakeBackup(file)
+	if backup != "ghostty_20240101_120000.backup" {
+		t.Errorf("Expected the noted backup, got %q", backup)
+	}
+	if again := TakeBackup(file); again != "" {
+		t.Errorf("Expected a backup to be taken once, got %q", again)
+	}
+
+	// A change that left the file as it was isn't recorded
+	if err := Record(Entry{App: "ghostty", File: file, Before: "same", After: "same"}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+	if err := Record(Entry{App: "ghostty", File: file, Before: "", After: "new", Backup: backup}); err != nil {
+		t.Fatalf("Record failed: %v", err)
+	}
+
+	log := NewLog(filepath.Join(state, "zeroui", "audit.jsonl"))
+	summary, err := log.Verify()
+	if err != nil || summary.Entries != 1 {
+		t.Fatalf("Expected one entry, got %+v, %v", summary, err)
+	}
+
+	data, _ := os.ReadFile(log.Path())
+	for _, want := range []string{`"command":"zeroui toggle"`, `"user":"`, `"before":""`, `"after":"new"`, `"backup":"ghostty_20240101_120000.backup"`} {
+		if !strings.Contains(string(data), want) {
+			t.Errorf("Expected the entry to contain %s, got %s", want, data)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package audit
+
+import (
+	"os"
+	"os/user"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
+)
+
+var command atomic.Value
+
+func init() {
+	command.Store("")
+}
+
+// SetCommand names the command recorded with the changes that follow, such
+// as "zeroui toggle". Arguments are left out as they can hold secret values.
+func SetCommand(name string) {
+	command.Store(name)
+}
+
+// backups holds, by config path, the backup taken before the change being made
+var backups sync.Map
+
+// NoteBackup remembers the backup just taken of a config, for the change
+// about to be made to it
+func NoteBackup(configPath, backupPath string) {
+	backups.Store(filepath.Clean(configPath), backupPath)
+}
+
+// ForgetBackup drops the backup noted for a config, for backups that are
+// removed once the change succeeds
+func ForgetBackup(configPath string) {
+	backups.Delete(filepath.Clean(configPath))
+}
+
+// TakeBackup returns the name of the backup noted for a config and forgets
+// it, or "" when none was taken
+func TakeBackup(configPath string) string {
+	backup, ok := backups.LoadAndDelete(filepath.Clean(configPath))
+	if !ok {
+		return ""
+	}
+	return filepath.Base(backup.(string))
+}
+
+// Record appends a change to the audit log in the zeroui state directory,
+// filling in the time, user and command. Changes that left the file as it
+// was are not recorded.
+func Record(e Entry) error {
+	if e.Before == e.After {
+		return nil
+	}
+	e.Time = time.Now().UTC()
+	e.User = currentUser()
+	if e.Command == "" {
+		e.Command = command.Load().(string)
+	}
+	if e.Command == "" {
+		e.Command = filepath.Base(os.Args[0])
+	}
+	_, err := NewLog(paths.AuditLog()).Append(e)
+	return err
+}
+
+// currentUser returns the name of the user running zeroui
+var currentUser = sync.OnceValue(func() string {
+	if u, err := user.Current(); err == nil && u.Username != "" {
+		return u.Username
+	}
+	for _, v := range []string{"USER", "USERNAME"} {
+		if name := os.Getenv(v); name != "" {
+			return name
+		}
+	}
+	return "unknown"
+})
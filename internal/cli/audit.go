@@ -0,0 +1,63 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mrtkrcm/ZeroUI/internal/audit"
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
+)
+
+func newAuditCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "audit",
+		Short: "Check the log of configuration changes",
+		Long: `Every configuration change zeroui makes is appended to an audit log with the time,
+user, command, app, file, changed keys, the file's checksums before and after, and the
+backup taken first. The log lives in the zeroui state directory (audit.jsonl).
+
+Each entry carries the hash of the entry before it, so edited, reordered or deleted
+entries break the chain.`,
+		Example: `  zeroui audit verify
+  zeroui audit verify ./audit.jsonl`,
+		Args: cobra.NoArgs,
+	}
+
+	cmd.AddCommand(newAuditVerifyCmd())
+
+	return cmd
+}
+
+func newAuditVerifyCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "verify [log]",
+		Short: "Check that the audit log's hash chain is intact",
+		Long: `Check every entry of the audit log against its hash and the entry before it.
+The command fails naming the first entry that doesn't check out.
+
+The hash of the last entry is printed: entries removed from the end of the log
+can only be noticed by comparing it with a hash noted earlier.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := paths.AuditLog()
+			if len(args) > 0 {
+				path = args[0]
+			}
+
+			summary, err := audit.NewLog(path).Verify()
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			if summary.Entries == 0 {
+				fmt.Fprintf(out, "No changes recorded in %s\n", path)
+				return nil
+			}
+			fmt.Fprintf(out, "✓ Audit log intact: %d entries in %s\n", summary.Entries, path)
+			fmt.Fprintf(out, "  Head: %s\n", summary.Head)
+			return nil
+		},
+	}
+}
@@ -9,10 +9,13 @@ import (
 	"path/filepath"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"golang.org/x/term"
 
+	"github.com/mrtkrcm/ZeroUI/internal/crypt"
 	"github.com/mrtkrcm/ZeroUI/internal/errors"
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
 	"github.com/mrtkrcm/ZeroUI/internal/recovery"
 	"github.com/mrtkrcm/ZeroUI/internal/toggle"
 	"github.com/spf13/cobra"
@@ -25,9 +28,13 @@ func newBackupCmd() *cobra.Command {
 		Long: `Manage configuration backups for applications. You can list, create, restore, and cleanup backups.
 
 Backups are automatically created before any configuration changes to ensure you can recover
-from any issues. Use these commands to manually manage your backups.`,
+from any issues. Use these commands to manually manage your backups.
+
+Backups are encrypted when ZEROUI_BACKUP_PASSPHRASE is set or --backup-key names a key file.
+Restoring an encrypted backup needs the same passphrase or the key file's private key.`,
 		Example: `  zeroui backup list
   zeroui backup create ghostty
+  zeroui backup keygen -o ~/.config/zeroui/backup.key
   zeroui backup restore ghostty ghostty_20240101T120000.tar.gz
   zeroui backup cleanup ghostty --keep 3`,
 		Args: cobra.NoArgs,
@@ -37,6 +44,7 @@ from any issues. Use these commands to manually manage your backups.`,
 	cmd.AddCommand(newBackupCreateCmd())
 	cmd.AddCommand(newBackupRestoreCmd())
 	cmd.AddCommand(newBackupCleanupCmd())
+	cmd.AddCommand(newBackupKeygenCmd())
 
 	return cmd
 }
@@ -85,8 +93,8 @@ for that app will be shown.`,
 
 			// Display backups in a table
 			w := tabwriter.NewWriter(os.Stdout, 0, 8, 2, ' ', 0)
-			fmt.Fprintln(w, "APP\tTIME\tSIZE\tFILE")
-			fmt.Fprintln(w, "---\t----\t----\t----")
+			fmt.Fprintln(w, "APP\tTIME\tSIZE\tENCRYPTED\tFILE")
+			fmt.Fprintln(w, "---\t----\t----\t---------\t----")
 
 			for _, backup := range backups {
 				// Extract app name from backup filename
@@ -100,7 +108,12 @@ for that app will be shown.`,
 				// Format time
 				timeStr := backup.Created.Format("2006-01-02 15:04:05")
 
-				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", backupApp, timeStr, size, backup.Name)
+				encrypted := "no"
+				if backup.Encrypted {
+					encrypted = "yes"
+				}
+
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", backupApp, timeStr, size, encrypted, backup.Name)
 			}
 
 			w.Flush()
@@ -137,11 +150,7 @@ This is useful before making major changes or for creating restore points.`,
 			}
 
 			// Resolve config path
-			configPath := appConfig.Path
-			if strings.HasPrefix(configPath, "~") {
-				home, _ := os.UserHomeDir()
-				configPath = strings.Replace(configPath, "~", home, 1)
-			}
+			configPath := paths.Expand(appConfig.Path)
 
 			// Create backup
 			backupManager, err := recovery.NewBackupManager()
@@ -204,11 +213,7 @@ Use 'zeroui backup list <app>' to see available backups.`,
 			}
 
 			// Resolve config path
-			configPath := appConfig.Path
-			if strings.HasPrefix(configPath, "~") {
-				home, _ := os.UserHomeDir()
-				configPath = strings.Replace(configPath, "~", home, 1)
-			}
+			configPath := paths.Expand(appConfig.Path)
 
 			// Find backup with security validation
 			backupManager, err := recovery.NewBackupManager()
@@ -362,6 +367,65 @@ By default, keeps the 5 most recent backups per application.`,
 	return cmd
 }
 
+func newBackupKeygenCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "keygen [-o file]",
+		Short: "Generate a key for encrypting backups",
+		Long: `Generate an X25519 key pair for encrypting backups. The private key is written to
+the output file (or printed) and the public key is printed to stderr.
+
+Pass the file with --backup-key (or ZEROUI_BACKUP_KEY) to encrypt new backups to it and restore
+encrypted ones. A key file may also hold only public keys, one per line: backups are then
+encrypted on this machine but can only be restored where the private key is.`,
+		Example: `  zeroui backup keygen -o ~/.config/zeroui/backup.key
+  zeroui --backup-key ~/.config/zeroui/backup.key backup create ghostty`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output, _ := cmd.Flags().GetString("output")
+
+			id, err := crypt.GenerateX25519Identity()
+			if err != nil {
+				return fmt.Errorf("failed to generate key: %w", err)
+			}
+			recipient := id.Recipient().String()
+			content := fmt.Sprintf("# created: %s\n# public key: %s\n%s\n", time.Now().Format(time.RFC3339), recipient, id)
+
+			if output == "" {
+				fmt.Fprint(cmd.OutOrStdout(), content)
+				fmt.Fprintf(os.Stderr, "Public key: %s\n", recipient)
+				return nil
+			}
+
+			output = paths.ExpandHome(output)
+			if err := os.MkdirAll(filepath.Dir(output), 0o700); err != nil {
+				return fmt.Errorf("failed to create key directory: %w", err)
+			}
+			// Never overwrite a key: backups encrypted to it would be lost
+			f, err := os.OpenFile(output, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+			if err != nil {
+				if os.IsExist(err) {
+					fmt.Fprintf(os.Stderr, "Error: %s already exists; backups encrypted to it need it to be restored\n", output)
+					return nil
+				}
+				return fmt.Errorf("failed to write key file: %w", err)
+			}
+			if _, err := f.WriteString(content); err != nil {
+				f.Close()
+				return fmt.Errorf("failed to write key file: %w", err)
+			}
+			if err := f.Close(); err != nil {
+				return fmt.Errorf("failed to write key file: %w", err)
+			}
+
+			fmt.Printf("✓ Key written to %s\n", output)
+			fmt.Fprintf(os.Stderr, "Public key: %s\n", recipient)
+			return nil
+		},
+	}
+	cmd.Flags().StringP("output", "o", "", "file to write the key to (default: print it)")
+	return cmd
+}
+
 // formatSize formats a file size in bytes to a human-readable string
 func formatSize(bytes int64) string {
 	const (
@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mrtkrcm/ZeroUI/internal/appconfig"
+	"github.com/mrtkrcm/ZeroUI/internal/doctor"
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
+	"github.com/mrtkrcm/ZeroUI/internal/plugins/rpc"
+	"github.com/mrtkrcm/ZeroUI/internal/policy"
+	"github.com/mrtkrcm/ZeroUI/internal/recovery"
+	"github.com/mrtkrcm/ZeroUI/pkg/reference"
+)
+
+func newDoctorCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "doctor [app...]",
+		Short: "Check application configs and ZeroUI state for problems",
+		Long: `Scan every application in the apps registry and report problems with a suggested fix.
+
+Checks:
+  config       config files exist, are readable and well-formed
+  parse        config files parse in their declared format
+  unknown-key  keys missing from the reference, with closest-match suggestions
+  deprecated   renamed or removed settings that 'zeroui migrate' can rewrite
+  type, enum   values that don't match the reference type or allowed values
+  policy       values the organization policy (/etc/zeroui/policy.yaml) enforces,
+               restricts or forbids
+  temp-files   temp and lock files left behind by interrupted edits
+  backups      backup directory is writable and free of broken backups
+  plugins      installed plugins are executable and respond to health checks
+
+The command exits with an error when any error-level finding is reported.`,
+		Example: `  zeroui doctor
+  zeroui doctor ghostty alacritty
+  zeroui doctor --json --min-severity info`,
+		RunE: runDoctor,
+	}
+
+	cmd.Flags().Bool("json", false, "Output findings as JSON")
+	cmd.Flags().String("min-severity", "warning", "Lowest severity to report (error, warning, info)")
+	cmd.Flags().String("configs-dir", "configs", "Directory containing reference configs")
+	cmd.Flags().String("plugin-dir", "", "Plugin directory (default: <config dir>/plugins)")
+	cmd.Flags().Bool("skip-plugin-health", false, "Don't start plugins to check their health")
+
+	return cmd
+}
+
+func runDoctor(cmd *cobra.Command, args []string) error {
+	asJSON, _ := cmd.Flags().GetBool("json")
+	minSeverity, _ := cmd.Flags().GetString("min-severity")
+	configsDir, _ := cmd.Flags().GetString("configs-dir")
+	pluginDir, _ := cmd.Flags().GetString("plugin-dir")
+	skipPluginHealth, _ := cmd.Flags().GetBool("skip-plugin-health")
+
+	severity, err := doctor.ParseSeverity(minSeverity)
+	if err != nil {
+		return err
+	}
+
+	registry, err := appconfig.LoadAppsRegistry()
+	if err != nil {
+		return fmt.Errorf("failed to load apps registry: %w", err)
+	}
+	for _, app := range args {
+		if _, ok := registry.GetApp(app); !ok {
+			return fmt.Errorf("unknown app: %s", app)
+		}
+	}
+
+	opts := doctor.Options{
+		Registry:   registry,
+		Apps:       args,
+		References: reference.NewStaticConfigLoader(configsDir),
+		TempDir:    paths.TempDir(),
+		PolicyPath: policy.DefaultPath(),
+	}
+
+	if loader, err := appconfig.NewLoader(); err == nil {
+		opts.Loader = loader
+		if pluginDir == "" {
+			pluginDir = filepath.Join(loader.ConfigDir(), "plugins")
+		}
+	}
+	opts.PluginDir = pluginDir
+	if !skipPluginHealth {
+		opts.PluginCheck = pluginHealthCheck(pluginDir)
+	}
+
+	if backups, err := recovery.NewBackupManager(); err == nil {
+		opts.Backups = backups
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	report := doctor.New(opts).Run(ctx)
+	hasErrors := report.HasErrors()
+	report = report.Filter(severity)
+
+	if asJSON {
+		err = doctor.WriteJSON(cmd.OutOrStdout(), report)
+	} else {
+		err = doctor.WriteText(cmd.OutOrStdout(), report)
+	}
+	if err != nil {
+		return err
+	}
+
+	if hasErrors {
+		return fmt.Errorf("doctor found %d errors", report.Count(doctor.SeverityError))
+	}
+	return nil
+}
+
+// pluginHealthCheck starts each plugin in isolation and asks for its info
+func pluginHealthCheck(pluginDir string) func(ctx context.Context, name string) error {
+	return func(ctx context.Context, name string) error {
+		registry := rpc.NewRegistry(pluginDir)
+		defer registry.Shutdown()
+
+		if _, err := registry.LoadPlugin(name); err != nil {
+			return err
+		}
+		return registry.HealthCheck(name)
+	}
+}
@@ -12,6 +12,7 @@ import (
 	"github.com/spf13/cobra"
 	"gopkg.in/yaml.v3"
 
+	"github.com/mrtkrcm/ZeroUI/pkg/configextractor/source"
 	"github.com/mrtkrcm/ZeroUI/pkg/legacyextractor"
 )
 
@@ -20,6 +21,9 @@ func newExtractCmd() *cobra.Command {
 		extractAll    bool
 		extractOutput string
 		extractApps   string
+		extractMerge  bool
+		sourceMode    string
+		sourcePath    string
 	)
 
 	cmd := &cobra.Command{
@@ -28,23 +32,42 @@ func newExtractCmd() *cobra.Command {
 		Long:  `Extract configuration from applications using parallel methods (CLI, GitHub, local files).`,
 		Example: `  zeroui extract ghostty
   zeroui extract --all
-  zeroui extract --apps "ghostty,zed" --output configs`,
+  zeroui extract --apps "ghostty,zed" --output configs
+  zeroui extract ghostty --merge
+  zeroui extract --all --source record --source-path testdata/extract.cassette.json
+  zeroui extract --all --source replay --source-path testdata/extract.cassette.json
+  zeroui extract zed --source mirror --source-path ~/mirrors/github`,
 		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			return runExtract(cmd, args, extractAll, extractOutput, extractApps)
+			return runExtract(cmd, args, extractAll, extractOutput, extractApps, extractMerge, sourceMode, sourcePath)
 		},
 	}
 
 	cmd.Flags().BoolVar(&extractAll, "all", false, "Extract all known apps")
 	cmd.Flags().StringVarP(&extractOutput, "output", "o", "configs", "Output directory")
 	cmd.Flags().StringVar(&extractApps, "apps", "", "Comma-separated app list")
+	cmd.Flags().BoolVar(&extractMerge, "merge", false, "Merge all strategies with per-setting provenance instead of picking one")
+	addSourceFlags(cmd, &sourceMode, &sourcePath)
 
 	return cmd
 }
 
-func runExtract(cmd *cobra.Command, args []string, extractAll bool, extractOutput string, extractApps string) error {
+func runExtract(cmd *cobra.Command, args []string, extractAll bool, extractOutput string, extractApps string, extractMerge bool, sourceMode, sourcePath string) (err error) {
 	start := time.Now()
-	ext := legacyextractor.New()
+
+	var opts []legacyextractor.Option
+	if src, err := openExtractionSource(sourceMode, sourcePath); err != nil {
+		return err
+	} else if src != nil {
+		opts = append(opts, legacyextractor.WithSource(src))
+		defer func() {
+			if closeErr := source.Close(src); closeErr != nil && err == nil {
+				err = fmt.Errorf("failed to save cassette: %w", closeErr)
+			}
+		}()
+	}
+
+	ext := legacyextractor.New(opts...)
 	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 	defer cancel()
 
@@ -73,6 +96,17 @@ func runExtract(cmd *cobra.Command, args []string, extractAll bool, extractOutpu
 	// Extract all in parallel
 	configs := make(map[string]*legacyextractor.Config)
 	for _, app := range apps {
+		if extractMerge {
+			cfg, report, err := ext.ExtractMerged(ctx, app)
+			if err != nil {
+				fmt.Printf("Failed to extract %s: %v\n", app, err)
+				continue
+			}
+			configs[app] = cfg
+			printMergeReport(report)
+			continue
+		}
+
 		cfg, err := ext.Extract(ctx, app)
 		if err != nil {
 			fmt.Printf("Failed to extract %s: %v\n", app, err)
@@ -97,6 +131,45 @@ func runExtract(cmd *cobra.Command, args []string, extractAll bool, extractOutpu
 	return nil
 }
 
+// addSourceFlags registers the flags selecting where remote files are fetched from
+func addSourceFlags(cmd *cobra.Command, mode, path *string) {
+	cmd.Flags().StringVar(mode, "source", string(source.ModeLive), "Remote file source: live, mirror, record, replay")
+	cmd.Flags().StringVar(path, "source-path", "", "Mirror directory (owner/repo/branch/path) or cassette file for record/replay")
+}
+
+// openExtractionSource returns nil for live mode so strategies keep their tuned HTTP clients
+func openExtractionSource(mode, path string) (source.Source, error) {
+	if mode == "" || source.Mode(mode) == source.ModeLive {
+		return nil, nil
+	}
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, path[2:])
+		}
+	}
+	return source.New(source.Mode(mode), path)
+}
+
+func printMergeReport(report *legacyextractor.MergeReport) {
+	fmt.Printf("  %s: merged from %s", report.App, strings.Join(report.Sources, ", "))
+	if len(report.Conflicts) > 0 {
+		fmt.Printf(" (%d conflicts)", len(report.Conflicts))
+	}
+	fmt.Println()
+
+	for _, conflict := range report.Conflicts {
+		claims := make([]string, 0, len(conflict.Claims))
+		for _, claim := range conflict.Claims {
+			value := claim.Type
+			if conflict.Field == "default" {
+				value = fmt.Sprintf("%v", claim.Default)
+			}
+			claims = append(claims, fmt.Sprintf("%s=%s (%.2f)", claim.Strategy, value, claim.Confidence))
+		}
+		fmt.Printf("    ! %s %s: %s\n", conflict.Key, conflict.Field, strings.Join(claims, ", "))
+	}
+}
+
 func saveConfig(cfg *legacyextractor.Config, path string) error {
 	data, err := yaml.Marshal(cfg)
 	if err != nil {
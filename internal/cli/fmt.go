@@ -0,0 +1,120 @@
+package cli
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/mrtkrcm/ZeroUI/internal/appconfig"
+	"github.com/mrtkrcm/ZeroUI/internal/container"
+	"github.com/mrtkrcm/ZeroUI/internal/errors"
+	"github.com/mrtkrcm/ZeroUI/internal/secrets"
+	"github.com/mrtkrcm/ZeroUI/internal/toggle"
+)
+
+func newFmtCmd(getContainer func() (*container.Container, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "fmt <app>",
+		Short: "Normalize the layout of an application's config file",
+		Long: `Rewrite an application's config file in a canonical layout for its format.
+
+Formatting keeps comments and is idempotent: running it twice changes nothing
+the second time.
+
+  custom (Ghostty-style)  "key = value" spacing, single blank lines, and repeated
+                          lines that set a key to the value it already has removed
+  json                    two-space indentation, key order kept
+  yaml                    two-space indentation
+  toml                    "key = value" spacing and single blank lines
+
+The file is written through the backup-protected save path.`,
+		Example: `  zeroui fmt ghostty
+  zeroui fmt ghostty --diff
+  zeroui fmt zed --check`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runFmt(cmd, args[0], getContainer)
+		},
+	}
+
+	cmd.Flags().Bool("check", false, "Exit with an error if the file is not formatted, without writing")
+	cmd.Flags().BoolP("diff", "d", false, "Show the changes instead of writing them")
+
+	return cmd
+}
+
+func runFmt(cmd *cobra.Command, appName string, getContainer func() (*container.Container, error)) error {
+	check, _ := cmd.Flags().GetBool("check")
+	showDiff, _ := cmd.Flags().GetBool("diff")
+	out := cmd.OutOrStdout()
+
+	engine, err := toggleEngine(getContainer)
+	if err != nil {
+		return err
+	}
+
+	result, err := engine.Format(appName)
+	if err != nil {
+		return reportEngineError(err)
+	}
+
+	if !result.Changed() {
+		fmt.Fprintf(out, "%s %s is already formatted\n", successStyle.Render("✓"), result.Path)
+		return nil
+	}
+
+	if check {
+		fmt.Fprintf(out, "%s %s is not formatted\n", errorStyle.Render("✗"), result.Path)
+		return fmt.Errorf("%s needs formatting (run: zeroui fmt %s)", result.Path, appName)
+	}
+
+	if showDiff || viper.GetBool("dry-run") {
+		printFormatDiff(cmd, result)
+		if viper.GetBool("dry-run") {
+			fmt.Fprintf(out, "\n(DRY-RUN) Would rewrite %s\n", result.Path)
+		}
+		return nil
+	}
+
+	if err := engine.ApplyFormat(result); err != nil {
+		return reportEngineError(err)
+	}
+
+	fmt.Fprintf(out, "%s Formatted %s\n", successStyle.Render("✓"), result.Path)
+	return nil
+}
+
+// printFormatDiff shows the lines formatting changes, with secrets masked
+func printFormatDiff(cmd *cobra.Command, result *toggle.FormatResult) {
+	out := cmd.OutOrStdout()
+	fmt.Fprintln(out, headerStyle.Render(fmt.Sprintf("--- %s", result.Path)))
+	fmt.Fprintln(out, headerStyle.Render(fmt.Sprintf("+++ %s (formatted)", result.Path)))
+	diff := appconfig.LineDiff(
+		[]byte(secrets.RedactText(string(result.Original))),
+		[]byte(secrets.RedactText(string(result.Formatted))))
+	fmt.Fprint(out, appconfig.FormatLineDiff(diff, 1))
+}
+
+// toggleEngine returns the container's toggle engine
+func toggleEngine(getContainer func() (*container.Container, error)) (*toggle.Engine, error) {
+	c, err := getContainer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get container: %w", err)
+	}
+	if c == nil {
+		return nil, fmt.Errorf("application container not initialized")
+	}
+	return c.ToggleEngine(), nil
+}
+
+// reportEngineError prints ZeroUI errors with their suggestions and swallows
+// them to avoid double printing; other errors are returned
+func reportEngineError(err error) error {
+	if ctErr, ok := errors.GetZeroUIError(err); ok {
+		fmt.Fprintf(os.Stderr, "Error: %s\n", ctErr.String())
+		return nil
+	}
+	return err
+}
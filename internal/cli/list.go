@@ -1,13 +1,18 @@
 package cli
 
 import (
+	"context"
 	"fmt"
 	"sort"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mrtkrcm/ZeroUI/internal/appconfig"
 	"github.com/mrtkrcm/ZeroUI/internal/container"
+	"github.com/mrtkrcm/ZeroUI/internal/errors"
+	"github.com/mrtkrcm/ZeroUI/internal/secrets"
 	"github.com/mrtkrcm/ZeroUI/internal/service"
+	"github.com/mrtkrcm/ZeroUI/pkg/configextractor"
 	"github.com/spf13/cobra"
 )
 
@@ -23,11 +28,20 @@ Examples:
   zeroui list presets ghostty
   zeroui list keys ghostty
   zeroui list values ghostty
-  zeroui list changed ghostty`,
+  zeroui list changed ghostty
+
+With --explain, values lists every setting from the main config, its
+includes, theme and environment overrides, with the file and line that sets it.
+
+With --effective, values runs the app itself (ghostty +show-config, git config
+--list, tmux show-options -g, starship print-config) and shows the file value
+next to the value the app actually uses, highlighting mismatches.`,
 		Example: `  zeroui list apps
   zeroui list presets ghostty
   zeroui list keys ghostty
   zeroui list values ghostty
+  zeroui list values ghostty --explain
+  zeroui list values ghostty --effective
   zeroui list changed ghostty`,
 		Args: cobra.RangeArgs(1, 2),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -64,6 +78,16 @@ Examples:
 				if app == "" {
 					return fmt.Errorf("app name required for listing values")
 				}
+				explain, _ := cmd.Flags().GetBool("explain")
+				effective, _ := cmd.Flags().GetBool("effective")
+				switch {
+				case explain && effective:
+					return fmt.Errorf("--explain and --effective cannot be combined")
+				case explain:
+					return listExplainedValues(getContainer, app)
+				case effective:
+					return listEffectiveValues(cmd.Context(), configService, app)
+				}
 				return listCurrentValues(configService, app)
 			case "changed":
 				if app == "" {
@@ -75,6 +99,10 @@ Examples:
 			}
 		},
 	}
+
+	cmd.Flags().Bool("explain", false, "show the file and line each value comes from (values only)")
+	cmd.Flags().Bool("effective", false, "compare file values with the values the running app reports (values only)")
+
 	return cmd
 }
 
@@ -253,7 +281,7 @@ func listCurrentValues(configService *service.ConfigService, app string) error {
 	}
 
 	for _, key := range keys {
-		value := values[key]
+		value := secrets.Redact(key, values[key])
 		fmt.Printf("  %s %s\n",
 			listItemDisplayStyle.Render(fmt.Sprintf("%s:", key)),
 			listDescriptionStyle.Render(fmt.Sprintf("%v", value)))
@@ -262,6 +290,122 @@ func listCurrentValues(configService *service.ConfigService, app string) error {
 	return nil
 }
 
+func listExplainedValues(getContainer func() (*container.Container, error), app string) error {
+	res, err := resolveProvenance(getContainer, app, nil)
+	if err != nil {
+		return err
+	}
+
+	keys := res.Keys()
+	if len(keys) == 0 {
+		fmt.Printf("No current configuration values found for %s\n", app)
+		return nil
+	}
+
+	header := listHeaderStyle.Render(fmt.Sprintf("Current Configuration Values for %s", app))
+	count := listCountStyle.Render(fmt.Sprintf("(%d)", len(keys)))
+	fmt.Printf("%s %s\n\n", header, count)
+
+	for _, key := range keys {
+		src := res.Explain(key).Effective
+		fmt.Printf("  %s %s %s\n",
+			listItemDisplayStyle.Render(fmt.Sprintf("%s:", key)),
+			listDescriptionStyle.Render(fmt.Sprintf("%v", secrets.Redact(key, src.Value))),
+			listDescriptionStyle.Render(fmt.Sprintf("(%s, %s)", src.Location(), src.Kind)))
+	}
+
+	for _, warning := range res.Warnings {
+		fmt.Println(warningStyle.Render("! " + warning))
+	}
+
+	return nil
+}
+
+func listEffectiveValues(ctx context.Context, configService *service.ConfigService, app string) error {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	reader := configextractor.NewEffectiveReader(nil)
+	if !reader.CanRead(app) {
+		return errors.New(errors.UserInputError, fmt.Sprintf("%s cannot report its effective configuration", app)).
+			WithApp(app).
+			WithSuggestions("Supported apps: " + strings.Join(reader.Apps(), ", "))
+	}
+
+	fileValues, err := configService.GetCurrentValues(app)
+	if err != nil {
+		return err
+	}
+
+	// Point the tool at the file zeroui edits, where it can be told
+	var configPath string
+	if appConfig, err := configService.GetApplicationConfig(app); err == nil {
+		configPath, _ = appconfig.ExpandTargetPath(appConfig.Path)
+	}
+
+	effective, err := reader.ReadConfig(ctx, app, configPath)
+	if err != nil {
+		return errors.Wrap(errors.SystemFileError, "failed to read effective configuration", err).
+			WithApp(app).
+			WithSuggestions(fmt.Sprintf("Check that %s is installed and on PATH", app))
+	}
+
+	rows := configextractor.CompareEffective(fileValues, effective)
+	if len(rows) == 0 {
+		fmt.Printf("No configuration values found for %s\n", app)
+		return nil
+	}
+
+	header := listHeaderStyle.Render(fmt.Sprintf("File vs Effective Values for %s", app))
+	fmt.Printf("%s %s\n", header, listCountStyle.Render(fmt.Sprintf("(%d)", len(rows))))
+	fmt.Printf("%s\n\n", listDescriptionStyle.Render("effective values from: "+effective.Command))
+
+	width := 0
+	for _, row := range rows {
+		width = max(width, len(row.Key))
+	}
+
+	mismatches := 0
+	for _, row := range rows {
+		fileValue, effectiveValue := "-", "-"
+		if row.File != nil {
+			fileValue = fmt.Sprintf("%v", secrets.Redact(row.Key, row.File))
+		}
+		switch {
+		case row.Effective != nil:
+			effectiveValue = fmt.Sprintf("%v", secrets.Redact(row.Key, row.Effective))
+		case row.Status == configextractor.EffectiveDefault:
+			effectiveValue = "(default)"
+		}
+
+		status := listDescriptionStyle.Render(string(row.Status))
+		if row.Mismatch() {
+			mismatches++
+			status = warningStyle.Render(string(row.Status))
+		}
+		if row.Origin != "" {
+			status += " " + listDescriptionStyle.Render("("+row.Origin+")")
+		}
+
+		fmt.Printf("  %s %s %s %s  %s\n",
+			listItemDisplayStyle.Render(fmt.Sprintf("%-*s", width+1, row.Key+":")),
+			fileValue,
+			listDescriptionStyle.Render("→"),
+			effectiveValue,
+			status)
+	}
+
+	fmt.Println()
+	if mismatches > 0 {
+		fmt.Println(warningStyle.Render(fmt.Sprintf("%d value(s) set in the file are not what %s uses", mismatches, app)))
+	} else {
+		fmt.Println(successStyle.Render("The file values match what " + app + " uses"))
+	}
+
+	return nil
+}
+
 func listChangedValues(configService *service.ConfigService, app string) error {
 	// Get current values
 	currentValues, err := configService.GetCurrentValues(app)
@@ -276,53 +420,19 @@ func listChangedValues(configService *service.ConfigService, app string) error {
 	}
 
 	// Compare with defaults and find changed values
-	var changedKeys []string
-	changedValues := make(map[string]interface{})
-
-	for key, field := range appConfig.Fields {
-		currentValue, hasCurrent := currentValues[key]
-		defaultValue := field.Default
-
-		// If field has a current value different from default, it's changed
-		if hasCurrent {
-			// Simple comparison - could be enhanced for complex types
-			currentStr := fmt.Sprintf("%v", currentValue)
-			defaultStr := fmt.Sprintf("%v", defaultValue)
-			if currentStr != defaultStr {
-				changedKeys = append(changedKeys, key)
-				changedValues[key] = currentValue
-			}
-		} else if defaultValue != nil {
-			// Field has a default but no current value - might be explicitly set to nil or empty
-			// For now, we'll consider this as potentially changed if the default is meaningful
-			defaultStr := fmt.Sprintf("%v", defaultValue)
-			if defaultStr != "" && defaultStr != "0" && defaultStr != "false" {
-				changedKeys = append(changedKeys, key)
-				changedValues[key] = currentValue // Will be nil or empty
-			}
-		}
-	}
+	changedKeys := appconfig.CompareWithDefaults(appConfig, currentValues).Changed
 
 	if len(changedKeys) == 0 {
 		fmt.Printf("No configuration values have been changed from defaults for %s\n", app)
 		return nil
 	}
 
-	// Sort keys for consistent output
-	for i := 0; i < len(changedKeys); i++ {
-		for j := i + 1; j < len(changedKeys); j++ {
-			if changedKeys[i] > changedKeys[j] {
-				changedKeys[i], changedKeys[j] = changedKeys[j], changedKeys[i]
-			}
-		}
-	}
-
 	header := listHeaderStyle.Render(fmt.Sprintf("Changed Configuration Values for %s", app))
 	count := listCountStyle.Render(fmt.Sprintf("(%d)", len(changedKeys)))
 	fmt.Printf("%s %s\n\n", header, count)
 
 	for _, key := range changedKeys {
-		value := changedValues[key]
+		value := secrets.Redact(key, currentValues[key])
 		field := appConfig.Fields[key]
 
 		// Show default value for context
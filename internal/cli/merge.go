@@ -0,0 +1,164 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/mrtkrcm/ZeroUI/internal/container"
+	"github.com/mrtkrcm/ZeroUI/internal/secrets"
+	"github.com/mrtkrcm/ZeroUI/internal/toggle"
+	"github.com/mrtkrcm/ZeroUI/pkg/configmerge"
+)
+
+func newMergeCmd(getContainer func() (*container.Container, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "merge <app> --base <file> --theirs <file>",
+		Short: "Three-way merge another version of an application's config",
+		Long: `Merge the changes made from a base config to another version ("theirs") into
+the application's current config.
+
+Keys only theirs changed are taken, keys only the current config changed are
+kept, and keys both changed to different values are conflicts. List settings,
+such as Ghostty keybinds, are merged entry by entry; keybinds are matched by
+trigger, so binding the same keys differently on both sides conflicts.
+
+Typical uses:
+  - reconcile a preset or shared team baseline with local edits
+    (base: the baseline you started from, theirs: its new version)
+  - restore a backup over newer changes
+    (base: the config the backup was taken from, theirs: the backup)
+
+Conflicts are shown with conflict markers and nothing is written, unless
+--prefer settles them. The merge is shown as a diff before anything is written,
+and the config is saved through the backup-protected save path.`,
+		Example: `  zeroui merge ghostty --base team-v1.conf --theirs team-v2.conf
+  zeroui merge ghostty --base old.conf --theirs backup.conf --prefer theirs
+  zeroui merge zed --base base.json --theirs shared.json --dry-run`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMerge(cmd, args[0], getContainer)
+		},
+	}
+
+	cmd.Flags().String("base", "", "Common ancestor of the current config and theirs")
+	cmd.Flags().String("theirs", "", "Config whose changes are merged in")
+	cmd.Flags().String("prefer", "", "Settle conflicts with one side's value (ours|theirs)")
+	cmd.Flags().BoolP("yes", "y", false, "skip confirmation prompt")
+	_ = cmd.MarkFlagRequired("base")
+	_ = cmd.MarkFlagRequired("theirs")
+
+	return cmd
+}
+
+func runMerge(cmd *cobra.Command, appName string, getContainer func() (*container.Container, error)) error {
+	basePath, _ := cmd.Flags().GetString("base")
+	theirsPath, _ := cmd.Flags().GetString("theirs")
+	prefer, _ := cmd.Flags().GetString("prefer")
+	out := cmd.OutOrStdout()
+
+	side := configmerge.Side(prefer)
+	switch side {
+	case "", configmerge.Ours, configmerge.Theirs:
+	default:
+		return fmt.Errorf("invalid --prefer %q: must be ours or theirs", prefer)
+	}
+
+	engine, err := toggleEngine(getContainer)
+	if err != nil {
+		return err
+	}
+
+	plan, err := engine.PlanMerge(appName, basePath, theirsPath, side)
+	if err != nil {
+		return reportEngineError(err)
+	}
+
+	if plan.Result.HasConflicts() {
+		fmt.Fprint(out, configmerge.FormatConflicts(plan.Result.Conflicts, configmerge.Labels{
+			Ours:   plan.Path,
+			Base:   plan.BasePath,
+			Theirs: plan.TheirsPath,
+		}, mergeLine(plan.Format)))
+		fmt.Fprintln(out)
+		return reportEngineError(engine.ApplyMerge(plan))
+	}
+
+	if !plan.HasChanges() {
+		fmt.Fprintf(out, "%s %s already has the changes from %s\n", successStyle.Render("✓"), plan.Path, plan.TheirsPath)
+		return nil
+	}
+
+	printMergeDiff(out, plan, prefer)
+
+	if viper.GetBool("dry-run") {
+		fmt.Fprintf(out, "\n(DRY-RUN) Would rewrite %s\n", plan.Path)
+		return nil
+	}
+
+	if !confirmChange(cmd, "Apply these changes?") {
+		fmt.Fprintln(out, "Merge cancelled")
+		return nil
+	}
+
+	if err := engine.ApplyMerge(plan); err != nil {
+		return reportEngineError(err)
+	}
+
+	fmt.Fprintf(out, "%s Merged %d settings into %s\n", successStyle.Render("✓"), len(plan.Result.Changed), plan.Path)
+	return nil
+}
+
+// printMergeDiff shows each key the merge changes as removed and added lines,
+// and the conflicts --prefer settled
+func printMergeDiff(out io.Writer, plan *toggle.MergePlan, prefer string) {
+	line := mergeLine(plan.Format)
+
+	fmt.Fprintln(out, headerStyle.Render(fmt.Sprintf("--- %s", plan.Path)))
+	fmt.Fprintln(out, headerStyle.Render(fmt.Sprintf("+++ %s (merged with %s)", plan.Path, filepath.Base(plan.TheirsPath))))
+
+	for _, key := range plan.Result.Changed {
+		if value, ok := plan.Ours[key]; ok {
+			for _, entry := range mergeEntries(value) {
+				fmt.Fprintln(out, errorStyle.Render("- "+line(key, entry)))
+			}
+		}
+		if value, ok := plan.Result.Merged[key]; ok {
+			for _, entry := range mergeEntries(value) {
+				fmt.Fprintln(out, successStyle.Render("+ "+line(key, entry)))
+			}
+		}
+	}
+
+	for _, c := range plan.Result.Resolved {
+		name := c.Key
+		if c.Entry != "" {
+			name += " " + c.Entry
+		}
+		fmt.Fprintln(out, warningStyle.Render(fmt.Sprintf("! %s: %s conflict settled with %s", name, c.Kind, prefer)))
+	}
+}
+
+// mergeLine renders config lines in the app's format for diffs and conflict
+// markers, with secrets masked
+func mergeLine(format string) configmerge.LineFunc {
+	line := configmerge.KeyValueLine
+	switch format {
+	case "yaml", "yml":
+		line = func(key string, value interface{}) string { return fmt.Sprintf("%s: %v", key, value) }
+	case "json":
+		line = func(key string, value interface{}) string { return fmt.Sprintf("%q: %v", key, value) }
+	}
+	return func(key string, value interface{}) string { return line(key, secrets.Redact(key, value)) }
+}
+
+// mergeEntries splits list values, such as keybinds, into one line per entry
+func mergeEntries(value interface{}) []interface{} {
+	if list, ok := value.([]interface{}); ok {
+		return list
+	}
+	return []interface{}{value}
+}
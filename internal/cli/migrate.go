@@ -0,0 +1,103 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/mrtkrcm/ZeroUI/internal/container"
+	"github.com/mrtkrcm/ZeroUI/internal/secrets"
+	"github.com/mrtkrcm/ZeroUI/internal/toggle"
+	"github.com/mrtkrcm/ZeroUI/pkg/reference"
+)
+
+func newMigrateCmd(getContainer func() (*container.Container, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "migrate <app>",
+		Short: "Rewrite deprecated settings in an application's config",
+		Long: `Rewrite renamed and removed settings using the deprecations recorded in the
+app's reference data.
+
+Renamed keys are moved to their replacement, deprecated values are mapped to
+their new spelling, and settings removed without a replacement are deleted.
+When both an old key and its replacement are set, the replacement wins.
+
+The changes are shown as a diff before anything is written. The config is saved
+through the same backup-protected path as toggle, so a failed write is rolled back.`,
+		Example: `  zeroui migrate ghostty
+  zeroui migrate ghostty --dry-run
+  zeroui migrate alacritty --yes`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runMigrate(cmd, args[0], getContainer)
+		},
+	}
+
+	cmd.Flags().String("configs-dir", "configs", "Directory containing reference configs")
+	cmd.Flags().BoolP("yes", "y", false, "skip confirmation prompt")
+
+	return cmd
+}
+
+func runMigrate(cmd *cobra.Command, appName string, getContainer func() (*container.Container, error)) error {
+	configsDir, _ := cmd.Flags().GetString("configs-dir")
+	out := cmd.OutOrStdout()
+
+	ref, err := reference.NewStaticConfigLoader(configsDir).LoadReference(appName)
+	if err != nil {
+		return fmt.Errorf("failed to load reference for %s: %w", appName, err)
+	}
+
+	engine, err := toggleEngine(getContainer)
+	if err != nil {
+		return err
+	}
+
+	plan, err := engine.PlanMigration(appName, ref)
+	if err != nil {
+		return reportEngineError(err)
+	}
+
+	if !plan.HasChanges() {
+		fmt.Fprintf(out, "%s No deprecated settings in %s\n", successStyle.Render("✓"), plan.Path)
+		return nil
+	}
+
+	printMigrationDiff(out, plan)
+
+	if viper.GetBool("dry-run") {
+		fmt.Fprintf(out, "\n(DRY-RUN) Would rewrite %s\n", plan.Path)
+		return nil
+	}
+
+	if !confirmChange(cmd, "Apply these changes?") {
+		fmt.Fprintln(out, "Migration cancelled")
+		return nil
+	}
+
+	if err := engine.ApplyMigration(plan); err != nil {
+		return reportEngineError(err)
+	}
+
+	fmt.Fprintf(out, "%s Migrated %d settings in %s\n", successStyle.Render("✓"), len(plan.Migrations), plan.Path)
+	return nil
+}
+
+// printMigrationDiff shows each migration as removed and added config lines
+func printMigrationDiff(out io.Writer, plan *toggle.MigrationPlan) {
+	fmt.Fprintln(out, headerStyle.Render(fmt.Sprintf("--- %s", plan.Path)))
+	fmt.Fprintln(out, headerStyle.Render(fmt.Sprintf("+++ %s (migrated)", plan.Path)))
+
+	for _, m := range plan.Migrations {
+		fmt.Fprintln(out, dimStyle.Render("# "+m.Deprecation.Message(m.Key)))
+		fmt.Fprintln(out, errorStyle.Render(fmt.Sprintf("- %s = %v", m.Key, secrets.Redact(m.Key, m.OldValue))))
+		switch {
+		case m.Conflict:
+			fmt.Fprintln(out, dimStyle.Render(fmt.Sprintf("  %s = %v (already set, kept)", m.NewKey, secrets.Redact(m.NewKey, m.NewValue))))
+		case !m.Dropped():
+			fmt.Fprintln(out, successStyle.Render(fmt.Sprintf("+ %s = %v", m.NewKey, secrets.Redact(m.NewKey, m.NewValue))))
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package cli
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// confirmChange asks before a command rewrites a config. It returns true when
+// --yes was passed or the user answered yes; non-interactive sessions are
+// never prompted and get a hint instead.
+func confirmChange(cmd *cobra.Command, question string) bool {
+	if yes, _ := cmd.Flags().GetBool("yes"); yes {
+		return true
+	}
+
+	if !term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintf(os.Stderr, "Non-interactive session detected. To run %s non-interactively pass --yes; to preview, use --dry-run.\n", cmd.Name())
+		return false
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "\n%s (y/N): ", question)
+	response, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	response = strings.ToLower(strings.TrimSpace(response))
+	return response == "y" || response == "yes"
+}
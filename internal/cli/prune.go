@@ -0,0 +1,74 @@
+package cli
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/mrtkrcm/ZeroUI/internal/container"
+	"github.com/mrtkrcm/ZeroUI/internal/secrets"
+)
+
+func newPruneCmd(getContainer func() (*container.Container, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "prune <app>",
+		Short: "Remove settings that are set to their default value",
+		Long: `Remove settings whose configured value equals the reference default.
+
+This is the complement of 'zeroui list changed': only settings listed there are
+kept. Settings without a known default are never removed. The file is written
+through the backup-protected save path.`,
+		Example: `  zeroui prune ghostty --dry-run
+  zeroui prune ghostty --yes`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runPrune(cmd, args[0], getContainer)
+		},
+	}
+
+	cmd.Flags().BoolP("yes", "y", false, "skip confirmation prompt")
+
+	return cmd
+}
+
+func runPrune(cmd *cobra.Command, appName string, getContainer func() (*container.Container, error)) error {
+	out := cmd.OutOrStdout()
+
+	engine, err := toggleEngine(getContainer)
+	if err != nil {
+		return err
+	}
+
+	plan, err := engine.PlanPrune(appName)
+	if err != nil {
+		return reportEngineError(err)
+	}
+
+	if !plan.HasChanges() {
+		fmt.Fprintf(out, "%s No default values to prune in %s\n", successStyle.Render("✓"), plan.Path)
+		return nil
+	}
+
+	fmt.Fprintln(out, headerStyle.Render(fmt.Sprintf("Settings equal to their default in %s", plan.Path)))
+	for _, key := range plan.Keys {
+		fmt.Fprintln(out, errorStyle.Render(fmt.Sprintf("- %s = %v", key, secrets.Redact(key, plan.Values[key]))))
+	}
+
+	if viper.GetBool("dry-run") {
+		fmt.Fprintf(out, "\n(DRY-RUN) Would remove %d settings\n", len(plan.Keys))
+		return nil
+	}
+
+	if !confirmChange(cmd, fmt.Sprintf("Remove %d settings?", len(plan.Keys))) {
+		fmt.Fprintln(out, "Prune cancelled")
+		return nil
+	}
+
+	if err := engine.ApplyPrune(plan); err != nil {
+		return reportEngineError(err)
+	}
+
+	fmt.Fprintf(out, "%s Removed %d settings from %s\n", successStyle.Render("✓"), len(plan.Keys), plan.Path)
+	return nil
+}
@@ -1,12 +1,21 @@
 package cli
 
 import (
+	"context"
+	"encoding/json"
+	stderrors "errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mrtkrcm/ZeroUI/internal/appconfig"
+	"github.com/mrtkrcm/ZeroUI/pkg/configextractor"
+	"github.com/mrtkrcm/ZeroUI/pkg/configextractor/source"
 	"github.com/mrtkrcm/ZeroUI/pkg/reference"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
@@ -77,6 +86,10 @@ Uses curated static configuration files instead of fragile web scraping.`,
 			Foreground(lipgloss.Color("9")).
 			Bold(true)
 
+	warningStyle = lipgloss.NewStyle().
+			Foreground(lipgloss.Color("11")).
+			Bold(true)
+
 	dimStyle = lipgloss.NewStyle().
 			Foreground(lipgloss.Color("8"))
 
@@ -97,7 +110,9 @@ Uses curated static configuration files instead of fragile web scraping.`,
 		Example: `  zeroui ref list
   zeroui ref show ghostty
   zeroui ref validate ghostty font_size 14
-  zeroui ref search zed theme`,
+  zeroui ref search zed theme
+  zeroui ref generate ghostty
+  zeroui ref export-schema zed`,
 		Args: cobra.NoArgs,
 	}
 
@@ -105,6 +120,8 @@ Uses curated static configuration files instead of fragile web scraping.`,
 	cmd.AddCommand(newRefShowCmd())
 	cmd.AddCommand(newRefValidateCmd())
 	cmd.AddCommand(newRefSearchCmd())
+	cmd.AddCommand(newRefGenerateCmd())
+	cmd.AddCommand(newRefExportSchemaCmd())
 
 	return cmd
 }
@@ -151,6 +168,61 @@ func newRefSearchCmd() *cobra.Command {
 	}
 }
 
+func newRefGenerateCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "generate [app]",
+		Short: "Generate a reference file from extraction output",
+		Long: `Run the extraction strategies for an application and merge the result
+into its curated reference file.
+
+Curated descriptions, categories, types and defaults are kept; extraction only
+fills in missing data. Settings that appear or disappear are reported so the
+resulting YAML can be reviewed like any other change.`,
+		Example: `  zeroui ref generate ghostty
+  zeroui ref generate zed --prune
+  zeroui ref generate ghostty --stdout
+  zeroui ref generate ghostty --dry-run
+  zeroui ref generate zed --schema ./zed-settings.schema.json`,
+		Args: cobra.ExactArgs(1),
+		RunE: runRefGenerate,
+	}
+
+	cmd.Flags().String("configs-dir", "configs", "Directory containing curated reference files")
+	cmd.Flags().StringP("output", "o", "", "Output file (default: <configs-dir>/<app>.yaml)")
+	cmd.Flags().Bool("prune", false, "Drop curated settings that extraction no longer reports")
+	cmd.Flags().Bool("stdout", false, "Write the generated reference to stdout instead of a file")
+	cmd.Flags().Duration("timeout", 30*time.Second, "Extraction timeout")
+	cmd.Flags().String("schema", "", "JSON Schema file or URL describing the app's settings")
+	var sourceMode, sourcePath string
+	addSourceFlags(cmd, &sourceMode, &sourcePath)
+
+	return cmd
+}
+
+func newRefExportSchemaCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "export-schema [app]",
+		Short: "Export a JSON Schema for editor integration",
+		Long: `Export a JSON Schema generated from the reference settings and app definition
+fields of an application (types, enums, defaults and descriptions).
+
+Use --kind to export the schema of ZeroUI's own file formats instead:
+  app-definition  app definition YAML files (~/.config/zeroui/apps/<app>.yaml)
+  registry        the apps registry format (apps.yaml)`,
+		Example: `  zeroui ref export-schema zed > zed-settings.schema.json
+  zeroui ref export-schema ghostty -o ghostty.schema.json
+  zeroui ref export-schema --kind app-definition
+  zeroui ref export-schema --kind registry`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runRefExportSchema,
+	}
+
+	cmd.Flags().String("kind", "app", "Schema to export: app, app-definition, registry")
+	cmd.Flags().StringP("output", "o", "", "Write the schema to a file instead of stdout")
+
+	return cmd
+}
+
 func setupImprovedManager() *reference.ReferenceManager {
 	configDir := "configs" // Relative to project root
 	loader := reference.NewStaticConfigLoader(configDir)
@@ -248,6 +320,13 @@ func runRefValidate(cmd *cobra.Command, args []string) error {
 		}
 	}
 
+	for _, warning := range result.Warnings {
+		fmt.Printf("%s Deprecated: %s\n", warningStyle.Render("!"), warning)
+	}
+	if len(result.Warnings) > 0 {
+		fmt.Printf("Run 'zeroui migrate %s' to update your config\n", appName)
+	}
+
 	if len(result.Suggestions) > 0 {
 		fmt.Printf("Suggestions: %s\n", strings.Join(result.Suggestions, ", "))
 	}
@@ -281,6 +360,207 @@ func runRefSearch(cmd *cobra.Command, args []string) error {
 	return nil
 }
 
+func runRefGenerate(cmd *cobra.Command, args []string) (err error) {
+	appName := args[0]
+	configsDir, _ := cmd.Flags().GetString("configs-dir")
+	output, _ := cmd.Flags().GetString("output")
+	prune, _ := cmd.Flags().GetBool("prune")
+	toStdout, _ := cmd.Flags().GetBool("stdout")
+	timeout, _ := cmd.Flags().GetDuration("timeout")
+	schema, _ := cmd.Flags().GetString("schema")
+	sourceMode, _ := cmd.Flags().GetString("source")
+	sourcePath, _ := cmd.Flags().GetString("source-path")
+
+	curatedOutput := output == ""
+	if curatedOutput {
+		output = filepath.Join(configsDir, appName+".yaml")
+	}
+
+	ctx := cmd.Context()
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	opts := []configextractor.Option{configextractor.WithTimeout(timeout)}
+
+	src, err := openExtractionSource(sourceMode, sourcePath)
+	if err != nil {
+		return err
+	}
+	if src != nil {
+		opts = append(opts, configextractor.WithSource(src))
+		defer func() {
+			if closeErr := source.Close(src); closeErr != nil && err == nil {
+				err = fmt.Errorf("failed to save cassette: %w", closeErr)
+			}
+		}()
+	}
+
+	if schema != "" {
+		opts = append(opts, configextractor.WithStrategy(
+			configextractor.NewJSONSchemaWithSource(appName, configextractor.SchemaSource{Location: schema})))
+	}
+
+	extractor := configextractor.New(opts...)
+	extracted, mergeReport, err := extractor.ExtractMerged(ctx, appName)
+	if err != nil {
+		return fmt.Errorf("failed to extract %s: %w", appName, err)
+	}
+
+	// A missing curated file is fine: the generated reference becomes the
+	// starting point. One that doesn't parse must be fixed first, or writing
+	// would replace its curated entries with extracted ones.
+	curated, err := reference.NewStaticConfigLoader(configsDir).LoadReference(appName)
+	if stderrors.Is(err, reference.ErrReferenceNotFound) {
+		curated = nil
+	} else if err != nil {
+		return fmt.Errorf("failed to load curated reference for %s, fix it before regenerating: %w", appName, err)
+	}
+
+	merged, report := reference.MergeGenerated(curated, configextractor.ToReference(extracted), reference.MergeOptions{Prune: prune})
+
+	if toStdout {
+		data, err := reference.MarshalReference(merged)
+		if err != nil {
+			return err
+		}
+		_, err = cmd.OutOrStdout().Write(data)
+		return err
+	}
+
+	printGenerateReport(cmd, appName, extracted.Source.Location, report)
+	printExtractionConflicts(cmd, mergeReport)
+
+	// Rewriting an up-to-date curated file would only churn its date
+	if curatedOutput && !report.Modified {
+		if _, err := os.Stat(output); err == nil {
+			fmt.Fprintf(cmd.OutOrStdout(), "%s %s is up to date\n", successStyle.Render("✓"), output)
+			return nil
+		}
+	}
+
+	if viper.GetBool("dry-run") {
+		fmt.Fprintf(cmd.OutOrStdout(), "(DRY-RUN) Would write %s\n", output)
+		return nil
+	}
+
+	if err := reference.WriteReferenceFile(output, merged); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(cmd.OutOrStdout(), "%s Wrote %s\n", successStyle.Render("✓"), output)
+	return nil
+}
+
+func printGenerateReport(cmd *cobra.Command, appName, method string, report *reference.GenerateReport) {
+	out := cmd.OutOrStdout()
+
+	fmt.Fprintln(out, titleStyle.Render(fmt.Sprintf("🛠  Generated reference for %s", appName)))
+	fmt.Fprintf(out, "Source: %s\n", method)
+	fmt.Fprintf(out, "Summary: %s\n", report.Summary())
+
+	if len(report.Added) > 0 {
+		fmt.Fprintln(out, headerStyle.Render("Added"))
+		for _, key := range report.Added {
+			fmt.Fprintf(out, "  %s %s\n", successStyle.Render("+"), key)
+		}
+	}
+
+	if len(report.Removed) > 0 {
+		label := "Not reported by extraction (kept)"
+		if report.Pruned {
+			label = "Removed"
+		}
+		fmt.Fprintln(out, headerStyle.Render(label))
+		for _, key := range report.Removed {
+			fmt.Fprintf(out, "  %s %s\n", errorStyle.Render("-"), key)
+		}
+	}
+
+	if len(report.Changed) > 0 {
+		fmt.Fprintln(out, headerStyle.Render("Curated values differing from extraction (kept)"))
+		for _, change := range report.Changed {
+			fmt.Fprintf(out, "  %s %s.%s: %v %s\n",
+				keyStyle.Render("~"), change.Key, change.Field, change.Curated,
+				dimStyle.Render(fmt.Sprintf("(extracted: %v)", change.Generated)))
+		}
+	}
+}
+
+func runRefExportSchema(cmd *cobra.Command, args []string) error {
+	kind, _ := cmd.Flags().GetString("kind")
+	output, _ := cmd.Flags().GetString("output")
+
+	var schema map[string]interface{}
+	switch kind {
+	case "app":
+		if len(args) != 1 {
+			return fmt.Errorf("an app name is required for --kind app")
+		}
+		appName := args[0]
+
+		ref, refErr := setupImprovedManager().GetReference(appName)
+		if refErr != nil {
+			ref = nil
+		}
+
+		var app *appconfig.AppConfig
+		if loader, err := appconfig.NewReferenceEnhancedLoader(); err == nil {
+			if cfg, err := loader.LoadAppConfig(appName); err == nil {
+				app = cfg
+			}
+		}
+
+		if ref == nil && app == nil {
+			return fmt.Errorf("no reference or app definition found for %s: %w", appName, refErr)
+		}
+		schema = appconfig.AppJSONSchema(ref, app)
+	case "app-definition":
+		schema = appconfig.AppDefinitionJSONSchema()
+	case "registry":
+		schema = appconfig.AppsRegistryJSONSchema()
+	default:
+		return fmt.Errorf("unknown schema kind %q (expected app, app-definition or registry)", kind)
+	}
+
+	data, err := json.MarshalIndent(schema, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode schema: %w", err)
+	}
+	data = append(data, '\n')
+
+	if output == "" {
+		_, err = cmd.OutOrStdout().Write(data)
+		return err
+	}
+
+	if err := os.WriteFile(output, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write schema: %w", err)
+	}
+	fmt.Fprintf(cmd.ErrOrStderr(), "%s Wrote %s\n", successStyle.Render("✓"), output)
+	return nil
+}
+
+func printExtractionConflicts(cmd *cobra.Command, report *configextractor.MergeReport) {
+	if len(report.Conflicts) == 0 {
+		return
+	}
+
+	out := cmd.OutOrStdout()
+	fmt.Fprintln(out, headerStyle.Render("Strategies disagree"))
+	for _, conflict := range report.Conflicts {
+		claims := make([]string, 0, len(conflict.Claims))
+		for _, claim := range conflict.Claims {
+			value := string(claim.Type)
+			if conflict.Field == "default" {
+				value = fmt.Sprintf("%v", claim.Default)
+			}
+			claims = append(claims, fmt.Sprintf("%s=%s (%.2f)", claim.Method, value, claim.Confidence))
+		}
+		fmt.Fprintf(out, "  %s %s.%s: %s\n", errorStyle.Render("!"), conflict.Key, conflict.Field, strings.Join(claims, ", "))
+	}
+}
+
 func formatAppInfo(ref *reference.ConfigReference) string {
 	return fmt.Sprintf("%s (%s, %d settings)",
 		keyStyle.Render(ref.AppName),
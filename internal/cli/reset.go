@@ -0,0 +1,121 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+
+	"github.com/mrtkrcm/ZeroUI/internal/container"
+	"github.com/mrtkrcm/ZeroUI/internal/secrets"
+	"github.com/mrtkrcm/ZeroUI/internal/toggle"
+)
+
+func newResetCmd(getContainer func() (*container.Container, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "reset <app> [key...]",
+		Short: "Return settings to their default value",
+		Long: `Return settings to their default by removing them from the config file, so
+the application falls back to its built-in default.
+
+Fields marked as required in the app definition cannot be removed; their
+reference default is written instead. Without keys, every field set in the
+config file is reset.
+
+The previous file is kept as a backup; restore it with 'zeroui backup restore'.`,
+		Example: `  zeroui reset ghostty font-size
+  zeroui reset ghostty theme font-family --yes
+  zeroui reset ghostty --dry-run`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRemoval(cmd, args[0], args[1:], false, getContainer)
+		},
+	}
+
+	cmd.Flags().BoolP("yes", "y", false, "skip confirmation prompt")
+
+	return cmd
+}
+
+func newUnsetCmd(getContainer func() (*container.Container, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "unset <app> <key...>",
+		Short: "Delete keys from an application's config file",
+		Long: `Delete keys from an application's config file. Unlike reset, any key that is
+set can be removed, including keys that are not fields of the app definition.
+
+The previous file is kept as a backup; restore it with 'zeroui backup restore'.`,
+		Example: `  zeroui unset ghostty custom-shader
+  zeroui unset ghostty keybind --yes`,
+		Args: cobra.MinimumNArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runRemoval(cmd, args[0], args[1:], true, getContainer)
+		},
+	}
+
+	cmd.Flags().BoolP("yes", "y", false, "skip confirmation prompt")
+
+	return cmd
+}
+
+func runRemoval(cmd *cobra.Command, appName string, keys []string, unset bool, getContainer func() (*container.Container, error)) error {
+	out := cmd.OutOrStdout()
+
+	engine, err := toggleEngine(getContainer)
+	if err != nil {
+		return err
+	}
+
+	var plan *toggle.RemovalPlan
+	if unset {
+		plan, err = engine.PlanUnset(appName, keys)
+	} else {
+		plan, err = engine.PlanReset(appName, keys)
+	}
+	if err != nil {
+		return reportEngineError(err)
+	}
+
+	if !plan.HasChanges() {
+		fmt.Fprintf(out, "%s Nothing to reset in %s\n", successStyle.Render("✓"), plan.Path)
+		return nil
+	}
+
+	printRemovalPlan(out, plan)
+
+	if viper.GetBool("dry-run") {
+		fmt.Fprintf(out, "\n(DRY-RUN) Would change %d settings\n", len(plan.Removed)+len(plan.Defaulted))
+		return nil
+	}
+
+	if !confirmChange(cmd, fmt.Sprintf("Change %d settings?", len(plan.Removed)+len(plan.Defaulted))) {
+		fmt.Fprintln(out, "Cancelled")
+		return nil
+	}
+
+	if err := engine.ApplyRemoval(plan); err != nil {
+		return reportEngineError(err)
+	}
+
+	fmt.Fprintf(out, "%s Updated %s\n", successStyle.Render("✓"), plan.Path)
+	if plan.BackupPath != "" {
+		fmt.Fprintf(out, "  Previous file saved as %s (zeroui backup restore %s %s)\n",
+			filepath.Base(plan.BackupPath), appName, filepath.Base(plan.BackupPath))
+	}
+	return nil
+}
+
+// printRemovalPlan shows deleted keys and the defaults written for required ones
+func printRemovalPlan(out io.Writer, plan *toggle.RemovalPlan) {
+	fmt.Fprintln(out, headerStyle.Render(fmt.Sprintf("Changes to %s", plan.Path)))
+	for _, key := range plan.Removed {
+		fmt.Fprintln(out, errorStyle.Render(fmt.Sprintf("- %s = %v", key, secrets.Redact(key, plan.Values[key]))))
+	}
+	for _, key := range plan.Defaulted {
+		fmt.Fprintln(out, errorStyle.Render(fmt.Sprintf("- %s = %v", key, secrets.Redact(key, plan.Values[key]))))
+		fmt.Fprintln(out, successStyle.Render(fmt.Sprintf("+ %s = %v", key, secrets.Redact(key, plan.Defaults[key])))+
+			dimStyle.Render(" (required, reset to default)"))
+	}
+}
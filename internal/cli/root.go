@@ -8,8 +8,12 @@ import (
 	"sync"
 	"time"
 
+	"github.com/mrtkrcm/ZeroUI/internal/audit"
 	"github.com/mrtkrcm/ZeroUI/internal/container"
+	"github.com/mrtkrcm/ZeroUI/internal/filelock"
 	"github.com/mrtkrcm/ZeroUI/internal/logger"
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
+	"github.com/mrtkrcm/ZeroUI/internal/recovery"
 	"github.com/mrtkrcm/ZeroUI/internal/runtimeconfig"
 	"github.com/mrtkrcm/ZeroUI/internal/tui"
 	"github.com/mrtkrcm/ZeroUI/internal/tui/styles"
@@ -22,6 +26,7 @@ import (
 type RootCommand struct {
 	cmd           *cobra.Command
 	cfgFile       string
+	root          string
 	container     *container.Container
 	cleanupHooks  []func()
 	cleanupMu     sync.Mutex
@@ -76,9 +81,13 @@ Built for speed and simplicity with both CLI and interactive TUI interfaces.`,
 	cobra.OnInitialize(rc.initConfig)
 
 	// Global flags
-	rc.cmd.PersistentFlags().StringVar(&rc.cfgFile, "config", "", "config file (default is $HOME/.config/zeroui/config.yaml)")
+	rc.cmd.PersistentFlags().StringVar(&rc.cfgFile, "config", "", "config file (default is $XDG_CONFIG_HOME/zeroui/config.yaml)")
+	rc.cmd.PersistentFlags().StringVar(&rc.root, "root", "", "operate on the directory tree under dir as the home directory, rebasing every config path into it (also ZEROUI_ROOT)")
 	rc.cmd.PersistentFlags().BoolP("verbose", "v", false, "verbose output")
 	rc.cmd.PersistentFlags().BoolP("dry-run", "n", false, "show what would be changed without making changes")
+	rc.cmd.PersistentFlags().Duration("lock-timeout", filelock.DefaultLockTimeout, "how long to wait for another zeroui process to finish writing a config")
+	rc.cmd.PersistentFlags().String("backup-secrets", string(recovery.SecretsKeep), "how backups treat secrets found in configs (keep, exclude)")
+	rc.cmd.PersistentFlags().String("backup-key", "", "key file to encrypt backups to and restore them with (see 'backup keygen')")
 
 	// Runtime config flags (for future use with runtime config loader)
 	rc.cmd.PersistentFlags().String("log-level", "info", "log level (debug, info, warn, error)")
@@ -88,6 +97,9 @@ Built for speed and simplicity with both CLI and interactive TUI interfaces.`,
 	// Bind flags to viper
 	viper.BindPFlag("verbose", rc.cmd.PersistentFlags().Lookup("verbose"))
 	viper.BindPFlag("dry-run", rc.cmd.PersistentFlags().Lookup("dry-run"))
+	viper.BindPFlag("lock-timeout", rc.cmd.PersistentFlags().Lookup("lock-timeout"))
+	viper.BindPFlag("backup-secrets", rc.cmd.PersistentFlags().Lookup("backup-secrets"))
+	viper.BindPFlag("backup-key", rc.cmd.PersistentFlags().Lookup("backup-key"))
 	viper.BindPFlag("log-level", rc.cmd.PersistentFlags().Lookup("log-level"))
 	viper.BindPFlag("log-format", rc.cmd.PersistentFlags().Lookup("log-format"))
 	viper.BindPFlag("default-theme", rc.cmd.PersistentFlags().Lookup("default-theme"))
@@ -129,16 +141,21 @@ func (rc *RootCommand) Execute(ctx context.Context, args []string) error {
 
 // initConfig reads in config file and ENV variables if set.
 func (rc *RootCommand) initConfig() {
+	// The root decides where everything else is looked up, so it comes first.
+	// A root that doesn't exist stops zeroui rather than let it touch the real
+	// home directory.
+	root := rc.root
+	if root == "" {
+		root = os.Getenv("ZEROUI_ROOT")
+	}
+	cobra.CheckErr(paths.SetRoot(root))
+
 	if rc.cfgFile != "" {
 		// Use config file from the flag.
 		viper.SetConfigFile(rc.cfgFile)
 	} else {
-		// Find home directory.
-		home, err := os.UserHomeDir()
-		cobra.CheckErr(err)
-
-		// Search config in home directory with name ".zeroui" (without extension).
-		viper.AddConfigPath(home + "/.config/zeroui")
+		// Search config in the zeroui config directory with name "config" (without extension).
+		viper.AddConfigPath(paths.ConfigDir())
 		viper.SetConfigType("yaml")
 		viper.SetConfigName("config")
 	}
@@ -166,6 +183,13 @@ func (rc *RootCommand) initConfig() {
 		}
 	}
 
+	filelock.SetDefaultTimeout(cfg.LockTimeout)
+	recovery.SetSecretMode(recovery.SecretMode(cfg.BackupSecrets))
+	recovery.SetEncryption(recovery.Encryption{
+		Passphrase: os.Getenv("ZEROUI_BACKUP_PASSPHRASE"),
+		KeyFile:    cfg.BackupKey,
+	})
+
 	// Map "text" format to "console" for logger
 	logFormat := cfg.LogFormat
 	if logFormat == "text" {
@@ -216,15 +240,24 @@ func (rc *RootCommand) AddSubcommands() {
 		newToggleCmd(getContainer),
 		newListCmd(getContainer),
 		newKeymapCmd(getContainer),
+		newAuditCmd(),
 		newBackupCmd(),
 		newCompletionCmd(rc.cmd),
 		newCycleCmd(getContainer),
 		newDesignSystemCmd(getContainer),
+		newDoctorCmd(),
 		newExtractCmd(),
+		newFmtCmd(getContainer),
+		newMergeCmd(getContainer),
+		newMigrateCmd(getContainer),
 		newPresetCmd(),
+		newPruneCmd(getContainer),
 		newReferenceImprovedCmd(),
+		newResetCmd(getContainer),
+		newUnsetCmd(getContainer),
 		newValidateReferenceCmd(),
 		newVersionCmd(),
+		newWhyCmd(getContainer),
 	)
 }
 
@@ -266,6 +299,9 @@ func attachCommandTracing(cmd *cobra.Command) {
 		ctx := logger.ContextWithLogger(cmd.Context(), cmdLogger)
 		cmd.SetContext(ctx)
 
+		// Changes made by this command are recorded under its name
+		audit.SetCommand(cmd.CommandPath())
+
 		// Log command execution start (debug level to avoid cluttering output)
 		cmdLogger.Debug("Command execution started",
 			logger.Field{Key: "command", Value: cmd.CommandPath()},
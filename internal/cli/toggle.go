@@ -6,11 +6,14 @@ import (
 
 	"github.com/mrtkrcm/ZeroUI/internal/container"
 	"github.com/mrtkrcm/ZeroUI/internal/errors"
+	"github.com/mrtkrcm/ZeroUI/internal/toggle"
 	"github.com/spf13/cobra"
 )
 
 func newToggleCmd(getContainer func() (*container.Container, error)) *cobra.Command {
-	return &cobra.Command{
+	var fix bool
+
+	cmd := &cobra.Command{
 		Use:   "toggle <app> <key> <value>",
 		Short: "Toggle a UI configuration value for an application",
 		Long: `Toggle a specific UI configuration key to a new value for a given application.
@@ -18,7 +21,11 @@ func newToggleCmd(getContainer func() (*container.Container, error)) *cobra.Comm
 Examples:
   zeroui toggle ghostty theme dark
   zeroui toggle alacritty font "JetBrains Mono"
-  zeroui toggle vscode editor.fontSize 14`,
+  zeroui toggle vscode editor.fontSize 14
+
+Validation schemas in <config dir>/schemas are enforced before writing: a change
+that breaks a declared dependency or conflict is rejected with an explanation.
+Use --fix to set required companion keys to their defaults instead.`,
 		Example: `  zeroui toggle ghostty theme dark
   zeroui toggle alacritty font "JetBrains Mono"
   zeroui toggle vscode editor.fontSize 14`,
@@ -38,7 +45,7 @@ Examples:
 			}
 
 			configService := container.ConfigService()
-			if err := configService.ToggleConfiguration(app, key, value); err != nil {
+			if err := configService.ToggleConfigurationWithOptions(app, key, value, toggle.ToggleOptions{Fix: fix}); err != nil {
 				// Check if it's a ZeroUIError for better user experience
 				if ctErr, ok := errors.GetZeroUIError(err); ok {
 					fmt.Fprintf(os.Stderr, "Error: %s\n", ctErr.String())
@@ -50,4 +57,8 @@ Examples:
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&fix, "fix", false, "set keys required by schema dependencies to their defaults")
+
+	return cmd
 }
@@ -0,0 +1,100 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/spf13/cobra"
+
+	"github.com/mrtkrcm/ZeroUI/internal/container"
+	"github.com/mrtkrcm/ZeroUI/internal/provenance"
+	"github.com/mrtkrcm/ZeroUI/internal/secrets"
+	"github.com/mrtkrcm/ZeroUI/pkg/reference"
+)
+
+func newWhyCmd(getContainer func() (*container.Container, error)) *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "why <app> <key>",
+		Short: "Show where a setting's value comes from",
+		Long: `Show where a setting's effective value comes from and which other sources
+it overrides.
+
+Sources are checked from highest to lowest precedence: environment overrides
+declared in the app definition, the main config file and the files it
+includes (in the order the app applies them), the theme it names, and the
+reference default. Each file source is reported with its line number.`,
+		Example: `  zeroui why ghostty font-size
+  zeroui why ghostty background --configs-dir resources/configs`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			configsDir, _ := cmd.Flags().GetString("configs-dir")
+			return runWhy(cmd.OutOrStdout(), args[0], args[1], configsDir, getContainer)
+		},
+	}
+
+	cmd.Flags().String("configs-dir", "configs", "Directory containing reference configs")
+
+	return cmd
+}
+
+func runWhy(out io.Writer, appName, key, configsDir string, getContainer func() (*container.Container, error)) error {
+	// Reference data only adds defaults for settings the app definition lacks
+	ref, _ := reference.NewStaticConfigLoader(configsDir).LoadReference(appName)
+
+	res, err := resolveProvenance(getContainer, appName, ref)
+	if err != nil {
+		return reportEngineError(err)
+	}
+
+	exp := res.Explain(key)
+	if exp.Effective == nil {
+		fmt.Fprintf(out, "%s is not set in %s and has no known default\n", keyStyle.Render(key), appName)
+		printProvenanceWarnings(out, res)
+		return nil
+	}
+
+	fmt.Fprintf(out, "%s = %s\n", keyStyle.Render(key), valueStyle.Render(fmt.Sprintf("%v", secrets.Redact(key, exp.Effective.Value))))
+	fmt.Fprintf(out, "  from %s\n", sourceLabel(*exp.Effective))
+
+	if len(exp.Overridden) > 0 {
+		fmt.Fprintln(out, "  overrides:")
+		for _, src := range exp.Overridden {
+			fmt.Fprintf(out, "    %s = %v\n", sourceLabel(src), secrets.Redact(key, src.Value))
+		}
+	}
+
+	printProvenanceWarnings(out, res)
+	return nil
+}
+
+// sourceLabel describes a source with its layer kind
+func sourceLabel(src provenance.Source) string {
+	if src.Kind == provenance.LayerDefault {
+		return src.Location()
+	}
+	return src.Location() + " " + dimStyle.Render("("+string(src.Kind)+")")
+}
+
+// resolveProvenance loads every configuration layer of an app
+func resolveProvenance(getContainer func() (*container.Container, error), appName string, ref *reference.ConfigReference) (*provenance.Resolution, error) {
+	c, err := getContainer()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get container: %w", err)
+	}
+	if c == nil {
+		return nil, fmt.Errorf("application container not initialized")
+	}
+
+	appConfig, err := c.ConfigService().GetApplicationConfig(appName)
+	if err != nil {
+		return nil, err
+	}
+
+	return provenance.NewResolver(c.ConfigLoader()).Resolve(appConfig, ref)
+}
+
+func printProvenanceWarnings(out io.Writer, res *provenance.Resolution) {
+	for _, warning := range res.Warnings {
+		fmt.Fprintln(out, warningStyle.Render("! "+warning))
+	}
+}
@@ -2,11 +2,14 @@ package container
 
 import (
 	"fmt"
+	"os"
+	"path/filepath"
 
 	"github.com/mrtkrcm/ZeroUI/internal/appconfig"
 	"github.com/mrtkrcm/ZeroUI/internal/logger"
 	"github.com/mrtkrcm/ZeroUI/internal/service"
 	"github.com/mrtkrcm/ZeroUI/internal/toggle"
+	"github.com/mrtkrcm/ZeroUI/internal/validation"
 )
 
 // Container holds all application dependencies
@@ -53,6 +56,16 @@ func New(cfg *Config) (*Container, error) {
 	// Initialize toggle engine with dependency injection
 	c.toggleEngine = toggle.NewEngineWithDeps(configLoader, c.logger)
 
+	// Enforce validation schemas from <config dir>/schemas when present
+	schemasDir := filepath.Join(configLoader.ConfigDir(), "schemas")
+	if _, err := os.Stat(schemasDir); err == nil {
+		validator := validation.NewValidator()
+		if err := validator.LoadSchemasFromDir(schemasDir); err != nil {
+			return nil, fmt.Errorf("failed to load validation schemas: %w", err)
+		}
+		c.toggleEngine.SetValidator(validator)
+	}
+
 	// Initialize config service with all dependencies
 	c.configService = service.NewConfigService(c.toggleEngine, configLoader, c.logger)
 
@@ -0,0 +1,297 @@
+// Package crypt encrypts backup payloads with authenticated encryption, in the
+// style of age. Each file gets a random file key; the header wraps it once per
+// recipient, either with a key derived from a passphrase by scrypt or with an
+// X25519 key agreement against a recipient's public key. An HMAC over the
+// header and ChaCha20-Poly1305 over the payload make any change to an
+// encrypted file fail decryption.
+//
+// An encrypted file looks like:
+//
+//	zeroui-encrypted/v1
+//	-> scrypt <salt> <log2 N>
+//	<wrapped file key>
+//	-> X25519 <ephemeral public key>
+//	<wrapped file key>
+//	--- <header MAC>
+//	<nonce><ciphertext>
+package crypt
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+// Magic is the first line of every encrypted file
+const Magic = "zeroui-encrypted/v1"
+
+const (
+	fileKeySize = 16
+	nonceSize   = 16
+)
+
+var (
+	// ErrNoIdentity is returned when decrypting without a passphrase or key
+	ErrNoIdentity = errors.New("no passphrase or key to decrypt with")
+	// ErrIncorrectIdentity is returned when none of the identities can open
+	// the file: a wrong passphrase or a key it wasn't encrypted to
+	ErrIncorrectIdentity = errors.New("incorrect passphrase or key")
+	// ErrMalformed is returned for files that aren't valid encrypted files
+	ErrMalformed = errors.New("malformed encrypted file")
+	// ErrTampered is returned when the header or payload fail authentication
+	ErrTampered = errors.New("encrypted file was modified or corrupted")
+)
+
+var b64 = base64.RawStdEncoding
+
+// stanza is one recipient's wrapped copy of the file key
+type stanza struct {
+	Type string
+	Args []string
+	Body []byte
+}
+
+// Recipient can wrap a file key so that a matching Identity can unwrap it.
+// String describes the recipient without revealing a secret, for manifests.
+type Recipient interface {
+	wrap(fileKey []byte) (*stanza, error)
+	String() string
+}
+
+// Identity can unwrap the file key from the stanzas it recognises
+type Identity interface {
+	unwrap(s *stanza) ([]byte, error)
+}
+
+// errNotMine is returned by identities for stanzas they don't recognise
+var errNotMine = errors.New("stanza is for another identity")
+
+// IsEncrypted reports whether data is an encrypted file
+func IsEncrypted(data []byte) bool {
+	return bytes.HasPrefix(data, []byte(Magic+"\n"))
+}
+
+// Encrypt encrypts plaintext to every recipient; any one of them can decrypt
+// the result
+func Encrypt(plaintext []byte, recipients ...Recipient) ([]byte, error) {
+	if len(recipients) == 0 {
+		return nil, errors.New("no recipients to encrypt to")
+	}
+
+	fileKey := make([]byte, fileKeySize)
+	if _, err := rand.Read(fileKey); err != nil {
+		return nil, err
+	}
+
+	var header bytes.Buffer
+	header.WriteString(Magic + "\n")
+	for _, r := range recipients {
+		s, err := r.wrap(fileKey)
+		if err != nil {
+			return nil, err
+		}
+		writeStanza(&header, s)
+	}
+	header.WriteString("---")
+	mac, err := headerMAC(fileKey, header.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	header.WriteString(" " + b64.EncodeToString(mac) + "\n")
+
+	nonce := make([]byte, nonceSize)
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	aead, err := payloadAEAD(fileKey, nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	out := header.Bytes()
+	out = append(out, nonce...)
+	return aead.Seal(out, make([]byte, chacha20poly1305.NonceSize), plaintext, nil), nil
+}
+
+// Decrypt opens data with the first identity that can unwrap its file key
+func Decrypt(data []byte, identities ...Identity) ([]byte, error) {
+	if len(identities) == 0 {
+		return nil, ErrNoIdentity
+	}
+
+	stanzas, headerEnd, mac, err := parseHeader(data)
+	if err != nil {
+		return nil, err
+	}
+
+	fileKey, err := unwrapFileKey(stanzas, identities)
+	if err != nil {
+		return nil, err
+	}
+
+	want, err := headerMAC(fileKey, data[:headerEnd])
+	if err != nil {
+		return nil, err
+	}
+	if !hmac.Equal(mac, want) {
+		return nil, ErrTampered
+	}
+
+	payload := data[bytes.IndexByte(data[headerEnd:], '\n')+headerEnd+1:]
+	if len(payload) < nonceSize+chacha20poly1305.Overhead {
+		return nil, ErrMalformed
+	}
+	aead, err := payloadAEAD(fileKey, payload[:nonceSize])
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := aead.Open(nil, make([]byte, chacha20poly1305.NonceSize), payload[nonceSize:], nil)
+	if err != nil {
+		return nil, ErrTampered
+	}
+	return plaintext, nil
+}
+
+// unwrapFileKey tries every identity on every stanza
+func unwrapFileKey(stanzas []*stanza, identities []Identity) ([]byte, error) {
+	for _, s := range stanzas {
+		for _, id := range identities {
+			fileKey, err := id.unwrap(s)
+			switch {
+			case err == nil:
+				return fileKey, nil
+			case errors.Is(err, errNotMine), errors.Is(err, ErrIncorrectIdentity):
+			default:
+				return nil, err
+			}
+		}
+	}
+	return nil, ErrIncorrectIdentity
+}
+
+// Recipients lists the stanza types in data's header, such as scrypt and
+// X25519, without decrypting it
+func Recipients(data []byte) ([]string, error) {
+	stanzas, _, _, err := parseHeader(data)
+	if err != nil {
+		return nil, err
+	}
+	types := make([]string, len(stanzas))
+	for i, s := range stanzas {
+		types[i] = s.Type
+	}
+	return types, nil
+}
+
+func writeStanza(w *bytes.Buffer, s *stanza) {
+	w.WriteString("-> " + s.Type)
+	for _, arg := range s.Args {
+		w.WriteString(" " + arg)
+	}
+	w.WriteString("\n" + b64.EncodeToString(s.Body) + "\n")
+}
+
+// parseHeader returns the stanzas, the offset of the MAC line's " <mac>" (the
+// MAC covers everything before it) and the MAC itself
+func parseHeader(data []byte) ([]*stanza, int, []byte, error) {
+	if !IsEncrypted(data) {
+		return nil, 0, nil, ErrMalformed
+	}
+
+	r := bufio.NewReader(bytes.NewReader(data))
+	offset := 0
+	readLine := func() (string, error) {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return "", ErrMalformed
+		}
+		offset += len(line)
+		return strings.TrimSuffix(line, "\n"), nil
+	}
+
+	if _, err := readLine(); err != nil {
+		return nil, 0, nil, err
+	}
+
+	var stanzas []*stanza
+	for {
+		line, err := readLine()
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		if strings.HasPrefix(line, "--- ") {
+			mac, err := b64.DecodeString(strings.TrimPrefix(line, "--- "))
+			if err != nil || len(stanzas) == 0 {
+				return nil, 0, nil, ErrMalformed
+			}
+			return stanzas, offset - len(line) - 1 + len("---"), mac, nil
+		}
+
+		fields := strings.Fields(line)
+		if len(fields) < 2 || fields[0] != "->" {
+			return nil, 0, nil, ErrMalformed
+		}
+		body, err := readLine()
+		if err != nil {
+			return nil, 0, nil, err
+		}
+		decoded, err := b64.DecodeString(body)
+		if err != nil {
+			return nil, 0, nil, ErrMalformed
+		}
+		stanzas = append(stanzas, &stanza{Type: fields[1], Args: fields[2:], Body: decoded})
+	}
+}
+
+func headerMAC(fileKey, header []byte) ([]byte, error) {
+	key, err := hkdf.Key(sha256.New, fileKey, nil, "header", 32)
+	if err != nil {
+		return nil, err
+	}
+	h := hmac.New(sha256.New, key)
+	h.Write(header)
+	return h.Sum(nil), nil
+}
+
+func payloadAEAD(fileKey, nonce []byte) (cipher.AEAD, error) {
+	key, err := hkdf.Key(sha256.New, fileKey, nonce, "payload", chacha20poly1305.KeySize)
+	if err != nil {
+		return nil, err
+	}
+	return chacha20poly1305.New(key)
+}
+
+// wrapKey and unwrapKey seal a file key under a key-wrapping key. The
+// wrapping key is fresh for every stanza, so a zero nonce is safe.
+func wrapKey(wrappingKey, fileKey []byte) ([]byte, error) {
+	aead, err := chacha20poly1305.New(wrappingKey)
+	if err != nil {
+		return nil, err
+	}
+	return aead.Seal(nil, make([]byte, chacha20poly1305.NonceSize), fileKey, nil), nil
+}
+
+func unwrapKey(wrappingKey, body []byte) ([]byte, error) {
+	if len(body) != fileKeySize+chacha20poly1305.Overhead {
+		return nil, fmt.Errorf("%w: wrapped key has the wrong size", ErrMalformed)
+	}
+	aead, err := chacha20poly1305.New(wrappingKey)
+	if err != nil {
+		return nil, err
+	}
+	fileKey, err := aead.Open(nil, make([]byte, chacha20poly1305.NonceSize), body, nil)
+	if err != nil {
+		return nil, ErrIncorrectIdentity
+	}
+	return fileKey, nil
+}
@@ -0,0 +1,136 @@
+package crypt
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// testPassphrase keeps scrypt cheap in tests
+func testPassphrase(p string) *Passphrase {
+	pass := NewPassphrase(p)
+	pass.WorkFactor = 10
+	return pass
+}
+
+func TestEncryptDecrypt_Passphrase(t *testing.T) {
+	plaintext := []byte("theme = dark\nfont-size = 14\n")
+
+	data, err := Encrypt(plaintext, testPassphrase("correct horse"))
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if !IsEncrypted(data) || bytes.Contains(data, []byte("theme")) {
+		t.Fatalf("Expected an encrypted file without the plaintext, got %q", data)
+	}
+
+	got, err := Decrypt(data, testPassphrase("correct horse"))
+	if err != nil || !bytes.Equal(got, plaintext) {
+		t.Fatalf("Decrypt = %q, %v; want %q", got, err, plaintext)
+	}
+
+	if _, err := Decrypt(data, testPassphrase("wrong")); !errors.Is(err, ErrIncorrectIdentity) {
+		t.Errorf("Expected a wrong passphrase to be refused, got %v", err)
+	}
+	if _, err := Decrypt(data); !errors.Is(err, ErrNoIdentity) {
+		t.Errorf("Expected ErrNoIdentity, got %v", err)
+	}
+}
+
+func TestEncryptDecrypt_X25519(t *testing.T) {
+	id, err := GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("GenerateX25519Identity failed: %v", err)
+	}
+	other, _ := GenerateX25519Identity()
+
+	plaintext := []byte("[user]\n\tname = Octo\n")
+	data, err := Encrypt(plaintext, id.Recipient(), testPassphrase("backup"))
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+
+	types, err := Recipients(data)
+	if err != nil || strings.Join(types, ",") != "X25519,scrypt" {
+		t.Errorf("Recipients = %v, %v", types, err)
+	}
+
+	for name, ids := range map[string][]Identity{
+		"key":        {id},
+		"passphrase": {testPassphrase("backup")},
+		"any":        {other, id},
+	} {
+		if got, err := Decrypt(data, ids...); err != nil || !bytes.Equal(got, plaintext) {
+			t.Errorf("%s: Decrypt = %q, %v", name, got, err)
+		}
+	}
+	if _, err := Decrypt(data, other); !errors.Is(err, ErrIncorrectIdentity) {
+		t.Errorf("Expected another key to be refused, got %v", err)
+	}
+}
+
+func TestDecrypt_Tampered(t *testing.T) {
+	pass := testPassphrase("p")
+	data, err := Encrypt([]byte("theme = dark\n"), pass)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+
+	payload := append([]byte{}, data...)
+	payload[len(payload)-1] ^= 1
+	if _, err := Decrypt(payload, pass); !errors.Is(err, ErrTampered) {
+		t.Errorf("Expected a modified payload to be refused, got %v", err)
+	}
+
+	// Changing the work factor changes the header, not just the key
+	header := bytes.Replace(data, []byte(" 10\n"), []byte(" 11\n"), 1)
+	if _, err := Decrypt(header, pass); err == nil {
+		t.Error("Expected a modified header to be refused")
+	}
+
+	truncated := data[:bytes.Index(data, []byte("---"))]
+	if _, err := Decrypt(truncated, pass); !errors.Is(err, ErrMalformed) {
+		t.Errorf("Expected a truncated file to be malformed, got %v", err)
+	}
+	if _, err := Decrypt([]byte("theme = dark\n"), pass); !errors.Is(err, ErrMalformed) {
+		t.Errorf("Expected plaintext to be malformed, got %v", err)
+	}
+}
+
+func TestParseKeyFile(t *testing.T) {
+	id, _ := GenerateX25519Identity()
+	backupOnly, _ := GenerateX25519Identity()
+
+	data := strings.Join([]string{
+		"# created: 2026-10-18",
+		"# public key: " + id.Recipient().String(),
+		id.String(),
+		"",
+		backupOnly.Recipient().String(),
+	}, "\n")
+	kf, err := ParseKeyFile([]byte(data))
+	if err != nil {
+		t.Fatalf("ParseKeyFile failed: %v", err)
+	}
+	if len(kf.Identities) != 1 || len(kf.Recipients) != 1 || len(kf.AllRecipients()) != 2 {
+		t.Fatalf("Expected one private and one public key, got %+v", kf)
+	}
+	if kf.Identities[0].String() != id.String() || kf.Recipients[0].String() != backupOnly.Recipient().String() {
+		t.Error("Expected keys to round-trip through their string form")
+	}
+
+	encrypted, err := Encrypt([]byte("x"), kf.AllRecipients()...)
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if _, err := Decrypt(encrypted, backupOnly); err != nil {
+		t.Errorf("Expected the listed public key's owner to decrypt, got %v", err)
+	}
+
+	for _, bad := range []string{"", "# only a comment", "ssh-ed25519 AAAA", RecipientPrefix + "!!"} {
+		if _, err := ParseKeyFile([]byte(bad)); err == nil {
+			t.Errorf("Expected %q to be refused", bad)
+		}
+	}
+}
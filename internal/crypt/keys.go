@@ -0,0 +1,281 @@
+package crypt
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ecdh"
+	"crypto/hkdf"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base32"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"golang.org/x/crypto/chacha20poly1305"
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// RecipientPrefix starts a public key, as printed by backup keygen
+	RecipientPrefix = "zeroui1"
+	// IdentityPrefix starts a private key in a key file
+	IdentityPrefix = "ZEROUI-SECRET-KEY-1"
+
+	// DefaultWorkFactor is log2 of scrypt's N for new passphrase stanzas,
+	// about 100ms on current hardware
+	DefaultWorkFactor = 15
+	// maxWorkFactor bounds the work a file can ask for when decrypting
+	maxWorkFactor = 22
+
+	scryptLabel = Magic + "/scrypt"
+	x25519Label = Magic + "/X25519"
+)
+
+var b32 = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// Passphrase is both a Recipient and an Identity: it wraps file keys with a
+// key derived by scrypt and unwraps them again
+type Passphrase struct {
+	passphrase string
+	// WorkFactor is log2 of scrypt's N for new stanzas
+	WorkFactor int
+}
+
+// NewPassphrase returns a passphrase recipient and identity
+func NewPassphrase(passphrase string) *Passphrase {
+	return &Passphrase{passphrase: passphrase, WorkFactor: DefaultWorkFactor}
+}
+
+// String names the method without revealing the passphrase
+func (p *Passphrase) String() string {
+	return "passphrase"
+}
+
+func (p *Passphrase) wrap(fileKey []byte) (*stanza, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+	key, err := p.key(salt, p.WorkFactor)
+	if err != nil {
+		return nil, err
+	}
+	body, err := wrapKey(key, fileKey)
+	if err != nil {
+		return nil, err
+	}
+	return &stanza{Type: "scrypt", Args: []string{b64.EncodeToString(salt), strconv.Itoa(p.WorkFactor)}, Body: body}, nil
+}
+
+func (p *Passphrase) unwrap(s *stanza) ([]byte, error) {
+	if s.Type != "scrypt" {
+		return nil, errNotMine
+	}
+	if len(s.Args) != 2 {
+		return nil, ErrMalformed
+	}
+	salt, err := b64.DecodeString(s.Args[0])
+	if err != nil || len(salt) != 16 {
+		return nil, ErrMalformed
+	}
+	logN, err := strconv.Atoi(s.Args[1])
+	if err != nil || logN <= 0 || logN > maxWorkFactor {
+		return nil, fmt.Errorf("%w: scrypt work factor %s", ErrMalformed, s.Args[1])
+	}
+	key, err := p.key(salt, logN)
+	if err != nil {
+		return nil, err
+	}
+	return unwrapKey(key, s.Body)
+}
+
+func (p *Passphrase) key(salt []byte, logN int) ([]byte, error) {
+	return scrypt.Key([]byte(p.passphrase), append([]byte(scryptLabel), salt...), 1<<logN, 8, 1, chacha20poly1305.KeySize)
+}
+
+// X25519Recipient is a public key that file keys can be wrapped to
+type X25519Recipient struct {
+	public *ecdh.PublicKey
+}
+
+// ParseX25519Recipient parses a zeroui1... public key
+func ParseX25519Recipient(s string) (*X25519Recipient, error) {
+	if !strings.HasPrefix(s, RecipientPrefix) {
+		return nil, fmt.Errorf("not a public key: must start with %s", RecipientPrefix)
+	}
+	raw, err := b32.DecodeString(strings.ToUpper(strings.TrimPrefix(s, RecipientPrefix)))
+	if err != nil {
+		return nil, fmt.Errorf("malformed public key: %w", err)
+	}
+	public, err := ecdh.X25519().NewPublicKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("malformed public key: %w", err)
+	}
+	return &X25519Recipient{public: public}, nil
+}
+
+// String returns the public key in zeroui1... form
+func (r *X25519Recipient) String() string {
+	return RecipientPrefix + strings.ToLower(b32.EncodeToString(r.public.Bytes()))
+}
+
+func (r *X25519Recipient) wrap(fileKey []byte) (*stanza, error) {
+	ephemeral, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := ephemeral.ECDH(r.public)
+	if err != nil {
+		return nil, err
+	}
+	share := ephemeral.PublicKey().Bytes()
+	key, err := x25519Key(shared, share, r.public.Bytes())
+	if err != nil {
+		return nil, err
+	}
+	body, err := wrapKey(key, fileKey)
+	if err != nil {
+		return nil, err
+	}
+	return &stanza{Type: "X25519", Args: []string{b64.EncodeToString(share)}, Body: body}, nil
+}
+
+// X25519Identity is a private key that unwraps file keys wrapped to its
+// public key
+type X25519Identity struct {
+	private *ecdh.PrivateKey
+}
+
+// GenerateX25519Identity creates a new random key pair
+func GenerateX25519Identity() (*X25519Identity, error) {
+	private, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+	return &X25519Identity{private: private}, nil
+}
+
+// ParseX25519Identity parses a ZEROUI-SECRET-KEY-1... private key
+func ParseX25519Identity(s string) (*X25519Identity, error) {
+	if !strings.HasPrefix(s, IdentityPrefix) {
+		return nil, fmt.Errorf("not a private key: must start with %s", IdentityPrefix)
+	}
+	raw, err := b32.DecodeString(strings.TrimPrefix(s, IdentityPrefix))
+	if err != nil {
+		return nil, fmt.Errorf("malformed private key: %w", err)
+	}
+	private, err := ecdh.X25519().NewPrivateKey(raw)
+	if err != nil {
+		return nil, fmt.Errorf("malformed private key: %w", err)
+	}
+	return &X25519Identity{private: private}, nil
+}
+
+// String returns the private key in ZEROUI-SECRET-KEY-1... form
+func (i *X25519Identity) String() string {
+	return IdentityPrefix + b32.EncodeToString(i.private.Bytes())
+}
+
+// Recipient returns the public key for the identity
+func (i *X25519Identity) Recipient() *X25519Recipient {
+	return &X25519Recipient{public: i.private.PublicKey()}
+}
+
+func (i *X25519Identity) unwrap(s *stanza) ([]byte, error) {
+	if s.Type != "X25519" {
+		return nil, errNotMine
+	}
+	if len(s.Args) != 1 {
+		return nil, ErrMalformed
+	}
+	share, err := b64.DecodeString(s.Args[0])
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	public, err := ecdh.X25519().NewPublicKey(share)
+	if err != nil {
+		return nil, ErrMalformed
+	}
+	shared, err := i.private.ECDH(public)
+	if err != nil {
+		// A low-order share can't have come from an honest sender
+		return nil, ErrMalformed
+	}
+	key, err := x25519Key(shared, share, i.private.PublicKey().Bytes())
+	if err != nil {
+		return nil, err
+	}
+	return unwrapKey(key, s.Body)
+}
+
+// x25519Key derives the wrapping key from a shared secret, bound to both
+// public keys
+func x25519Key(shared, share, recipient []byte) ([]byte, error) {
+	salt := append(append([]byte{}, share...), recipient...)
+	return hkdf.Key(sha256.New, shared, salt, x25519Label, chacha20poly1305.KeySize)
+}
+
+// KeyFile holds the keys read from a key file: private keys decrypt, public
+// keys only encrypt. A machine that only takes backups can hold just a public
+// key, so its backups can be restored elsewhere but not read where they are.
+type KeyFile struct {
+	Identities []*X25519Identity
+	Recipients []*X25519Recipient
+}
+
+// ParseKeyFile reads a key file: one key per line, private
+// (ZEROUI-SECRET-KEY-1...) or public (zeroui1...), with # comments
+func ParseKeyFile(data []byte) (*KeyFile, error) {
+	kf := &KeyFile{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		switch {
+		case line == "" || strings.HasPrefix(line, "#"):
+		case strings.HasPrefix(line, IdentityPrefix):
+			id, err := ParseX25519Identity(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			kf.Identities = append(kf.Identities, id)
+		case strings.HasPrefix(line, RecipientPrefix):
+			r, err := ParseX25519Recipient(line)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", n, err)
+			}
+			kf.Recipients = append(kf.Recipients, r)
+		default:
+			return nil, fmt.Errorf("line %d: expected a %s... private key or a %s... public key", n, IdentityPrefix, RecipientPrefix)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(kf.Identities) == 0 && len(kf.Recipients) == 0 {
+		return nil, fmt.Errorf("no keys found")
+	}
+	return kf, nil
+}
+
+// AllRecipients returns the public keys to encrypt to: those listed and those
+// of the private keys
+func (kf *KeyFile) AllRecipients() []Recipient {
+	var recipients []Recipient
+	for _, id := range kf.Identities {
+		recipients = append(recipients, id.Recipient())
+	}
+	for _, r := range kf.Recipients {
+		recipients = append(recipients, r)
+	}
+	return recipients
+}
+
+// AllIdentities returns the private keys as identities
+func (kf *KeyFile) AllIdentities() []Identity {
+	var identities []Identity
+	for _, id := range kf.Identities {
+		identities = append(identities, id)
+	}
+	return identities
+}
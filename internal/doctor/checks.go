@@ -0,0 +1,534 @@
+package doctor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/mrtkrcm/ZeroUI/internal/appconfig"
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
+	"github.com/mrtkrcm/ZeroUI/internal/policy"
+	"github.com/mrtkrcm/ZeroUI/internal/secrets"
+	"github.com/mrtkrcm/ZeroUI/pkg/reference"
+)
+
+// parsableFormats are the config formats the loader can read
+var parsableFormats = map[string]bool{"json": true, "yaml": true, "yml": true, "toml": true, "custom": true}
+
+// checkApp runs the per-app config checks
+func (d *Doctor) checkApp(app appconfig.AppDefinition) []Finding {
+	var path string
+	for _, candidate := range app.ConfigPaths {
+		expanded := expandHome(candidate)
+		if _, err := os.Stat(expanded); err == nil {
+			path = expanded
+			break
+		}
+	}
+
+	if path == "" {
+		return []Finding{{
+			App:      app.Name,
+			Severity: SeverityInfo,
+			Check:    CheckConfig,
+			Message:  "no config file found",
+			Fix:      fmt.Sprintf("Create one of: %s", strings.Join(app.ConfigPaths, ", ")),
+		}}
+	}
+
+	findings := d.checkIntegrity(app.Name, path)
+	if len(findings) > 0 {
+		return findings
+	}
+
+	format := strings.ToLower(app.ConfigFormat)
+	if !parsableFormats[format] {
+		return nil
+	}
+
+	k, err := d.opts.Loader.LoadTargetConfig(&appconfig.AppConfig{Name: app.Name, Path: path, Format: format})
+	if err != nil {
+		return []Finding{{
+			App:      app.Name,
+			File:     path,
+			Severity: SeverityError,
+			Check:    CheckParse,
+			Message:  fmt.Sprintf("config does not parse: %v", err),
+			Fix:      fmt.Sprintf("Fix the syntax error or restore a backup (zeroui backup list %s)", app.Name),
+		}}
+	}
+
+	findings = d.checkPolicy(app.Name, path, k.All())
+
+	if d.opts.References == nil {
+		return findings
+	}
+	ref, err := d.opts.References.LoadReference(app.Name)
+	if err != nil || ref == nil || len(ref.Settings) == 0 {
+		return findings
+	}
+
+	return append(findings, checkKeys(app.Name, path, format == "custom", k.All(), ref)...)
+}
+
+// loadPolicy reads the organization policy for the policy checks
+func (d *Doctor) loadPolicy() []Finding {
+	if d.opts.PolicyPath == "" {
+		return nil
+	}
+	p, err := policy.Load(d.opts.PolicyPath)
+	if err != nil {
+		return []Finding{{
+			File:     d.opts.PolicyPath,
+			Severity: SeverityError,
+			Check:    CheckPolicy,
+			Message:  fmt.Sprintf("organization policy cannot be read: %v", err),
+			Fix:      "Fix the policy file; zeroui refuses to write configs until it parses",
+		}}
+	}
+	d.policy = p
+	return nil
+}
+
+// checkPolicy reports values the organization policy doesn't allow
+func (d *Doctor) checkPolicy(appName, path string, values map[string]interface{}) []Finding {
+	var findings []Finding
+	for _, v := range d.policy.Violations(appName, values) {
+		finding := Finding{
+			App:      appName,
+			File:     path,
+			Severity: SeverityError,
+			Check:    CheckPolicy,
+			Message:  v.Message,
+		}
+		switch {
+		case v.Rule.Enforce != nil:
+			finding.Fix = fmt.Sprintf("zeroui toggle %s %s %v", appName, v.Key, v.Rule.Enforce)
+		case v.Rule.Forbidden:
+			finding.Fix = fmt.Sprintf("zeroui unset %s %s", appName, v.Key)
+		case len(v.Rule.Allow) > 0:
+			finding.Fix = fmt.Sprintf("Set %s to one of: %s", v.Key, strings.Join(v.Rule.Allow, ", "))
+		default:
+			finding.Fix = fmt.Sprintf("Change %s to a value %s allows", v.Key, v.Source)
+		}
+		findings = append(findings, finding)
+	}
+	return findings
+}
+
+// checkIntegrity verifies the file is readable, well-formed and safely permissioned
+func (d *Doctor) checkIntegrity(appName, path string) []Finding {
+	report, err := appconfig.NewIntegrityChecker().CreateIntegrityReport(path)
+	if err != nil || report.Error != "" {
+		msg := report.Error
+		if err != nil {
+			msg = err.Error()
+		}
+		return []Finding{{
+			App:      appName,
+			File:     path,
+			Severity: SeverityError,
+			Check:    CheckConfig,
+			Message:  fmt.Sprintf("config cannot be read: %s", msg),
+			Fix:      "Check file ownership and permissions",
+		}}
+	}
+
+	var findings []Finding
+	if !report.FormatValid {
+		findings = append(findings, Finding{
+			App:      appName,
+			File:     path,
+			Severity: SeverityError,
+			Check:    CheckConfig,
+			Message:  fmt.Sprintf("config is malformed: %s", report.FormatError),
+			Fix:      fmt.Sprintf("Fix the file or restore a backup (zeroui backup list %s)", appName),
+		})
+	}
+
+	if info, err := os.Stat(path); err == nil && runtime.GOOS != "windows" && info.Mode().Perm()&0o002 != 0 {
+		findings = append(findings, Finding{
+			App:      appName,
+			File:     path,
+			Severity: SeverityWarning,
+			Check:    CheckConfig,
+			Message:  "config is world-writable",
+			Fix:      fmt.Sprintf("chmod o-w %s", path),
+		})
+	}
+
+	return findings
+}
+
+// checkKeys compares config keys and values against the reference
+func checkKeys(appName, path string, textual bool, values map[string]interface{}, ref *reference.ConfigReference) []Finding {
+	known := make([]string, 0, len(ref.Settings))
+	for name := range ref.Settings {
+		known = append(known, name)
+	}
+	sort.Strings(known)
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var findings []Finding
+	for _, key := range keys {
+		if d, deprecated := ref.Deprecation(key); deprecated && d.Applies(values[key]) {
+			findings = append(findings, Finding{
+				App:      appName,
+				File:     path,
+				Severity: SeverityWarning,
+				Check:    CheckDeprecated,
+				Message:  d.Message(key),
+				Fix:      fmt.Sprintf("zeroui migrate %s", appName),
+			})
+			continue
+		}
+
+		setting, ok := lookupSetting(ref, key)
+		if !ok {
+			f := Finding{
+				App:      appName,
+				File:     path,
+				Severity: SeverityWarning,
+				Check:    CheckUnknownKey,
+				Message:  fmt.Sprintf("unknown key %q", key),
+				Fix:      fmt.Sprintf("Remove the key, or refresh the reference with: zeroui ref generate %s", appName),
+			}
+			if match := closestKey(key, known); match != "" {
+				f.Fix = fmt.Sprintf("Did you mean %q? Rename the key", match)
+			}
+			findings = append(findings, f)
+			continue
+		}
+
+		for _, value := range valueItems(values[key], setting.Type) {
+			if !valueMatchesType(setting.Type, value, textual) {
+				findings = append(findings, Finding{
+					App:      appName,
+					File:     path,
+					Severity: SeverityError,
+					Check:    CheckType,
+					Message:  fmt.Sprintf("%s: expected %s, got %v", key, setting.Type, secrets.Redact(key, value)),
+					Fix:      fmt.Sprintf("Set %s to a %s value", key, setting.Type),
+				})
+				break
+			}
+			if len(setting.ValidValues) > 0 && !containsString(setting.ValidValues, fmt.Sprintf("%v", value)) {
+				findings = append(findings, Finding{
+					App:      appName,
+					File:     path,
+					Severity: SeverityError,
+					Check:    CheckEnum,
+					Message:  fmt.Sprintf("%s: %v is not a valid value", key, secrets.Redact(key, value)),
+					Fix:      fmt.Sprintf("Use one of: %s", strings.Join(setting.ValidValues, ", ")),
+				})
+				break
+			}
+		}
+	}
+
+	return findings
+}
+
+// lookupSetting finds the reference setting for a key, falling back to the
+// closest ancestor so values inside documented objects are not flagged
+func lookupSetting(ref *reference.ConfigReference, key string) (reference.ConfigSetting, bool) {
+	if setting, ok := ref.Settings[key]; ok {
+		return setting, true
+	}
+	for i := strings.LastIndex(key, "."); i > 0; i = strings.LastIndex(key[:i], ".") {
+		if _, ok := ref.Settings[key[:i]]; ok {
+			// Nested values of an object setting aren't typed individually
+			return reference.ConfigSetting{Name: key}, true
+		}
+	}
+	return reference.ConfigSetting{}, false
+}
+
+// valueItems splits repeated values (e.g. Ghostty keys set several times)
+// unless the setting itself is a list
+func valueItems(value interface{}, t reference.SettingType) []interface{} {
+	if t == reference.TypeArray {
+		return []interface{}{value}
+	}
+	switch v := value.(type) {
+	case []interface{}:
+		return v
+	case []string:
+		items := make([]interface{}, len(v))
+		for i, s := range v {
+			items[i] = s
+		}
+		return items
+	default:
+		return []interface{}{value}
+	}
+}
+
+// valueMatchesType checks a value against a reference type. Text formats store
+// every value as a string, so they only need to parse as the expected type.
+func valueMatchesType(t reference.SettingType, value interface{}, textual bool) bool {
+	if s, ok := value.(string); ok && textual {
+		switch t {
+		case reference.TypeNumber:
+			_, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+			return err == nil
+		case reference.TypeBoolean:
+			_, err := strconv.ParseBool(strings.TrimSpace(s))
+			return err == nil
+		default:
+			return true
+		}
+	}
+
+	switch t {
+	case reference.TypeString:
+		_, ok := value.(string)
+		return ok
+	case reference.TypeNumber:
+		switch value.(type) {
+		case int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+			return true
+		}
+		return false
+	case reference.TypeBoolean:
+		_, ok := value.(bool)
+		return ok
+	case reference.TypeArray:
+		switch value.(type) {
+		case []interface{}, []string:
+			return true
+		}
+		return false
+	case reference.TypeObject:
+		_, ok := value.(map[string]interface{})
+		return ok
+	default:
+		return true
+	}
+}
+
+func containsString(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// checkTempFiles looks for temp and lock files left behind by TempFileManager
+func (d *Doctor) checkTempFiles() []Finding {
+	if d.opts.TempDir == "" {
+		return nil
+	}
+
+	entries, err := os.ReadDir(d.opts.TempDir)
+	if err != nil {
+		return nil
+	}
+
+	var findings []Finding
+	for _, entry := range entries {
+		if !entry.IsDir() || !strings.HasPrefix(entry.Name(), "zeroui-temp-") {
+			continue
+		}
+		dir := filepath.Join(d.opts.TempDir, entry.Name())
+		files, err := os.ReadDir(dir)
+		if err != nil || len(files) == 0 {
+			continue
+		}
+
+		pid, _ := strconv.Atoi(strings.TrimPrefix(entry.Name(), "zeroui-temp-"))
+		if !processAlive(pid) {
+			findings = append(findings, Finding{
+				File:     dir,
+				Severity: SeverityWarning,
+				Check:    CheckTempFiles,
+				Message:  fmt.Sprintf("%d leftover temp/lock files from exited process %d", len(files), pid),
+				Fix:      fmt.Sprintf("rm -rf %s", dir),
+			})
+			continue
+		}
+
+		for _, file := range files {
+			if !strings.HasSuffix(file.Name(), ".lock") {
+				continue
+			}
+			lockPath := filepath.Join(dir, file.Name())
+			if age, ok := lockAge(lockPath); ok && age > d.opts.StaleAfter {
+				findings = append(findings, Finding{
+					File:     lockPath,
+					Severity: SeverityWarning,
+					Check:    CheckTempFiles,
+					Message:  fmt.Sprintf("lock held for %s", age.Round(time.Minute)),
+					Fix:      fmt.Sprintf("If no edit is in progress: rm %s %s", lockPath, strings.TrimSuffix(lockPath, ".lock")),
+				})
+			}
+		}
+	}
+
+	return findings
+}
+
+// lockAge reads the creation time recorded in a lock file ("pid:os:unix-time")
+func lockAge(path string) (time.Duration, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	parts := strings.Split(strings.TrimSpace(string(data)), ":")
+	if len(parts) != 3 {
+		return 0, false
+	}
+	ts, err := strconv.ParseInt(parts[2], 10, 64)
+	if err != nil {
+		return 0, false
+	}
+	return time.Since(time.Unix(ts, 0)), true
+}
+
+// processAlive reports whether a process with the pid exists
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	if pid == os.Getpid() {
+		return true
+	}
+	proc, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = proc.Signal(syscall.Signal(0))
+	return err == nil || err == syscall.EPERM
+}
+
+// checkBackups verifies the backup directory is usable and not accumulating junk
+func (d *Doctor) checkBackups() []Finding {
+	if d.opts.Backups == nil {
+		return nil
+	}
+
+	if err := d.opts.Backups.HealthCheck(); err != nil {
+		return []Finding{{
+			Severity: SeverityError,
+			Check:    CheckBackups,
+			Message:  fmt.Sprintf("backup directory is not usable: %v", err),
+			Fix:      fmt.Sprintf("Check permissions of %s", paths.BackupDir()),
+		}}
+	}
+
+	backups, err := d.opts.Backups.ListBackups("")
+	if err != nil {
+		return []Finding{{
+			Severity: SeverityError,
+			Check:    CheckBackups,
+			Message:  fmt.Sprintf("failed to list backups: %v", err),
+		}}
+	}
+
+	var findings []Finding
+	perApp := make(map[string]int)
+	for _, backup := range backups {
+		app := backupApp(backup.Name)
+		perApp[app]++
+		if backup.Size == 0 {
+			findings = append(findings, Finding{
+				App:      app,
+				File:     backup.Path,
+				Severity: SeverityWarning,
+				Check:    CheckBackups,
+				Message:  "backup is empty and cannot be restored",
+				Fix:      fmt.Sprintf("rm %s", backup.Path),
+			})
+		}
+	}
+
+	for app, count := range perApp {
+		if count > d.opts.BackupWarnCount {
+			findings = append(findings, Finding{
+				App:      app,
+				Severity: SeverityInfo,
+				Check:    CheckBackups,
+				Message:  fmt.Sprintf("%d backups kept", count),
+				Fix:      fmt.Sprintf("zeroui backup cleanup %s --keep 5", app),
+			})
+		}
+	}
+
+	return findings
+}
+
+// backupApp extracts the app name from "<app>_<date>_<time>.backup"
+func backupApp(name string) string {
+	name = strings.TrimSuffix(name, ".backup")
+	for i := 0; i < 2; i++ {
+		if idx := strings.LastIndex(name, "_"); idx > 0 {
+			name = name[:idx]
+		}
+	}
+	return name
+}
+
+// checkPlugins verifies installed plugins are executable and respond
+func (d *Doctor) checkPlugins(ctx context.Context) []Finding {
+	if d.opts.PluginDir == "" {
+		return nil
+	}
+
+	entries, err := os.ReadDir(d.opts.PluginDir)
+	if err != nil {
+		return nil
+	}
+
+	var findings []Finding
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasPrefix(entry.Name(), "zeroui-plugin-") {
+			continue
+		}
+		path := filepath.Join(d.opts.PluginDir, entry.Name())
+		name := strings.TrimSuffix(strings.TrimPrefix(entry.Name(), "zeroui-plugin-"), ".exe")
+
+		info, err := entry.Info()
+		if err != nil {
+			continue
+		}
+		if runtime.GOOS != "windows" && info.Mode().Perm()&0o111 == 0 {
+			findings = append(findings, Finding{
+				File:     path,
+				Severity: SeverityError,
+				Check:    CheckPlugins,
+				Message:  fmt.Sprintf("plugin %s is not executable", name),
+				Fix:      fmt.Sprintf("chmod +x %s", path),
+			})
+			continue
+		}
+
+		if d.opts.PluginCheck == nil {
+			continue
+		}
+		if err := d.opts.PluginCheck(ctx, name); err != nil {
+			findings = append(findings, Finding{
+				File:     path,
+				Severity: SeverityError,
+				Check:    CheckPlugins,
+				Message:  fmt.Sprintf("plugin %s failed health check: %v", name, err),
+				Fix:      "Reinstall the plugin or remove it from the plugin directory",
+			})
+		}
+	}
+
+	return findings
+}
@@ -0,0 +1,254 @@
+// Package doctor scans application configs and ZeroUI's own state for problems
+// and reports actionable findings.
+package doctor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/knadh/koanf/v2"
+
+	"github.com/mrtkrcm/ZeroUI/internal/appconfig"
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
+	"github.com/mrtkrcm/ZeroUI/internal/policy"
+	"github.com/mrtkrcm/ZeroUI/internal/recovery"
+	"github.com/mrtkrcm/ZeroUI/pkg/reference"
+)
+
+// Severity ranks how urgent a finding is
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+// rank orders severities from most to least urgent
+func (s Severity) rank() int {
+	switch s {
+	case SeverityError:
+		return 0
+	case SeverityWarning:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// Check names reported in findings
+const (
+	CheckConfig     = "config"
+	CheckParse      = "parse"
+	CheckUnknownKey = "unknown-key"
+	CheckDeprecated = "deprecated"
+	CheckType       = "type"
+	CheckEnum       = "enum"
+	CheckTempFiles  = "temp-files"
+	CheckBackups    = "backups"
+	CheckPlugins    = "plugins"
+	CheckPolicy     = "policy"
+)
+
+// Finding is a single problem discovered by a check
+type Finding struct {
+	App      string   `json:"app,omitempty"`
+	File     string   `json:"file,omitempty"`
+	Severity Severity `json:"severity"`
+	Check    string   `json:"check"`
+	Message  string   `json:"message"`
+	Fix      string   `json:"fix,omitempty"`
+}
+
+// Report collects the findings of a scan
+type Report struct {
+	Apps     []string  `json:"apps"`
+	Findings []Finding `json:"findings"`
+}
+
+// Count returns the number of findings with a severity
+func (r *Report) Count(severity Severity) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+// HasErrors reports whether any finding is an error
+func (r *Report) HasErrors() bool {
+	return r.Count(SeverityError) > 0
+}
+
+// Filter returns a copy of the report without findings below a severity
+func (r *Report) Filter(min Severity) *Report {
+	filtered := &Report{Apps: r.Apps, Findings: []Finding{}}
+	for _, f := range r.Findings {
+		if f.Severity.rank() <= min.rank() {
+			filtered.Findings = append(filtered.Findings, f)
+		}
+	}
+	return filtered
+}
+
+// ParseSeverity converts a severity name, as used by command line flags
+func ParseSeverity(s string) (Severity, error) {
+	switch sev := Severity(strings.ToLower(s)); sev {
+	case SeverityError, SeverityWarning, SeverityInfo:
+		return sev, nil
+	default:
+		return "", fmt.Errorf("unknown severity %q (expected error, warning or info)", s)
+	}
+}
+
+// TargetLoader parses an app's config file
+type TargetLoader interface {
+	LoadTargetConfig(appConfig *appconfig.AppConfig) (*koanf.Koanf, error)
+}
+
+// BackupStore exposes the backup directory to the backup checks
+type BackupStore interface {
+	HealthCheck() error
+	ListBackups(appName string) ([]recovery.BackupInfo, error)
+}
+
+// Options configures a scan. Zero values disable the corresponding check
+// unless noted otherwise.
+type Options struct {
+	Registry   *appconfig.AppsRegistry // Apps to scan (required)
+	Apps       []string                // Limit the scan to these apps
+	Loader     TargetLoader            // Config parser (defaults to appconfig.Loader)
+	References reference.ConfigLoader  // Reference settings for key, type and enum checks
+	Backups    BackupStore             // Backup directory to check
+	TempDir    string                  // Directory holding zeroui-temp-* directories
+	PluginDir  string                  // Directory holding zeroui-plugin-* binaries
+	PolicyPath string                  // Organization policy the configs must satisfy
+	// PluginCheck starts a plugin and verifies it responds; nil skips the check
+	PluginCheck func(ctx context.Context, name string) error
+	// StaleAfter is the age after which a live process's lock is considered stale (default 24h)
+	StaleAfter time.Duration
+	// BackupWarnCount is the number of backups per app that triggers a cleanup hint (default 50)
+	BackupWarnCount int
+}
+
+// Doctor runs health checks
+type Doctor struct {
+	opts   Options
+	policy *policy.Policy
+}
+
+// New creates a doctor with the given options
+func New(opts Options) *Doctor {
+	if opts.Loader == nil {
+		opts.Loader = &appconfig.Loader{}
+	}
+	if opts.StaleAfter == 0 {
+		opts.StaleAfter = 24 * time.Hour
+	}
+	if opts.BackupWarnCount == 0 {
+		opts.BackupWarnCount = 50
+	}
+	return &Doctor{opts: opts}
+}
+
+// Run performs all checks and returns the findings, most severe first
+func (d *Doctor) Run(ctx context.Context) *Report {
+	report := &Report{Apps: []string{}, Findings: []Finding{}}
+
+	report.Findings = append(report.Findings, d.loadPolicy()...)
+	for _, app := range d.apps() {
+		if ctx.Err() != nil {
+			break
+		}
+		report.Apps = append(report.Apps, app.Name)
+		report.Findings = append(report.Findings, d.checkApp(app)...)
+	}
+
+	report.Findings = append(report.Findings, d.checkTempFiles()...)
+	report.Findings = append(report.Findings, d.checkBackups()...)
+	report.Findings = append(report.Findings, d.checkPlugins(ctx)...)
+
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		a, b := report.Findings[i], report.Findings[j]
+		if a.Severity.rank() != b.Severity.rank() {
+			return a.Severity.rank() < b.Severity.rank()
+		}
+		if a.App != b.App {
+			return a.App < b.App
+		}
+		return a.File < b.File
+	})
+
+	return report
+}
+
+// apps returns the registry apps selected for the scan
+func (d *Doctor) apps() []appconfig.AppDefinition {
+	if d.opts.Registry == nil {
+		return nil
+	}
+
+	all := d.opts.Registry.GetAllApps()
+	if len(d.opts.Apps) == 0 {
+		return all
+	}
+
+	wanted := make(map[string]bool, len(d.opts.Apps))
+	for _, name := range d.opts.Apps {
+		wanted[name] = true
+	}
+	var selected []appconfig.AppDefinition
+	for _, app := range all {
+		if wanted[app.Name] {
+			selected = append(selected, app)
+		}
+	}
+	return selected
+}
+
+// WriteText renders a report for terminals
+func WriteText(w io.Writer, report *Report) error {
+	if len(report.Findings) == 0 {
+		_, err := fmt.Fprintf(w, "No problems found in %d apps\n", len(report.Apps))
+		return err
+	}
+
+	icons := map[Severity]string{SeverityError: "✗", SeverityWarning: "!", SeverityInfo: "i"}
+	for _, f := range report.Findings {
+		scope := f.App
+		if scope == "" {
+			scope = "zeroui"
+		}
+		fmt.Fprintf(w, "%s [%s] %s: %s\n", icons[f.Severity], f.Check, scope, f.Message)
+		if f.File != "" {
+			fmt.Fprintf(w, "    file: %s\n", f.File)
+		}
+		if f.Fix != "" {
+			fmt.Fprintf(w, "    fix:  %s\n", f.Fix)
+		}
+	}
+
+	_, err := fmt.Fprintf(w, "\n%d errors, %d warnings, %d notes across %d apps\n",
+		report.Count(SeverityError), report.Count(SeverityWarning), report.Count(SeverityInfo), len(report.Apps))
+	return err
+}
+
+// WriteJSON renders a report as indented JSON
+func WriteJSON(w io.Writer, report *Report) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(report)
+}
+
+// expandHome replaces a leading ~ with the user's home directory, rebased
+// under --root
+func expandHome(path string) string {
+	return paths.Expand(path)
+}
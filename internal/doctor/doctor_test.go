@@ -0,0 +1,304 @@
+package doctor
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mrtkrcm/ZeroUI/internal/appconfig"
+	"github.com/mrtkrcm/ZeroUI/internal/recovery"
+	"github.com/mrtkrcm/ZeroUI/pkg/reference"
+)
+
+type stubReferences map[string]*reference.ConfigReference
+
+func (s stubReferences) LoadReference(app string) (*reference.ConfigReference, error) {
+	if ref, ok := s[app]; ok {
+		return ref, nil
+	}
+	return nil, fmt.Errorf("no reference for %s", app)
+}
+
+type stubBackups struct {
+	healthErr error
+	backups   []recovery.BackupInfo
+}
+
+func (s *stubBackups) HealthCheck() error { return s.healthErr }
+
+func (s *stubBackups) ListBackups(string) ([]recovery.BackupInfo, error) { return s.backups, nil }
+
+func writeFile(t *testing.T, path, content string, mode os.FileMode) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatalf("failed to create dir: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), mode); err != nil {
+		t.Fatalf("failed to write %s: %v", path, err)
+	}
+}
+
+func findingsFor(report *Report, app, check string) []Finding {
+	var out []Finding
+	for _, f := range report.Findings {
+		if f.App == app && f.Check == check {
+			out = append(out, f)
+		}
+	}
+	return out
+}
+
+func TestDoctor_Run(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, filepath.Join(dir, "good.json"), `{"theme": "dark", "font-size": 12}`, 0o644)
+	writeFile(t, filepath.Join(dir, "typo.json"), `{"theme": "blue", "font-sise": 12, "font-size": "big", "blur-radius": 20}`, 0o644)
+	writeFile(t, filepath.Join(dir, "broken.json"), `{"theme": `, 0o644)
+	writeFile(t, filepath.Join(dir, "term", "config"), "font-size = abc\ntheme = dark\n", 0o644)
+	writeFile(t, filepath.Join(dir, "secret.json"), `{"api-key": "s3cr3t-v4lue"}`, 0o644)
+
+	registry := fmt.Sprintf(`applications:
+  - name: good
+    config_paths: ["%[1]s/good.json"]
+    config_format: json
+  - name: typo
+    config_paths: ["%[1]s/typo.json"]
+    config_format: json
+  - name: broken
+    config_paths: ["%[1]s/broken.json"]
+    config_format: json
+  - name: term
+    config_paths: ["%[1]s/term/config"]
+    config_format: custom
+  - name: secret
+    config_paths: ["%[1]s/secret.json"]
+    config_format: json
+  - name: missing
+    config_paths: ["%[1]s/missing.json"]
+    config_format: json
+`, dir)
+	registryPath := filepath.Join(dir, "registry.yaml")
+	writeFile(t, registryPath, registry, 0o644)
+	reg, err := appconfig.LoadAppsRegistryFromFile(registryPath)
+	if err != nil {
+		t.Fatalf("failed to load registry: %v", err)
+	}
+
+	settings := map[string]reference.ConfigSetting{
+		"theme":     {Name: "theme", Type: reference.TypeString, ValidValues: []string{"dark", "light"}},
+		"font-size": {Name: "font-size", Type: reference.TypeNumber},
+	}
+	refs := stubReferences{
+		"good": {AppName: "good", Settings: settings},
+		"typo": {AppName: "typo", Settings: settings, Deprecations: map[string]reference.Deprecation{
+			"blur-radius": {ReplacedBy: "blur", RemovedIn: "2.0"},
+		}},
+		"broken": {AppName: "broken", Settings: settings},
+		"term":   {AppName: "term", Settings: settings},
+		"secret": {AppName: "secret", Settings: map[string]reference.ConfigSetting{
+			"api-key": {Name: "api-key", Type: reference.TypeNumber},
+		}},
+	}
+
+	tempRoot := filepath.Join(dir, "tmp")
+	writeFile(t, filepath.Join(tempRoot, "zeroui-temp-999999999", "config_1.tmp"), "x", 0o600)
+	writeFile(t, filepath.Join(tempRoot, "zeroui-temp-999999999", "config_1.tmp.lock"), "999999999:linux:1", 0o600)
+
+	pluginDir := filepath.Join(dir, "plugins")
+	writeFile(t, filepath.Join(pluginDir, "zeroui-plugin-noexec"), "", 0o644)
+	writeFile(t, filepath.Join(pluginDir, "zeroui-plugin-dead"), "", 0o755)
+	writeFile(t, filepath.Join(pluginDir, "zeroui-plugin-alive"), "", 0o755)
+
+	backups := &stubBackups{backups: []recovery.BackupInfo{
+		{Name: "good_20240101_120000.backup", Path: "/backups/good_20240101_120000.backup", Size: 0},
+		{Name: "good_20240102_120000.backup", Path: "/backups/good_20240102_120000.backup", Size: 10},
+	}}
+
+	report := New(Options{
+		Registry:   reg,
+		References: refs,
+		Backups:    backups,
+		TempDir:    tempRoot,
+		PluginDir:  pluginDir,
+		PluginCheck: func(_ context.Context, name string) error {
+			if name == "dead" {
+				return errors.New("connection refused")
+			}
+			return nil
+		},
+	}).Run(context.Background())
+
+	if len(report.Apps) != 6 {
+		t.Errorf("expected 6 apps scanned, got %v", report.Apps)
+	}
+
+	for _, check := range []string{CheckConfig, CheckParse, CheckUnknownKey, CheckDeprecated, CheckType, CheckEnum} {
+		if got := findingsFor(report, "good", check); len(got) != 0 {
+			t.Errorf("expected no %s findings for good app, got %+v", check, got)
+		}
+	}
+
+	unknown := findingsFor(report, "typo", CheckUnknownKey)
+	if len(unknown) != 1 || !strings.Contains(unknown[0].Fix, `"font-size"`) {
+		t.Errorf("expected unknown key with suggestion, got %+v", unknown)
+	}
+	if got := findingsFor(report, "typo", CheckDeprecated); len(got) != 1 || got[0].Fix != "zeroui migrate typo" || !strings.Contains(got[0].Message, "use blur instead") {
+		t.Errorf("expected deprecated key with migrate hint, got %+v", got)
+	}
+	if got := findingsFor(report, "typo", CheckType); len(got) != 1 || got[0].Severity != SeverityError {
+		t.Errorf("expected type error for font-size, got %+v", got)
+	}
+	if got := findingsFor(report, "typo", CheckEnum); len(got) != 1 || !strings.Contains(got[0].Fix, "dark, light") {
+		t.Errorf("expected enum error for theme, got %+v", got)
+	}
+
+	if got := findingsFor(report, "broken", CheckConfig); len(got) != 1 || got[0].Severity != SeverityError {
+		t.Errorf("expected malformed config error, got %+v", got)
+	}
+	if got := findingsFor(report, "missing", CheckConfig); len(got) != 1 || got[0].Severity != SeverityInfo {
+		t.Errorf("expected missing config note, got %+v", got)
+	}
+
+	// Ghostty-style text values only need to parse as the expected type
+	if got := findingsFor(report, "term", CheckType); len(got) != 1 || !strings.Contains(got[0].Message, "font-size") {
+		t.Errorf("expected type error for text value, got %+v", got)
+	}
+
+	// Values in findings are masked like everywhere else
+	if got := findingsFor(report, "secret", CheckType); len(got) != 1 || strings.Contains(got[0].Message, "s3cr3t") {
+		t.Errorf("expected type error with the value masked, got %+v", got)
+	}
+
+	if got := findingsFor(report, "", CheckTempFiles); len(got) != 1 || !strings.Contains(got[0].Message, "exited process 999999999") {
+		t.Errorf("expected leftover temp files, got %+v", got)
+	}
+
+	plugins := findingsFor(report, "", CheckPlugins)
+	if len(plugins) != 2 {
+		t.Fatalf("expected two plugin findings, got %+v", plugins)
+	}
+	for _, f := range plugins {
+		if strings.Contains(f.Message, "alive") {
+			t.Errorf("healthy plugin should not be reported: %+v", f)
+		}
+	}
+
+	if got := findingsFor(report, "good", CheckBackups); len(got) != 1 || !strings.Contains(got[0].Message, "empty") {
+		t.Errorf("expected empty backup warning, got %+v", got)
+	}
+
+	if !report.HasErrors() || report.Findings[0].Severity != SeverityError {
+		t.Error("expected errors sorted first")
+	}
+}
+
+func TestDoctor_BackupHealth(t *testing.T) {
+	report := New(Options{Backups: &stubBackups{healthErr: errors.New("read-only file system")}}).Run(context.Background())
+	if got := findingsFor(report, "", CheckBackups); len(got) != 1 || got[0].Severity != SeverityError {
+		t.Errorf("expected backup directory error, got %+v", got)
+	}
+}
+
+func TestReport_Output(t *testing.T) {
+	report := &Report{
+		Apps: []string{"ghostty"},
+		Findings: []Finding{
+			{App: "ghostty", Severity: SeverityError, Check: CheckParse, Message: "bad", Fix: "fix it"},
+			{App: "ghostty", Severity: SeverityInfo, Check: CheckConfig, Message: "note"},
+		},
+	}
+
+	filtered := report.Filter(SeverityWarning)
+	if len(filtered.Findings) != 1 || len(report.Findings) != 2 {
+		t.Errorf("unexpected filter result: %+v", filtered.Findings)
+	}
+
+	var text bytes.Buffer
+	if err := WriteText(&text, report); err != nil {
+		t.Fatalf("WriteText failed: %v", err)
+	}
+	if !strings.Contains(text.String(), "fix:  fix it") || !strings.Contains(text.String(), "1 errors, 0 warnings, 1 notes") {
+		t.Errorf("unexpected text output:\n%s", text.String())
+	}
+
+	var buf bytes.Buffer
+	if err := WriteJSON(&buf, report); err != nil {
+		t.Fatalf("WriteJSON failed: %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(buf.Bytes(), &decoded); err != nil {
+		t.Fatalf("invalid JSON: %v", err)
+	}
+	if len(decoded.Findings) != 2 || decoded.Findings[0].Fix != "fix it" {
+		t.Errorf("unexpected JSON round trip: %+v", decoded)
+	}
+
+	if _, err := ParseSeverity("fatal"); err == nil {
+		t.Error("expected error for unknown severity")
+	}
+}
+
+func TestClosestKey(t *testing.T) {
+	keys := []string{"font-family", "font-size", "background-opacity"}
+
+	tests := map[string]string{
+		"font-sise":          "font-size",
+		"Font-Family":        "font-family",
+		"backgroud-opacity":  "background-opacity",
+		"completely-unknown": "",
+	}
+	for input, want := range tests {
+		if got := closestKey(input, keys); got != want {
+			t.Errorf("closestKey(%q) = %q, want %q", input, got, want)
+		}
+	}
+}
+
+func TestDoctor_Policy(t *testing.T) {
+	dir := t.TempDir()
+
+	writeFile(t, filepath.Join(dir, "git.json"), `{"commit.gpgsign": false, "theme": "blue"}`, 0o644)
+	registryPath := filepath.Join(dir, "registry.yaml")
+	writeFile(t, registryPath, fmt.Sprintf(`applications:
+  - name: git
+    config_paths: ["%s/git.json"]
+    config_format: json
+`, dir), 0o644)
+	reg, err := appconfig.LoadAppsRegistryFromFile(registryPath)
+	if err != nil {
+		t.Fatalf("failed to load registry: %v", err)
+	}
+
+	policyPath := filepath.Join(dir, "policy.yaml")
+	writeFile(t, policyPath, `apps:
+  git:
+    commit.gpgsign:
+      enforce: true
+    theme:
+      allow: [dark, light]
+`, 0o644)
+
+	report := New(Options{Registry: reg, PolicyPath: policyPath}).Run(context.Background())
+	got := findingsFor(report, "git", CheckPolicy)
+	if len(got) != 2 || got[0].Severity != SeverityError {
+		t.Fatalf("expected two policy errors, got %+v", got)
+	}
+	if got[0].Fix != "zeroui toggle git commit.gpgsign true" || !strings.Contains(got[0].Message, policyPath) {
+		t.Errorf("expected enforced key fix citing the policy, got %+v", got[0])
+	}
+	if !strings.Contains(got[1].Fix, "dark, light") {
+		t.Errorf("expected allowed values in fix, got %+v", got[1])
+	}
+
+	writeFile(t, policyPath, "apps: [", 0o644)
+	report = New(Options{Registry: reg, PolicyPath: policyPath}).Run(context.Background())
+	if got := findingsFor(report, "", CheckPolicy); len(got) != 1 || got[0].File != policyPath {
+		t.Errorf("expected unreadable policy error, got %+v", got)
+	}
+}
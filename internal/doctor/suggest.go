@@ -0,0 +1,45 @@
+package doctor
+
+import "strings"
+
+// closestKey returns the candidate nearest to key by edit distance, or "" when
+// nothing is close enough to be a plausible typo
+func closestKey(key string, candidates []string) string {
+	target := strings.ToLower(key)
+	limit := len(target) / 3
+	if limit < 2 {
+		limit = 2
+	}
+
+	best, bestDist := "", limit+1
+	for _, candidate := range candidates {
+		dist := editDistance(target, strings.ToLower(candidate))
+		if dist < bestDist {
+			best, bestDist = candidate, dist
+		}
+	}
+	return best
+}
+
+// editDistance computes the Levenshtein distance between two strings
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	prev := make([]int, len(rb)+1)
+	curr := make([]int, len(rb)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ra); i++ {
+		curr[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(rb)]
+}
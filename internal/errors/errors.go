@@ -3,6 +3,8 @@ package errors
 import (
 	"fmt"
 	"strings"
+
+	"github.com/mrtkrcm/ZeroUI/internal/secrets"
 )
 
 // ErrorType represents the type of error that occurred
@@ -15,6 +17,8 @@ const (
 	ConfigWriteError    ErrorType = "CONFIG_WRITE_ERROR"
 	ConfigInvalidFormat ErrorType = "CONFIG_INVALID_FORMAT"
 	ConfigPermission    ErrorType = "CONFIG_PERMISSION"
+	ConfigLocked        ErrorType = "CONFIG_LOCKED"
+	ConfigConflict      ErrorType = "CONFIG_CONFLICT"
 
 	// App related errors
 	AppNotFound    ErrorType = "APP_NOT_FOUND"
@@ -47,6 +51,13 @@ const (
 	ValidationError ErrorType = "VALIDATION_ERROR"
 	SchemaError     ErrorType = "SCHEMA_ERROR"
 	TypeConversion  ErrorType = "TYPE_CONVERSION"
+	PolicyViolation ErrorType = "POLICY_VIOLATION"
+
+	// Backup related errors
+	BackupDecrypt ErrorType = "BACKUP_DECRYPT"
+
+	// Audit related errors
+	AuditTampered ErrorType = "AUDIT_TAMPERED"
 
 	// User input errors
 	UserInputError   ErrorType = "USER_INPUT_ERROR"
@@ -96,7 +107,7 @@ func (e *ZeroUIError) Error() string {
 		parts = append(parts, fmt.Sprintf("field: %s", e.Field))
 	}
 	if e.Value != "" {
-		parts = append(parts, fmt.Sprintf("value: %s", e.Value))
+		parts = append(parts, fmt.Sprintf("value: %s", secrets.RedactString(e.Field, e.Value)))
 	}
 
 	context := ""
@@ -194,6 +205,16 @@ func (e *ZeroUIError) WithSeverity(s Severity) *ZeroUIError {
 	return e
 }
 
+// WithContext records an additional piece of context, such as the keys
+// involved in a conflict, for callers that inspect the error
+func (e *ZeroUIError) WithContext(key, value string) *ZeroUIError {
+	if e.Context == nil {
+		e.Context = make(map[string]string)
+	}
+	e.Context[key] = value
+	return e
+}
+
 // Common error constructors for convenience
 
 // NewAppNotFoundError creates an app not found error
@@ -232,7 +253,7 @@ func NewInvalidValueError(app, field, value string, validValues []string) *ZeroU
 		suggestions = append(suggestions, fmt.Sprintf("Valid values: %s", strings.Join(validValues, ", ")))
 	}
 
-	return New(FieldInvalidValue, fmt.Sprintf("invalid value '%s' for field '%s'", value, field)).
+	return New(FieldInvalidValue, fmt.Sprintf("invalid value '%s' for field '%s'", secrets.RedactString(field, value), field)).
 		WithApp(app).
 		WithField(field).
 		WithValue(value).
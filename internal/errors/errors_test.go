@@ -2,6 +2,7 @@ package errors
 
 import (
 	"fmt"
+	"strings"
 	"testing"
 )
 
@@ -21,6 +22,14 @@ func TestZeroUIError_Error(t *testing.T) {
 	}
 }
 
+// TestZeroUIError_ErrorMasksSecrets tests that secret values stay out of messages
+func TestZeroUIError_ErrorMasksSecrets(t *testing.T) {
+	err := NewInvalidValueError("git", "github.token", "gh-secret", nil)
+	if msg := err.Error(); strings.Contains(msg, "gh-secret") || !strings.Contains(msg, "value: ********") {
+		t.Errorf("Expected the token masked, got '%s'", msg)
+	}
+}
+
 // TestZeroUIError_String tests user-friendly error formatting
 func TestZeroUIError_String(t *testing.T) {
 	err := &ZeroUIError{
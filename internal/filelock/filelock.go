@@ -0,0 +1,292 @@
+// Package filelock serializes config writes across processes. Each target
+// config gets a lock file in a shared directory, held with an OS advisory
+// lock (flock on Unix) for the whole read-modify-write cycle, so the TUI,
+// CLI invocations and other front ends never clobber each other's changes.
+//
+// Within a process locks are reentrant by path: locking a config this
+// process already holds succeeds immediately, so nested save paths (an engine
+// operation that calls the loader's save) don't deadlock.
+package filelock
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/mrtkrcm/ZeroUI/internal/errors"
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
+	"github.com/mrtkrcm/ZeroUI/internal/security"
+)
+
+// DefaultLockTimeout is how long Acquire waits for another process by default
+const DefaultLockTimeout = 5 * time.Second
+
+const defaultPollInterval = 50 * time.Millisecond
+
+// unownedStaleAge is how old a lock file without a readable owner must be
+// before it is considered abandoned
+const unownedStaleAge = time.Minute
+
+// errLocked reports that another process holds the lock
+var errLocked = fmt.Errorf("lock is held by another process")
+
+var defaultTimeout atomic.Int64
+
+func init() {
+	defaultTimeout.Store(int64(DefaultLockTimeout))
+}
+
+// SetDefaultTimeout changes how long Acquire waits when Options.Timeout is
+// zero. Non-positive values restore DefaultLockTimeout.
+func SetDefaultTimeout(d time.Duration) {
+	if d <= 0 {
+		d = DefaultLockTimeout
+	}
+	defaultTimeout.Store(int64(d))
+}
+
+// DefaultTimeout returns the current default wait
+func DefaultTimeout() time.Duration {
+	return time.Duration(defaultTimeout.Load())
+}
+
+// Options adjusts how a lock is acquired
+type Options struct {
+	// Timeout bounds the wait for another process; zero uses DefaultTimeout
+	// and a negative value fails immediately
+	Timeout time.Duration
+	// PollInterval is the delay between attempts, default 50ms
+	PollInterval time.Duration
+	// Dir holds the lock files, default Dir()
+	Dir string
+}
+
+// Owner describes the process holding a lock, as recorded in its lock file
+type Owner struct {
+	PID     int
+	Command string
+	Since   time.Time
+}
+
+// Lock is a held config lock
+type Lock struct {
+	key      string
+	once     sync.Once
+	released error
+}
+
+// held tracks the locks this process owns, by lock key
+var (
+	heldMu sync.Mutex
+	held   = make(map[string]*heldLock)
+)
+
+type heldLock struct {
+	file *os.File
+	path string
+	refs int
+}
+
+// Dir returns the default lock directory: $ZEROUI_LOCK_DIR, or locks in the
+// zeroui cache directory
+func Dir() string {
+	if dir := os.Getenv("ZEROUI_LOCK_DIR"); dir != "" {
+		return dir
+	}
+	return paths.LockDir()
+}
+
+// LockPath returns the lock file used for a target config
+func LockPath(target string, dir string) string {
+	if dir == "" {
+		dir = Dir()
+	}
+	key := lockKey(target)
+	sum := sha256.Sum256([]byte(key))
+	name := strings.TrimPrefix(filepath.Base(key), ".")
+	return filepath.Join(dir, fmt.Sprintf("%s-%s.lock", name, hex.EncodeToString(sum[:6])))
+}
+
+// Acquire locks the config at target, waiting for other processes up to the
+// configured timeout. A lock whose recorded owner is no longer running is
+// taken over.
+func Acquire(target string, opts Options) (*Lock, error) {
+	key := lockKey(target)
+
+	heldMu.Lock()
+	if h, ok := held[key]; ok {
+		h.refs++
+		heldMu.Unlock()
+		return &Lock{key: key}, nil
+	}
+	heldMu.Unlock()
+
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = DefaultTimeout()
+	}
+	poll := opts.PollInterval
+	if poll <= 0 {
+		poll = defaultPollInterval
+	}
+
+	lockPath := LockPath(target, opts.Dir)
+	if err := os.MkdirAll(filepath.Dir(lockPath), 0o700); err != nil {
+		return nil, errors.Wrap(errors.SystemFileError, "failed to create lock directory", err).
+			WithValue(filepath.Dir(lockPath))
+	}
+
+	deadline := time.Now().Add(timeout)
+	stalePID := 0
+	for {
+		file, err := tryLock(lockPath)
+		if err == nil {
+			writeOwner(file)
+
+			heldMu.Lock()
+			if h, ok := held[key]; ok {
+				// Another goroutine of this process won the race; share its lock
+				h.refs++
+				heldMu.Unlock()
+				unlock(file, lockPath)
+				return &Lock{key: key}, nil
+			}
+			held[key] = &heldLock{file: file, path: lockPath, refs: 1}
+			heldMu.Unlock()
+			return &Lock{key: key}, nil
+		}
+		if err != errLocked {
+			return nil, errors.Wrap(errors.SystemFileError, "failed to lock config", err).
+				WithValue(target)
+		}
+
+		owner, _ := ReadOwner(lockPath)
+		switch {
+		case owner != nil && owner.PID > 0 && owner.PID != os.Getpid() && !processAlive(owner.PID):
+			// The owner died without releasing (a descendant may still hold
+			// the file open). Seeing the same dead owner twice rules out a
+			// new owner that locked but hasn't recorded itself yet.
+			if stalePID == owner.PID && breakStale(lockPath) {
+				stalePID = 0
+				continue
+			}
+			stalePID = owner.PID
+		case owner == nil && olderThan(lockPath, unownedStaleAge):
+			if breakStale(lockPath) {
+				continue
+			}
+		}
+
+		if !time.Now().Before(deadline) {
+			return nil, lockedError(target, owner, timeout)
+		}
+		time.Sleep(poll)
+	}
+}
+
+// Release unlocks the config. It is safe to call more than once.
+func (l *Lock) Release() error {
+	l.once.Do(func() {
+		heldMu.Lock()
+		defer heldMu.Unlock()
+
+		h, ok := held[l.key]
+		if !ok {
+			return
+		}
+		h.refs--
+		if h.refs > 0 {
+			return
+		}
+		delete(held, l.key)
+		l.released = unlock(h.file, h.path)
+	})
+	return l.released
+}
+
+// Held reports whether this process holds the lock for target
+func Held(target string) bool {
+	heldMu.Lock()
+	defer heldMu.Unlock()
+	_, ok := held[lockKey(target)]
+	return ok
+}
+
+// ReadOwner reads the owner recorded in a lock file
+func ReadOwner(lockPath string) (*Owner, error) {
+	data, err := os.ReadFile(lockPath)
+	if err != nil {
+		return nil, err
+	}
+
+	lines := strings.SplitN(string(data), "\n", 3)
+	pid, err := strconv.Atoi(strings.TrimSpace(lines[0]))
+	if err != nil {
+		return nil, fmt.Errorf("malformed lock file %s", lockPath)
+	}
+	owner := &Owner{PID: pid}
+	if len(lines) > 1 {
+		if unix, err := strconv.ParseInt(strings.TrimSpace(lines[1]), 10, 64); err == nil {
+			owner.Since = time.Unix(unix, 0)
+		}
+	}
+	if len(lines) > 2 {
+		owner.Command = strings.TrimSpace(lines[2])
+	}
+	return owner, nil
+}
+
+// writeOwner records this process in a freshly locked file
+func writeOwner(file *os.File) {
+	owner := fmt.Sprintf("%d\n%d\n%s\n", os.Getpid(), time.Now().Unix(), strings.Join(os.Args, " "))
+	if err := file.Truncate(0); err == nil {
+		_, _ = file.WriteAt([]byte(owner), 0)
+	}
+}
+
+// breakStale removes an abandoned lock file so the next attempt starts on a
+// fresh one
+func breakStale(lockPath string) bool {
+	return os.Remove(lockPath) == nil
+}
+
+func olderThan(path string, age time.Duration) bool {
+	info, err := os.Stat(path)
+	return err == nil && time.Since(info.ModTime()) > age
+}
+
+// lockKey identifies a target config independent of how its path is spelled
+func lockKey(target string) string {
+	// A config and a symlink to it share one lock
+	if resolved, err := security.ResolvePath(target); err == nil {
+		return resolved
+	}
+	return filepath.Clean(target)
+}
+
+func lockedError(target string, owner *Owner, timeout time.Duration) error {
+	err := errors.New(errors.ConfigLocked, fmt.Sprintf("%s is being changed by another process", target)).
+		WithValue(target)
+
+	var suggestions []string
+	if owner != nil {
+		holder := fmt.Sprintf("Locked by PID %d", owner.PID)
+		if owner.Command != "" {
+			holder += " (" + owner.Command + ")"
+		}
+		if !owner.Since.IsZero() {
+			holder += " since " + owner.Since.Format(time.RFC3339)
+		}
+		suggestions = append(suggestions, holder)
+	}
+	suggestions = append(suggestions,
+		fmt.Sprintf("Waited %s; retry when it finishes or raise --lock-timeout", timeout))
+	return err.WithSuggestions(suggestions...)
+}
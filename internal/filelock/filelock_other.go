@@ -0,0 +1,31 @@
+//go:build !unix
+
+package filelock
+
+import (
+	"os"
+)
+
+// tryLock creates the lock file exclusively; its existence is the lock
+func tryLock(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		if os.IsExist(err) {
+			return nil, errLocked
+		}
+		return nil, err
+	}
+	return file, nil
+}
+
+// unlock closes and removes the lock file
+func unlock(file *os.File, path string) error {
+	file.Close()
+	return os.Remove(path)
+}
+
+// processAlive reports whether a process with pid exists
+func processAlive(pid int) bool {
+	_, err := os.FindProcess(pid)
+	return err == nil
+}
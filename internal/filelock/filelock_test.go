@@ -0,0 +1,128 @@
+package filelock
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrtkrcm/ZeroUI/internal/errors"
+)
+
+func TestAcquire_Reentrant(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(t.TempDir(), "config")
+
+	outer, err := Acquire(target, Options{Dir: dir})
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	inner, err := Acquire(target, Options{Dir: dir, Timeout: -1})
+	if err != nil {
+		t.Fatalf("Nested Acquire should succeed immediately: %v", err)
+	}
+
+	if err := inner.Release(); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if err := inner.Release(); err != nil {
+		t.Fatalf("Second Release should be a no-op: %v", err)
+	}
+	if !Held(target) {
+		t.Fatal("Outer lock should still be held after the inner release")
+	}
+
+	owner, err := ReadOwner(LockPath(target, dir))
+	if err != nil || owner.PID != os.Getpid() {
+		t.Errorf("Expected this process as the owner, got %+v (%v)", owner, err)
+	}
+
+	if err := outer.Release(); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if Held(target) {
+		t.Error("Lock should be released")
+	}
+}
+
+func TestAcquire_TimesOutWhileHeld(t *testing.T) {
+	dir := t.TempDir()
+	target := filepath.Join(t.TempDir(), "config")
+	lockPath := LockPath(target, dir)
+
+	// Another holder, as a separate open file, recorded as a live process
+	file, err := tryLock(lockPath)
+	if err != nil {
+		t.Fatalf("tryLock failed: %v", err)
+	}
+	defer unlock(file, lockPath)
+	fmt.Fprintf(file, "%d\n%d\nzeroui toggle ghostty theme\n", os.Getppid(), time.Now().Unix())
+
+	start := time.Now()
+	_, err = Acquire(target, Options{Dir: dir, Timeout: 150 * time.Millisecond, PollInterval: 10 * time.Millisecond})
+	if err == nil {
+		t.Fatal("Expected Acquire to time out")
+	}
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Errorf("Acquire gave up after %s, before the timeout", elapsed)
+	}
+
+	zerr, ok := errors.GetZeroUIError(err)
+	if !ok || zerr.Type != errors.ConfigLocked {
+		t.Fatalf("Expected a ConfigLocked error, got %v", err)
+	}
+	holder := fmt.Sprintf("Locked by PID %d (zeroui toggle ghostty theme)", os.Getppid())
+	if len(zerr.Suggestions) == 0 || !strings.HasPrefix(zerr.Suggestions[0], holder) {
+		t.Errorf("Expected the holder in the suggestions, got %v", zerr.Suggestions)
+	}
+}
+
+func TestAcquire_TakesOverStaleLock(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("uses a short-lived shell process")
+	}
+	dir := t.TempDir()
+	target := filepath.Join(t.TempDir(), "config")
+	lockPath := LockPath(target, dir)
+
+	dead := exec.Command("sh", "-c", "exit 0")
+	if err := dead.Run(); err != nil {
+		t.Fatalf("Failed to run helper process: %v", err)
+	}
+
+	// The dead owner's lock is still held open, as by an orphaned child
+	file, err := tryLock(lockPath)
+	if err != nil {
+		t.Fatalf("tryLock failed: %v", err)
+	}
+	defer file.Close()
+	fmt.Fprintf(file, "%d\n%d\nzeroui\n", dead.Process.Pid, time.Now().Unix())
+
+	lock, err := Acquire(target, Options{Dir: dir, Timeout: time.Second, PollInterval: 10 * time.Millisecond})
+	if err != nil {
+		t.Fatalf("Expected the stale lock to be taken over: %v", err)
+	}
+	defer lock.Release()
+
+	owner, err := ReadOwner(lockPath)
+	if err != nil || owner.PID != os.Getpid() {
+		t.Errorf("Expected this process to own the new lock, got %+v (%v)", owner, err)
+	}
+}
+
+func TestSetDefaultTimeout(t *testing.T) {
+	defer SetDefaultTimeout(0)
+
+	SetDefaultTimeout(time.Minute)
+	if DefaultTimeout() != time.Minute {
+		t.Errorf("Expected 1m, got %s", DefaultTimeout())
+	}
+	SetDefaultTimeout(-1)
+	if DefaultTimeout() != DefaultLockTimeout {
+		t.Errorf("Non-positive timeouts should restore the default, got %s", DefaultTimeout())
+	}
+}
@@ -0,0 +1,45 @@
+//go:build unix
+
+package filelock
+
+import (
+	"os"
+	"syscall"
+)
+
+// tryLock opens the lock file and takes an exclusive flock without waiting
+func tryLock(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if err == syscall.EWOULDBLOCK {
+			return nil, errLocked
+		}
+		return nil, err
+	}
+
+	// A stale lock may have been broken while we waited on the old file
+	var locked, current syscall.Stat_t
+	if syscall.Fstat(int(file.Fd()), &locked) != nil || syscall.Stat(path, &current) != nil ||
+		locked.Ino != current.Ino || locked.Dev != current.Dev {
+		file.Close()
+		return nil, errLocked
+	}
+	return file, nil
+}
+
+// unlock releases the flock. The file stays so waiters keep locking the same
+// inode.
+func unlock(file *os.File, path string) error {
+	_ = syscall.Flock(int(file.Fd()), syscall.LOCK_UN)
+	return file.Close()
+}
+
+// processAlive reports whether a process with pid exists
+func processAlive(pid int) bool {
+	err := syscall.Kill(pid, 0)
+	return err == nil || err == syscall.EPERM
+}
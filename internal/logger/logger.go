@@ -4,9 +4,12 @@ import (
 	"context"
 	"io"
 	"os"
+	"strings"
 	"time"
 
 	"github.com/rs/zerolog"
+
+	"github.com/mrtkrcm/ZeroUI/internal/secrets"
 )
 
 // contextKey is a private type for context keys to avoid collisions
@@ -33,6 +36,9 @@ type LoggerInterface interface {
 // It implements the LoggerInterface
 type Logger struct {
 	logger zerolog.Logger
+	// secretField is set when the logger's field names a credential, so
+	// the values logged for it are masked
+	secretField bool
 }
 
 // Config holds logger configuration
@@ -85,26 +91,35 @@ func New(config *Config) *Logger {
 
 // WithContext adds contextual fields to the logger
 func (l *Logger) WithContext(fields map[string]interface{}) *Logger {
+	secretField := l.secretField
+	for _, key := range []string{"field", "key"} {
+		if name, ok := fields[key].(string); ok && secrets.IsSecretKey(name) {
+			secretField = true
+		}
+	}
+
+	next := &Logger{secretField: secretField}
 	ctx := l.logger.With()
 	for key, value := range fields {
-		ctx = ctx.Interface(key, value)
-	}
-	return &Logger{
-		logger: ctx.Logger(),
+		ctx = ctx.Interface(key, next.redact(key, value))
 	}
+	next.logger = ctx.Logger()
+	return next
 }
 
 // WithApp adds app context to the logger
 func (l *Logger) WithApp(app string) *Logger {
 	return &Logger{
-		logger: l.logger.With().Str("app", app).Logger(),
+		logger:      l.logger.With().Str("app", app).Logger(),
+		secretField: l.secretField,
 	}
 }
 
 // WithField adds a field context to the logger
 func (l *Logger) WithField(field string) *Logger {
 	return &Logger{
-		logger: l.logger.With().Str("field", field).Logger(),
+		logger:      l.logger.With().Str("field", field).Logger(),
+		secretField: l.secretField || secrets.IsSecretKey(field),
 	}
 }
 
@@ -184,7 +199,7 @@ func (l *Logger) Success(msg string, fields ...map[string]interface{}) {
 func (l *Logger) addFields(event *zerolog.Event, fields ...map[string]interface{}) {
 	for _, fieldMap := range fields {
 		for key, value := range fieldMap {
-			event = event.Interface(key, value)
+			event = event.Interface(key, l.redact(key, value))
 		}
 	}
 }
@@ -192,8 +207,17 @@ func (l *Logger) addFields(event *zerolog.Event, fields ...map[string]interface{
 // addStructuredFields adds structured Field types to a log event
 func (l *Logger) addStructuredFields(event *zerolog.Event, fields ...Field) {
 	for _, field := range fields {
-		event = event.Interface(field.Key, field.Value)
+		event = event.Interface(field.Key, l.redact(field.Key, field.Value))
+	}
+}
+
+// redact masks secrets in a log field: values of credential keys, token
+// formats, and every value logged for a credential field
+func (l *Logger) redact(key string, value interface{}) interface{} {
+	if l.secretField && strings.Contains(strings.ToLower(key), "value") {
+		return secrets.Masked
 	}
+	return secrets.Redact(key, value)
 }
 
 // With adds structured fields to the logger and returns a new logger instance
@@ -201,10 +225,10 @@ func (l *Logger) addStructuredFields(event *zerolog.Event, fields ...Field) {
 func (l *Logger) With(fields ...Field) LoggerInterface {
 	ctx := l.logger.With()
 	for _, field := range fields {
-		ctx = ctx.Interface(field.Key, field.Value)
+		ctx = ctx.Interface(field.Key, l.redact(field.Key, field.Value))
 	}
 	return &loggerAdapter{
-		logger: &Logger{logger: ctx.Logger()},
+		logger: &Logger{logger: ctx.Logger(), secretField: l.secretField},
 	}
 }
 
@@ -213,7 +237,8 @@ func (l *Logger) With(fields ...Field) LoggerInterface {
 func (l *Logger) WithRequest(requestID string) LoggerInterface {
 	return &loggerAdapter{
 		logger: &Logger{
-			logger: l.logger.With().Str("request_id", requestID).Logger(),
+			logger:      l.logger.With().Str("request_id", requestID).Logger(),
+			secretField: l.secretField,
 		},
 	}
 }
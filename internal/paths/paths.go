@@ -0,0 +1,126 @@
+// Package paths resolves where zeroui keeps its own files, following the XDG
+// Base Directory specification:
+//
+//   - config (app definitions, presets, runtime config): $XDG_CONFIG_HOME/zeroui,
+//     default ~/.config/zeroui, overridden by $ZEROUI_CONFIG_DIR
+//   - state (backups, logs, audit log): $XDG_STATE_HOME/zeroui, default ~/.local/state/zeroui
+//   - cache (locks, reference configs): $XDG_CACHE_HOME/zeroui, default ~/.cache/zeroui
+//
+// The same defaults apply on every platform, so a zeroui directory can be
+// shared between machines. As the specification requires, XDG variables
+// holding relative paths are ignored.
+//
+// With a root (--root, ZEROUI_ROOT) all of these, and the config paths of the
+// apps zeroui manages, resolve inside the root instead; see SetRoot.
+package paths
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// appName is the directory zeroui uses under each base directory
+const appName = "zeroui"
+
+// Home returns the home directory configs are resolved against: the root
+// when one is set, the user's home directory otherwise, or "." when it can't
+// be determined
+func Home() string {
+	if r := Root(); r != "" {
+		return r
+	}
+	if home, err := os.UserHomeDir(); err == nil {
+		return home
+	}
+	return "."
+}
+
+// ConfigDir returns the directory for zeroui's configuration:
+// $ZEROUI_CONFIG_DIR, $XDG_CONFIG_HOME/zeroui or ~/.config/zeroui
+func ConfigDir() string {
+	if dir := os.Getenv("ZEROUI_CONFIG_DIR"); dir != "" {
+		return Expand(dir)
+	}
+	return filepath.Join(ConfigHome(), appName)
+}
+
+// StateDir returns the directory for data zeroui keeps between runs:
+// $XDG_STATE_HOME/zeroui or ~/.local/state/zeroui
+func StateDir() string {
+	return filepath.Join(base("XDG_STATE_HOME", ".local", "state"), appName)
+}
+
+// CacheDir returns the directory for data zeroui can recreate:
+// $XDG_CACHE_HOME/zeroui or ~/.cache/zeroui
+func CacheDir() string {
+	return filepath.Join(base("XDG_CACHE_HOME", ".cache"), appName)
+}
+
+// ConfigHome returns the base directory for user configuration, where the
+// configs of the apps zeroui manages live as well: $XDG_CONFIG_HOME or
+// ~/.config
+func ConfigHome() string {
+	return base("XDG_CONFIG_HOME", ".config")
+}
+
+// BackupDir returns the directory holding config backups
+func BackupDir() string {
+	return filepath.Join(StateDir(), "backups")
+}
+
+// AuditLog returns the log of configuration changes
+func AuditLog() string {
+	return filepath.Join(StateDir(), "audit.jsonl")
+}
+
+// LogFile returns the default log file
+func LogFile() string {
+	return filepath.Join(StateDir(), "zeroui.log")
+}
+
+// LockDir returns the directory holding cross-process lock files
+func LockDir() string {
+	return filepath.Join(CacheDir(), "locks")
+}
+
+// ReferenceCacheDir returns the directory for reference configs when no
+// curated configs directory is found
+func ReferenceCacheDir() string {
+	return filepath.Join(CacheDir(), "configs")
+}
+
+// TempDir returns the directory under which zeroui creates its temporary
+// working directories
+func TempDir() string {
+	return os.TempDir()
+}
+
+// LegacyBackupDir returns where backups were kept before they moved to the
+// state directory
+func LegacyBackupDir() string {
+	return filepath.Join(Home(), ".config", "configtoggle", "backups")
+}
+
+// LegacyConfigDir returns the platform config directory zeroui read apps.yaml
+// from before it followed XDG (~/Library/Application Support/zeroui on macOS,
+// %AppData%\zeroui on Windows), or "" under a root, which the platform
+// directory lies outside of
+func LegacyConfigDir() string {
+	if Root() != "" {
+		return ""
+	}
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(dir, appName)
+}
+
+// base returns an XDG base directory: the variable's value when it holds an
+// absolute path, the default under the home directory otherwise
+func base(variable string, fallback ...string) string {
+	if dir := os.Getenv(variable); dir != "" && filepath.IsAbs(dir) {
+		return Rebase(dir)
+	}
+	return filepath.Join(append([]string{Home()}, fallback...)...)
+}
@@ -0,0 +1,58 @@
+package paths
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestDefaults(t *testing.T) {
+	t.Setenv("HOME", "/home/octo")
+	t.Setenv("ZEROUI_CONFIG_DIR", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_STATE_HOME", "")
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	tests := map[string]struct{ got, want string }{
+		"config":  {ConfigDir(), "/home/octo/.config/zeroui"},
+		"state":   {StateDir(), "/home/octo/.local/state/zeroui"},
+		"cache":   {CacheDir(), "/home/octo/.cache/zeroui"},
+		"backups": {BackupDir(), "/home/octo/.local/state/zeroui/backups"},
+		"log":     {LogFile(), "/home/octo/.local/state/zeroui/zeroui.log"},
+		"audit":   {AuditLog(), "/home/octo/.local/state/zeroui/audit.jsonl"},
+		"locks":   {LockDir(), "/home/octo/.cache/zeroui/locks"},
+		"legacy":  {LegacyBackupDir(), "/home/octo/.config/configtoggle/backups"},
+	}
+	for name, tt := range tests {
+		if tt.got != filepath.FromSlash(tt.want) {
+			t.Errorf("%s: got %s, want %s", name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestXDGVariables(t *testing.T) {
+	t.Setenv("HOME", "/home/octo")
+	t.Setenv("ZEROUI_CONFIG_DIR", "")
+	t.Setenv("XDG_CONFIG_HOME", "/xdg/config")
+	t.Setenv("XDG_STATE_HOME", "/xdg/state")
+	t.Setenv("XDG_CACHE_HOME", "relative/cache")
+
+	if got := ConfigDir(); got != filepath.FromSlash("/xdg/config/zeroui") {
+		t.Errorf("ConfigDir = %s", got)
+	}
+	if got := BackupDir(); got != filepath.FromSlash("/xdg/state/zeroui/backups") {
+		t.Errorf("BackupDir = %s", got)
+	}
+	// Relative XDG paths are invalid and ignored
+	if got := CacheDir(); got != filepath.FromSlash("/home/octo/.cache/zeroui") {
+		t.Errorf("CacheDir = %s", got)
+	}
+
+	t.Setenv("ZEROUI_CONFIG_DIR", "/custom")
+	if got := ConfigDir(); got != "/custom" {
+		t.Errorf("Expected ZEROUI_CONFIG_DIR to win, got %s", got)
+	}
+	// The old backup location doesn't follow XDG_CONFIG_HOME
+	if got := LegacyBackupDir(); got != filepath.FromSlash("/home/octo/.config/configtoggle/backups") {
+		t.Errorf("LegacyBackupDir = %s", got)
+	}
+}
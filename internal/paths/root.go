@@ -0,0 +1,96 @@
+package paths
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+)
+
+var root atomic.Value
+
+func init() {
+	root.Store("")
+}
+
+// SetRoot makes zeroui operate on the tree under dir instead of the user's
+// home directory: ~ expands to dir, and absolute config paths are rebased
+// into it. An empty dir turns the root off. The directory must exist, so a
+// mistyped root can't silently fall back to the real files.
+func SetRoot(dir string) error {
+	if dir == "" {
+		root.Store("")
+		return nil
+	}
+	abs, err := filepath.Abs(ExpandHome(dir))
+	if err != nil {
+		return fmt.Errorf("invalid root %s: %w", dir, err)
+	}
+	info, err := os.Stat(abs)
+	if err != nil {
+		return fmt.Errorf("invalid root: %w", err)
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("invalid root: %s is not a directory", abs)
+	}
+	root.Store(filepath.Clean(abs))
+	return nil
+}
+
+// Root returns the directory zeroui is rebased into, or "" when it works on
+// the user's own home directory
+func Root() string {
+	return root.Load().(string)
+}
+
+// Expand resolves a config path: a leading ~ expands to the home directory,
+// and under a root absolute paths are rebased into it. Paths already inside
+// the root and relative paths are returned as they are.
+func Expand(path string) string {
+	if isHomeRelative(path) {
+		return filepath.Join(Home(), path[1:])
+	}
+	return Rebase(path)
+}
+
+// Rebase moves an absolute path into the root: paths under the user's home
+// directory keep their place relative to it, so /home/me/.gitconfig and
+// ~/.gitconfig both become <root>/.gitconfig, and other paths move below it,
+// so /etc/tmux.conf becomes <root>/etc/tmux.conf.
+func Rebase(path string) string {
+	r := Root()
+	if r == "" || !filepath.IsAbs(path) || within(r, path) {
+		return path
+	}
+	if home, err := os.UserHomeDir(); err == nil && within(home, path) {
+		rel, _ := filepath.Rel(home, path)
+		return filepath.Join(r, rel)
+	}
+	return filepath.Join(r, path[len(filepath.VolumeName(path)):])
+}
+
+// ExpandHome expands a leading ~ to the user's own home directory, ignoring
+// the root. It is for paths naming files of this machine rather than configs
+// zeroui manages, such as commands and key files.
+func ExpandHome(path string) string {
+	if !isHomeRelative(path) {
+		return path
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return path
+	}
+	return filepath.Join(home, path[1:])
+}
+
+// isHomeRelative reports whether path is ~ or starts with ~/
+func isHomeRelative(path string) bool {
+	return path == "~" || strings.HasPrefix(path, "~/") || strings.HasPrefix(path, "~"+string(os.PathSeparator))
+}
+
+// within reports whether path is dir or below it
+func within(dir, path string) bool {
+	rel, err := filepath.Rel(dir, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(os.PathSeparator))
+}
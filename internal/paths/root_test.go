@@ -0,0 +1,74 @@
+package paths
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSetRoot(t *testing.T) {
+	t.Cleanup(func() { _ = SetRoot("") })
+
+	if err := SetRoot(filepath.Join(t.TempDir(), "missing")); err == nil {
+		t.Error("Expected an error for a missing root")
+	}
+	file := filepath.Join(t.TempDir(), "file")
+	if err := os.WriteFile(file, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := SetRoot(file); err == nil {
+		t.Error("Expected an error for a root that isn't a directory")
+	}
+	if Root() != "" {
+		t.Errorf("A failed SetRoot changed the root to %s", Root())
+	}
+
+	dir := t.TempDir()
+	if err := SetRoot(dir); err != nil {
+		t.Fatalf("SetRoot failed: %v", err)
+	}
+	if Root() != dir {
+		t.Errorf("Root = %s, want %s", Root(), dir)
+	}
+	if err := SetRoot(""); err != nil || Root() != "" {
+		t.Errorf("Expected SetRoot(\"\") to clear the root, got %q, %v", Root(), err)
+	}
+}
+
+func TestExpandUnderRoot(t *testing.T) {
+	t.Setenv("HOME", "/home/octo")
+	t.Setenv("ZEROUI_CONFIG_DIR", "")
+	t.Setenv("XDG_CONFIG_HOME", "")
+	t.Setenv("XDG_STATE_HOME", "/var/state")
+	t.Setenv("XDG_CACHE_HOME", "")
+
+	dir := t.TempDir()
+	if err := SetRoot(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = SetRoot("") })
+
+	tests := map[string]struct{ got, want string }{
+		"tilde":       {Expand("~/.config/ghostty/config"), filepath.Join(dir, ".config/ghostty/config")},
+		"home":        {Expand("/home/octo/.gitconfig"), filepath.Join(dir, ".gitconfig")},
+		"absolute":    {Expand("/etc/tmux.conf"), filepath.Join(dir, "etc/tmux.conf")},
+		"inside root": {Expand(filepath.Join(dir, "a")), filepath.Join(dir, "a")},
+		"relative":    {Expand("configs/a.yaml"), "configs/a.yaml"},
+		"host file":   {ExpandHome("~/.ssh/key"), "/home/octo/.ssh/key"},
+		"config dir":  {ConfigDir(), filepath.Join(dir, ".config/zeroui")},
+		"state dir":   {StateDir(), filepath.Join(dir, "var/state/zeroui")},
+		"cache dir":   {CacheDir(), filepath.Join(dir, ".cache/zeroui")},
+	}
+	for name, tt := range tests {
+		if tt.got != filepath.FromSlash(tt.want) {
+			t.Errorf("%s: got %s, want %s", name, tt.got, tt.want)
+		}
+	}
+
+	if err := SetRoot(""); err != nil {
+		t.Fatal(err)
+	}
+	if got := Expand("/etc/tmux.conf"); got != "/etc/tmux.conf" {
+		t.Errorf("Expected no rebasing without a root, got %s", got)
+	}
+}
@@ -0,0 +1,380 @@
+// Package policy reads the organization policy that constrains which values
+// ZeroUI may write. The policy is a read-only YAML file, shipped by a platform
+// team at /etc/zeroui/policy.yaml, that marks app keys as enforced, limits them
+// to a subset of values or a numeric range, or forbids them:
+//
+//	apps:
+//	  git:
+//	    commit.gpgsign:
+//	      enforce: true
+//	      reason: Signed commits are required
+//	  tmux:
+//	    history-limit:
+//	      min: 10000
+//	  ghostty:
+//	    theme:
+//	      allow: [dark, light]
+//	    custom-shader:
+//	      forbid: true
+//	    font-family:
+//	      forbid: [Comic Sans MS]
+package policy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/mrtkrcm/ZeroUI/internal/errors"
+	"github.com/mrtkrcm/ZeroUI/internal/secrets"
+)
+
+// Rule constrains one app key. A rule may combine constraints; a value must
+// satisfy all of them.
+type Rule struct {
+	// Enforce is the only value the key may have; removing the key also
+	// violates the rule
+	Enforce interface{} `yaml:"enforce"`
+	// Allow lists the values the key may be set to
+	Allow []string `yaml:"allow"`
+	// Forbid lists values the key may not be set to
+	Forbid []string `yaml:"-"`
+	// Forbidden means the key may not be set at all ("forbid: true")
+	Forbidden bool `yaml:"-"`
+	// Min and Max bound numeric values
+	Min *float64 `yaml:"min"`
+	Max *float64 `yaml:"max"`
+	// Reason explains the rule to users
+	Reason string `yaml:"reason"`
+}
+
+// UnmarshalYAML reads "forbid" as either true (the key is forbidden) or a
+// list of forbidden values
+func (r *Rule) UnmarshalYAML(node *yaml.Node) error {
+	type plain Rule
+	var raw struct {
+		plain  `yaml:",inline"`
+		Forbid yaml.Node `yaml:"forbid"`
+	}
+	if err := node.Decode(&raw); err != nil {
+		return err
+	}
+	*r = Rule(raw.plain)
+
+	switch raw.Forbid.Kind {
+	case 0:
+	case yaml.ScalarNode:
+		if err := raw.Forbid.Decode(&r.Forbidden); err != nil {
+			var value string
+			if err := raw.Forbid.Decode(&value); err != nil {
+				return fmt.Errorf("line %d: forbid must be true or a list of values", raw.Forbid.Line)
+			}
+			r.Forbid = []string{value}
+		}
+	case yaml.SequenceNode:
+		if err := raw.Forbid.Decode(&r.Forbid); err != nil {
+			return fmt.Errorf("line %d: forbid must be true or a list of values", raw.Forbid.Line)
+		}
+	default:
+		return fmt.Errorf("line %d: forbid must be true or a list of values", raw.Forbid.Line)
+	}
+	return nil
+}
+
+// Policy is a parsed policy file
+type Policy struct {
+	// Source is the file the policy was read from, cited in violations
+	Source string
+	Apps   map[string]map[string]Rule `yaml:"apps"`
+}
+
+// Violation is a value a policy rule doesn't allow
+type Violation struct {
+	App    string
+	Key    string
+	Value  interface{} // nil when the key is not set
+	Rule   Rule
+	Source string
+	// Message states the violation and cites the policy
+	Message string
+}
+
+// Err converts the violation to a POLICY_VIOLATION error citing the policy
+func (v Violation) Err() *errors.ZeroUIError {
+	err := errors.New(errors.PolicyViolation, v.Message).
+		WithApp(v.App).
+		WithField(v.Key).
+		WithContext("policy", v.Source)
+	if v.Value != nil {
+		err = err.WithValue(fmt.Sprint(v.Value))
+	}
+
+	var suggestions []string
+	if v.Rule.Reason != "" {
+		suggestions = append(suggestions, v.Rule.Reason)
+	}
+	if v.Rule.Allow != nil {
+		suggestions = append(suggestions, fmt.Sprintf("Allowed values: %s", strings.Join(v.Rule.Allow, ", ")))
+	}
+	suggestions = append(suggestions, fmt.Sprintf("The rule is set by %s; ask its maintainer to change it", v.Source))
+	return err.WithSuggestions(suggestions...)
+}
+
+// DefaultPath returns the system policy location, /etc/zeroui/policy.yaml
+// (%ProgramData%\zeroui\policy.yaml on Windows). It can't be overridden from
+// the environment: a user could otherwise point zeroui away from the policy
+// their platform team ships. For the same reason it isn't rebased under
+// --root; the policy governs this machine whichever tree zeroui works on.
+func DefaultPath() string {
+	if runtime.GOOS == "windows" {
+		if dir := os.Getenv("ProgramData"); dir != "" {
+			return filepath.Join(dir, "zeroui", "policy.yaml")
+		}
+	}
+	return "/etc/zeroui/policy.yaml"
+}
+
+var (
+	defaultOnce   sync.Once
+	defaultPolicy *Policy
+	defaultErr    error
+)
+
+// Default loads the policy at DefaultPath once per process. A missing file is
+// an empty policy; a policy that can't be read is an error, so writes fail
+// closed rather than ignoring it.
+func Default() (*Policy, error) {
+	defaultOnce.Do(func() {
+		defaultPolicy, defaultErr = Load(DefaultPath())
+	})
+	return defaultPolicy, defaultErr
+}
+
+// Load reads a policy file. A missing file yields an empty policy.
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &Policy{Source: path}, nil
+	}
+	if err != nil {
+		return nil, policyError(path, err)
+	}
+	return Parse(path, data)
+}
+
+// Parse reads a policy from data; source names it in violations
+func Parse(source string, data []byte) (*Policy, error) {
+	p := &Policy{}
+	if err := yaml.Unmarshal(data, p); err != nil {
+		return nil, policyError(source, err)
+	}
+	p.Source = source
+	for app, rules := range p.Apps {
+		for key, rule := range rules {
+			if rule.Min != nil && rule.Max != nil && *rule.Min > *rule.Max {
+				return nil, policyError(source, fmt.Errorf("%s %s: min is greater than max", app, key))
+			}
+		}
+	}
+	return p, nil
+}
+
+func policyError(source string, err error) error {
+	return errors.Wrap(errors.ConfigParseError, fmt.Sprintf("organization policy %s cannot be read", source), err).
+		WithContext("policy", source).
+		WithSuggestions(
+			"Nothing is written while the policy is unreadable",
+			fmt.Sprintf("Fix %s or ask its maintainer to", source),
+		)
+}
+
+// Rules returns the rules for an app's keys
+func (p *Policy) Rules(app string) map[string]Rule {
+	if p == nil {
+		return nil
+	}
+	return p.Apps[app]
+}
+
+// Rule returns the rule for an app key, if any
+func (p *Policy) Rule(app, key string) (Rule, bool) {
+	rule, ok := p.Rules(app)[key]
+	return rule, ok
+}
+
+// Check returns a POLICY_VIOLATION error when the policy doesn't allow
+// setting key to value. A nil value checks removing the key.
+func (p *Policy) Check(app, key string, value interface{}) error {
+	if v := p.violation(app, key, value, value != nil); v != nil {
+		return v.Err()
+	}
+	return nil
+}
+
+// CheckChanges checks the keys whose values differ between before and after,
+// the changes a write makes. Keys the write leaves alone aren't checked, so an
+// existing violation doesn't block unrelated edits.
+func (p *Policy) CheckChanges(app string, before, after map[string]interface{}) error {
+	rules := p.Rules(app)
+	keys := make([]string, 0, len(rules))
+	for key := range rules {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		old, wasSet := before[key]
+		value, set := after[key]
+		if wasSet == set && (!set || fmt.Sprint(old) == fmt.Sprint(value)) {
+			continue
+		}
+		if v := p.violation(app, key, value, set); v != nil {
+			return v.Err()
+		}
+	}
+	return nil
+}
+
+// Violations lists every rule an app's current values break, including
+// enforced keys that aren't set
+func (p *Policy) Violations(app string, values map[string]interface{}) []Violation {
+	rules := p.Rules(app)
+	keys := make([]string, 0, len(rules))
+	for key := range rules {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var violations []Violation
+	for _, key := range keys {
+		value, set := values[key]
+		if v := p.violation(app, key, value, set); v != nil {
+			violations = append(violations, *v)
+		}
+	}
+	return violations
+}
+
+// Allowed filters choices down to the values the policy allows for a key
+func (p *Policy) Allowed(app, key string, choices []string) []string {
+	rule, ok := p.Rule(app, key)
+	if !ok {
+		return choices
+	}
+	allowed := make([]string, 0, len(choices))
+	for _, choice := range choices {
+		if rule.check(key, choice) == "" {
+			allowed = append(allowed, choice)
+		}
+	}
+	return allowed
+}
+
+// Locked reports whether the policy leaves no choice for a key: it is
+// enforced or forbidden. The message says why.
+func (p *Policy) Locked(app, key string) (string, bool) {
+	rule, ok := p.Rule(app, key)
+	switch {
+	case !ok:
+		return "", false
+	case rule.Enforce != nil:
+		return fmt.Sprintf("enforced as %v by %s", rule.Enforce, p.Source), true
+	case rule.Forbidden:
+		return fmt.Sprintf("forbidden by %s", p.Source), true
+	}
+	return "", false
+}
+
+func (p *Policy) violation(app, key string, value interface{}, set bool) *Violation {
+	rule, ok := p.Rule(app, key)
+	if !ok {
+		return nil
+	}
+
+	var message string
+	switch {
+	case !set && rule.Enforce != nil:
+		message = fmt.Sprintf("must be set to %v", rule.Enforce)
+	case !set:
+		return nil
+	default:
+		for _, entry := range entries(value) {
+			if message = rule.check(key, entry); message != "" {
+				break
+			}
+		}
+	}
+	if message == "" {
+		return nil
+	}
+
+	v := &Violation{App: app, Key: key, Rule: rule, Source: p.Source,
+		Message: fmt.Sprintf("%s %s under organization policy %s", key, message, p.Source)}
+	if set {
+		v.Value = value
+	}
+	return v
+}
+
+// check returns why a single value of key breaks the rule, or "". Secret
+// values are masked in the reason.
+func (r Rule) check(key string, value interface{}) string {
+	text := fmt.Sprint(value)
+
+	if r.Forbidden {
+		return "may not be set"
+	}
+	if r.Enforce != nil && text != fmt.Sprint(r.Enforce) {
+		return fmt.Sprintf("is enforced as %v", r.Enforce)
+	}
+	if r.Allow != nil && !contains(r.Allow, text) {
+		return fmt.Sprintf("may not be %q", secrets.RedactString(key, text))
+	}
+	if contains(r.Forbid, text) {
+		return fmt.Sprintf("may not be %q", secrets.RedactString(key, text))
+	}
+	if r.Min != nil || r.Max != nil {
+		n, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+		switch {
+		case err != nil:
+			return "must be a number"
+		case r.Min != nil && n < *r.Min:
+			return fmt.Sprintf("must be at least %v", *r.Min)
+		case r.Max != nil && n > *r.Max:
+			return fmt.Sprintf("must be at most %v", *r.Max)
+		}
+	}
+	return ""
+}
+
+// entries splits list values, such as repeated keybind lines, so each entry
+// is checked
+func entries(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		return v
+	case []string:
+		list := make([]interface{}, len(v))
+		for i, s := range v {
+			list[i] = s
+		}
+		return list
+	}
+	return []interface{}{value}
+}
+
+func contains(values []string, s string) bool {
+	for _, v := range values {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
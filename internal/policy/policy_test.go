@@ -0,0 +1,222 @@
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/mrtkrcm/ZeroUI/internal/errors"
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
+)
+
+const testPolicy = `apps:
+  git:
+    commit.gpgsign:
+      enforce: true
+      reason: Signed commits are required
+  tmux:
+    history-limit:
+      min: 10000
+  ghostty:
+    theme:
+      allow: [dark, light]
+    custom-shader:
+      forbid: true
+    font-family:
+      forbid: [Comic Sans MS]
+`
+
+func parseTestPolicy(t *testing.T) *Policy {
+	t.Helper()
+	p, err := Parse("/etc/zeroui/policy.yaml", []byte(testPolicy))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	return p
+}
+
+func TestParse(t *testing.T) {
+	p := parseTestPolicy(t)
+
+	if rule, ok := p.Rule("ghostty", "custom-shader"); !ok || !rule.Forbidden {
+		t.Errorf("Expected custom-shader to be forbidden, got %+v", rule)
+	}
+	if rule, _ := p.Rule("ghostty", "font-family"); len(rule.Forbid) != 1 || rule.Forbid[0] != "Comic Sans MS" {
+		t.Errorf("Expected a forbidden font, got %+v", rule)
+	}
+	if rule, _ := p.Rule("tmux", "history-limit"); rule.Min == nil || *rule.Min != 10000 {
+		t.Errorf("Expected a history-limit floor, got %+v", rule)
+	}
+
+	for name, data := range map[string]string{
+		"malformed":  "apps: [",
+		"bad forbid": "apps:\n  a:\n    k:\n      forbid: {x: 1}\n",
+		"bad range":  "apps:\n  a:\n    k:\n      min: 5\n      max: 1\n",
+	} {
+		_, err := Parse("policy.yaml", []byte(data))
+		if zerr, ok := errors.GetZeroUIError(err); !ok || zerr.Context["policy"] != "policy.yaml" {
+			t.Errorf("%s: expected an error citing the policy, got %v", name, err)
+		}
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+
+	p, err := Load(filepath.Join(dir, "missing.yaml"))
+	if err != nil || p.Check("git", "commit.gpgsign", false) != nil {
+		t.Errorf("Expected a missing policy to allow everything, got %v", err)
+	}
+
+	path := filepath.Join(dir, "policy.yaml")
+	if err := os.WriteFile(path, []byte(testPolicy), 0o644); err != nil {
+		t.Fatalf("Failed to write policy: %v", err)
+	}
+	p, err = Load(path)
+	if err != nil || p.Source != path {
+		t.Fatalf("Load failed: %v", err)
+	}
+}
+
+func TestDefaultPath(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("the system policy lives under %ProgramData% on Windows")
+	}
+
+	// The environment can't point zeroui away from the system policy
+	t.Setenv("ZEROUI_POLICY", filepath.Join(t.TempDir(), "policy.yaml"))
+	if got := DefaultPath(); got != "/etc/zeroui/policy.yaml" {
+		t.Errorf("Expected the system policy path, got %s", got)
+	}
+
+	// Nor does a root, which would otherwise load an empty policy from it
+	if err := paths.SetRoot(t.TempDir()); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = paths.SetRoot("") })
+	if got := DefaultPath(); got != "/etc/zeroui/policy.yaml" {
+		t.Errorf("Expected the system policy path under a root, got %s", got)
+	}
+}
+
+func TestPolicy_Check(t *testing.T) {
+	p := parseTestPolicy(t)
+
+	allowed := []struct {
+		app, key string
+		value    interface{}
+	}{
+		{"git", "commit.gpgsign", true},
+		{"git", "commit.gpgsign", "true"},
+		{"git", "user.name", "anyone"},
+		{"tmux", "history-limit", 50000},
+		{"ghostty", "theme", "light"},
+		{"ghostty", "font-family", "Iosevka"},
+		{"ghostty", "custom-shader", nil},
+		{"other", "theme", "blue"},
+	}
+	for _, tt := range allowed {
+		if err := p.Check(tt.app, tt.key, tt.value); err != nil {
+			t.Errorf("Expected %s %s=%v to be allowed, got %v", tt.app, tt.key, tt.value, err)
+		}
+	}
+
+	refused := []struct {
+		app, key string
+		value    interface{}
+		message  string
+	}{
+		{"git", "commit.gpgsign", false, "is enforced as true"},
+		{"git", "commit.gpgsign", nil, "must be set to true"},
+		{"tmux", "history-limit", "2000", "must be at least 10000"},
+		{"tmux", "history-limit", "lots", "must be a number"},
+		{"ghostty", "theme", "blue", `may not be "blue"`},
+		{"ghostty", "font-family", "Comic Sans MS", `may not be "Comic Sans MS"`},
+		{"ghostty", "custom-shader", "crt.glsl", "may not be set"},
+		{"ghostty", "font-family", []interface{}{"Iosevka", "Comic Sans MS"}, `may not be "Comic Sans MS"`},
+	}
+	for _, tt := range refused {
+		err := p.Check(tt.app, tt.key, tt.value)
+		zerr, ok := errors.GetZeroUIError(err)
+		if !ok || zerr.Type != errors.PolicyViolation {
+			t.Errorf("Expected %s %s=%v to be refused, got %v", tt.app, tt.key, tt.value, err)
+			continue
+		}
+		if !strings.Contains(zerr.Message, tt.message) || !strings.Contains(zerr.Message, "/etc/zeroui/policy.yaml") {
+			t.Errorf("Unexpected message for %s %s: %q", tt.app, tt.key, zerr.Message)
+		}
+		if zerr.App != tt.app || zerr.Field != tt.key || zerr.Context["policy"] != "/etc/zeroui/policy.yaml" {
+			t.Errorf("Expected the error to cite the app, key and policy, got %+v", zerr)
+		}
+	}
+
+	zerr, _ := errors.GetZeroUIError(p.Check("git", "commit.gpgsign", false))
+	if len(zerr.Suggestions) == 0 || zerr.Suggestions[0] != "Signed commits are required" {
+		t.Errorf("Expected the rule's reason as a suggestion, got %v", zerr.Suggestions)
+	}
+}
+
+func TestPolicy_CheckChanges(t *testing.T) {
+	p := parseTestPolicy(t)
+
+	before := map[string]interface{}{"history-limit": 2000, "status": "on"}
+
+	// An existing violation doesn't block unrelated edits
+	if err := p.CheckChanges("tmux", before, map[string]interface{}{"history-limit": 2000, "status": "off"}); err != nil {
+		t.Errorf("Expected an unrelated change to be allowed, got %v", err)
+	}
+	if err := p.CheckChanges("tmux", before, map[string]interface{}{"history-limit": 5000, "status": "on"}); err == nil {
+		t.Error("Expected a change below the floor to be refused")
+	}
+	if err := p.CheckChanges("git", map[string]interface{}{"commit.gpgsign": true}, map[string]interface{}{}); err == nil {
+		t.Error("Expected removing an enforced key to be refused")
+	}
+}
+
+func TestPolicy_Violations(t *testing.T) {
+	p := parseTestPolicy(t)
+
+	violations := p.Violations("ghostty", map[string]interface{}{
+		"theme":         "blue",
+		"custom-shader": "crt.glsl",
+		"font-family":   "Iosevka",
+	})
+	if len(violations) != 2 || violations[0].Key != "custom-shader" || violations[1].Key != "theme" {
+		t.Fatalf("Expected custom-shader and theme violations, got %+v", violations)
+	}
+	if violations[1].Value != "blue" {
+		t.Errorf("Expected the violating value, got %v", violations[1].Value)
+	}
+
+	if got := p.Violations("git", map[string]interface{}{}); len(got) != 1 || got[0].Value != nil {
+		t.Errorf("Expected an unset enforced key to be reported, got %+v", got)
+	}
+}
+
+func TestPolicy_AllowedAndLocked(t *testing.T) {
+	p := parseTestPolicy(t)
+
+	if got := p.Allowed("ghostty", "theme", []string{"dark", "blue", "light"}); strings.Join(got, ",") != "dark,light" {
+		t.Errorf("Expected dark and light, got %v", got)
+	}
+	if got := p.Allowed("ghostty", "cursor", []string{"block", "bar"}); len(got) != 2 {
+		t.Errorf("Expected unconstrained choices to be kept, got %v", got)
+	}
+
+	if msg, ok := p.Locked("git", "commit.gpgsign"); !ok || !strings.Contains(msg, "enforced as true") {
+		t.Errorf("Expected commit.gpgsign to be locked, got %q", msg)
+	}
+	if msg, ok := p.Locked("ghostty", "custom-shader"); !ok || !strings.Contains(msg, "forbidden") {
+		t.Errorf("Expected custom-shader to be locked, got %q", msg)
+	}
+	if _, ok := p.Locked("ghostty", "theme"); ok {
+		t.Error("Expected a restricted key to stay editable")
+	}
+
+	var empty *Policy
+	if err := empty.Check("git", "commit.gpgsign", false); err != nil {
+		t.Errorf("Expected a nil policy to allow everything, got %v", err)
+	}
+}
@@ -0,0 +1,284 @@
+// Package provenance explains where an application setting's effective value
+// comes from: the main config file, files it includes, a theme, an
+// environment override or the reference default.
+package provenance
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/mrtkrcm/ZeroUI/internal/appconfig"
+	"github.com/mrtkrcm/ZeroUI/pkg/reference"
+)
+
+// LayerKind names a source of setting values
+type LayerKind string
+
+// Layer kinds, in increasing precedence
+const (
+	LayerDefault LayerKind = "default"
+	LayerTheme   LayerKind = "theme"
+	LayerFile    LayerKind = "file"
+	LayerInclude LayerKind = "include"
+	LayerEnv     LayerKind = "env"
+)
+
+// Source is one place that sets a value for a setting
+type Source struct {
+	Kind  LayerKind
+	File  string // Config file, empty for defaults and env overrides
+	Line  int    // 1-based line in File
+	Env   string // Environment variable, for env overrides
+	Value interface{}
+}
+
+// Location describes where the value was set
+func (s Source) Location() string {
+	switch s.Kind {
+	case LayerDefault:
+		return "reference default"
+	case LayerEnv:
+		return "$" + s.Env
+	}
+	if s.Line > 0 {
+		return fmt.Sprintf("%s:%d", s.File, s.Line)
+	}
+	return s.File
+}
+
+// Explanation describes how a setting's effective value was determined
+type Explanation struct {
+	Key string
+	// Effective is the source whose value applies, nil when nothing sets the
+	// key and it has no known default
+	Effective *Source
+	// Overridden lists lower-precedence sources, highest precedence first
+	Overridden []Source
+}
+
+// Resolution holds every layer of an app's configuration
+type Resolution struct {
+	App string
+	// Warnings reports includes and themes that could not be followed
+	Warnings []string
+
+	// Config files sit between the theme and environment layers
+	below []layer // lowest precedence first
+	graph *appconfig.IncludeGraph
+	above []layer
+}
+
+type layer struct {
+	kind    LayerKind
+	file    string
+	values  map[string]interface{}
+	sources appconfig.SourceMap
+	env     map[string]string // key -> variable, for the env layer
+}
+
+// GraphLoader loads an app's main config file and the files it includes
+type GraphLoader interface {
+	LoadIncludeGraph(appConfig *appconfig.AppConfig) (*appconfig.IncludeGraph, error)
+}
+
+// Resolver builds the layered view of an app's configuration
+type Resolver struct {
+	loader GraphLoader
+}
+
+// NewResolver creates a resolver that reads config files through loader
+func NewResolver(loader GraphLoader) *Resolver {
+	return &Resolver{loader: loader}
+}
+
+// Resolve loads every layer of an app's configuration. ref supplies defaults
+// for settings the app definition doesn't list and may be nil.
+func (r *Resolver) Resolve(appConfig *appconfig.AppConfig, ref *reference.ConfigReference) (*Resolution, error) {
+	graph, err := r.loader.LoadIncludeGraph(appConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &Resolution{App: appConfig.Name, graph: graph}
+	res.Warnings = append(res.Warnings, graph.Warnings...)
+	res.below = append(res.below, defaultLayer(appConfig, ref))
+
+	if appConfig.Name == "ghostty" {
+		if theme, ok := res.loadGhosttyTheme(graph); ok {
+			res.below = append(res.below, theme)
+		}
+	}
+
+	if env := envLayer(appConfig); env != nil {
+		res.above = append(res.above, *env)
+	}
+
+	return res, nil
+}
+
+// Explain reports the sources of a setting, highest precedence first
+func (r *Resolution) Explain(key string) *Explanation {
+	exp := &Explanation{Key: key}
+	add := func(src Source) {
+		if exp.Effective == nil {
+			exp.Effective = &src
+		} else {
+			exp.Overridden = append(exp.Overridden, src)
+		}
+	}
+
+	explainLayers(r.above, key, add)
+	for _, occurrence := range r.graph.Occurrences(key) {
+		kind := LayerInclude
+		if occurrence.File == r.graph.Root {
+			kind = LayerFile
+		}
+		add(Source{Kind: kind, File: occurrence.File.Path, Line: occurrence.Line, Value: occurrence.Value})
+	}
+	explainLayers(r.below, key, add)
+
+	return exp
+}
+
+// explainLayers reports the sources of key in layers, highest precedence first
+func explainLayers(layers []layer, key string, add func(Source)) {
+	for i := len(layers) - 1; i >= 0; i-- {
+		l := layers[i]
+		value, ok := l.values[key]
+		if !ok {
+			continue
+		}
+
+		src := Source{Kind: l.kind, File: l.file, Env: l.env[key], Value: value}
+		if pos, ok := l.sources.Last(key); ok {
+			src.Line = pos.Line
+		}
+		add(src)
+	}
+}
+
+// Keys returns every key set by a config file, theme or environment
+// override, sorted
+func (r *Resolution) Keys() []string {
+	seen := make(map[string]bool)
+	var keys []string
+	addKey := func(key string) {
+		if !seen[key] {
+			seen[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	for _, l := range append(append([]layer(nil), r.below...), r.above...) {
+		if l.kind == LayerDefault {
+			continue
+		}
+		for key := range l.values {
+			addKey(key)
+		}
+	}
+	for _, key := range r.graph.Keys() {
+		addKey(key)
+	}
+
+	sort.Strings(keys)
+	return keys
+}
+
+// Files returns the config files that were read: the theme, then the include
+// graph in discovery order
+func (r *Resolution) Files() []string {
+	var files []string
+	for _, l := range r.below {
+		if l.file != "" {
+			files = append(files, l.file)
+		}
+	}
+	for _, f := range r.graph.Files {
+		files = append(files, f.Path)
+	}
+	return files
+}
+
+func defaultLayer(appConfig *appconfig.AppConfig, ref *reference.ConfigReference) layer {
+	values := make(map[string]interface{})
+	if ref != nil {
+		for key, setting := range ref.Settings {
+			if hasDefault(setting.DefaultValue) {
+				values[key] = setting.DefaultValue
+			}
+		}
+	}
+	for key, field := range appConfig.Fields {
+		if hasDefault(field.Default) {
+			values[key] = field.Default
+		}
+	}
+	return layer{kind: LayerDefault, values: values}
+}
+
+// hasDefault treats an empty default as unknown, as generated reference data
+// uses it for settings whose default could not be extracted
+func hasDefault(value interface{}) bool {
+	return value != nil && fmt.Sprintf("%v", value) != ""
+}
+
+func envLayer(appConfig *appconfig.AppConfig) *layer {
+	env := layer{kind: LayerEnv, values: make(map[string]interface{}), env: make(map[string]string)}
+	for key, field := range appConfig.Fields {
+		if field.EnvOverride == "" {
+			continue
+		}
+		if value, ok := os.LookupEnv(field.EnvOverride); ok {
+			env.values[key] = value
+			env.env[key] = field.EnvOverride
+		}
+	}
+	if len(env.values) == 0 {
+		return nil
+	}
+	return &env
+}
+
+// loadGhosttyTheme loads the theme named by the config files. Themes are
+// looked up in the themes directory next to the main config, then in
+// $GHOSTTY_RESOURCES_DIR/themes. Config files override theme values.
+func (r *Resolution) loadGhosttyTheme(graph *appconfig.IncludeGraph) (layer, bool) {
+	theme := graph.Merged().Get("theme")
+	if theme == nil {
+		return layer{}, false
+	}
+	mainPath := graph.Root.Path
+
+	name, ok := theme.(string)
+	if !ok || strings.Contains(name, ":") {
+		r.Warnings = append(r.Warnings, fmt.Sprintf("theme %v depends on the system appearance and was not resolved", theme))
+		return layer{}, false
+	}
+
+	candidates := []string{name}
+	if !filepath.IsAbs(name) {
+		candidates = []string{filepath.Join(filepath.Dir(mainPath), "themes", name)}
+		if dir := os.Getenv("GHOSTTY_RESOURCES_DIR"); dir != "" {
+			candidates = append(candidates, filepath.Join(dir, "themes", name))
+		}
+	}
+
+	for _, path := range candidates {
+		if _, err := os.Stat(path); err != nil {
+			continue
+		}
+		k, sources, err := appconfig.ParseGhosttyConfigWithSources(path)
+		if err != nil {
+			r.Warnings = append(r.Warnings, fmt.Sprintf("cannot load theme %s: %v", path, err))
+			return layer{}, false
+		}
+		return layer{kind: LayerTheme, file: path, values: k.All(), sources: sources}, true
+	}
+
+	r.Warnings = append(r.Warnings, fmt.Sprintf("theme %q not found in %s", name, strings.Join(candidates, ", ")))
+	return layer{}, false
+}
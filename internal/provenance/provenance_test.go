@@ -0,0 +1,88 @@
+package provenance
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mrtkrcm/ZeroUI/internal/appconfig"
+	"github.com/mrtkrcm/ZeroUI/pkg/reference"
+)
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestResolveGhosttyLayers(t *testing.T) {
+	dir := t.TempDir()
+	mainPath := filepath.Join(dir, "config")
+	writeFile(t, mainPath, `# main
+theme = mytheme
+font-size = 13
+background = #000000
+config-file = extra.conf
+config-file = ?missing.conf
+`)
+	writeFile(t, filepath.Join(dir, "extra.conf"), "\nfont-size = 15\nconfig-file = config\n")
+	writeFile(t, filepath.Join(dir, "themes", "mytheme"), "background = #282a36\nforeground = #f8f8f2\n")
+
+	t.Setenv("TEST_GHOSTTY_SHELL", "/bin/fish")
+	appConfig := &appconfig.AppConfig{
+		Name:   "ghostty",
+		Path:   mainPath,
+		Format: "custom",
+		Fields: map[string]appconfig.FieldConfig{
+			"font-size": {Type: "number", Default: 12},
+			"command":   {Type: "string", EnvOverride: "TEST_GHOSTTY_SHELL"},
+		},
+	}
+	ref := &reference.ConfigReference{Settings: map[string]reference.ConfigSetting{
+		"cursor-style": {Name: "cursor-style", DefaultValue: "block"},
+	}}
+
+	res, err := NewResolver(&appconfig.Loader{}).Resolve(appConfig, ref)
+	if err != nil {
+		t.Fatalf("Resolve failed: %v", err)
+	}
+
+	exp := res.Explain("font-size")
+	if exp.Effective == nil || exp.Effective.Kind != LayerInclude || exp.Effective.Value != "15" || exp.Effective.Line != 2 {
+		t.Fatalf("Expected font-size from extra.conf:2, got %+v", exp.Effective)
+	}
+	if len(exp.Overridden) != 2 || exp.Overridden[0].Kind != LayerFile || exp.Overridden[0].Line != 3 || exp.Overridden[1].Kind != LayerDefault {
+		t.Errorf("Expected main file then default to be overridden, got %+v", exp.Overridden)
+	}
+
+	exp = res.Explain("background")
+	if exp.Effective.Kind != LayerFile || len(exp.Overridden) != 1 || exp.Overridden[0].Kind != LayerTheme {
+		t.Errorf("Expected main file to override the theme, got %+v / %+v", exp.Effective, exp.Overridden)
+	}
+	if exp := res.Explain("foreground"); exp.Effective == nil || exp.Effective.Kind != LayerTheme {
+		t.Errorf("Expected foreground from the theme, got %+v", exp.Effective)
+	}
+
+	if exp := res.Explain("command"); exp.Effective == nil || exp.Effective.Location() != "$TEST_GHOSTTY_SHELL" {
+		t.Errorf("Expected command from the environment, got %+v", exp.Effective)
+	}
+	if exp := res.Explain("cursor-style"); exp.Effective == nil || exp.Effective.Location() != "reference default" {
+		t.Errorf("Expected cursor-style from the reference default, got %+v", exp.Effective)
+	}
+	if exp := res.Explain("unknown"); exp.Effective != nil {
+		t.Errorf("Expected no source for an unknown key, got %+v", exp.Effective)
+	}
+
+	// The optional missing include is silent; the include of the main file is a cycle
+	if len(res.Warnings) != 1 || !strings.Contains(res.Warnings[0], "cycle") {
+		t.Errorf("Expected a single include cycle warning, got %v", res.Warnings)
+	}
+	if got := strings.Join(res.Keys(), ","); got != "background,command,config-file,font-size,foreground,theme" {
+		t.Errorf("Unexpected keys: %s", got)
+	}
+}
@@ -0,0 +1,150 @@
+package recovery
+
+import (
+	stderrors "errors"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+
+	"github.com/mrtkrcm/ZeroUI/internal/crypt"
+	"github.com/mrtkrcm/ZeroUI/internal/errors"
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
+)
+
+// Encryption says how new backups are encrypted. With neither a passphrase
+// nor a key file, backups are plain copies.
+type Encryption struct {
+	// Passphrase encrypts with a key derived from it by scrypt
+	Passphrase string
+	// KeyFile holds X25519 keys: backups are encrypted to its public keys and
+	// decrypted with its private keys
+	KeyFile string
+}
+
+// Enabled reports whether new backups are encrypted
+func (e Encryption) Enabled() bool {
+	return e.Passphrase != "" || e.KeyFile != ""
+}
+
+var encryption atomic.Value
+
+func init() {
+	encryption.Store(Encryption{})
+}
+
+// SetEncryption changes how new backups are encrypted and which keys restore
+// encrypted ones
+func SetEncryption(enc Encryption) {
+	encryption.Store(enc)
+}
+
+// CurrentEncryption returns how new backups are encrypted
+func CurrentEncryption() Encryption {
+	return encryption.Load().(Encryption)
+}
+
+// keyFile reads the configured key file
+func (e Encryption) keyFile() (*crypt.KeyFile, error) {
+	// The key belongs to this machine, not to the configs under a root
+	data, err := os.ReadFile(paths.ExpandHome(e.KeyFile))
+	if err != nil {
+		return nil, errors.Wrap(errors.SystemFileError, "failed to read backup key file", err).
+			WithContext("key_file", e.KeyFile).
+			WithSuggestions("Create one with: zeroui backup keygen -o " + e.KeyFile)
+	}
+	kf, err := crypt.ParseKeyFile(data)
+	if err != nil {
+		return nil, errors.Wrap(errors.ConfigParseError, "invalid backup key file", err).
+			WithContext("key_file", e.KeyFile)
+	}
+	return kf, nil
+}
+
+// recipients returns who new backups are encrypted to
+func (e Encryption) recipients() ([]crypt.Recipient, error) {
+	var recipients []crypt.Recipient
+	if e.KeyFile != "" {
+		kf, err := e.keyFile()
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, kf.AllRecipients()...)
+	}
+	if e.Passphrase != "" {
+		recipients = append(recipients, crypt.NewPassphrase(e.Passphrase))
+	}
+	return recipients, nil
+}
+
+// identities returns what encrypted backups can be decrypted with
+func (e Encryption) identities() ([]crypt.Identity, error) {
+	var identities []crypt.Identity
+	if e.KeyFile != "" {
+		kf, err := e.keyFile()
+		if err != nil {
+			return nil, err
+		}
+		identities = append(identities, kf.AllIdentities()...)
+	}
+	if e.Passphrase != "" {
+		identities = append(identities, crypt.NewPassphrase(e.Passphrase))
+	}
+	return identities, nil
+}
+
+// encryptBackup encrypts a backup's content when encryption is enabled and
+// returns the recipients it was encrypted to, none for a plain backup
+func encryptBackup(data []byte) ([]byte, []string, error) {
+	enc := CurrentEncryption()
+	if !enc.Enabled() {
+		return data, nil, nil
+	}
+
+	recipients, err := enc.recipients()
+	if err != nil {
+		return nil, nil, err
+	}
+	encrypted, err := crypt.Encrypt(data, recipients...)
+	if err != nil {
+		return nil, nil, errors.Wrap(errors.SystemFileError, "failed to encrypt backup", err)
+	}
+
+	names := make([]string, len(recipients))
+	for i, r := range recipients {
+		names[i] = r.String()
+	}
+	return encrypted, names, nil
+}
+
+// decryptBackup returns an encrypted backup's content; plain backups are
+// returned as they are
+func decryptBackup(data []byte, backupPath string) ([]byte, error) {
+	if !crypt.IsEncrypted(data) {
+		return data, nil
+	}
+
+	identities, err := CurrentEncryption().identities()
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := crypt.Decrypt(data, identities...)
+	if err == nil {
+		return plaintext, nil
+	}
+
+	zerr := errors.Wrap(errors.BackupDecrypt, "failed to decrypt backup", err).
+		WithContext("backup", filepath.Base(backupPath))
+	switch {
+	case stderrors.Is(err, crypt.ErrNoIdentity):
+		zerr = zerr.WithSuggestions(
+			"Set ZEROUI_BACKUP_PASSPHRASE to the passphrase the backup was made with",
+			"Or pass --backup-key with a key file holding its private key",
+		)
+	case stderrors.Is(err, crypt.ErrIncorrectIdentity):
+		zerr = zerr.WithSuggestions(
+			"Check the passphrase or key file",
+			"A key file with only a public key can encrypt backups but not restore them",
+		)
+	}
+	return nil, zerr
+}
@@ -0,0 +1,109 @@
+package recovery
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"time"
+
+	"github.com/mrtkrcm/ZeroUI/internal/crypt"
+	"github.com/mrtkrcm/ZeroUI/internal/errors"
+)
+
+// manifestSuffix is appended to a backup's path for its manifest
+const manifestSuffix = ".manifest"
+
+// Manifest records what a backup holds. It sits next to the backup as
+// <backup>.manifest; backups made before manifests existed have none.
+type Manifest struct {
+	Version int       `json:"version"`
+	App     string    `json:"app"`
+	Source  string    `json:"source"`
+	Created time.Time `json:"created"`
+	// Size is the size of the config that was backed up
+	Size int64 `json:"size"`
+	// SHA256 is the checksum of the backup file as stored
+	SHA256    string `json:"sha256"`
+	Encrypted bool   `json:"encrypted"`
+	// Recipients describes who can decrypt the backup: "passphrase" or
+	// public keys
+	Recipients      []string `json:"recipients,omitempty"`
+	SecretsExcluded bool     `json:"secrets_excluded,omitempty"`
+}
+
+func manifestPath(backupPath string) string {
+	return backupPath + manifestSuffix
+}
+
+func checksum(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func writeManifest(backupPath string, m *Manifest) error {
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(manifestPath(backupPath), append(data, '\n'), 0o600)
+}
+
+// ReadManifest returns a backup's manifest, or nil when it has none
+func ReadManifest(backupPath string) (*Manifest, error) {
+	data, err := os.ReadFile(manifestPath(backupPath))
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, errors.Wrap(errors.SystemFileError, "failed to read backup manifest", err)
+	}
+	var m Manifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		return nil, errors.Wrap(errors.ConfigParseError, "invalid backup manifest", err).
+			WithContext("manifest", manifestPath(backupPath))
+	}
+	return &m, nil
+}
+
+// verifyManifest checks a backup against the checksum in its manifest
+func verifyManifest(backupPath string, data []byte) error {
+	m, err := ReadManifest(backupPath)
+	if err != nil || m == nil {
+		return err
+	}
+	if m.SHA256 != "" && m.SHA256 != checksum(data) {
+		return errors.New(errors.SystemFileError, "backup does not match the checksum in its manifest").
+			WithContext("manifest", manifestPath(backupPath)).
+			WithSuggestions("The backup was modified or corrupted; restore an earlier one")
+	}
+	return nil
+}
+
+// removeBackup deletes a backup and its manifest
+func removeBackup(backupPath string) error {
+	if err := os.Remove(backupPath); err != nil {
+		return err
+	}
+	if err := os.Remove(manifestPath(backupPath)); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+// isEncrypted reports whether a backup is encrypted, from its manifest or,
+// for backups without one, from the file's header
+func isEncrypted(backupPath string) bool {
+	if m, err := ReadManifest(backupPath); err == nil && m != nil {
+		return m.Encrypted
+	}
+	f, err := os.Open(backupPath)
+	if err != nil {
+		return false
+	}
+	defer f.Close()
+	header := make([]byte, len(crypt.Magic)+1)
+	n, _ := io.ReadFull(f, header)
+	return crypt.IsEncrypted(header[:n])
+}
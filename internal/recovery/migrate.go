@@ -0,0 +1,95 @@
+package recovery
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// migrateLegacyBackups moves backups and their manifests from the directory
+// used before backups moved to the state directory, then removes it. Files
+// that can't be moved, or whose name is already taken, stay where they are
+// and are tried again by the next run.
+func migrateLegacyBackups(legacyDir, backupDir string) (int, error) {
+	absLegacy, _ := filepath.Abs(legacyDir)
+	absBackup, _ := filepath.Abs(backupDir)
+	if absLegacy == absBackup {
+		return 0, nil
+	}
+
+	entries, err := os.ReadDir(legacyDir)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	moved, left := 0, 0
+	var firstErr error
+	for _, entry := range entries {
+		if entry.IsDir() {
+			left++
+			continue
+		}
+		src := filepath.Join(legacyDir, entry.Name())
+		dst := filepath.Join(backupDir, entry.Name())
+		if _, err := os.Lstat(dst); err == nil {
+			left++
+			continue
+		}
+		if err := moveFile(src, dst); err != nil {
+			left++
+			if firstErr == nil {
+				firstErr = err
+			}
+			continue
+		}
+		moved++
+	}
+
+	if left == 0 {
+		// Remove the old directory and its configtoggle parent once empty
+		if err := os.Remove(legacyDir); err == nil {
+			_ = os.Remove(filepath.Dir(legacyDir))
+		}
+	} else if firstErr == nil {
+		firstErr = fmt.Errorf("%d file(s) left in %s because their names are taken in %s", left, legacyDir, backupDir)
+	}
+	return moved, firstErr
+}
+
+// moveFile renames src to dst, copying across filesystems
+func moveFile(src, dst string) error {
+	if err := os.Rename(src, dst); err == nil {
+		return nil
+	}
+
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(dst)
+		return err
+	}
+	if err := out.Close(); err != nil {
+		os.Remove(dst)
+		return err
+	}
+	// Keep backup times, which order backups in listings and cleanups
+	_ = os.Chtimes(dst, info.ModTime(), info.ModTime())
+	return os.Remove(src)
+}
@@ -8,7 +8,11 @@ import (
 	"strings"
 	"time"
 
+	"github.com/mrtkrcm/ZeroUI/internal/audit"
 	"github.com/mrtkrcm/ZeroUI/internal/errors"
+	"github.com/mrtkrcm/ZeroUI/internal/filelock"
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
+	"github.com/mrtkrcm/ZeroUI/internal/safefile"
 	"github.com/mrtkrcm/ZeroUI/internal/security"
 )
 
@@ -18,19 +22,25 @@ type BackupManager struct {
 	pathValidator *security.PathValidator
 }
 
-// NewBackupManager creates a new backup manager
+// NewBackupManager creates a new backup manager for the backups in the state
+// directory, moving any from the old ~/.config/configtoggle/backups there
 func NewBackupManager() (*BackupManager, error) {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return nil, errors.Wrap(errors.SystemFileError, "failed to get home directory", err)
-	}
-
-	backupDir := filepath.Join(home, ".config", "configtoggle", "backups")
-	if err := os.MkdirAll(backupDir, 0o755); err != nil {
+	backupDir := paths.BackupDir()
+	if err := os.MkdirAll(backupDir, 0o700); err != nil {
 		return nil, errors.Wrap(errors.SystemPermission, "failed to create backup directory", err).
+			WithContext("backup_dir", backupDir).
 			WithSuggestions("Check directory permissions")
 	}
 
+	legacyDir := paths.LegacyBackupDir()
+	moved, err := migrateLegacyBackups(legacyDir, backupDir)
+	if moved > 0 {
+		fmt.Fprintf(os.Stderr, "Moved %d backup file(s) from %s to %s\n", moved, legacyDir, backupDir)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to move old backups: %v\n", err)
+	}
+
 	// Create path validator with backup directory as the only allowed path
 	pathValidator := security.NewPathValidator(backupDir)
 
@@ -42,7 +52,8 @@ func NewBackupManager() (*BackupManager, error) {
 
 // CreateBackup creates a backup of a configuration file
 func (bm *BackupManager) CreateBackup(configPath, appName string) (string, error) {
-	if _, err := os.Stat(configPath); os.IsNotExist(err) {
+	info, err := os.Stat(configPath)
+	if os.IsNotExist(err) {
 		// File doesn't exist, no backup needed
 		return "", nil
 	}
@@ -51,17 +62,53 @@ func (bm *BackupManager) CreateBackup(configPath, appName string) (string, error
 	backupName := fmt.Sprintf("%s_%s.backup", appName, timestamp)
 	backupPath := filepath.Join(bm.backupDir, backupName)
 
+	// Several changes within one second must not overwrite each other's backup
+	for n := 1; ; n++ {
+		if _, err := os.Stat(backupPath); os.IsNotExist(err) {
+			break
+		}
+		backupPath = filepath.Join(bm.backupDir, fmt.Sprintf("%s_%s_%d.backup", appName, timestamp, n))
+	}
+
 	data, err := os.ReadFile(configPath)
 	if err != nil {
 		return "", errors.Wrap(errors.SystemFileError, "failed to read config for backup", err).
 			WithSuggestions("Check file permissions")
 	}
 
-	if err := os.WriteFile(backupPath, data, 0o644); err != nil {
+	content, recipients, err := encryptBackup(backupContent(data))
+	if err != nil {
+		return "", err
+	}
+
+	// Backups are as private as the config they copy; encrypted ones are
+	// private to the user
+	perm := info.Mode().Perm()
+	if recipients != nil {
+		perm = 0o600
+	}
+	if err := os.WriteFile(backupPath, content, perm); err != nil {
 		return "", errors.Wrap(errors.SystemFileError, "failed to write backup", err).
 			WithSuggestions("Check disk space and permissions")
 	}
 
+	manifest := &Manifest{
+		Version:         1,
+		App:             appName,
+		Source:          configPath,
+		Created:         time.Now(),
+		Size:            int64(len(data)),
+		SHA256:          checksum(content),
+		Encrypted:       recipients != nil,
+		Recipients:      recipients,
+		SecretsExcluded: CurrentSecretMode() == SecretsExclude,
+	}
+	if err := writeManifest(backupPath, manifest); err != nil {
+		// The backup itself is usable without its manifest
+		fmt.Printf("Warning: failed to write manifest for backup %s: %v\n", backupName, err)
+	}
+	audit.NoteBackup(configPath, backupPath)
+
 	return backupPath, nil
 }
 
@@ -91,13 +138,26 @@ func (bm *BackupManager) RestoreBackup(backupPath, targetPath string) error {
 
 	if _, err := os.Stat(backupPath); os.IsNotExist(err) {
 		return errors.New(errors.ConfigNotFound, "backup file not found").
-			WithSuggestions("Check if backup exists", "List backups with: configtoggle list backups")
+			WithSuggestions("Check if backup exists", "List backups with: zeroui backup list")
 	}
 
 	data, err := os.ReadFile(backupPath)
 	if err != nil {
 		return errors.Wrap(errors.SystemFileError, "failed to read backup", err)
 	}
+	if err := verifyManifest(backupPath, data); err != nil {
+		return err
+	}
+	if data, err = decryptBackup(data, backupPath); err != nil {
+		return err
+	}
+
+	// Don't restore underneath another process's write
+	lock, err := filelock.Acquire(targetPath, filelock.Options{})
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
 
 	// Ensure target directory exists
 	targetDir := filepath.Dir(targetPath)
@@ -105,14 +165,47 @@ func (bm *BackupManager) RestoreBackup(backupPath, targetPath string) error {
 		return errors.Wrap(errors.SystemPermission, "failed to create target directory", err)
 	}
 
-	if err := os.WriteFile(targetPath, data, 0o644); err != nil {
+	var before string
+	if current, err := os.ReadFile(targetPath); err == nil {
+		before = checksum(current)
+	}
+	restored := restoreContent(data, targetPath)
+	if err := safefile.WriteFile(targetPath, restored, 0o644); err != nil {
 		return errors.Wrap(errors.SystemFileError, "failed to restore backup", err).
 			WithSuggestions("Check target directory permissions")
 	}
 
+	entry := audit.Entry{
+		App:      backupApp(backupPath),
+		File:     targetPath,
+		Before:   before,
+		After:    checksum(restored),
+		Restored: filepath.Base(backupPath),
+	}
+	// A rollback restores the backup taken for the change it undoes
+	if backup := audit.TakeBackup(targetPath); backup != entry.Restored {
+		entry.Backup = backup
+	}
+	if err := audit.Record(entry); err != nil {
+		fmt.Fprintf(os.Stderr, "Warning: failed to record restore of %s in the audit log: %v\n", targetPath, err)
+	}
+
 	return nil
 }
 
+// backupApp returns the app a backup was taken for, from its manifest or
+// else from its name
+func backupApp(backupPath string) string {
+	if m, err := ReadManifest(backupPath); err == nil && m != nil {
+		return m.App
+	}
+	name := filepath.Base(backupPath)
+	if i := strings.Index(name, "_"); i > 0 {
+		return name[:i]
+	}
+	return ""
+}
+
 // ListBackups returns available backups for an app
 func (bm *BackupManager) ListBackups(appName string) ([]BackupInfo, error) {
 	entries, err := os.ReadDir(bm.backupDir)
@@ -130,9 +223,9 @@ func (bm *BackupManager) ListBackups(appName string) ([]BackupInfo, error) {
 	for _, entry := range entries {
 		if !entry.IsDir() {
 			name := entry.Name()
-			if appName == "" || (len(name) > len(prefix)+len(suffix) &&
-				name[:len(prefix)] == prefix &&
-				name[len(name)-len(suffix):] == suffix) {
+			// Manifests and other files next to the backups aren't backups
+			if strings.HasSuffix(name, suffix) && (appName == "" || (len(name) > len(prefix)+len(suffix) &&
+				name[:len(prefix)] == prefix)) {
 
 				info, err := entry.Info()
 				if err != nil {
@@ -141,11 +234,12 @@ func (bm *BackupManager) ListBackups(appName string) ([]BackupInfo, error) {
 
 				backupPath := filepath.Join(bm.backupDir, name)
 				backups = append(backups, BackupInfo{
-					Name:    name,
-					Path:    backupPath,
-					App:     appName,
-					Created: info.ModTime(),
-					Size:    info.Size(),
+					Name:      name,
+					Path:      backupPath,
+					App:       appName,
+					Created:   info.ModTime(),
+					Size:      info.Size(),
+					Encrypted: isEncrypted(backupPath),
 				})
 			}
 		}
@@ -170,7 +264,7 @@ func (bm *BackupManager) CleanupOldBackups(appName string, keepCount int) error
 	// Sort by creation time (newest first)
 	// Remove the oldest ones
 	for i := keepCount; i < len(backups); i++ {
-		if err := os.Remove(backups[i].Path); err != nil {
+		if err := removeBackup(backups[i].Path); err != nil {
 			// Log but don't fail on individual file removal errors
 			fmt.Printf("Warning: failed to remove old backup %s: %v\n", backups[i].Name, err)
 		}
@@ -186,6 +280,8 @@ type BackupInfo struct {
 	App     string
 	Created time.Time
 	Size    int64
+	// Encrypted is true for backups that need a passphrase or key to restore
+	Encrypted bool
 }
 
 // SafeOperation provides a safe way to perform config operations with automatic backup/restore
@@ -207,6 +303,9 @@ func NewSafeOperation(targetPath, appName string) (*SafeOperation, error) {
 	if err != nil {
 		return nil, err
 	}
+	// Commit removes the backup, so the audit log mustn't refer to it
+	// unless the caller keeps it
+	audit.ForgetBackup(targetPath)
 
 	return &SafeOperation{
 		backupManager: backupManager,
@@ -216,6 +315,20 @@ func NewSafeOperation(targetPath, appName string) (*SafeOperation, error) {
 	}, nil
 }
 
+// BackupPath returns the path of the backup taken before the operation, or ""
+// when the target did not exist yet
+func (so *SafeOperation) BackupPath() string {
+	return so.backupPath
+}
+
+// Keep marks the backup as one that outlives the operation, so the change
+// recorded in the audit log refers to it. Don't call Commit afterwards.
+func (so *SafeOperation) Keep() {
+	if so.backupPath != "" {
+		audit.NoteBackup(so.targetPath, so.backupPath)
+	}
+}
+
 // Rollback restores the configuration from backup
 func (so *SafeOperation) Rollback() error {
 	if so.backupPath == "" {
@@ -231,7 +344,7 @@ func (so *SafeOperation) Commit() error {
 		return nil // No backup to remove
 	}
 
-	if err := os.Remove(so.backupPath); err != nil {
+	if err := removeBackup(so.backupPath); err != nil {
 		// Log but don't fail - backup can stay
 		fmt.Printf("Warning: failed to remove backup %s: %v\n", so.backupPath, err)
 	}
@@ -248,7 +361,7 @@ func (so *SafeOperation) Cleanup(keepCount int) error {
 func (bm *BackupManager) HealthCheck() error {
 	// Check if backup directory exists and is writable
 	if _, err := os.Stat(bm.backupDir); os.IsNotExist(err) {
-		if err := os.MkdirAll(bm.backupDir, 0o755); err != nil {
+		if err := os.MkdirAll(bm.backupDir, 0o700); err != nil {
 			return errors.Wrap(errors.SystemPermission, "backup directory not accessible", err).
 				WithSuggestions("Check directory permissions")
 		}
@@ -278,11 +391,17 @@ func (bm *BackupManager) GetStats() map[string]interface{} {
 	}
 
 	stats["backup_directory"] = bm.backupDir
-	stats["total_backups"] = len(entries)
 
-	// Calculate total backup size
+	// Count backups; the total size includes their manifests
 	var totalSize int64
+	var total, encrypted int
 	for _, entry := range entries {
+		if strings.HasSuffix(entry.Name(), ".backup") {
+			total++
+			if isEncrypted(filepath.Join(bm.backupDir, entry.Name())) {
+				encrypted++
+			}
+		}
 		if !entry.IsDir() {
 			info, err := entry.Info()
 			if err == nil {
@@ -291,6 +410,8 @@ func (bm *BackupManager) GetStats() map[string]interface{} {
 		}
 	}
 
+	stats["total_backups"] = total
+	stats["encrypted_backups"] = encrypted
 	stats["total_size_bytes"] = totalSize
 	return stats
 }
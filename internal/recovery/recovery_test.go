@@ -3,10 +3,19 @@ package recovery
 import (
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
+
+	"github.com/mrtkrcm/ZeroUI/internal/crypt"
+	"github.com/mrtkrcm/ZeroUI/internal/errors"
+	"github.com/mrtkrcm/ZeroUI/test/helpers"
 )
 
+func TestMain(m *testing.M) {
+	helpers.RunTestMainWithCleanup(m, "internal/recovery", "zeroui-internal-recovery-test-home-", nil)
+}
+
 // TestBackupManager_CreateBackup tests creating backups
 func TestBackupManager_CreateBackup(t *testing.T) {
 	tmpDir, err := os.MkdirTemp("", "recovery-test")
@@ -52,6 +61,21 @@ func TestBackupManager_CreateBackup(t *testing.T) {
 		t.Errorf("Expected backup content '%s', got '%s'", configContent, string(backupContent))
 	}
 
+	// A second backup in the same second must not overwrite the first
+	if err := os.WriteFile(configPath, []byte("theme = light"), 0o644); err != nil {
+		t.Fatalf("Failed to update config file: %v", err)
+	}
+	secondPath, err := bm.CreateBackup(configPath, "test-app")
+	if err != nil {
+		t.Fatalf("Failed to create second backup: %v", err)
+	}
+	if secondPath == backupPath {
+		t.Error("Expected a distinct path for the second backup")
+	}
+	if backupContent, _ := os.ReadFile(backupPath); string(backupContent) != configContent {
+		t.Errorf("Expected first backup to be preserved, got '%s'", string(backupContent))
+	}
+
 	// Test backing up non-existent file
 	backupPath2, err := bm.CreateBackup("/nonexistent/file", "test-app")
 	if err != nil {
@@ -333,3 +357,242 @@ func BenchmarkBackupManager_CreateBackup(b *testing.B) {
 		}
 	}
 }
+
+// TestBackupManager_ExcludeSecrets tests that excluded secrets stay out of
+// backups and come back from the current config on restore
+func TestBackupManager_ExcludeSecrets(t *testing.T) {
+	tmpDir := t.TempDir()
+	bm := &BackupManager{backupDir: tmpDir}
+
+	SetSecretMode(SecretsExclude)
+	defer SetSecretMode(SecretsKeep)
+
+	configPath := filepath.Join(tmpDir, "config", "gitconfig")
+	if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	original := "[user]\n\tname = Octo\n[github]\n\ttoken = gh-secret\n"
+	if err := os.WriteFile(configPath, []byte(original), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	backupPath, err := bm.CreateBackup(configPath, "git")
+	if err != nil {
+		t.Fatalf("Failed to create backup: %v", err)
+	}
+	backup, err := os.ReadFile(backupPath)
+	if err != nil {
+		t.Fatalf("Failed to read backup: %v", err)
+	}
+	if strings.Contains(string(backup), "gh-secret") || !strings.Contains(string(backup), "name = Octo") {
+		t.Fatalf("Expected the token masked in the backup, got %q", backup)
+	}
+
+	// The restore keeps the backup's other values and the current token
+	changed := "[user]\n\tname = Cat\n[github]\n\ttoken = gh-rotated\n"
+	if err := os.WriteFile(configPath, []byte(changed), 0o600); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+	if err := bm.RestoreBackup(backupPath, configPath); err != nil {
+		t.Fatalf("Failed to restore backup: %v", err)
+	}
+	restored, err := os.ReadFile(configPath)
+	if err != nil {
+		t.Fatalf("Failed to read restored config: %v", err)
+	}
+	if want := "[user]\n\tname = Octo\n[github]\n\ttoken = gh-rotated\n"; string(restored) != want {
+		t.Errorf("Expected %q, got %q", want, restored)
+	}
+}
+
+// TestBackupManager_Encryption tests that encrypted backups are listed,
+// restored and cleaned up like plain ones
+func TestBackupManager_Encryption(t *testing.T) {
+	tmpDir := t.TempDir()
+	bm := &BackupManager{backupDir: tmpDir}
+	defer SetEncryption(Encryption{})
+
+	configPath := filepath.Join(tmpDir, "config", "ghostty")
+	if err := os.MkdirAll(filepath.Dir(configPath), 0o755); err != nil {
+		t.Fatalf("Failed to create config dir: %v", err)
+	}
+	original := "theme = dark\nfont-size = 14\n"
+	if err := os.WriteFile(configPath, []byte(original), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	SetEncryption(Encryption{Passphrase: "correct horse"})
+	backupPath, err := bm.CreateBackup(configPath, "ghostty")
+	if err != nil {
+		t.Fatalf("Failed to create backup: %v", err)
+	}
+
+	data, err := os.ReadFile(backupPath)
+	if err != nil {
+		t.Fatalf("Failed to read backup: %v", err)
+	}
+	if strings.Contains(string(data), "theme") {
+		t.Fatalf("Expected the backup to be encrypted, got %q", data)
+	}
+	if info, _ := os.Stat(backupPath); info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected an encrypted backup to be private, got %v", info.Mode().Perm())
+	}
+
+	manifest, err := ReadManifest(backupPath)
+	if err != nil || manifest == nil {
+		t.Fatalf("Expected a manifest, got %v", err)
+	}
+	if !manifest.Encrypted || len(manifest.Recipients) != 1 || manifest.Recipients[0] != "passphrase" || manifest.Size != int64(len(original)) {
+		t.Errorf("Unexpected manifest: %+v", manifest)
+	}
+
+	backups, err := bm.ListBackups("ghostty")
+	if err != nil || len(backups) != 1 || !backups[0].Encrypted {
+		t.Fatalf("Expected one encrypted backup and no manifest entry, got %+v, %v", backups, err)
+	}
+
+	if err := os.WriteFile(configPath, []byte("theme = light\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	for name, enc := range map[string]Encryption{"no passphrase": {}, "wrong passphrase": {Passphrase: "wrong"}} {
+		SetEncryption(enc)
+		err := bm.RestoreBackup(backupPath, configPath)
+		if zerr, ok := errors.GetZeroUIError(err); !ok || zerr.Type != errors.BackupDecrypt {
+			t.Errorf("%s: expected a decrypt error, got %v", name, err)
+		}
+	}
+
+	SetEncryption(Encryption{Passphrase: "correct horse"})
+	if err := bm.RestoreBackup(backupPath, configPath); err != nil {
+		t.Fatalf("Failed to restore backup: %v", err)
+	}
+	if restored, _ := os.ReadFile(configPath); string(restored) != original {
+		t.Errorf("Expected %q, got %q", original, restored)
+	}
+
+	if err := bm.CleanupOldBackups("ghostty", 0); err != nil {
+		t.Fatalf("Failed to clean up: %v", err)
+	}
+	if entries, _ := os.ReadDir(tmpDir); len(entries) != 1 {
+		t.Errorf("Expected the backup and its manifest removed, got %d entries", len(entries))
+	}
+}
+
+// TestBackupManager_EncryptionKeyFile tests backups encrypted to a key file,
+// including one that only holds a public key
+func TestBackupManager_EncryptionKeyFile(t *testing.T) {
+	tmpDir := t.TempDir()
+	bm := &BackupManager{backupDir: tmpDir}
+	defer SetEncryption(Encryption{})
+
+	id, err := crypt.GenerateX25519Identity()
+	if err != nil {
+		t.Fatalf("Failed to generate key: %v", err)
+	}
+	privateKey := filepath.Join(tmpDir, "backup.key")
+	publicKey := filepath.Join(tmpDir, "backup.pub")
+	if err := os.WriteFile(privateKey, []byte(id.String()+"\n"), 0o600); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+	if err := os.WriteFile(publicKey, []byte(id.Recipient().String()+"\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write key: %v", err)
+	}
+
+	configPath := filepath.Join(tmpDir, "tmux.conf")
+	if err := os.WriteFile(configPath, []byte("set -g mouse on\n"), 0o644); err != nil {
+		t.Fatalf("Failed to write config: %v", err)
+	}
+
+	SetEncryption(Encryption{KeyFile: publicKey})
+	backupPath, err := bm.CreateBackup(configPath, "tmux")
+	if err != nil {
+		t.Fatalf("Failed to create backup: %v", err)
+	}
+	if manifest, _ := ReadManifest(backupPath); manifest == nil || manifest.Recipients[0] != id.Recipient().String() {
+		t.Errorf("Expected the manifest to record the public key, got %+v", manifest)
+	}
+
+	if err := bm.RestoreBackup(backupPath, configPath); err == nil {
+		t.Error("Expected a public key alone not to restore the backup")
+	}
+
+	SetEncryption(Encryption{KeyFile: privateKey})
+	if err := bm.RestoreBackup(backupPath, configPath); err != nil {
+		t.Fatalf("Failed to restore with the private key: %v", err)
+	}
+
+	// A backup that no longer matches its manifest isn't restored
+	data, _ := os.ReadFile(backupPath)
+	data[len(data)-1] ^= 1
+	if err := os.WriteFile(backupPath, data, 0o600); err != nil {
+		t.Fatalf("Failed to modify backup: %v", err)
+	}
+	if err := bm.RestoreBackup(backupPath, configPath); err == nil || !strings.Contains(err.Error(), "checksum") {
+		t.Errorf("Expected a checksum error, got %v", err)
+	}
+
+	SetEncryption(Encryption{KeyFile: filepath.Join(tmpDir, "missing.key")})
+	if _, err := bm.CreateBackup(configPath, "tmux"); err == nil {
+		t.Error("Expected a missing key file to fail the backup")
+	}
+}
+
+// TestNewBackupManager_MigratesLegacyBackups tests that backups move from
+// ~/.config/configtoggle/backups to the state directory once
+func TestNewBackupManager_MigratesLegacyBackups(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_STATE_HOME", filepath.Join(home, "state"))
+
+	legacyDir := filepath.Join(home, ".config", "configtoggle", "backups")
+	if err := os.MkdirAll(legacyDir, 0o755); err != nil {
+		t.Fatalf("Failed to create legacy dir: %v", err)
+	}
+	old := time.Now().Add(-48 * time.Hour)
+	for _, name := range []string{"git_20240101_120000.backup", "git_20240101_120000.backup.manifest"} {
+		path := filepath.Join(legacyDir, name)
+		if err := os.WriteFile(path, []byte(name), 0o600); err != nil {
+			t.Fatalf("Failed to write %s: %v", name, err)
+		}
+		if err := os.Chtimes(path, old, old); err != nil {
+			t.Fatalf("Failed to set times: %v", err)
+		}
+	}
+
+	bm, err := NewBackupManager()
+	if err != nil {
+		t.Fatalf("NewBackupManager failed: %v", err)
+	}
+	if want := filepath.Join(home, "state", "zeroui", "backups"); bm.backupDir != want {
+		t.Errorf("Expected backups in %s, got %s", want, bm.backupDir)
+	}
+
+	backups, err := bm.ListBackups("git")
+	if err != nil || len(backups) != 1 {
+		t.Fatalf("Expected the old backup to be listed, got %+v, %v", backups, err)
+	}
+	if backups[0].Created.Sub(old).Abs() > time.Second {
+		t.Errorf("Expected the backup's time to be kept, got %v", backups[0].Created)
+	}
+	if _, err := os.Stat(backups[0].Path + manifestSuffix); err != nil {
+		t.Errorf("Expected the manifest to move with the backup: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(home, ".config", "configtoggle")); !os.IsNotExist(err) {
+		t.Errorf("Expected the old directory to be removed, got %v", err)
+	}
+
+	// A name taken in the new directory leaves the old file in place
+	if err := os.MkdirAll(legacyDir, 0o755); err != nil {
+		t.Fatalf("Failed to recreate legacy dir: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(legacyDir, backups[0].Name), []byte("other"), 0o600); err != nil {
+		t.Fatalf("Failed to write backup: %v", err)
+	}
+	if moved, err := migrateLegacyBackups(legacyDir, bm.backupDir); moved != 0 || err == nil {
+		t.Errorf("Expected a clash to be reported, got %d, %v", moved, err)
+	}
+	if data, _ := os.ReadFile(backups[0].Path); string(data) != "git_20240101_120000.backup" {
+		t.Errorf("Expected the migrated backup to be kept, got %q", data)
+	}
+}
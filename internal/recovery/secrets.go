@@ -0,0 +1,63 @@
+package recovery
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"sync/atomic"
+
+	"github.com/mrtkrcm/ZeroUI/internal/secrets"
+)
+
+// SecretMode says how backups treat secrets found in the configs they copy
+type SecretMode string
+
+const (
+	// SecretsKeep copies configs into backups verbatim
+	SecretsKeep SecretMode = "keep"
+	// SecretsExclude masks secrets in backups; restoring one takes the
+	// secrets from the config being replaced
+	SecretsExclude SecretMode = "exclude"
+)
+
+var secretMode atomic.Value
+
+func init() {
+	secretMode.Store(SecretsKeep)
+}
+
+// SetSecretMode changes how new backups treat secrets. An empty mode
+// restores SecretsKeep.
+func SetSecretMode(mode SecretMode) {
+	if mode == "" {
+		mode = SecretsKeep
+	}
+	secretMode.Store(mode)
+}
+
+// CurrentSecretMode returns how new backups treat secrets
+func CurrentSecretMode() SecretMode {
+	return secretMode.Load().(SecretMode)
+}
+
+// backupContent prepares a config's content for a backup
+func backupContent(data []byte) []byte {
+	if CurrentSecretMode() == SecretsExclude {
+		return []byte(secrets.RedactText(string(data)))
+	}
+	return data
+}
+
+// restoreContent puts the secrets a backup excluded back, taking them from
+// the config at targetPath. Secrets the config no longer has are left out.
+func restoreContent(data []byte, targetPath string) []byte {
+	if !bytes.Contains(data, []byte(secrets.Masked)) {
+		return data
+	}
+	current, _ := os.ReadFile(targetPath)
+	restored, dropped := secrets.Refill(string(data), string(current))
+	if dropped > 0 {
+		fmt.Printf("Warning: %d secret line(s) excluded from the backup are not in %s and were left out\n", dropped, targetPath)
+	}
+	return []byte(restored)
+}
@@ -54,6 +54,17 @@
 //   - ZEROUI_DEFAULT_THEME - Default theme (default, modern, catppuccin, nord, dracula)
 //   - ZEROUI_VERBOSE - Verbose output (true, false)
 //   - ZEROUI_DRY_RUN - Dry run mode (true, false)
+//   - ZEROUI_BACKUP_SECRETS - Secrets in backups (keep, exclude)
+//   - ZEROUI_BACKUP_KEY - Key file that backups are encrypted to
+//   - ZEROUI_BACKUP_PASSPHRASE - Passphrase that backups are encrypted with;
+//     read from the environment only, never from flags or config files
+//
+// ZEROUI_ROOT (or --root) makes zeroui treat another directory as the home
+// directory; it is read before the config file, whose location it changes.
+//
+// The default directories follow the XDG Base Directory specification:
+// XDG_CONFIG_HOME for the config directory, XDG_STATE_HOME for backups, logs
+// and the audit log and XDG_CACHE_HOME for locks and reference configs (see package paths).
 //
 // # Configuration Precedence
 //
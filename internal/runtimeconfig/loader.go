@@ -3,8 +3,9 @@ package runtimeconfig
 import (
 	"fmt"
 	"os"
-	"path/filepath"
+	"time"
 
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
 	"github.com/spf13/pflag"
 	"github.com/spf13/viper"
 )
@@ -13,13 +14,16 @@ import (
 // It supports loading from multiple sources with the following precedence:
 // flags > environment variables > config file > defaults
 type Config struct {
-	ConfigFile   string `mapstructure:"config" validate:"omitempty,filepath"`
-	ConfigDir    string `mapstructure:"config_dir" validate:"required,dirpath"`
-	LogLevel     string `mapstructure:"log_level" validate:"required,oneof=debug info warn error"`
-	LogFormat    string `mapstructure:"log_format" validate:"required,oneof=text json"`
-	DefaultTheme string `mapstructure:"default_theme" validate:"required,oneof=default modern dracula light nord catppuccin"`
-	Verbose      bool   `mapstructure:"verbose"`
-	DryRun       bool   `mapstructure:"dry_run"`
+	ConfigFile    string        `mapstructure:"config" validate:"omitempty,filepath"`
+	ConfigDir     string        `mapstructure:"config_dir" validate:"required,dirpath"`
+	LogLevel      string        `mapstructure:"log_level" validate:"required,oneof=debug info warn error"`
+	LogFormat     string        `mapstructure:"log_format" validate:"required,oneof=text json"`
+	DefaultTheme  string        `mapstructure:"default_theme" validate:"required,oneof=default modern dracula light nord catppuccin"`
+	Verbose       bool          `mapstructure:"verbose"`
+	DryRun        bool          `mapstructure:"dry_run"`
+	LockTimeout   time.Duration `mapstructure:"lock_timeout"`
+	BackupSecrets string        `mapstructure:"backup_secrets" validate:"omitempty,oneof=keep exclude"`
+	BackupKey     string        `mapstructure:"backup_key" validate:"omitempty,filepath"`
 }
 
 // Loader manages loading runtime configuration from multiple sources.
@@ -95,6 +99,8 @@ func (l *Loader) setDefaults() {
 	l.v.SetDefault("default_theme", "modern")
 	l.v.SetDefault("verbose", false)
 	l.v.SetDefault("dry_run", false)
+	l.v.SetDefault("lock_timeout", 5*time.Second)
+	l.v.SetDefault("backup_secrets", "keep")
 }
 
 // bindFlags binds command-line flags to viper configuration keys.
@@ -102,13 +108,16 @@ func (l *Loader) setDefaults() {
 func (l *Loader) bindFlags(flags *pflag.FlagSet) error {
 	// Map of flag names to viper keys
 	flagMap := map[string]string{
-		"config-dir":    "config_dir",
-		"log-level":     "log_level",
-		"log-format":    "log_format",
-		"default-theme": "default_theme",
-		"verbose":       "verbose",
-		"dry-run":       "dry_run",
-		"config":        "config",
+		"config-dir":     "config_dir",
+		"log-level":      "log_level",
+		"log-format":     "log_format",
+		"default-theme":  "default_theme",
+		"verbose":        "verbose",
+		"dry-run":        "dry_run",
+		"lock-timeout":   "lock_timeout",
+		"backup-secrets": "backup_secrets",
+		"backup-key":     "backup_key",
+		"config":         "config",
 	}
 
 	// Bind each flag individually with proper key mapping
@@ -165,6 +174,13 @@ func (l *Loader) validate(cfg *Config) error {
 		return fmt.Errorf("invalid default_theme: %s (must be one of: default, modern, dracula, light, nord, catppuccin)", cfg.DefaultTheme)
 	}
 
+	// Validate BackupSecrets
+	switch cfg.BackupSecrets {
+	case "", "keep", "exclude":
+	default:
+		return fmt.Errorf("invalid backup_secrets: %s (must be one of: keep, exclude)", cfg.BackupSecrets)
+	}
+
 	// Validate ConfigFile exists if specified
 	if cfg.ConfigFile != "" {
 		if _, err := os.Stat(cfg.ConfigFile); os.IsNotExist(err) {
@@ -178,19 +194,8 @@ func (l *Loader) validate(cfg *Config) error {
 // DefaultConfigDir returns the default configuration directory for ZeroUI.
 // It checks the following in order:
 // 1. ZEROUI_CONFIG_DIR environment variable
-// 2. $HOME/.config/zeroui (default)
+// 2. $XDG_CONFIG_HOME/zeroui
+// 3. $HOME/.config/zeroui (default)
 func DefaultConfigDir() string {
-	// Check environment variable first
-	if dir := os.Getenv("ZEROUI_CONFIG_DIR"); dir != "" {
-		return dir
-	}
-
-	// Fall back to default
-	home, err := os.UserHomeDir()
-	if err != nil {
-		// If we can't get home dir, use relative path as last resort
-		return ".config/zeroui"
-	}
-
-	return filepath.Join(home, ".config", "zeroui")
+	return paths.ConfigDir()
 }
@@ -59,6 +59,7 @@ func TestDefaultConfigDir(t *testing.T) {
 
 		// Unset environment variable
 		os.Unsetenv("ZEROUI_CONFIG_DIR")
+		t.Setenv("XDG_CONFIG_HOME", "")
 
 		result := DefaultConfigDir()
 		home, err := os.UserHomeDir()
@@ -66,6 +67,13 @@ func TestDefaultConfigDir(t *testing.T) {
 		expected := filepath.Join(home, ".config", "zeroui")
 		assert.Equal(t, expected, result)
 	})
+
+	t.Run("WithXDGConfigHome", func(t *testing.T) {
+		t.Setenv("ZEROUI_CONFIG_DIR", "")
+		t.Setenv("XDG_CONFIG_HOME", "/tmp/xdg-config")
+
+		assert.Equal(t, filepath.Join("/tmp/xdg-config", "zeroui"), DefaultConfigDir())
+	})
 }
 
 func TestLoader_Load_Defaults(t *testing.T) {
@@ -259,6 +267,16 @@ func TestLoader_Load_Validation(t *testing.T) {
 			},
 			expectError: "invalid default_theme",
 		},
+		{
+			name: "InvalidBackupSecrets",
+			setupFunc: func() *pflag.FlagSet {
+				flags := pflag.NewFlagSet("test", pflag.ContinueOnError)
+				flags.String("backup-secrets", "", "backup secrets")
+				flags.Set("backup-secrets", "publish")
+				return flags
+			},
+			expectError: "invalid backup_secrets",
+		},
 	}
 
 	for _, tt := range tests {
@@ -0,0 +1,140 @@
+// Package safefile replaces config files the way an editor saving in place
+// would: writes go through symlinks to the real file, which is replaced
+// atomically from its own directory and keeps its mode, owner and group.
+package safefile
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/mrtkrcm/ZeroUI/internal/security"
+)
+
+// WriteFile atomically replaces the file at path, following symlinks, with
+// data. An existing file keeps its mode and ownership; a new one is created
+// with perm.
+func WriteFile(path string, data []byte, perm os.FileMode) error {
+	target, err := security.ResolvePath(path)
+	if err != nil {
+		return err
+	}
+	return replace(bytes.NewReader(data), target, perm)
+}
+
+// Replace atomically replaces the file at dst, following symlinks, with the
+// contents of src, then removes src. An existing dst keeps its mode and
+// ownership; a new one takes src's mode.
+func Replace(src, dst string) error {
+	target, err := security.ResolvePath(dst)
+	if err != nil {
+		return err
+	}
+
+	source, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer source.Close()
+
+	info, err := source.Stat()
+	if err != nil {
+		return err
+	}
+	if err := replace(source, target, info.Mode().Perm()); err != nil {
+		return err
+	}
+
+	source.Close()
+	return os.Remove(src)
+}
+
+// replace writes contents to a file staged in target's directory, so the
+// final rename never crosses filesystems, and renames it over target
+func replace(contents io.Reader, target string, perm os.FileMode) error {
+	attrs, err := statAttrs(target)
+	if err != nil {
+		return fmt.Errorf("failed to stat %s: %w", target, err)
+	}
+	if attrs == nil {
+		attrs = &fileAttrs{mode: perm}
+	}
+
+	staged, err := os.CreateTemp(filepath.Dir(target), "."+filepath.Base(target)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("failed to stage write: %w", err)
+	}
+	stagedPath := staged.Name()
+	committed := false
+	defer func() {
+		if !committed {
+			staged.Close()
+			os.Remove(stagedPath)
+		}
+	}()
+
+	if _, err := io.Copy(staged, contents); err != nil {
+		return fmt.Errorf("failed to stage write: %w", err)
+	}
+	if err := attrs.apply(staged); err != nil {
+		return err
+	}
+	if err := staged.Sync(); err != nil {
+		return fmt.Errorf("failed to sync staged write: %w", err)
+	}
+	if err := staged.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(stagedPath, target); err != nil {
+		return err
+	}
+	committed = true
+	return nil
+}
+
+// fileAttrs are the attributes a replaced file keeps: its permission bits
+// and, where the platform has them, its owner and group
+type fileAttrs struct {
+	mode     os.FileMode
+	uid, gid int
+	owned    bool
+}
+
+// statAttrs returns the attributes of an existing file, or nil when it
+// doesn't exist
+func statAttrs(path string) (*fileAttrs, error) {
+	info, err := os.Stat(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	attrs := &fileAttrs{mode: info.Mode().Perm()}
+	attrs.uid, attrs.gid, attrs.owned = fileOwner(info)
+	return attrs, nil
+}
+
+// apply gives a file these attributes
+func (a *fileAttrs) apply(file *os.File) error {
+	if err := file.Chmod(a.mode); err != nil {
+		return fmt.Errorf("failed to set mode: %w", err)
+	}
+	if !a.owned {
+		return nil
+	}
+	info, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	// Only chown when needed: giving a file to another owner takes privileges
+	if uid, gid, ok := fileOwner(info); ok && uid == a.uid && gid == a.gid {
+		return nil
+	}
+	if err := file.Chown(a.uid, a.gid); err != nil {
+		return fmt.Errorf("failed to keep owner %d:%d: %w", a.uid, a.gid, err)
+	}
+	return nil
+}
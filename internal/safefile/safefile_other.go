@@ -0,0 +1,12 @@
+//go:build !unix
+
+package safefile
+
+import (
+	"os"
+)
+
+// fileOwner reports no owner; ownership is kept by the filesystem's ACLs
+func fileOwner(info os.FileInfo) (uid, gid int, ok bool) {
+	return 0, 0, false
+}
@@ -0,0 +1,74 @@
+package safefile
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestWriteFile_KeepsSymlinkAndMode(t *testing.T) {
+	dir := t.TempDir()
+	real := filepath.Join(dir, "dotfiles", "gitconfig")
+	link := filepath.Join(dir, "home", ".gitconfig")
+	for _, d := range []string{filepath.Dir(real), filepath.Dir(link)} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.WriteFile(real, []byte("[user]\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(real, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink("../dotfiles/gitconfig", link); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := WriteFile(link, []byte("[user]\n\tname = x\n"), 0o644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	info, err := os.Lstat(link)
+	if err != nil || info.Mode()&os.ModeSymlink == 0 {
+		t.Fatalf("Expected %s to still be a symlink, got %v (err %v)", link, info.Mode(), err)
+	}
+	data, err := os.ReadFile(real)
+	if err != nil || string(data) != "[user]\n\tname = x\n" {
+		t.Errorf("Expected the link target to be written, got %q (err %v)", data, err)
+	}
+	if info, _ := os.Stat(real); info.Mode().Perm() != 0o600 {
+		t.Errorf("Expected mode 0600 to be kept, got %v", info.Mode().Perm())
+	}
+
+	entries, _ := os.ReadDir(filepath.Dir(real))
+	if len(entries) != 1 {
+		t.Errorf("Expected no staged files left behind, got %v", entries)
+	}
+}
+
+func TestReplace(t *testing.T) {
+	dir := t.TempDir()
+	src := filepath.Join(dir, "src")
+	dst := filepath.Join(dir, "new", "dst")
+	if err := os.WriteFile(src, []byte("a = 1\n"), 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chmod(src, 0o640); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := Replace(src, dst); err != nil {
+		t.Fatalf("Replace failed: %v", err)
+	}
+	if _, err := os.Stat(src); !os.IsNotExist(err) {
+		t.Errorf("Expected src to be removed, got %v", err)
+	}
+	info, err := os.Stat(dst)
+	if err != nil || info.Mode().Perm() != 0o640 {
+		t.Errorf("Expected a new dst to take src's mode, got %v (err %v)", info, err)
+	}
+}
@@ -0,0 +1,313 @@
+// Package secrets recognises credentials in config values so they can be
+// masked in output, logs and backups. A value is a secret when its key names
+// one (password, api_key, GITHUB_TOKEN), when it contains a provider token
+// (ghp_..., AKIA..., xoxb-..., sk-...), a password embedded in a URL or an
+// inline password=... assignment, or when it looks like a random key: long,
+// without spaces and with high entropy.
+package secrets
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// Masked replaces secret values in output
+const Masked = "********"
+
+// Kind says why a value was classified as a secret
+type Kind string
+
+const (
+	// KindKeyName means the key names a credential
+	KindKeyName Kind = "key-name"
+	// KindToken means the value contains a known token format
+	KindToken Kind = "token"
+	// KindEntropy means the value looks like a random key
+	KindEntropy Kind = "high-entropy"
+)
+
+// Match describes why a value is a secret
+type Match struct {
+	Kind Kind
+	// Name is the key word or token format that matched
+	Name string
+}
+
+// pattern is a token format; the secret is capture group 1 when the pattern
+// has one, the whole match otherwise
+type pattern struct {
+	name string
+	re   *regexp.Regexp
+}
+
+// patterns lists provider token formats, most specific first
+var patterns = []pattern{
+	{"private-key", regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+	{"github", regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{36,}|github_pat_[A-Za-z0-9_]{22,})`)},
+	{"gitlab", regexp.MustCompile(`\bglpat-[A-Za-z0-9_\-]{20,}`)},
+	{"aws", regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{"slack", regexp.MustCompile(`\bxox[abposr]-[A-Za-z0-9\-]{10,}`)},
+	{"anthropic", regexp.MustCompile(`\bsk-ant-[A-Za-z0-9_\-]{20,}`)},
+	{"openai", regexp.MustCompile(`\bsk-(?:proj-)?[A-Za-z0-9_\-]{20,}`)},
+	{"stripe", regexp.MustCompile(`\b[rs]k_(?:live|test)_[A-Za-z0-9]{16,}`)},
+	{"google", regexp.MustCompile(`\bAIza[0-9A-Za-z_\-]{35}`)},
+	{"npm", regexp.MustCompile(`\bnpm_[A-Za-z0-9]{36}`)},
+	{"jwt", regexp.MustCompile(`\beyJ[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]{10,}\.[A-Za-z0-9_\-]{10,}`)},
+	{"bearer", regexp.MustCompile(`(?i)\bbearer\s+([A-Za-z0-9._~+/\-]{16,}=*)`)},
+	{"url-password", regexp.MustCompile(`://[^/\s:@]+:([^/\s@]+)@`)},
+	{"assignment", regexp.MustCompile(`(?i)\b(?:password|passwd|token|secret|api[_\-]?key)\s*[=:]\s*["']?([^\s"';&|}]+)`)},
+}
+
+// secret returns the secret part of a match, which FindStringSubmatch
+// returned
+func (p pattern) secret(sub []string) string {
+	if len(sub) > 1 {
+		return sub[1]
+	}
+	return sub[0]
+}
+
+// matches reports whether text contains the token format; an inline
+// assignment of a switch, as in password=false, doesn't count
+func (p pattern) matches(text string) bool {
+	for _, sub := range p.re.FindAllStringSubmatch(text, -1) {
+		if !isSwitch(p.secret(sub)) {
+			return true
+		}
+	}
+	return false
+}
+
+// secretWords are key words that name a credential
+var secretWords = map[string]bool{
+	"password":     true,
+	"passwd":       true,
+	"passphrase":   true,
+	"secret":       true,
+	"token":        true,
+	"apikey":       true,
+	"accesskey":    true,
+	"privatekey":   true,
+	"secretkey":    true,
+	"clientsecret": true,
+}
+
+// referenceWords mark keys that point at a credential rather than hold one:
+// password-command, token-file, credential.helper, max-tokens
+var referenceWords = map[string]bool{
+	"file": true, "path": true, "dir": true, "helper": true, "command": true,
+	"cmd": true, "program": true, "prompt": true, "env": true, "url": true,
+	"uri": true, "endpoint": true, "name": true, "type": true, "store": true,
+	"cache": true, "limit": true, "max": true, "min": true, "count": true,
+	"size": true, "length": true, "timeout": true, "ttl": true, "expiry": true,
+	"expires": true,
+}
+
+// IsSecretKey reports whether a key names a credential, such as password,
+// api_key or GITHUB_TOKEN
+func IsSecretKey(key string) bool {
+	_, ok := keyWord(key)
+	return ok
+}
+
+// keyWord returns the credential word in a key, if the key holds one
+func keyWord(key string) (string, bool) {
+	words := splitWords(key)
+	found := ""
+	for i, word := range words {
+		if referenceWords[word] {
+			return "", false
+		}
+		if found != "" {
+			continue
+		}
+		if secretWords[word] {
+			found = word
+		} else if i+1 < len(words) && secretWords[word+words[i+1]] {
+			// api_key, access-key, privateKey
+			found = word + words[i+1]
+		}
+	}
+	return found, found != ""
+}
+
+// splitWords splits a key on punctuation and camelCase into lowercase words
+func splitWords(key string) []string {
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	runes := []rune(key)
+	for i, r := range runes {
+		switch {
+		case !unicode.IsLetter(r) && !unicode.IsDigit(r):
+			flush()
+			continue
+		case unicode.IsUpper(r) && i > 0 && unicode.IsLower(runes[i-1]):
+			flush()
+		}
+		word = append(word, r)
+	}
+	flush()
+	return words
+}
+
+// Classify reports whether a value is a secret and why. The key is the
+// setting's name; values of credential keys are secrets unless they are
+// empty or a boolean switch.
+func Classify(key string, value interface{}) (Match, bool) {
+	switch v := value.(type) {
+	case nil, bool:
+		return Match{}, false
+	case []interface{}:
+		for _, entry := range v {
+			if m, ok := Classify(key, entry); ok {
+				return m, true
+			}
+		}
+		return Match{}, false
+	case []string:
+		for _, entry := range v {
+			if m, ok := Classify(key, entry); ok {
+				return m, true
+			}
+		}
+		return Match{}, false
+	case map[string]interface{}:
+		for k, entry := range v {
+			if m, ok := Classify(k, entry); ok {
+				return m, true
+			}
+		}
+		return Match{}, false
+	}
+
+	text := strings.TrimSpace(fmt.Sprint(value))
+	if text == "" || text == Masked || isSwitch(text) {
+		return Match{}, false
+	}
+	if word, ok := keyWord(key); ok {
+		return Match{Kind: KindKeyName, Name: word}, true
+	}
+	for _, p := range patterns {
+		if p.matches(text) {
+			return Match{Kind: KindToken, Name: p.name}, true
+		}
+	}
+	if highEntropy(text) {
+		return Match{Kind: KindEntropy}, true
+	}
+	return Match{}, false
+}
+
+// IsSecret reports whether a key's value is a secret
+func IsSecret(key string, value interface{}) bool {
+	_, ok := Classify(key, value)
+	return ok
+}
+
+// Redact returns value with secrets replaced by Masked. Maps are redacted key
+// by key and lists entry by entry, so the shape of the value is kept.
+func Redact(key string, value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		redacted := make(map[string]interface{}, len(v))
+		for k, entry := range v {
+			redacted[k] = Redact(k, entry)
+		}
+		return redacted
+	case map[string]string:
+		redacted := make(map[string]string, len(v))
+		for k, entry := range v {
+			redacted[k] = RedactString(k, entry)
+		}
+		return redacted
+	case []interface{}:
+		redacted := make([]interface{}, len(v))
+		for i, entry := range v {
+			redacted[i] = Redact(key, entry)
+		}
+		return redacted
+	case []string:
+		redacted := make([]string, len(v))
+		for i, entry := range v {
+			redacted[i] = RedactString(key, entry)
+		}
+		return redacted
+	}
+	if text, ok := value.(string); ok {
+		return RedactString(key, text)
+	}
+	if IsSecret(key, value) {
+		return Masked
+	}
+	return value
+}
+
+// RedactString is Redact for string values. Tokens found inside a longer
+// value, such as a password in a URL, are masked in place.
+func RedactString(key, value string) string {
+	m, ok := Classify(key, value)
+	switch {
+	case !ok:
+		return value
+	case m.Kind == KindToken:
+		return redactTokens(value)
+	}
+	return Masked
+}
+
+// isSwitch reports whether a value is an on/off setting rather than a secret,
+// as in use-password = false
+func isSwitch(text string) bool {
+	switch strings.ToLower(text) {
+	case "true", "false", "yes", "no", "on", "off":
+		return true
+	}
+	return false
+}
+
+// highEntropy reports whether text looks like a random key: at least 20
+// characters from a key alphabet, mixing letters and digits, with entropy
+// close to the most its length allows. Hex digests and UUIDs fall short, as
+// their 16-symbol alphabet caps entropy at 4 bits.
+func highEntropy(text string) bool {
+	if len(text) < 20 || len(text) > 512 {
+		return false
+	}
+	if strings.HasPrefix(text, "/") || strings.HasPrefix(text, "~") || strings.HasPrefix(text, ".") || strings.Contains(text, "://") {
+		return false
+	}
+
+	var letters, digits bool
+	counts := make(map[rune]int)
+	for _, r := range text {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+			letters = true
+		case r >= '0' && r <= '9':
+			digits = true
+		case strings.ContainsRune("+/=_-.", r):
+		default:
+			return false
+		}
+		counts[r]++
+	}
+	if !letters || !digits {
+		return false
+	}
+
+	entropy := 0.0
+	for _, n := range counts {
+		p := float64(n) / float64(len(text))
+		entropy -= p * math.Log2(p)
+	}
+	return entropy >= 0.85*math.Log2(math.Min(float64(len(text)), 64))
+}
@@ -0,0 +1,97 @@
+package secrets
+
+import (
+	"regexp"
+	"strings"
+)
+
+// assignmentLine splits config lines of the common formats into prefix, key,
+// separator and value: "key = value" (Ghostty, gitconfig, INI), "key: value"
+// (YAML), "\"key\": value," (JSON) and "export KEY=value" (shell rc files)
+var assignmentLine = regexp.MustCompile(`^(\s*(?:export\s+|set\s+-g\s+|set\s+)?)(["']?)([A-Za-z0-9_.@\-]+)(["']?\s*[=:]\s*)(.*)$`)
+
+// RedactText masks the secrets in config file text line by line: values of
+// credential keys and anything matching a token format. Line structure,
+// quoting and trailing commas are kept, so the result still reads as the same
+// config.
+func RedactText(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = redactLine(line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+func redactLine(line string) string {
+	if m := assignmentLine.FindStringSubmatch(line); m != nil {
+		value, open, close := unquote(m[5])
+		if _, ok := keyWord(m[3]); ok && value != "" && !isSwitch(value) && value != Masked {
+			return m[1] + m[2] + m[3] + m[4] + open + Masked + close
+		}
+	}
+	return redactTokens(line)
+}
+
+// redactTokens masks token-format matches within a line
+func redactTokens(line string) string {
+	for _, p := range patterns {
+		line = p.re.ReplaceAllStringFunc(line, func(match string) string {
+			sub := p.re.FindStringSubmatchIndex(match)
+			switch {
+			case len(sub) < 4 || sub[2] < 0:
+				return Masked
+			case isSwitch(match[sub[2]:sub[3]]):
+				return match
+			}
+			return match[:sub[2]] + Masked + match[sub[3]:]
+		})
+	}
+	return line
+}
+
+// unquote splits a raw value into the value and the quoting and punctuation
+// around it, so `"abc",` gives abc, `"` and `",`
+func unquote(raw string) (value, open, close string) {
+	value = strings.TrimRight(raw, " \t,;")
+	close = raw[len(value):]
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		open = value[:1]
+		close = value[len(value)-1:] + close
+		value = value[1 : len(value)-1]
+	}
+	return value, open, close
+}
+
+// Refill puts secrets back into text that RedactText masked, taking each
+// masked line from current: the n-th masked line whose text before the mask
+// is P is replaced by the n-th line of current starting with P. Masked lines
+// with no counterpart are dropped, since their secret is gone; dropped counts
+// them, as are lines that start with the mask.
+func Refill(redacted, current string) (text string, dropped int) {
+	candidates := strings.Split(current, "\n")
+	used := make(map[int]bool)
+
+	var out []string
+	for _, line := range strings.Split(redacted, "\n") {
+		i := strings.Index(line, Masked)
+		if i < 0 {
+			out = append(out, line)
+			continue
+		}
+		prefix := line[:i]
+		found := -1
+		for n, candidate := range candidates {
+			if prefix != "" && !used[n] && strings.HasPrefix(candidate, prefix) && !strings.Contains(candidate, Masked) {
+				found = n
+				break
+			}
+		}
+		if found < 0 {
+			dropped++
+			continue
+		}
+		used[found] = true
+		out = append(out, candidates[found])
+	}
+	return strings.Join(out, "\n"), dropped
+}
@@ -72,16 +72,24 @@ func (pv *PathValidator) ValidatePath(inputPath string) error {
 		return fmt.Errorf("failed to resolve path: %w", err)
 	}
 
-	// Validate against allowed paths if configured
+	// Validate against allowed paths if configured. The check uses the
+	// resolved path, so a symlink inside an allowed directory can't point a
+	// write outside it.
 	if len(pv.allowedPaths) > 0 {
+		resolved, err := ResolvePath(absPath)
+		if err != nil {
+			return fmt.Errorf("failed to resolve path: %w", err)
+		}
+
 		var allowed bool
 		for _, allowedPath := range pv.allowedPaths {
-			// normalize allowed path as absolute as well
-			absAllowed, aerr := filepath.Abs(allowedPath)
+			// Resolve allowed paths as well, since they may be symlinks
+			// themselves (such as /tmp on macOS)
+			absAllowed, aerr := ResolvePath(allowedPath)
 			if aerr != nil {
 				absAllowed = filepath.Clean(allowedPath)
 			}
-			if strings.HasPrefix(absPath, absAllowed) {
+			if within(resolved, absAllowed) {
 				allowed = true
 				break
 			}
@@ -94,6 +102,12 @@ func (pv *PathValidator) ValidatePath(inputPath string) error {
 	return nil
 }
 
+// within reports whether path is base or inside it
+func within(path, base string) bool {
+	rel, err := filepath.Rel(base, path)
+	return err == nil && rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
 // ValidateBackupName validates a backup filename for security
 func (pv *PathValidator) ValidateBackupName(backupName string) error {
 	// Basic filename validation
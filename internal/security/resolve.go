@@ -0,0 +1,59 @@
+package security
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// maxSymlinkHops bounds symlink chains, as the kernel's ELOOP limit does
+const maxSymlinkHops = 40
+
+// ResolvePath returns the real path a write to path lands on. Symlinks are
+// followed in every component, including a final link whose target doesn't
+// exist yet (a dotfiles link to a file not created yet); components that don't
+// exist are kept as given.
+func ResolvePath(path string) (string, error) {
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path: %w", err)
+	}
+	return resolve(absPath, 0)
+}
+
+func resolve(path string, hops int) (string, error) {
+	parent := filepath.Dir(path)
+	if parent == path {
+		return path, nil
+	}
+
+	dir, err := resolve(parent, hops)
+	if err != nil {
+		return "", err
+	}
+	path = filepath.Join(dir, filepath.Base(path))
+
+	info, err := os.Lstat(path)
+	if os.IsNotExist(err) {
+		return path, nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path: %w", err)
+	}
+	if info.Mode()&os.ModeSymlink == 0 {
+		return path, nil
+	}
+
+	if hops >= maxSymlinkHops {
+		return "", fmt.Errorf("too many levels of symbolic links: %s", path)
+	}
+	link, err := os.Readlink(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to resolve path: %w", err)
+	}
+	if !filepath.IsAbs(link) {
+		link = filepath.Join(dir, link)
+	}
+	// The link target may itself go through symlinked directories
+	return resolve(filepath.Clean(link), hops+1)
+}
@@ -0,0 +1,79 @@
+package security
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolvePath(t *testing.T) {
+	dir, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	dotfiles := filepath.Join(dir, "dotfiles")
+	config := filepath.Join(dir, "config")
+	for _, d := range []string{filepath.Join(dotfiles, "ghostty"), config} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	real := filepath.Join(dotfiles, "ghostty", "config")
+	if err := os.WriteFile(real, []byte("font-size = 13\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mustLink := func(target, link string) {
+		t.Helper()
+		if err := os.Symlink(target, link); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// A stow-style relative link to a file, a link to a directory, a link to
+	// a file that doesn't exist yet and a cycle
+	mustLink("../dotfiles/ghostty/config", filepath.Join(config, "ghostty"))
+	mustLink(filepath.Join(dotfiles, "ghostty"), filepath.Join(config, "ghostty-dir"))
+	mustLink("../dotfiles/kitty.conf", filepath.Join(config, "kitty"))
+	mustLink("loop-b", filepath.Join(config, "loop-a"))
+	mustLink("loop-a", filepath.Join(config, "loop-b"))
+
+	tests := []struct {
+		path string
+		want string
+	}{
+		{filepath.Join(config, "ghostty"), real},
+		{filepath.Join(config, "ghostty-dir", "config"), real},
+		{filepath.Join(config, "kitty"), filepath.Join(dotfiles, "kitty.conf")},
+		{filepath.Join(config, "missing", "file"), filepath.Join(config, "missing", "file")},
+		{real, real},
+	}
+	for _, tt := range tests {
+		got, err := ResolvePath(tt.path)
+		if err != nil || got != tt.want {
+			t.Errorf("ResolvePath(%q) = %q, %v; want %q", tt.path, got, err, tt.want)
+		}
+	}
+
+	if _, err := ResolvePath(filepath.Join(config, "loop-a")); err == nil {
+		t.Error("Expected an error for a symlink cycle")
+	}
+}
+
+func TestPathValidator_ResolvesSymlinks(t *testing.T) {
+	allowed := t.TempDir()
+	outside := t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(allowed, "escape")); err != nil {
+		t.Fatal(err)
+	}
+
+	validator := NewPathValidator(allowed)
+	if err := validator.ValidatePath(filepath.Join(allowed, "escape", "backup.txt")); err == nil {
+		t.Error("Expected a symlink out of the allowed directory to be rejected")
+	}
+	if err := validator.ValidatePath(filepath.Join(allowed, "backup.txt")); err != nil {
+		t.Errorf("Expected a path inside the allowed directory to be valid: %v", err)
+	}
+	if err := validator.ValidatePath(filepath.Join(allowed+"-other", "backup.txt")); err == nil {
+		t.Error("Expected a sibling sharing the allowed prefix to be rejected")
+	}
+}
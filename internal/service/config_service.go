@@ -41,6 +41,17 @@ func (s *ConfigService) ToggleConfiguration(app, key, value string) error {
 	return s.engine.Toggle(app, key, value)
 }
 
+// ToggleConfigurationWithOptions sets a configuration value with toggle options
+func (s *ConfigService) ToggleConfigurationWithOptions(app, key, value string, opts toggle.ToggleOptions) error {
+	log := s.logger.WithApp(app).WithField(key)
+	log.Info("Toggling configuration", map[string]interface{}{
+		"value": value,
+		"fix":   opts.Fix,
+	})
+
+	return s.engine.ToggleWithOptions(app, key, value, opts)
+}
+
 // CycleConfiguration cycles to the next value for a configuration key
 func (s *ConfigService) CycleConfiguration(app, key string) error {
 	log := s.logger.WithApp(app).WithField(key)
@@ -79,6 +90,36 @@ func (s *ConfigService) RemoveConfiguration(app, key, value string) error {
 	return s.engine.RemoveConfiguration(app, key, value)
 }
 
+// ResetConfiguration returns fields to their default and keeps a backup of
+// the previous file. With no keys every set field is reset.
+func (s *ConfigService) ResetConfiguration(app string, keys ...string) (*toggle.RemovalPlan, error) {
+	log := s.logger.WithApp(app)
+	log.Info("Resetting configuration", map[string]interface{}{
+		"keys": keys,
+	})
+
+	plan, err := s.engine.PlanReset(app, keys)
+	if err != nil {
+		return nil, err
+	}
+	return plan, s.engine.ApplyRemoval(plan)
+}
+
+// UnsetConfiguration deletes keys from an app's config file and keeps a
+// backup of the previous file
+func (s *ConfigService) UnsetConfiguration(app string, keys ...string) (*toggle.RemovalPlan, error) {
+	log := s.logger.WithApp(app)
+	log.Info("Unsetting configuration", map[string]interface{}{
+		"keys": keys,
+	})
+
+	plan, err := s.engine.PlanUnset(app, keys)
+	if err != nil {
+		return nil, err
+	}
+	return plan, s.engine.ApplyRemoval(plan)
+}
+
 // ListApplications returns all available applications
 func (s *ConfigService) ListApplications() ([]string, error) {
 	s.logger.Debug("Listing applications")
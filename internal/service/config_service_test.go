@@ -12,8 +12,13 @@ import (
 	"github.com/mrtkrcm/ZeroUI/internal/logger"
 	"github.com/mrtkrcm/ZeroUI/internal/service"
 	"github.com/mrtkrcm/ZeroUI/internal/toggle"
+	"github.com/mrtkrcm/ZeroUI/test/helpers"
 )
 
+func TestMain(m *testing.M) {
+	helpers.RunTestMainWithCleanup(m, "internal/service", "zeroui-internal-service-test-home-", nil)
+}
+
 func TestConfigService_Integration(t *testing.T) {
 	// Create temporary directory for test
 	tmpDir, err := os.MkdirTemp("", "zeroui-service-test")
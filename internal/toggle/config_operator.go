@@ -1,33 +1,29 @@
 package toggle
 
 import (
-	"os"
-	"path/filepath"
 	"sync"
 
 	lru "github.com/hashicorp/golang-lru/v2"
 	"github.com/knadh/koanf/v2"
 	"github.com/mrtkrcm/ZeroUI/internal/appconfig"
 	"github.com/mrtkrcm/ZeroUI/internal/errors"
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
 	"github.com/spf13/viper"
 )
 
 // ConfigOperator handles core config read/write operations
 type ConfigOperator struct {
 	loader    ConfigLoader
-	homeDir   string
 	pathCache *lru.Cache[string, string]
 	pathMutex sync.RWMutex
 }
 
 // NewConfigOperator creates a new config operator
 func NewConfigOperator(loader ConfigLoader) *ConfigOperator {
-	homeDir, _ := os.UserHomeDir()
 	pathCache, _ := lru.New[string, string](1000)
 
 	return &ConfigOperator{
 		loader:    loader,
-		homeDir:   homeDir,
 		pathCache: pathCache,
 	}
 }
@@ -89,13 +85,8 @@ func (co *ConfigOperator) expandPath(configPath string) string {
 	}
 	co.pathMutex.RUnlock()
 
-	// Expand the path
-	var expanded string
-	if configPath[:1] == "~" {
-		expanded = filepath.Join(co.homeDir, configPath[1:])
-	} else {
-		expanded = configPath
-	}
+	// Expand the path, rebased under --root
+	expanded := paths.Expand(configPath)
 
 	// Cache the result
 	co.pathMutex.Lock()
@@ -16,7 +16,10 @@ import (
 	"github.com/mrtkrcm/ZeroUI/internal/appconfig"
 	"github.com/mrtkrcm/ZeroUI/internal/errors"
 	"github.com/mrtkrcm/ZeroUI/internal/logger"
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
+	"github.com/mrtkrcm/ZeroUI/internal/policy"
 	"github.com/mrtkrcm/ZeroUI/internal/recovery"
+	"github.com/mrtkrcm/ZeroUI/internal/validation"
 	"github.com/mrtkrcm/ZeroUI/pkg/configextractor"
 	"github.com/spf13/viper"
 )
@@ -33,9 +36,10 @@ type ConfigLoader interface {
 type Engine struct {
 	loader    ConfigLoader
 	logger    *logger.Logger
-	homeDir   string                     // Cache for home directory
 	pathCache *lru.Cache[string, string] // LRU cache for expanded paths (prevents memory leak)
 	pathMutex sync.RWMutex               // Thread-safe access to pathCache
+	validator *validation.Validator      // Optional schema rules enforced on changes
+	policy    *policy.Policy             // Organization policy; nil reads the system policy
 }
 
 // NewEngine creates a new toggle engine (backwards compatibility)
@@ -50,31 +54,26 @@ func NewEngine() (*Engine, error) {
 		}
 		// Use basic loader as ConfigLoader interface
 		var loader ConfigLoader = basicLoader
-		homeDir, _ := os.UserHomeDir()
 		pathCache, _ := lru.New[string, string](1000)
 		return &Engine{
 			loader:    loader,
 			logger:    logger.Global(),
-			homeDir:   homeDir,
 			pathCache: pathCache,
 		}, nil
 	}
 
 	// Use enhanced loader as ConfigLoader interface
 	var loader ConfigLoader = enhancedLoader
-	homeDir, _ := os.UserHomeDir()
 	pathCache, _ := lru.New[string, string](1000) // 1000 entry limit prevents memory leak
 	return &Engine{
 		loader:    loader,
 		logger:    logger.Global(), // Use global logger for backwards compatibility
-		homeDir:   homeDir,
 		pathCache: pathCache,
 	}, nil
 }
 
 // NewEngineWithDeps creates a new toggle engine with injected dependencies
 func NewEngineWithDeps(configLoader ConfigLoader, log *logger.Logger) *Engine {
-	homeDir, _ := os.UserHomeDir()
 	pathCache, _ := lru.New[string, string](1000) // 1000 entry limit prevents memory leak
 	return &Engine{
 		loader: configLoader,
@@ -84,13 +83,18 @@ func NewEngineWithDeps(configLoader ConfigLoader, log *logger.Logger) *Engine {
 			}
 			return logger.New(logger.DefaultConfig())
 		}(),
-		homeDir:   homeDir,
 		pathCache: pathCache,
 	}
 }
 
 // Toggle sets a specific configuration key to a value
 func (e *Engine) Toggle(appName, key, value string) error {
+	return e.ToggleWithOptions(appName, key, value, ToggleOptions{})
+}
+
+// ToggleWithOptions sets a configuration key to a value, enforcing schema rules
+// on the resulting config before it is written
+func (e *Engine) ToggleWithOptions(appName, key, value string, opts ToggleOptions) error {
 	log := e.logger.WithApp(appName).WithField(key)
 
 	if viper.GetBool("verbose") {
@@ -136,6 +140,13 @@ func (e *Engine) Toggle(appName, key, value string) error {
 			WithApp(appName).WithField(key).WithValue(value)
 	}
 
+	// Hold the config for the whole read-modify-write
+	lock, err := e.lockConfig(appConfig)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
 	// Load target config
 	targetConfig, err := e.loader.LoadTargetConfig(appConfig)
 	if err != nil {
@@ -144,12 +155,29 @@ func (e *Engine) Toggle(appName, key, value string) error {
 			WithSuggestions("Check if the config file exists and is readable")
 	}
 
+	before := targetConfig.All()
+
 	// Set the value
 	if err := targetConfig.Set(key, convertedValue); err != nil {
 		return errors.Wrap(errors.ConfigWriteError, "failed to set config value", err).
 			WithApp(appName).WithField(key).WithValue(value)
 	}
 
+	// Check schema dependencies and conflicts for the whole post-change state
+	fixed, err := e.enforceSchemaRules(appConfig, before, targetConfig, key, value, opts.Fix)
+	if err != nil {
+		return err
+	}
+	if len(fixed) > 0 {
+		log.Info("Setting required companion values", map[string]interface{}{
+			"fixed": fixed,
+		})
+	}
+
+	if err := e.enforcePolicy(appName, before, targetConfig.All()); err != nil {
+		return err
+	}
+
 	if viper.GetBool("dry-run") {
 		log.Info("Would set configuration", map[string]interface{}{
 			"converted_value": convertedValue,
@@ -168,13 +196,7 @@ func (e *Engine) Toggle(appName, key, value string) error {
 
 	// Save the config
 	if err := e.loader.SaveTargetConfig(appConfig, targetConfig); err != nil {
-		// Rollback on failure
-		if rollbackErr := safeOp.Rollback(); rollbackErr != nil {
-			log.Error("Failed to rollback changes", rollbackErr)
-		}
-		return errors.Wrap(errors.ConfigWriteError, "failed to save config", err).
-			WithApp(appName).
-			WithSuggestions("Check file permissions and disk space", "Configuration has been rolled back")
+		return e.failedSave(safeOp, appName, err)
 	}
 
 	// Commit the operation (remove backup)
@@ -191,6 +213,8 @@ func (e *Engine) Toggle(appName, key, value string) error {
 		"value": value,
 	})
 
+	// Release before hooks, which may run zeroui themselves
+	lock.Release()
 	// Run post-toggle hooks
 	return e.runHooks(appConfig, "post-toggle")
 }
@@ -217,6 +241,23 @@ func (e *Engine) Cycle(appName, key string) error {
 		return fmt.Errorf("field %s has no predefined values to cycle through", key)
 	}
 
+	// Only cycle through the values the organization policy allows
+	orgPolicy, err := e.orgPolicy()
+	if err != nil {
+		return err
+	}
+	values := orgPolicy.Allowed(appName, key, fieldConfig.Values)
+	if len(values) == 0 {
+		return orgPolicy.Check(appName, key, fieldConfig.Values[0])
+	}
+
+	// Hold the config for the whole read-modify-write
+	lock, err := e.lockConfig(appConfig)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
 	// Load current config to get current value
 	targetConfig, err := e.loader.LoadTargetConfig(appConfig)
 	if err != nil {
@@ -227,7 +268,7 @@ func (e *Engine) Cycle(appName, key string) error {
 
 	// Find current value index
 	currentIndex := -1
-	for i, value := range fieldConfig.Values {
+	for i, value := range values {
 		if value == currentValue {
 			currentIndex = i
 			break
@@ -235,8 +276,8 @@ func (e *Engine) Cycle(appName, key string) error {
 	}
 
 	// Get next value (wrap around)
-	nextIndex := (currentIndex + 1) % len(fieldConfig.Values)
-	nextValue := fieldConfig.Values[nextIndex]
+	nextIndex := (currentIndex + 1) % len(values)
+	nextValue := values[nextIndex]
 
 	// Convert value to appropriate type
 	convertedValue, err := e.convertValue(nextValue, fieldConfig.Type)
@@ -244,9 +285,18 @@ func (e *Engine) Cycle(appName, key string) error {
 		return fmt.Errorf("failed to convert value: %w", err)
 	}
 
+	before := targetConfig.All()
+
 	// Set the value
 	_ = targetConfig.Set(key, convertedValue)
 
+	if _, err := e.enforceSchemaRules(appConfig, before, targetConfig, key, nextValue, false); err != nil {
+		return err
+	}
+	if err := e.enforcePolicy(appName, before, targetConfig.All()); err != nil {
+		return err
+	}
+
 	if viper.GetBool("dry-run") {
 		log.Info("Would cycle configuration", map[string]interface{}{
 			"from": currentValue,
@@ -266,13 +316,7 @@ func (e *Engine) Cycle(appName, key string) error {
 
 	// Save the config
 	if err := e.loader.SaveTargetConfig(appConfig, targetConfig); err != nil {
-		// Rollback on failure
-		if rollbackErr := safeOp.Rollback(); rollbackErr != nil {
-			log.Error("Failed to rollback changes", rollbackErr)
-		}
-		return errors.Wrap(errors.ConfigWriteError, "failed to save config", err).
-			WithApp(appName).
-			WithSuggestions("Check file permissions and disk space", "Configuration has been rolled back")
+		return e.failedSave(safeOp, appName, err)
 	}
 
 	// Commit the operation (remove backup)
@@ -290,6 +334,8 @@ func (e *Engine) Cycle(appName, key string) error {
 		"to":   nextValue,
 	})
 
+	// Release before hooks, which may run zeroui themselves
+	lock.Release()
 	// Run post-toggle hooks
 	return e.runHooks(appConfig, "post-cycle")
 }
@@ -321,6 +367,13 @@ func (e *Engine) AppendConfiguration(appName, key, value string) error {
 		return errors.NewFieldNotFoundError(appName, key, availableFields)
 	}
 
+	// Hold the config for the whole read-modify-write
+	lock, err := e.lockConfig(appConfig)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
 	// Load target config
 	targetConfig, err := e.loader.LoadTargetConfig(appConfig)
 	if err != nil {
@@ -329,6 +382,8 @@ func (e *Engine) AppendConfiguration(appName, key, value string) error {
 			WithSuggestions("Check if the config file exists and is readable")
 	}
 
+	before := targetConfig.All()
+
 	// Get current value
 	currentVal := targetConfig.Get(key)
 	var newList []interface{}
@@ -366,6 +421,10 @@ func (e *Engine) AppendConfiguration(appName, key, value string) error {
 			WithApp(appName).WithField(key).WithValue(value)
 	}
 
+	if err := e.enforcePolicy(appName, before, targetConfig.All()); err != nil {
+		return err
+	}
+
 	if viper.GetBool("dry-run") {
 		log.Info("Would append configuration", map[string]interface{}{
 			"value": value,
@@ -384,13 +443,7 @@ func (e *Engine) AppendConfiguration(appName, key, value string) error {
 
 	// Save the config
 	if err := e.loader.SaveTargetConfig(appConfig, targetConfig); err != nil {
-		// Rollback on failure
-		if rollbackErr := safeOp.Rollback(); rollbackErr != nil {
-			log.Error("Failed to rollback changes", rollbackErr)
-		}
-		return errors.Wrap(errors.ConfigWriteError, "failed to save config", err).
-			WithApp(appName).
-			WithSuggestions("Check file permissions and disk space", "Configuration has been rolled back")
+		return e.failedSave(safeOp, appName, err)
 	}
 
 	// Commit the operation (remove backup)
@@ -407,6 +460,8 @@ func (e *Engine) AppendConfiguration(appName, key, value string) error {
 		"value": value,
 	})
 
+	// Release before hooks, which may run zeroui themselves
+	lock.Release()
 	// Run post-toggle hooks (reusing same hook type for now or add new one)
 	return e.runHooks(appConfig, "post-toggle")
 }
@@ -427,6 +482,13 @@ func (e *Engine) RemoveConfiguration(appName, key, value string) error {
 		return errors.NewAppNotFoundError(appName, apps)
 	}
 
+	// Hold the config for the whole read-modify-write
+	lock, err := e.lockConfig(appConfig)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
 	// Load target config
 	targetConfig, err := e.loader.LoadTargetConfig(appConfig)
 	if err != nil {
@@ -435,6 +497,8 @@ func (e *Engine) RemoveConfiguration(appName, key, value string) error {
 			WithSuggestions("Check if the config file exists and is readable")
 	}
 
+	before := targetConfig.All()
+
 	// Get current value
 	currentVal := targetConfig.Get(key)
 	if currentVal == nil {
@@ -490,6 +554,10 @@ func (e *Engine) RemoveConfiguration(appName, key, value string) error {
 		}
 	}
 
+	if err := e.enforcePolicy(appName, before, targetConfig.All()); err != nil {
+		return err
+	}
+
 	if viper.GetBool("dry-run") {
 		log.Info("Would remove configuration", map[string]interface{}{
 			"value": value,
@@ -508,13 +576,7 @@ func (e *Engine) RemoveConfiguration(appName, key, value string) error {
 
 	// Save the config
 	if err := e.loader.SaveTargetConfig(appConfig, targetConfig); err != nil {
-		// Rollback on failure
-		if rollbackErr := safeOp.Rollback(); rollbackErr != nil {
-			log.Error("Failed to rollback changes", rollbackErr)
-		}
-		return errors.Wrap(errors.ConfigWriteError, "failed to save config", err).
-			WithApp(appName).
-			WithSuggestions("Check file permissions and disk space", "Configuration has been rolled back")
+		return e.failedSave(safeOp, appName, err)
 	}
 
 	// Commit the operation (remove backup)
@@ -531,6 +593,8 @@ func (e *Engine) RemoveConfiguration(appName, key, value string) error {
 		"value": value,
 	})
 
+	// Release before hooks, which may run zeroui themselves
+	lock.Release()
 	// Run post-toggle hooks
 	return e.runHooks(appConfig, "post-toggle")
 }
@@ -559,12 +623,21 @@ func (e *Engine) ApplyPreset(appName, presetName string) error {
 		return errors.NewPresetNotFoundError(appName, presetName, availablePresets)
 	}
 
+	// Hold the config for the whole read-modify-write
+	lock, err := e.lockConfig(appConfig)
+	if err != nil {
+		return err
+	}
+	defer lock.Release()
+
 	// Load target config
 	targetConfig, err := e.loader.LoadTargetConfig(appConfig)
 	if err != nil {
 		return fmt.Errorf("failed to load target config: %w", err)
 	}
 
+	before := targetConfig.All()
+
 	// Apply all values from the preset
 	for key, value := range preset.Values {
 		fieldConfig, exists := appConfig.Fields[key]
@@ -588,6 +661,13 @@ func (e *Engine) ApplyPreset(appName, presetName string) error {
 		_ = targetConfig.Set(key, convertedValue)
 	}
 
+	if err := e.enforcePolicy(appName, before, targetConfig.All()); err != nil {
+		if zerr, ok := errors.GetZeroUIError(err); ok {
+			return zerr.WithContext("preset", presetName)
+		}
+		return err
+	}
+
 	if viper.GetBool("dry-run") {
 		log.Info("Would apply preset", map[string]interface{}{
 			"values": preset.Values,
@@ -606,13 +686,7 @@ func (e *Engine) ApplyPreset(appName, presetName string) error {
 
 	// Save the config
 	if err := e.loader.SaveTargetConfig(appConfig, targetConfig); err != nil {
-		// Rollback on failure
-		if rollbackErr := safeOp.Rollback(); rollbackErr != nil {
-			log.Error("Failed to rollback changes", rollbackErr)
-		}
-		return errors.Wrap(errors.ConfigWriteError, "failed to save config", err).
-			WithApp(appName).
-			WithSuggestions("Check file permissions and disk space", "Configuration has been rolled back")
+		return e.failedSave(safeOp, appName, err)
 	}
 
 	// Commit the operation (remove backup)
@@ -632,6 +706,8 @@ func (e *Engine) ApplyPreset(appName, presetName string) error {
 		})
 	}
 
+	// Release before hooks, which may run zeroui themselves
+	lock.Release()
 	// Run post-preset hooks
 	return e.runHooks(appConfig, "post-preset")
 }
@@ -971,13 +1047,8 @@ func (e *Engine) expandPath(path string) string {
 	}
 	e.pathMutex.RUnlock()
 
-	// Expand path
-	var expanded string
-	if strings.HasPrefix(path, "~") {
-		expanded = strings.Replace(path, "~", e.homeDir, 1)
-	} else {
-		expanded = path
-	}
+	// Expand path, rebased under --root
+	expanded := paths.Expand(path)
 
 	// Cache the result with write lock
 	e.pathMutex.Lock()
@@ -1,14 +1,27 @@
 package toggle
 
 import (
+	"encoding/json"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/mrtkrcm/ZeroUI/internal/appconfig"
+	"github.com/mrtkrcm/ZeroUI/internal/audit"
 	"github.com/mrtkrcm/ZeroUI/internal/errors"
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
+	"github.com/mrtkrcm/ZeroUI/internal/policy"
+	"github.com/mrtkrcm/ZeroUI/internal/validation"
+	"github.com/mrtkrcm/ZeroUI/pkg/configmerge"
+	"github.com/mrtkrcm/ZeroUI/pkg/reference"
+	"github.com/mrtkrcm/ZeroUI/test/helpers"
 )
 
+func TestMain(m *testing.M) {
+	helpers.RunTestMainWithCleanup(m, "internal/toggle", "zeroui-internal-toggle-test-home-", nil)
+}
+
 // setupTestEngine creates a test engine with a temporary config directory
 func setupTestEngine(t testing.TB) (*Engine, string, func()) {
 	tmpDir, err := os.MkdirTemp("", "configtoggle-test")
@@ -741,3 +754,438 @@ func TestEngine_NewEngineError(t *testing.T) {
 		}
 	})
 }
+
+// TestEngine_ToggleSchemaRules tests that schema dependencies and conflicts are enforced before writing
+func TestEngine_ToggleSchemaRules(t *testing.T) {
+	engine, tmpDir, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	validator := validation.NewValidator()
+	validator.RegisterSchema(&validation.Schema{
+		Name: "test-app",
+		Fields: map[string]*validation.FieldRule{
+			"theme":     {Type: "string"},
+			"debug":     {Type: "boolean", Dependencies: []string{"log-level"}},
+			"log-level": {Type: "string", Default: "info"},
+			"font-size": {Type: "number", ConflictsWith: []string{"font-scale"}},
+		},
+	})
+	engine.SetValidator(validator)
+
+	targetPath := filepath.Join(tmpDir, "target", "appconfig.json")
+	writeTarget := func(content string) {
+		t.Helper()
+		if err := os.WriteFile(targetPath, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write target config: %v", err)
+		}
+	}
+	readTarget := func() map[string]interface{} {
+		t.Helper()
+		data, err := os.ReadFile(targetPath)
+		if err != nil {
+			t.Fatalf("Failed to read target config: %v", err)
+		}
+		var out map[string]interface{}
+		if err := json.Unmarshal(data, &out); err != nil {
+			t.Fatalf("Failed to parse target config: %v", err)
+		}
+		return out
+	}
+
+	// Missing dependency is rejected and nothing is written
+	writeTarget(`{"theme": "dark"}`)
+	err := engine.Toggle("test-app", "debug", "true")
+	ctErr, ok := err.(*errors.ZeroUIError)
+	if !ok || ctErr.Type != errors.ValidationError {
+		t.Fatalf("Expected ValidationError, got %v", err)
+	}
+	if !strings.Contains(ctErr.Message, "debug requires log-level") {
+		t.Errorf("Expected dependency explanation, got %q", ctErr.Message)
+	}
+	if len(ctErr.Suggestions) == 0 || !strings.Contains(ctErr.Suggestions[0], "--fix") {
+		t.Errorf("Expected --fix suggestion, got %v", ctErr.Suggestions)
+	}
+	if _, exists := readTarget()["debug"]; exists {
+		t.Error("Config should not be written when a dependency is violated")
+	}
+
+	// Fix sets the companion key from the schema default
+	if err := engine.ToggleWithOptions("test-app", "debug", "true", ToggleOptions{Fix: true}); err != nil {
+		t.Fatalf("Expected fix to succeed: %v", err)
+	}
+	if got := readTarget(); got["debug"] != true || got["log-level"] != "info" {
+		t.Errorf("Expected debug and log-level to be set, got %v", got)
+	}
+
+	// Conflicts are explained and cannot be fixed automatically
+	writeTarget(`{"theme": "dark", "font-scale": 1.2}`)
+	err = engine.ToggleWithOptions("test-app", "font-size", "16", ToggleOptions{Fix: true})
+	ctErr, ok = err.(*errors.ZeroUIError)
+	if !ok || !strings.Contains(ctErr.Message, "font-size conflicts with font-scale") {
+		t.Fatalf("Expected conflict error, got %v", err)
+	}
+
+	// Violations that already existed do not block unrelated changes
+	writeTarget(`{"theme": "dark", "debug": true}`)
+	if err := engine.Toggle("test-app", "theme", "light"); err != nil {
+		t.Errorf("Pre-existing violation should not block toggle: %v", err)
+	}
+}
+
+func TestEngine_Migrate(t *testing.T) {
+	engine, tmpDir, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	targetPath := filepath.Join(tmpDir, "target", "appconfig.json")
+	if err := os.WriteFile(targetPath, []byte(`{"colour-theme": "light", "font-size": 14, "debug": "yes", "legacy": 1}`), 0o644); err != nil {
+		t.Fatalf("Failed to write target config: %v", err)
+	}
+
+	ref := &reference.ConfigReference{
+		AppName: "test-app",
+		Deprecations: map[string]reference.Deprecation{
+			"colour-theme": {ReplacedBy: "theme"},
+			"debug":        {Values: map[string]string{"yes": "true"}},
+			"legacy":       {RemovedIn: "2.0"},
+		},
+	}
+
+	plan, err := engine.PlanMigration("test-app", ref)
+	if err != nil {
+		t.Fatalf("PlanMigration failed: %v", err)
+	}
+	if len(plan.Migrations) != 3 || plan.Path != targetPath {
+		t.Fatalf("Unexpected plan: %+v", plan)
+	}
+
+	if err := engine.ApplyMigration(plan); err != nil {
+		t.Fatalf("ApplyMigration failed: %v", err)
+	}
+
+	data, err := os.ReadFile(targetPath)
+	if err != nil {
+		t.Fatalf("Failed to read target config: %v", err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Failed to parse target config: %v", err)
+	}
+
+	want := map[string]interface{}{"theme": "light", "font-size": float64(14), "debug": "true"}
+	if len(got) != len(want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("Expected %s = %v, got %v", key, value, got[key])
+		}
+	}
+
+	// Running again finds nothing left to migrate
+	plan, err = engine.PlanMigration("test-app", ref)
+	if err != nil || plan.HasChanges() {
+		t.Errorf("Expected no further migrations, got %+v (err %v)", plan, err)
+	}
+}
+
+func TestEngine_Merge(t *testing.T) {
+	engine, tmpDir, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	targetPath := filepath.Join(tmpDir, "target", "appconfig.json")
+	basePath := filepath.Join(tmpDir, "base.json")
+	theirsPath := filepath.Join(tmpDir, "theirs.json")
+	files := map[string]string{
+		basePath:   `{"theme": "light", "font-size": 14, "debug": false, "extra": 1}`,
+		targetPath: `{"theme": "light", "font-size": 16, "debug": false, "extra": 1}`,
+		theirsPath: `{"theme": "dark", "font-size": 14, "debug": true}`,
+	}
+	for path, content := range files {
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatalf("Failed to write %s: %v", path, err)
+		}
+	}
+
+	plan, err := engine.PlanMerge("test-app", basePath, theirsPath, "")
+	if err != nil {
+		t.Fatalf("PlanMerge failed: %v", err)
+	}
+	if plan.Result.HasConflicts() || strings.Join(plan.Result.Changed, ",") != "debug,extra,theme" {
+		t.Fatalf("Unexpected plan: %+v", plan.Result)
+	}
+
+	if err := engine.ApplyMerge(plan); err != nil {
+		t.Fatalf("ApplyMerge failed: %v", err)
+	}
+
+	data, err := os.ReadFile(targetPath)
+	if err != nil {
+		t.Fatalf("Failed to read target config: %v", err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Failed to parse target config: %v", err)
+	}
+	want := map[string]interface{}{"theme": "dark", "font-size": float64(16), "debug": true}
+	if len(got) != len(want) {
+		t.Errorf("Expected %v, got %v", want, got)
+	}
+	for key, value := range want {
+		if got[key] != value {
+			t.Errorf("Expected %s = %v, got %v", key, value, got[key])
+		}
+	}
+
+	// Changing font-size on both sides conflicts and nothing is written
+	if err := os.WriteFile(theirsPath, []byte(`{"theme": "dark", "font-size": 12, "debug": true}`), 0o644); err != nil {
+		t.Fatalf("Failed to write theirs: %v", err)
+	}
+	plan, err = engine.PlanMerge("test-app", basePath, theirsPath, "")
+	if err != nil {
+		t.Fatalf("PlanMerge failed: %v", err)
+	}
+	err = engine.ApplyMerge(plan)
+	if zerr, ok := errors.GetZeroUIError(err); !ok || zerr.Type != errors.ConfigConflict || zerr.Context["conflicts"] != "font-size" {
+		t.Fatalf("Expected a font-size conflict, got %v", err)
+	}
+	if after, _ := os.ReadFile(targetPath); string(after) != string(data) {
+		t.Error("Expected the config to be left unchanged on conflict")
+	}
+
+	// Preferring theirs settles it
+	plan, err = engine.PlanMerge("test-app", basePath, theirsPath, configmerge.Theirs)
+	if err != nil {
+		t.Fatalf("PlanMerge failed: %v", err)
+	}
+	if err := engine.ApplyMerge(plan); err != nil {
+		t.Fatalf("ApplyMerge failed: %v", err)
+	}
+	if v := plan.Result.Merged["font-size"]; v != float64(12) {
+		t.Errorf("Expected theirs' font-size to win, got %v", v)
+	}
+}
+
+func TestEngine_FormatAndPrune(t *testing.T) {
+	engine, tmpDir, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	targetPath := filepath.Join(tmpDir, "target", "appconfig.json")
+	if err := os.WriteFile(targetPath, []byte(`{"theme": "light",   "font-size": 14, "debug": false}`), 0o644); err != nil {
+		t.Fatalf("Failed to write target config: %v", err)
+	}
+
+	result, err := engine.Format("test-app")
+	if err != nil {
+		t.Fatalf("Format failed: %v", err)
+	}
+	if !result.Changed() {
+		t.Fatal("Expected compact JSON to need formatting")
+	}
+	if err := engine.ApplyFormat(result); err != nil {
+		t.Fatalf("ApplyFormat failed: %v", err)
+	}
+	if result, err = engine.Format("test-app"); err != nil || result.Changed() {
+		t.Errorf("Expected formatted file to be stable, got changed=%v err=%v", result.Changed(), err)
+	}
+
+	plan, err := engine.PlanPrune("test-app")
+	if err != nil {
+		t.Fatalf("PlanPrune failed: %v", err)
+	}
+	if strings.Join(plan.Keys, ",") != "debug,font-size" {
+		t.Fatalf("Expected debug and font-size to be pruned, got %v", plan.Keys)
+	}
+	if err := engine.ApplyPrune(plan); err != nil {
+		t.Fatalf("ApplyPrune failed: %v", err)
+	}
+
+	data, err := os.ReadFile(targetPath)
+	if err != nil {
+		t.Fatalf("Failed to read target config: %v", err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Failed to parse target config: %v", err)
+	}
+	if len(got) != 1 || got["theme"] != "light" {
+		t.Errorf("Expected only the changed theme to remain, got %v", got)
+	}
+}
+
+func TestEngine_ResetAndUnset(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	engine, tmpDir, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	// Mark theme as required so reset writes its default instead of deleting it
+	appPath := filepath.Join(tmpDir, "apps", "test-app.yaml")
+	appData, err := os.ReadFile(appPath)
+	if err != nil {
+		t.Fatalf("Failed to read app config: %v", err)
+	}
+	appData = []byte(strings.Replace(string(appData), `default: "dark"`, "default: \"dark\"\n    required: true", 1))
+	if err := os.WriteFile(appPath, appData, 0o644); err != nil {
+		t.Fatalf("Failed to write app config: %v", err)
+	}
+
+	targetPath := filepath.Join(tmpDir, "target", "appconfig.json")
+	original := `{"theme": "light", "font-size": 18, "debug": true, "extra": "x"}`
+	if err := os.WriteFile(targetPath, []byte(original), 0o644); err != nil {
+		t.Fatalf("Failed to write target config: %v", err)
+	}
+
+	readTarget := func() map[string]interface{} {
+		t.Helper()
+		data, err := os.ReadFile(targetPath)
+		if err != nil {
+			t.Fatalf("Failed to read target config: %v", err)
+		}
+		var got map[string]interface{}
+		if err := json.Unmarshal(data, &got); err != nil {
+			t.Fatalf("Failed to parse target config: %v", err)
+		}
+		return got
+	}
+
+	if _, err := engine.PlanReset("test-app", []string{"extra"}); err == nil {
+		t.Error("Expected reset of a non-field key to fail")
+	}
+
+	plan, err := engine.PlanReset("test-app", []string{"theme", "font-size"})
+	if err != nil {
+		t.Fatalf("PlanReset failed: %v", err)
+	}
+	if strings.Join(plan.Removed, ",") != "font-size" || strings.Join(plan.Defaulted, ",") != "theme" {
+		t.Fatalf("Expected font-size removed and theme defaulted, got removed=%v defaulted=%v", plan.Removed, plan.Defaulted)
+	}
+	if err := engine.ApplyRemoval(plan); err != nil {
+		t.Fatalf("ApplyRemoval failed: %v", err)
+	}
+
+	got := readTarget()
+	if _, ok := got["font-size"]; ok || got["theme"] != "dark" || got["debug"] != true {
+		t.Errorf("Unexpected config after reset: %v", got)
+	}
+
+	backup, err := os.ReadFile(plan.BackupPath)
+	if err != nil {
+		t.Fatalf("Expected reset to keep a backup: %v", err)
+	}
+	if string(backup) != original {
+		t.Errorf("Expected backup to hold the previous file, got %q", backup)
+	}
+
+	if _, err := engine.PlanUnset("test-app", []string{"missing"}); err == nil {
+		t.Error("Expected unset of a key that is not set to fail")
+	}
+
+	plan, err = engine.PlanUnset("test-app", []string{"extra"})
+	if err != nil {
+		t.Fatalf("PlanUnset failed: %v", err)
+	}
+	if err := engine.ApplyRemoval(plan); err != nil {
+		t.Fatalf("ApplyRemoval failed: %v", err)
+	}
+	if _, ok := readTarget()["extra"]; ok {
+		t.Error("Expected extra to be removed")
+	}
+}
+
+// TestEngine_AuditBackup tests that the audit log only refers to backups that
+// are kept after the change
+func TestEngine_AuditBackup(t *testing.T) {
+	t.Setenv("XDG_STATE_HOME", t.TempDir())
+	engine, _, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	lastEntry := func() audit.Entry {
+		t.Helper()
+		data, err := os.ReadFile(paths.AuditLog())
+		if err != nil {
+			t.Fatalf("Failed to read audit log: %v", err)
+		}
+		lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+		var entry audit.Entry
+		if err := json.Unmarshal([]byte(lines[len(lines)-1]), &entry); err != nil {
+			t.Fatalf("Failed to parse audit entry: %v", err)
+		}
+		return entry
+	}
+
+	if err := engine.Toggle("test-app", "theme", "light"); err != nil {
+		t.Fatalf("Toggle failed: %v", err)
+	}
+	if entry := lastEntry(); entry.Backup != "" {
+		t.Errorf("Expected no backup for a toggle, whose backup is removed, got %q", entry.Backup)
+	}
+
+	plan, err := engine.PlanUnset("test-app", []string{"debug"})
+	if err != nil {
+		t.Fatalf("PlanUnset failed: %v", err)
+	}
+	if err := engine.ApplyRemoval(plan); err != nil {
+		t.Fatalf("ApplyRemoval failed: %v", err)
+	}
+	entry := lastEntry()
+	if entry.Backup == "" {
+		t.Fatal("Expected the unset to record its backup")
+	}
+	if _, err := os.Stat(filepath.Join(paths.BackupDir(), entry.Backup)); err != nil {
+		t.Errorf("Expected the recorded backup to exist: %v", err)
+	}
+}
+
+// TestEngine_Policy tests that changes the organization policy forbids are refused
+func TestEngine_Policy(t *testing.T) {
+	engine, tmpDir, cleanup := setupTestEngine(t)
+	defer cleanup()
+
+	p, err := policy.Parse("policy.yaml", []byte(`apps:
+  test-app:
+    theme:
+      allow: [dark, auto]
+    debug:
+      enforce: false
+      reason: Debug output leaks secrets
+`))
+	if err != nil {
+		t.Fatalf("Failed to parse policy: %v", err)
+	}
+	engine.SetPolicy(p)
+
+	targetPath := filepath.Join(tmpDir, "target", "appconfig.json")
+	original, err := os.ReadFile(targetPath)
+	if err != nil {
+		t.Fatalf("Failed to read target config: %v", err)
+	}
+
+	err = engine.Toggle("test-app", "debug", "true")
+	if zerr, ok := errors.GetZeroUIError(err); !ok || zerr.Type != errors.PolicyViolation || zerr.Context["policy"] != "policy.yaml" {
+		t.Fatalf("Expected a policy violation, got %v", err)
+	}
+
+	err = engine.ApplyPreset("test-app", "light-mode")
+	if zerr, ok := errors.GetZeroUIError(err); !ok || zerr.Type != errors.PolicyViolation || zerr.Context["preset"] != "light-mode" || zerr.Field != "theme" {
+		t.Fatalf("Expected the preset to be refused on theme, got %v", err)
+	}
+
+	if data, _ := os.ReadFile(targetPath); string(data) != string(original) {
+		t.Errorf("Refused changes must not be written, got %s", data)
+	}
+
+	// Cycling skips the values the policy doesn't allow
+	if err := engine.Cycle("test-app", "theme"); err != nil {
+		t.Fatalf("Failed to cycle theme: %v", err)
+	}
+	values, err := engine.GetCurrentValues("test-app")
+	if err != nil {
+		t.Fatalf("Failed to read values: %v", err)
+	}
+	if values["theme"] != "auto" {
+		t.Errorf("Expected theme to cycle to auto, got %v", values["theme"])
+	}
+
+	if err := engine.Toggle("test-app", "font-size", "16"); err != nil {
+		t.Errorf("Expected unconstrained keys to change, got %v", err)
+	}
+}
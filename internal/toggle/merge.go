@@ -0,0 +1,149 @@
+package toggle
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/knadh/koanf/v2"
+	"github.com/mrtkrcm/ZeroUI/internal/appconfig"
+	"github.com/mrtkrcm/ZeroUI/internal/errors"
+	"github.com/mrtkrcm/ZeroUI/pkg/configmerge"
+	"github.com/spf13/viper"
+)
+
+// MergePlan holds the result of merging another version of an app's config
+// into the current one. It is created by PlanMerge and written by ApplyMerge.
+type MergePlan struct {
+	App        string
+	Path       string
+	BasePath   string
+	TheirsPath string
+	Format     string
+	// Ours holds the current config's values before the merge
+	Ours   map[string]interface{}
+	Result *configmerge.Result
+
+	appConfig *appconfig.AppConfig
+	target    *koanf.Koanf
+	removed   []string
+}
+
+// HasChanges reports whether the merge changes the current config
+func (p *MergePlan) HasChanges() bool {
+	return len(p.Result.Changed) > 0
+}
+
+// PlanMerge merges the changes from basePath to theirsPath into an app's
+// current config, three ways: keys only theirs changed are taken, keys only the
+// current config changed are kept, and keys both changed differently are
+// conflicts unless prefer settles them. Nothing is written.
+func (e *Engine) PlanMerge(appName, basePath, theirsPath string, prefer configmerge.Side) (*MergePlan, error) {
+	appConfig, err := e.loader.LoadAppConfig(appName)
+	if err != nil {
+		apps, _ := e.loader.ListApps()
+		return nil, errors.NewAppNotFoundError(appName, apps)
+	}
+
+	targetConfig, err := e.loader.LoadTargetConfig(appConfig)
+	if err != nil {
+		return nil, errors.Wrap(errors.ConfigParseError, "failed to load target config", err).
+			WithApp(appName).
+			WithSuggestions("Check if the config file exists and is readable")
+	}
+
+	plan := &MergePlan{
+		App:        appName,
+		Path:       e.expandPath(appConfig.Path),
+		BasePath:   e.expandPath(basePath),
+		TheirsPath: e.expandPath(theirsPath),
+		Format:     appConfig.Format,
+		appConfig:  appConfig,
+		target:     targetConfig,
+	}
+
+	base, err := e.loadMergeInput(appConfig, plan.BasePath)
+	if err != nil {
+		return nil, err
+	}
+	theirs, err := e.loadMergeInput(appConfig, plan.TheirsPath)
+	if err != nil {
+		return nil, err
+	}
+
+	plan.Ours = targetConfig.All()
+	plan.Result = configmerge.Merge(base.All(), plan.Ours, theirs.All(), configmerge.Options{
+		ListIdentity: configmerge.KnownLists,
+		Prefer:       prefer,
+	})
+
+	for _, key := range plan.Result.Changed {
+		value, ok := plan.Result.Merged[key]
+		if !ok {
+			targetConfig.Delete(key)
+			plan.removed = append(plan.removed, key)
+			continue
+		}
+		if err := targetConfig.Set(key, value); err != nil {
+			return nil, errors.Wrap(errors.ConfigWriteError, "failed to set merged value", err).
+				WithApp(appName).WithField(key)
+		}
+	}
+
+	if err := e.enforcePolicy(appName, plan.Ours, targetConfig.All()); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// loadMergeInput parses a base or theirs file in the app's config format
+func (e *Engine) loadMergeInput(appConfig *appconfig.AppConfig, path string) (*koanf.Koanf, error) {
+	k, err := appconfig.LoadConfigFile(path, appConfig.Format)
+	if err != nil {
+		return nil, errors.Wrap(errors.ConfigParseError, fmt.Sprintf("failed to load %s", path), err).
+			WithApp(appConfig.Name).
+			WithSuggestions(fmt.Sprintf("Check that %s exists and is a %s config", path, appConfig.Format))
+	}
+	return k, nil
+}
+
+// ApplyMerge writes a merge plan through the backup-protected save path. Plans
+// with unresolved conflicts are refused.
+func (e *Engine) ApplyMerge(plan *MergePlan) error {
+	log := e.logger.WithApp(plan.App)
+
+	if plan.Result.HasConflicts() {
+		keys := plan.Result.ConflictKeys()
+		return errors.New(errors.ConfigConflict,
+			fmt.Sprintf("merging %s conflicts on %s", plan.TheirsPath, strings.Join(keys, ", "))).
+			WithApp(plan.App).
+			WithContext("conflicts", strings.Join(keys, ",")).
+			WithSuggestions(
+				"Nothing was written",
+				"Resolve the keys in the config, or pass --prefer ours or --prefer theirs",
+			)
+	}
+
+	if !plan.HasChanges() {
+		return nil
+	}
+
+	if viper.GetBool("dry-run") {
+		log.Info("Would merge config", map[string]interface{}{
+			"changed": len(plan.Result.Changed),
+		})
+		return nil
+	}
+
+	err := e.saveWithBackup(plan.App, plan.Path, func() error {
+		return e.saveTargetConfig(plan.appConfig, plan.target, plan.removed)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Success("Config merged", map[string]interface{}{
+		"changed": len(plan.Result.Changed),
+	})
+
+	return e.runHooks(plan.appConfig, "post-toggle")
+}
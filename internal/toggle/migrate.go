@@ -0,0 +1,102 @@
+package toggle
+
+import (
+	"github.com/knadh/koanf/v2"
+	"github.com/mrtkrcm/ZeroUI/internal/appconfig"
+	"github.com/mrtkrcm/ZeroUI/internal/errors"
+	"github.com/mrtkrcm/ZeroUI/pkg/reference"
+	"github.com/spf13/viper"
+)
+
+// MigrationPlan holds the rewrites needed to move an app's config off
+// deprecated settings. It is created by PlanMigration and written by
+// ApplyMigration.
+type MigrationPlan struct {
+	App        string
+	Path       string
+	Migrations []reference.Migration
+
+	appConfig *appconfig.AppConfig
+	target    *koanf.Koanf
+	removed   []string
+}
+
+// HasChanges reports whether the plan rewrites anything
+func (p *MigrationPlan) HasChanges() bool {
+	return len(p.Migrations) > 0
+}
+
+// PlanMigration computes the migrations the reference's deprecations require
+// for an app's current config. Nothing is written.
+func (e *Engine) PlanMigration(appName string, ref *reference.ConfigReference) (*MigrationPlan, error) {
+	appConfig, err := e.loader.LoadAppConfig(appName)
+	if err != nil {
+		apps, _ := e.loader.ListApps()
+		return nil, errors.NewAppNotFoundError(appName, apps)
+	}
+
+	targetConfig, err := e.loader.LoadTargetConfig(appConfig)
+	if err != nil {
+		return nil, errors.Wrap(errors.ConfigParseError, "failed to load target config", err).
+			WithApp(appName).
+			WithSuggestions("Check if the config file exists and is readable")
+	}
+
+	before := targetConfig.All()
+	plan := &MigrationPlan{
+		App:        appName,
+		Path:       e.expandPath(appConfig.Path),
+		Migrations: ref.PlanMigrations(targetConfig.All()),
+		appConfig:  appConfig,
+		target:     targetConfig,
+	}
+
+	for _, m := range plan.Migrations {
+		if m.Renamed() || m.Dropped() {
+			targetConfig.Delete(m.Key)
+			plan.removed = append(plan.removed, m.Key)
+		}
+		if m.Dropped() {
+			continue
+		}
+		if err := targetConfig.Set(m.NewKey, m.NewValue); err != nil {
+			return nil, errors.Wrap(errors.ConfigWriteError, "failed to set migrated value", err).
+				WithApp(appName).WithField(m.NewKey)
+		}
+	}
+
+	if err := e.enforcePolicy(appName, before, targetConfig.All()); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// ApplyMigration writes a migration plan through the backup-protected save path
+func (e *Engine) ApplyMigration(plan *MigrationPlan) error {
+	log := e.logger.WithApp(plan.App)
+
+	if !plan.HasChanges() {
+		return nil
+	}
+
+	if viper.GetBool("dry-run") {
+		log.Info("Would migrate deprecated settings", map[string]interface{}{
+			"migrations": len(plan.Migrations),
+		})
+		return nil
+	}
+
+	err := e.saveWithBackup(plan.App, plan.Path, func() error {
+		return e.saveTargetConfig(plan.appConfig, plan.target, plan.removed)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Success("Deprecated settings migrated", map[string]interface{}{
+		"migrations": len(plan.Migrations),
+	})
+
+	// Migrated values take effect the same way toggled ones do
+	return e.runHooks(plan.appConfig, "post-toggle")
+}
@@ -0,0 +1,31 @@
+package toggle
+
+import (
+	"github.com/mrtkrcm/ZeroUI/internal/policy"
+)
+
+// SetPolicy replaces the organization policy enforced on changes, which is
+// otherwise read from policy.DefaultPath
+func (e *Engine) SetPolicy(p *policy.Policy) {
+	e.policy = p
+}
+
+// orgPolicy returns the organization policy in force
+func (e *Engine) orgPolicy() (*policy.Policy, error) {
+	if e.policy != nil {
+		return e.policy, nil
+	}
+	return policy.Default()
+}
+
+// enforcePolicy refuses a change the organization policy doesn't allow. Only
+// keys the change touches are checked, like schema rules, so a config that
+// already breaks the policy can still be edited elsewhere; doctor reports the
+// existing violations.
+func (e *Engine) enforcePolicy(appName string, before, after map[string]interface{}) error {
+	p, err := e.orgPolicy()
+	if err != nil {
+		return err
+	}
+	return p.CheckChanges(appName, before, after)
+}
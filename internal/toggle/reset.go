@@ -0,0 +1,189 @@
+package toggle
+
+import (
+	"sort"
+
+	"github.com/knadh/koanf/v2"
+	"github.com/mrtkrcm/ZeroUI/internal/appconfig"
+	"github.com/mrtkrcm/ZeroUI/internal/errors"
+	"github.com/spf13/viper"
+)
+
+// RemovalPlan lists settings to delete from an app's config file and required
+// settings to write back with their default. It is created by PlanReset or
+// PlanUnset and written by ApplyRemoval.
+type RemovalPlan struct {
+	App       string
+	Path      string
+	Removed   []string
+	Defaulted []string
+	Values    map[string]interface{} // Current values of the affected keys
+	Defaults  map[string]interface{} // Values written for Defaulted keys
+
+	// BackupPath is the backup of the file before the change, set by ApplyRemoval
+	BackupPath string
+
+	appConfig *appconfig.AppConfig
+	target    *koanf.Koanf
+}
+
+// HasChanges reports whether the plan changes anything
+func (p *RemovalPlan) HasChanges() bool {
+	return len(p.Removed) > 0 || len(p.Defaulted) > 0
+}
+
+// PlanReset returns fields to their default. Each key must be a field of the
+// app; with no keys every field set in the config is reset. Fields marked
+// required are written with their default, all others are deleted from the
+// file. Keys that are not set are skipped. Nothing is written.
+func (e *Engine) PlanReset(appName string, keys []string) (*RemovalPlan, error) {
+	plan, err := e.newRemovalPlan(appName)
+	if err != nil {
+		return nil, err
+	}
+	appConfig := plan.appConfig
+	before := plan.target.All()
+
+	if len(keys) == 0 {
+		for key := range appConfig.Fields {
+			if plan.target.Exists(key) {
+				keys = append(keys, key)
+			}
+		}
+		sort.Strings(keys)
+	}
+
+	for _, key := range keys {
+		field, exists := appConfig.Fields[key]
+		if !exists {
+			return nil, errors.NewFieldNotFoundError(appName, key, fieldNames(appConfig))
+		}
+		if !plan.target.Exists(key) {
+			continue
+		}
+
+		plan.Values[key] = plan.target.Get(key)
+
+		if !field.Required {
+			plan.target.Delete(key)
+			plan.Removed = append(plan.Removed, key)
+			continue
+		}
+
+		if field.Default == nil {
+			return nil, errors.New(errors.ValidationError, "required field has no default to reset to").
+				WithApp(appName).WithField(key).
+				WithSuggestions("Set the value explicitly with 'zeroui toggle'")
+		}
+		if err := plan.target.Set(key, field.Default); err != nil {
+			return nil, errors.Wrap(errors.ConfigWriteError, "failed to set default value", err).
+				WithApp(appName).WithField(key)
+		}
+		plan.Defaulted = append(plan.Defaulted, key)
+		plan.Defaults[key] = field.Default
+	}
+
+	if err := e.enforcePolicy(appName, before, plan.target.All()); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// PlanUnset deletes arbitrary keys from an app's config file, including keys
+// that are not fields of the app. Every key must be set. Nothing is written.
+func (e *Engine) PlanUnset(appName string, keys []string) (*RemovalPlan, error) {
+	if len(keys) == 0 {
+		return nil, errors.New(errors.UserInputError, "no keys to unset").
+			WithApp(appName)
+	}
+
+	plan, err := e.newRemovalPlan(appName)
+	if err != nil {
+		return nil, err
+	}
+	before := plan.target.All()
+
+	for _, key := range keys {
+		if !plan.target.Exists(key) {
+			return nil, errors.New(errors.FieldNotFound, "key is not set in the config file").
+				WithApp(appName).WithField(key).
+				WithSuggestions("Use 'zeroui list values " + appName + "' to see the keys that are set")
+		}
+		plan.Values[key] = plan.target.Get(key)
+		plan.target.Delete(key)
+		plan.Removed = append(plan.Removed, key)
+	}
+
+	if err := e.enforcePolicy(appName, before, plan.target.All()); err != nil {
+		return nil, err
+	}
+	return plan, nil
+}
+
+// ApplyRemoval writes a reset or unset plan. Unlike toggle, the backup taken
+// before the write is kept so the removed settings can be restored with
+// `zeroui backup restore`.
+func (e *Engine) ApplyRemoval(plan *RemovalPlan) error {
+	if !plan.HasChanges() {
+		return nil
+	}
+
+	log := e.logger.WithApp(plan.App)
+	if viper.GetBool("dry-run") {
+		log.Info("Would remove settings", map[string]interface{}{
+			"removed":   plan.Removed,
+			"defaulted": plan.Defaulted,
+		})
+		return nil
+	}
+
+	backupPath, err := e.saveKeepingBackup(plan.App, plan.Path, func() error {
+		return e.saveTargetConfig(plan.appConfig, plan.target, plan.Removed)
+	})
+	if err != nil {
+		return err
+	}
+	plan.BackupPath = backupPath
+
+	log.Success("Settings removed", map[string]interface{}{
+		"removed":   len(plan.Removed),
+		"defaulted": len(plan.Defaulted),
+		"backup":    backupPath,
+	})
+
+	return e.runHooks(plan.appConfig, "post-toggle")
+}
+
+func (e *Engine) newRemovalPlan(appName string) (*RemovalPlan, error) {
+	appConfig, err := e.loader.LoadAppConfig(appName)
+	if err != nil {
+		apps, _ := e.loader.ListApps()
+		return nil, errors.NewAppNotFoundError(appName, apps)
+	}
+
+	targetConfig, err := e.loader.LoadTargetConfig(appConfig)
+	if err != nil {
+		return nil, errors.Wrap(errors.ConfigParseError, "failed to load target config", err).
+			WithApp(appName).
+			WithSuggestions("Check if the config file exists and is readable")
+	}
+
+	return &RemovalPlan{
+		App:       appName,
+		Path:      e.expandPath(appConfig.Path),
+		Values:    make(map[string]interface{}),
+		Defaults:  make(map[string]interface{}),
+		appConfig: appConfig,
+		target:    targetConfig,
+	}, nil
+}
+
+// fieldNames returns the sorted field names of an app
+func fieldNames(appConfig *appconfig.AppConfig) []string {
+	names := make([]string, 0, len(appConfig.Fields))
+	for name := range appConfig.Fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
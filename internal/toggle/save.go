@@ -0,0 +1,128 @@
+package toggle
+
+import (
+	"github.com/knadh/koanf/v2"
+	"github.com/mrtkrcm/ZeroUI/internal/appconfig"
+	"github.com/mrtkrcm/ZeroUI/internal/errors"
+	"github.com/mrtkrcm/ZeroUI/internal/filelock"
+	"github.com/mrtkrcm/ZeroUI/internal/recovery"
+)
+
+// targetSaver is implemented by loaders that can delete keys while saving
+type targetSaver interface {
+	SaveTargetConfigWithOptions(appConfig *appconfig.AppConfig, k *koanf.Koanf, opts appconfig.SaveOptions) error
+}
+
+// targetDataSaver is implemented by loaders that can write raw file contents
+type targetDataSaver interface {
+	SaveTargetData(appConfig *appconfig.AppConfig, data []byte) error
+}
+
+// lockConfig takes the cross-process lock on an app's config file, so another
+// zeroui process can't write it between this operation's read and write
+func (e *Engine) lockConfig(appConfig *appconfig.AppConfig) (*filelock.Lock, error) {
+	return lockPath(appConfig.Name, e.expandPath(appConfig.Path))
+}
+
+func lockPath(appName, configPath string) (*filelock.Lock, error) {
+	lock, err := filelock.Acquire(configPath, filelock.Options{})
+	if err != nil {
+		if zerr, ok := errors.GetZeroUIError(err); ok {
+			return nil, zerr.WithApp(appName)
+		}
+		return nil, err
+	}
+	return lock, nil
+}
+
+// saveTargetConfig saves a config, deleting removed keys when the loader supports it
+func (e *Engine) saveTargetConfig(appConfig *appconfig.AppConfig, k *koanf.Koanf, removed []string) error {
+	if saver, ok := e.loader.(targetSaver); ok && len(removed) > 0 {
+		return saver.SaveTargetConfigWithOptions(appConfig, k, appconfig.SaveOptions{RemoveKeys: removed})
+	}
+	return e.loader.SaveTargetConfig(appConfig, k)
+}
+
+// saveTargetData writes raw contents to an app's config file
+func (e *Engine) saveTargetData(appConfig *appconfig.AppConfig, data []byte) error {
+	saver, ok := e.loader.(targetDataSaver)
+	if !ok {
+		return errors.New(errors.ConfigWriteError, "config loader cannot write raw file contents").
+			WithApp(appConfig.Name)
+	}
+	return saver.SaveTargetData(appConfig, data)
+}
+
+// saveWithBackup runs save inside a safe operation: the file is backed up
+// first and rolled back if save fails
+func (e *Engine) saveWithBackup(appName, configPath string, save func() error) error {
+	_, err := e.runSafeSave(appName, configPath, false, save)
+	return err
+}
+
+// saveKeepingBackup is saveWithBackup for destructive changes: the backup is
+// kept after a successful save so the removed settings can be restored. It
+// returns the backup path, which is empty when the file did not exist.
+func (e *Engine) saveKeepingBackup(appName, configPath string, save func() error) (string, error) {
+	return e.runSafeSave(appName, configPath, true, save)
+}
+
+func (e *Engine) runSafeSave(appName, configPath string, keepBackup bool, save func() error) (string, error) {
+	log := e.logger.WithApp(appName)
+
+	lock, err := lockPath(appName, configPath)
+	if err != nil {
+		return "", err
+	}
+	defer lock.Release()
+
+	safeOp, err := recovery.NewSafeOperation(configPath, appName)
+	if err != nil {
+		return "", errors.Wrap(errors.SystemFileError, "failed to create backup", err).
+			WithApp(appName)
+	}
+
+	if keepBackup {
+		safeOp.Keep()
+	}
+
+	if err := save(); err != nil {
+		return "", e.failedSave(safeOp, appName, err)
+	}
+
+	backupPath := safeOp.BackupPath()
+	if !keepBackup {
+		if err := safeOp.Commit(); err != nil {
+			log.Error("Failed to cleanup backup", err)
+		}
+		backupPath = ""
+	}
+
+	if err := safeOp.Cleanup(5); err != nil {
+		log.Error("Failed to cleanup old backups", err)
+	}
+
+	return backupPath, nil
+}
+
+// failedSave rolls back a failed save and reports it. A save refused because
+// another program changed the file, or holds it, wrote nothing; rolling back
+// then would overwrite that program's changes with the backup.
+func (e *Engine) failedSave(safeOp *recovery.SafeOperation, appName string, err error) error {
+	zerr, ok := errors.GetZeroUIError(err)
+	if ok && (zerr.Type == errors.ConfigConflict || zerr.Type == errors.ConfigLocked) {
+		return zerr.WithApp(appName)
+	}
+
+	if rollbackErr := safeOp.Rollback(); rollbackErr != nil {
+		e.logger.WithApp(appName).Error("Failed to rollback changes", rollbackErr)
+	}
+
+	// The app's validator rejected the change; its message says why
+	if ok && zerr.Type == errors.ValidationError {
+		return zerr.WithApp(appName)
+	}
+	return errors.Wrap(errors.ConfigWriteError, "failed to save config", err).
+		WithApp(appName).
+		WithSuggestions("Check file permissions and disk space", "Configuration has been rolled back")
+}
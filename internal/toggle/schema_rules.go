@@ -0,0 +1,144 @@
+package toggle
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/knadh/koanf/v2"
+	"github.com/mrtkrcm/ZeroUI/internal/appconfig"
+	"github.com/mrtkrcm/ZeroUI/internal/errors"
+	"github.com/mrtkrcm/ZeroUI/internal/validation"
+)
+
+// ToggleOptions controls optional toggle behaviour
+type ToggleOptions struct {
+	// Fix sets companion keys required by schema dependencies to their
+	// defaults instead of rejecting the change
+	Fix bool
+}
+
+// Schema rule codes enforced before a change is written
+var enforcedRuleCodes = map[string]bool{
+	"missing_dependency": true,
+	"field_conflict":     true,
+	"CUSTOM_VALIDATION":  true,
+}
+
+// SetValidator enables enforcement of the validator's schema rules
+// (dependencies, conflicts and custom rules) when values are changed
+func (e *Engine) SetValidator(v *validation.Validator) {
+	e.validator = v
+}
+
+// hasSchema reports whether schema rules apply to an app
+func (e *Engine) hasSchema(appName string) bool {
+	return e.validator != nil && e.validator.HasSchema(appName)
+}
+
+// enforceSchemaRules validates the post-change config against the app's schema.
+// Only violations introduced by the change are reported, so pre-existing problems
+// don't block unrelated edits. With fix set, missing dependencies that have a
+// default are filled in; the keys that were set are returned.
+func (e *Engine) enforceSchemaRules(appConfig *appconfig.AppConfig, before map[string]interface{}, targetConfig *koanf.Koanf, key, value string, fix bool) ([]string, error) {
+	if !e.hasSchema(appConfig.Name) {
+		return nil, nil
+	}
+	schema, _ := e.validator.GetSchema(appConfig.Name)
+
+	baseline := make(map[string]bool)
+	for _, v := range e.ruleViolations(appConfig.Name, before) {
+		baseline[violationID(v)] = true
+	}
+
+	var fixed []string
+	for attempt := 0; ; attempt++ {
+		var introduced []*validation.ValidationError
+		for _, v := range e.ruleViolations(appConfig.Name, targetConfig.All()) {
+			if !baseline[violationID(v)] {
+				introduced = append(introduced, v)
+			}
+		}
+		if len(introduced) == 0 {
+			return fixed, nil
+		}
+
+		// Each pass adds at least one key, so dependency chains are bounded by the schema size
+		progress := false
+		if fix && attempt <= len(schema.Fields) {
+			for _, v := range introduced {
+				if v.Code != "missing_dependency" || targetConfig.Exists(v.Related) {
+					continue
+				}
+				def, ok := dependencyDefault(schema, appConfig, v.Related)
+				if !ok {
+					continue
+				}
+				if err := targetConfig.Set(v.Related, def); err != nil {
+					return nil, errors.Wrap(errors.ConfigWriteError, "failed to set dependent value", err).
+						WithApp(appConfig.Name).WithField(v.Related)
+				}
+				fixed = append(fixed, fmt.Sprintf("%s=%v", v.Related, def))
+				progress = true
+			}
+		}
+		if !progress {
+			return nil, schemaViolationError(appConfig, schema, key, value, introduced, fix)
+		}
+	}
+}
+
+// ruleViolations returns the enforced schema rule errors for a config
+func (e *Engine) ruleViolations(appName string, data map[string]interface{}) []*validation.ValidationError {
+	var violations []*validation.ValidationError
+	for _, v := range e.validator.ValidateTargetConfig(appName, data).Errors {
+		if enforcedRuleCodes[v.Code] {
+			violations = append(violations, v)
+		}
+	}
+	return violations
+}
+
+func violationID(v *validation.ValidationError) string {
+	return v.Code + "\x00" + v.Field + "\x00" + v.Related + "\x00" + v.Message
+}
+
+// dependencyDefault finds the value used to satisfy a missing dependency,
+// preferring the schema default over the app definition default
+func dependencyDefault(schema *validation.Schema, appConfig *appconfig.AppConfig, key string) (interface{}, bool) {
+	if rule, ok := schema.Fields[key]; ok && rule.Default != nil {
+		return rule.Default, true
+	}
+	if field, ok := appConfig.Fields[key]; ok && field.Default != nil {
+		return field.Default, true
+	}
+	return nil, false
+}
+
+// schemaViolationError explains why a change was rejected and how to resolve it
+func schemaViolationError(appConfig *appconfig.AppConfig, schema *validation.Schema, key, value string, violations []*validation.ValidationError, fix bool) error {
+	var problems, suggestions []string
+	for _, v := range violations {
+		switch v.Code {
+		case "missing_dependency":
+			problems = append(problems, fmt.Sprintf("%s requires %s to be set", v.Field, v.Related))
+			if def, ok := dependencyDefault(schema, appConfig, v.Related); ok && !fix {
+				suggestions = append(suggestions, fmt.Sprintf("Rerun with --fix to set %s to its default (%v)", v.Related, def))
+			} else {
+				suggestions = append(suggestions, fmt.Sprintf("Set %s first", v.Related))
+			}
+		case "field_conflict":
+			problems = append(problems, fmt.Sprintf("%s conflicts with %s", v.Field, v.Related))
+			other := v.Related
+			if other == key {
+				other = v.Field
+			}
+			suggestions = append(suggestions, fmt.Sprintf("Remove %s from the config before setting %s", other, key))
+		default:
+			problems = append(problems, fmt.Sprintf("%s: %s", v.Field, v.Message))
+		}
+	}
+
+	return errors.New(errors.ValidationError, "change violates schema rules: "+strings.Join(problems, "; ")).
+		WithApp(appConfig.Name).WithField(key).WithValue(value).
+		WithSuggestions(suggestions...)
+}
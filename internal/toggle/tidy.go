@@ -0,0 +1,180 @@
+package toggle
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/knadh/koanf/v2"
+	"github.com/mrtkrcm/ZeroUI/internal/appconfig"
+	"github.com/mrtkrcm/ZeroUI/internal/errors"
+	"github.com/spf13/viper"
+)
+
+// FormatResult holds an app's config file before and after formatting
+type FormatResult struct {
+	App       string
+	Path      string
+	Original  []byte
+	Formatted []byte
+
+	appConfig *appconfig.AppConfig
+}
+
+// Changed reports whether formatting changes the file
+func (r *FormatResult) Changed() bool {
+	return !bytes.Equal(r.Original, r.Formatted)
+}
+
+// Format computes the canonical layout of an app's config file. Nothing is written.
+func (e *Engine) Format(appName string) (*FormatResult, error) {
+	appConfig, err := e.loader.LoadAppConfig(appName)
+	if err != nil {
+		apps, _ := e.loader.ListApps()
+		return nil, errors.NewAppNotFoundError(appName, apps)
+	}
+
+	configPath := e.expandPath(appConfig.Path)
+	original, err := os.ReadFile(configPath)
+	if err != nil {
+		return nil, errors.Wrap(errors.ConfigNotFound, "failed to read config file", err).
+			WithApp(appName).
+			WithSuggestions("Check if the config file exists and is readable")
+	}
+
+	formatted, err := appconfig.FormatConfigData(appConfig.Format, original)
+	if err != nil {
+		return nil, errors.Wrap(errors.ConfigParseError, "failed to format config", err).
+			WithApp(appName)
+	}
+
+	return &FormatResult{
+		App:       appName,
+		Path:      configPath,
+		Original:  original,
+		Formatted: formatted,
+		appConfig: appConfig,
+	}, nil
+}
+
+// ApplyFormat writes formatted contents through the backup-protected save path
+func (e *Engine) ApplyFormat(result *FormatResult) error {
+	if !result.Changed() {
+		return nil
+	}
+
+	log := e.logger.WithApp(result.App)
+	if viper.GetBool("dry-run") {
+		log.Info("Would format configuration", map[string]interface{}{
+			"path": result.Path,
+		})
+		return nil
+	}
+
+	err := e.saveWithBackup(result.App, result.Path, func() error {
+		// Formatted was derived from Original; don't replace newer edits
+		if current, err := os.ReadFile(result.Path); err == nil && !bytes.Equal(current, result.Original) {
+			return errors.New(errors.ConfigConflict, fmt.Sprintf("%s was changed by another program since it was formatted", result.Path)).
+				WithApp(result.App).
+				WithSuggestions("Nothing was written", "Run the command again to format the current file")
+		}
+		return e.saveTargetData(result.appConfig, result.Formatted)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Success("Configuration formatted")
+	return nil
+}
+
+// PrunePlan lists settings that are explicitly set to their default value
+type PrunePlan struct {
+	App      string
+	Path     string
+	Keys     []string
+	Values   map[string]interface{}
+	Defaults map[string]interface{}
+
+	appConfig *appconfig.AppConfig
+	target    *koanf.Koanf
+}
+
+// HasChanges reports whether the plan removes anything
+func (p *PrunePlan) HasChanges() bool {
+	return len(p.Keys) > 0
+}
+
+// PlanPrune finds settings whose value equals the field default, the
+// complement of what `list changed` reports. Nothing is written.
+func (e *Engine) PlanPrune(appName string) (*PrunePlan, error) {
+	appConfig, err := e.loader.LoadAppConfig(appName)
+	if err != nil {
+		apps, _ := e.loader.ListApps()
+		return nil, errors.NewAppNotFoundError(appName, apps)
+	}
+
+	targetConfig, err := e.loader.LoadTargetConfig(appConfig)
+	if err != nil {
+		return nil, errors.Wrap(errors.ConfigParseError, "failed to load target config", err).
+			WithApp(appName).
+			WithSuggestions("Check if the config file exists and is readable")
+	}
+
+	orgPolicy, err := e.orgPolicy()
+	if err != nil {
+		return nil, err
+	}
+
+	current := targetConfig.All()
+	comparison := appconfig.CompareWithDefaults(appConfig, current)
+
+	plan := &PrunePlan{
+		App:       appName,
+		Path:      e.expandPath(appConfig.Path),
+		Values:    make(map[string]interface{}, len(comparison.AtDefault)),
+		Defaults:  make(map[string]interface{}, len(comparison.AtDefault)),
+		appConfig: appConfig,
+		target:    targetConfig,
+	}
+	for _, key := range comparison.AtDefault {
+		// Keys the organization policy enforces stay explicit
+		if rule, ok := orgPolicy.Rule(appName, key); !ok || rule.Enforce == nil {
+			plan.Keys = append(plan.Keys, key)
+		}
+	}
+	for _, key := range plan.Keys {
+		plan.Values[key] = current[key]
+		plan.Defaults[key] = appConfig.Fields[key].Default
+		targetConfig.Delete(key)
+	}
+
+	return plan, nil
+}
+
+// ApplyPrune removes the planned settings through the backup-protected save path
+func (e *Engine) ApplyPrune(plan *PrunePlan) error {
+	if !plan.HasChanges() {
+		return nil
+	}
+
+	log := e.logger.WithApp(plan.App)
+	if viper.GetBool("dry-run") {
+		log.Info("Would prune default values", map[string]interface{}{
+			"keys": plan.Keys,
+		})
+		return nil
+	}
+
+	err := e.saveWithBackup(plan.App, plan.Path, func() error {
+		return e.saveTargetConfig(plan.appConfig, plan.target, plan.Keys)
+	})
+	if err != nil {
+		return err
+	}
+
+	log.Success("Default values pruned", map[string]interface{}{
+		"keys": len(plan.Keys),
+	})
+	return nil
+}
@@ -128,6 +128,9 @@ func (m *Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case core.ConfigSavedMsg:
 		return m.handleConfigSaved(msg)
 
+	case core.ConfigResetMsg:
+		return m.handleConfigReset(msg)
+
 	case core.PresetAppliedMsg:
 		return m.handlePresetApplied(msg)
 
@@ -284,6 +287,22 @@ func (m *Model) handleStateKeys(msg tea.KeyMsg) tea.Cmd {
 				return util.ErrorMsg{Err: fmt.Errorf("form validation failed")}
 			}
 
+		case key.Matches(msg, m.keyMap.Reset):
+			if m.configEditor != nil {
+				if field := m.configEditor.SelectedKey(); field != "" {
+					m.logger.Info("Resetting field", "app", m.currentApp, "key", field)
+					return m.resetField(m.currentApp, field)
+				}
+			}
+
+		case key.Matches(msg, m.keyMap.Unset):
+			if m.configEditor != nil {
+				if field := m.configEditor.SelectedKey(); field != "" {
+					m.logger.Info("Unsetting field", "app", m.currentApp, "key", field)
+					return m.unsetField(m.currentApp, field)
+				}
+			}
+
 		case msg.String() == "p":
 			// Show preset selector if available
 			if m.presetSel != nil {
@@ -495,6 +514,21 @@ func (m *Model) handleConfigSaved(msg core.ConfigSavedMsg) (tea.Model, tea.Cmd)
 	}
 }
 
+// handleConfigReset updates the form after a field was reset or unset on disk
+func (m *Model) handleConfigReset(msg core.ConfigResetMsg) (tea.Model, tea.Cmd) {
+	if msg.Error != nil {
+		return m.handleError(util.ErrorMsg{Err: msg.Error})
+	}
+
+	m.logger.Info("Field reset", "app", msg.AppName, "key", msg.Key, "backup", msg.BackupPath)
+	if m.configEditor != nil && msg.AppName == m.currentApp {
+		m.configEditor.ResetField(msg.Key, msg.Value)
+	}
+	m.invalidateCache()
+
+	return m, nil
+}
+
 // handlePresetApplied handles preset application
 func (m *Model) handlePresetApplied(msg core.PresetAppliedMsg) (tea.Model, tea.Cmd) {
 	m.logger.Info("Preset applied", "preset", msg.PresetName)
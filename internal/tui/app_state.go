@@ -8,8 +8,11 @@ import (
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"github.com/mrtkrcm/ZeroUI/internal/policy"
+	"github.com/mrtkrcm/ZeroUI/internal/secrets"
 	"github.com/mrtkrcm/ZeroUI/internal/service"
 	app "github.com/mrtkrcm/ZeroUI/internal/tui/components/app"
+	core "github.com/mrtkrcm/ZeroUI/internal/tui/components/core"
 	display "github.com/mrtkrcm/ZeroUI/internal/tui/components/display"
 	forms "github.com/mrtkrcm/ZeroUI/internal/tui/components/forms"
 	ui "github.com/mrtkrcm/ZeroUI/internal/tui/components/ui"
@@ -246,7 +249,7 @@ func (m *Model) loadAppConfigForForm(appName string) error {
 	if targetPath != "" {
 		content, err := os.ReadFile(targetPath)
 		if err == nil {
-			m.configEditor.SetConfigFile(targetPath, string(content))
+			m.configEditor.SetConfigFile(targetPath, secrets.RedactText(string(content)))
 		} else {
 			m.logger.Warn("Failed to read config file for viewing",
 				"path", targetPath,
@@ -254,13 +257,29 @@ func (m *Model) loadAppConfigForForm(appName string) error {
 		}
 	}
 
+	// The organization policy restricts the choices and locks enforced or
+	// forbidden keys; an unreadable policy is reported again on save
+	orgPolicy, policyErr := policy.Default()
+	if policyErr != nil {
+		m.logger.Warn("Failed to load organization policy",
+			"path", policy.DefaultPath(),
+			"error", policyErr.Error())
+	}
+	m.configEditor.SetValueCheck(func(key, value string) error {
+		if policyErr != nil {
+			return policyErr
+		}
+		return orgPolicy.Check(appName, key, value)
+	})
+
 	// Convert config fields to the format expected by components
 	var configFields []forms.ConfigField
 	for key, field := range appConfig.Fields {
 		configField := forms.ConfigField{
 			Key:         key,
 			Description: field.Description,
-			Required:    false, // AppConfig doesn't carry required info; validator/schema can enrich later
+			Default:     field.Default,
+			Required:    field.Required,
 			IsSet:       false,
 			Source:      "default",
 		}
@@ -307,6 +326,11 @@ func (m *Model) loadAppConfigForForm(appName string) error {
 			}
 		}
 
+		if len(configField.Options) > 0 {
+			configField.Options = orgPolicy.Allowed(appName, key, configField.Options)
+		}
+		configField.Constraint, configField.Locked = orgPolicy.Locked(appName, key)
+
 		configFields = append(configFields, configField)
 	}
 
@@ -334,6 +358,16 @@ func (m *Model) saveConfiguration(appName string, values map[string]interface{})
 	return func() tea.Msg {
 		m.logger.Info("Saving configuration", "app", appName, "values", len(values))
 
+		orgPolicy, err := policy.Default()
+		if err != nil {
+			return util.ErrorMsg{Err: err}
+		}
+		for key, value := range values {
+			if err := orgPolicy.Check(appName, key, value); err != nil {
+				return util.ErrorMsg{Err: err}
+			}
+		}
+
 		// NOTE: Engine-based save not yet implemented - configuration changes are logged but not persisted
 		// For now, just return success (future enhancement needed)
 		_ = values // Suppress unused variable warning
@@ -346,6 +380,43 @@ func (m *Model) saveConfiguration(appName string, values map[string]interface{})
 	}
 }
 
+// resetField returns a field to its default through the config service. The
+// previous file is kept as a backup.
+func (m *Model) resetField(appName, key string) tea.Cmd {
+	return m.removeField(appName, key, false)
+}
+
+// unsetField deletes a field's key from the config file, also for required
+// fields that reset would write the default of. The previous file is kept as
+// a backup.
+func (m *Model) unsetField(appName, key string) tea.Cmd {
+	return m.removeField(appName, key, true)
+}
+
+func (m *Model) removeField(appName, key string, unset bool) tea.Cmd {
+	return func() tea.Msg {
+		if m.configService == nil {
+			return core.ConfigResetMsg{AppName: appName, Key: key, Error: fmt.Errorf("config service not available")}
+		}
+
+		remove := m.configService.ResetConfiguration
+		if unset {
+			remove = m.configService.UnsetConfiguration
+		}
+		plan, err := remove(appName, key)
+		if err != nil {
+			return core.ConfigResetMsg{AppName: appName, Key: key, Error: err}
+		}
+
+		return core.ConfigResetMsg{
+			AppName:    appName,
+			Key:        key,
+			Value:      plan.Defaults[key],
+			BackupPath: plan.BackupPath,
+		}
+	}
+}
+
 // updateComponentSizes updates component sizes based on window dimensions
 func (m *Model) updateComponentSizes() tea.Cmd {
 	// Update styles with new dimensions
@@ -13,6 +13,7 @@ import (
 	"github.com/charmbracelet/lipgloss"
 
 	"github.com/mrtkrcm/ZeroUI/internal/appconfig"
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
 )
 
 // AppStatus represents the status of an application
@@ -308,7 +309,7 @@ func (s *AppScanner) performFallbackScanMsg() tea.Msg {
 
 	s.totalApps = len(knownApps)
 	results := []AppInfo{}
-	home, _ := os.UserHomeDir()
+	home := paths.Home()
 
 	for i, app := range knownApps {
 		s.currentApp = i
@@ -369,7 +370,7 @@ func (s *AppScanner) FindApp(name string) (*AppInfo, bool) {
 func (s *AppScanner) RescanApp(name string) tea.Cmd {
 	return func() tea.Msg {
 		// Check config for specific app
-		home, _ := os.UserHomeDir()
+		home := paths.Home()
 
 		// Update the app info
 		for i, app := range s.apps {
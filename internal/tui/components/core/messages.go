@@ -158,6 +158,16 @@ type (
 		Error   error
 	}
 
+	// ConfigResetMsg is sent when a field is reset to its default or unset.
+	// Value is nil when the key was removed from the file.
+	ConfigResetMsg struct {
+		AppName    string
+		Key        string
+		Value      interface{}
+		BackupPath string
+		Error      error
+	}
+
 	// PresetAppliedMsg is sent when a preset is applied
 	PresetAppliedMsg struct {
 		AppName    string
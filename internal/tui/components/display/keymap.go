@@ -179,6 +179,7 @@ func (k EnhancedKeyMap) GetContextualHelp(context string) []HelpItem {
 			{"Tab", "Next field"},
 			{"Ctrl+S", "Save config"},
 			{"Ctrl+R", "Reset field"},
+			{"Ctrl+X", "Unset field"},
 			{"Esc", "Back to grid"},
 			{"?", "Toggle help"},
 		}
@@ -8,6 +8,7 @@ import (
 	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/mrtkrcm/ZeroUI/internal/secrets"
 	"github.com/mrtkrcm/ZeroUI/internal/tui/animations"
 	"github.com/mrtkrcm/ZeroUI/internal/tui/feedback"
 	"github.com/mrtkrcm/ZeroUI/internal/tui/help"
@@ -37,8 +38,9 @@ type SimpleConfigModel struct {
 	allFields   []ConfigField // Original unfiltered fields
 
 	// Values
-	values  map[string]string
-	changed map[string]bool
+	values     map[string]string
+	changed    map[string]bool
+	valueCheck func(key, value string) error
 
 	// UI state
 	showHelp    bool
@@ -108,6 +110,33 @@ func (m *SimpleConfigModel) SetFields(fields []ConfigField) {
 	m.updateValues()
 }
 
+// SetValueCheck sets a check run on every edited value before it is
+// accepted, such as the organization policy
+func (m *SimpleConfigModel) SetValueCheck(check func(key, value string) error) {
+	m.valueCheck = check
+}
+
+// editable reports whether a field may be edited, explaining when it can't
+func (m *SimpleConfigModel) editable(field ConfigField) bool {
+	if field.Locked {
+		m.notifications.ShowWarning(fmt.Sprintf("🔒 %s is %s", field.Key, field.Constraint), 4*time.Second)
+		return false
+	}
+	return true
+}
+
+// acceptable runs the value check on an edit, explaining a rejection
+func (m *SimpleConfigModel) acceptable(key, value string) bool {
+	if m.valueCheck == nil {
+		return true
+	}
+	if err := m.valueCheck(key, value); err != nil {
+		m.notifications.ShowError(err.Error(), 5*time.Second)
+		return false
+	}
+	return true
+}
+
 // SetSize sets the terminal size
 func (m *SimpleConfigModel) SetSize(width, height int) {
 	m.width = width
@@ -321,9 +350,9 @@ func (m *SimpleConfigModel) renderField(index int) string {
 			Foreground(lipgloss.Color("#CDD6F4")) // Default color
 	}
 
-	// Build the field line
+	// Build the field line; secrets stay masked until edited
 	key := field.Key
-	value := currentValue
+	value := secrets.RedactString(key, currentValue)
 
 	if value == "" {
 		value = toString(field.Default)
@@ -338,6 +367,9 @@ func (m *SimpleConfigModel) renderField(index int) string {
 	}
 
 	line := fmt.Sprintf("%s%-20s: %s", prefix, key, value)
+	if field.Locked {
+		line += " 🔒"
+	}
 
 	if m.editing && index == m.cursor {
 		editLine := fmt.Sprintf("%s✏️ %s: %s", prefix[:1], key, m.editInput.View())
@@ -404,6 +436,8 @@ func (m *SimpleConfigModel) renderHelp() string {
 		"│ ↑/↓/j/k    Navigate fields              │",
 		"│ Enter/Space Start editing               │",
 		"│ Esc         Cancel edit / Close help    │",
+		"│ Ctrl+R      Reset field to default      │",
+		"│ Ctrl+X      Remove field from file      │",
 		"│ ?           Toggle this help            │",
 		"│ q/Ctrl+C    Quit                        │",
 		"└─────────────────────────────────────────┘",
@@ -448,6 +482,9 @@ func (m *SimpleConfigModel) startEdit() {
 	}
 
 	field := m.filtered[m.cursor]
+	if !m.editable(field) {
+		return
+	}
 	currentValue := m.getValue(field.Key)
 	if currentValue == "" {
 		currentValue = toString(field.Default)
@@ -464,6 +501,9 @@ func (m *SimpleConfigModel) saveEdit() {
 
 	field := m.filtered[m.cursor]
 	newValue := strings.TrimSpace(m.editInput.Value())
+	if !m.acceptable(field.Key, newValue) {
+		return
+	}
 	m.setValue(field.Key, newValue)
 
 	m.editing = false
@@ -716,6 +756,9 @@ func (m *SimpleConfigModel) startEditingWithAnimation() {
 	}
 
 	field := m.filtered[m.cursor]
+	if !m.editable(field) {
+		return
+	}
 	currentValue := m.getValue(field.Key)
 	if currentValue == "" {
 		currentValue = toString(field.Default)
@@ -745,6 +788,9 @@ func (m *SimpleConfigModel) saveEditWithFeedback() {
 		m.notifications.ShowError("Value cannot be empty", 3*time.Second)
 		return
 	}
+	if !m.acceptable(field.Key, newValue) {
+		return
+	}
 
 	m.setValue(field.Key, newValue)
 	m.editing = false
@@ -982,6 +1028,43 @@ func (m *SimpleConfigModel) GetValues() map[string]interface{} {
 	return result
 }
 
+// SelectedKey returns the key of the field under the cursor, or "" when no
+// field is selected or a field is being edited
+func (m *SimpleConfigModel) SelectedKey() string {
+	if m.editing || len(m.filtered) == 0 {
+		return ""
+	}
+	return m.filtered[m.cursor].Key
+}
+
+// ResetField records that a field was reset on disk. A nil value means the
+// key was removed from the file and the field now shows its default.
+func (m *SimpleConfigModel) ResetField(key string, value interface{}) {
+	delete(m.changed, key)
+	delete(m.values, key)
+	if value != nil {
+		m.values[key] = toString(value)
+	}
+
+	for _, fields := range [][]ConfigField{m.fields, m.allFields, m.filtered} {
+		for i := range fields {
+			if fields[i].Key != key {
+				continue
+			}
+			fields[i].IsSet = value != nil
+			if value != nil {
+				fields[i].Value = value
+				fields[i].Source = "file"
+			} else {
+				fields[i].Value = fields[i].Default
+				fields[i].Source = "default"
+			}
+		}
+	}
+
+	m.notifications.ShowSuccess(fmt.Sprintf("↺ Reset %s", key), 2*time.Second)
+}
+
 // SetConfigFile sets the configuration file path (for backward compatibility)
 func (m *SimpleConfigModel) SetConfigFile(path string, appName string) {
 	// This method is for backward compatibility
@@ -1,6 +1,7 @@
 package forms
 
 import (
+	"fmt"
 	"strings"
 	"testing"
 	"time"
@@ -294,6 +295,48 @@ func TestSaveEditWithValidation(t *testing.T) {
 	}
 }
 
+func TestPolicyConstrainedFields(t *testing.T) {
+	config := NewSimpleConfig("TestApp")
+
+	config.SetFields([]ConfigField{
+		{Key: "commit.gpgsign", Value: "true", Type: "bool", Locked: true, Constraint: "enforced as true by policy.yaml"},
+		{Key: "theme", Value: "dark", Type: "string"},
+	})
+	config.SetValueCheck(func(key, value string) error {
+		if key == "theme" && value != "dark" && value != "light" {
+			return fmt.Errorf("theme may not be %q under organization policy policy.yaml", value)
+		}
+		return nil
+	})
+
+	// Locked fields can't be edited
+	config.startEditingWithAnimation()
+	if config.editing {
+		t.Error("Expected a locked field not to start editing")
+	}
+	if !strings.Contains(config.renderField(0), "🔒") {
+		t.Error("Expected a locked field to be marked")
+	}
+
+	// Values the check refuses are not saved
+	config.cursor = 1
+	config.startEditingWithAnimation()
+	config.editInput.SetValue("blue")
+	config.saveEditWithFeedback()
+	if !config.editing {
+		t.Error("Expected editing to remain true after a refused value")
+	}
+	if config.values["theme"] != "dark" {
+		t.Errorf("Expected theme to stay dark, got %q", config.values["theme"])
+	}
+
+	config.editInput.SetValue("light")
+	config.saveEditWithFeedback()
+	if config.editing || config.values["theme"] != "light" {
+		t.Errorf("Expected an allowed value to be saved, got %q", config.values["theme"])
+	}
+}
+
 func TestNotificationSystem(t *testing.T) {
 	config := NewSimpleConfig("TestApp")
 
@@ -15,6 +15,11 @@ type ConfigField struct {
 	IsSet    bool     // Whether field has been set
 	Source   string   // Source of the field value
 	Options  []string // Available options for select fields
+
+	// Locked fields can't be edited, because a policy enforces or forbids
+	// them; Constraint says why
+	Locked     bool
+	Constraint string
 }
 
 // ValidationResult represents the result of field validation
@@ -23,6 +23,7 @@ type AppKeyMap struct {
 	Save    key.Binding
 	Cancel  key.Binding
 	Reset   key.Binding
+	Unset   key.Binding
 
 	// UI controls
 	Search    key.Binding
@@ -116,6 +117,10 @@ func NewAppKeyMap() AppKeyMap {
 			key.WithKeys("ctrl+r"),
 			key.WithHelp("ctrl+r", "reset"),
 		),
+		Unset: key.NewBinding(
+			key.WithKeys("ctrl+x"),
+			key.WithHelp("ctrl+x", "unset"),
+		),
 
 		// UI controls
 		Search: key.NewBinding(
@@ -215,7 +220,7 @@ func (k AppKeyMap) FullHelp() [][]key.Binding {
 
 		// Actions
 		{k.Select, k.Edit, k.Refresh, k.Save},
-		{k.Search, k.Filter, k.Reset, k.Cancel},
+		{k.Search, k.Filter, k.Reset, k.Unset, k.Cancel},
 
 		// Edit operations
 		{k.Undo, k.Redo, k.ThemeCycle},
@@ -9,6 +9,8 @@ import (
 
 	"github.com/charmbracelet/lipgloss"
 	"github.com/charmbracelet/log"
+
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
 )
 
 // LogLevel represents different log levels
@@ -216,11 +218,7 @@ func mapLogLevel(level LogLevel) log.Level {
 
 // getDefaultLogPath returns the default log file path
 func getDefaultLogPath() string {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "./zeroui.log"
-	}
-	return filepath.Join(homeDir, ".local", "state", "zeroui", "zeroui.log")
+	return paths.LogFile()
 }
 
 // Logging methods with both terminal and file output
@@ -5,6 +5,8 @@ import (
 	"os/exec"
 	"path/filepath"
 	"strings"
+
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
 )
 
 // AppDefinition represents a supported application
@@ -103,25 +105,9 @@ func CheckExecutable(name string) (string, bool) {
 	return path, true
 }
 
-// ExpandPath expands ~ to home directory
+// ExpandPath expands ~ to home directory, rebasing onto the root when one is set
 func ExpandPath(path string) string {
-	if strings.HasPrefix(path, "~/") {
-		home := getHomeDir()
-		return filepath.Join(home, path[2:])
-	}
-	return path
-}
-
-// getHomeDir returns the user's home directory
-func getHomeDir() string {
-	if home := os.Getenv("HOME"); home != "" {
-		return home
-	}
-	// Fallback for Windows
-	if home := os.Getenv("USERPROFILE"); home != "" {
-		return home
-	}
-	return ""
+	return paths.Expand(path)
 }
 
 // GetAppStatuses returns the status of all supported applications
@@ -158,7 +144,7 @@ func fileExists(path string) bool {
 
 // hasZeroUIConfig checks if we have a ZeroUI config for this app
 func hasZeroUIConfig(appName string) bool {
-	// Check if app config exists in ~/.config/zeroui/apps/
-	configPath := filepath.Join(getHomeDir(), ".config", "zeroui", "apps", strings.ToLower(appName)+".yaml")
+	// Check if app config exists in the zeroui config directory's apps/
+	configPath := filepath.Join(paths.ConfigDir(), "apps", strings.ToLower(appName)+".yaml")
 	return fileExists(configPath)
 }
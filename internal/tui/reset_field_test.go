@@ -0,0 +1,62 @@
+package tui
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/mrtkrcm/ZeroUI/internal/appconfig"
+	"github.com/mrtkrcm/ZeroUI/internal/logger"
+	"github.com/mrtkrcm/ZeroUI/internal/service"
+	"github.com/mrtkrcm/ZeroUI/internal/toggle"
+	core "github.com/mrtkrcm/ZeroUI/internal/tui/components/core"
+)
+
+// Reset writes the default of a required field; unset removes the key
+func TestResetAndUnsetField(t *testing.T) {
+	tmpDir := t.TempDir()
+	t.Setenv("XDG_STATE_HOME", filepath.Join(tmpDir, "state"))
+
+	appsDir := filepath.Join(tmpDir, "apps")
+	require.NoError(t, os.MkdirAll(appsDir, 0o755))
+	target := filepath.Join(tmpDir, "ghostty.conf")
+	schema := `name: ghostty
+path: ` + target + `
+format: custom
+fields:
+  font-size:
+    type: number
+    default: 13
+    required: true
+`
+	require.NoError(t, os.WriteFile(filepath.Join(appsDir, "ghostty.yaml"), []byte(schema), 0o644))
+
+	loader, err := appconfig.NewLoader()
+	require.NoError(t, err)
+	loader.SetConfigDir(tmpDir)
+	log := logger.Global()
+	configService := service.NewConfigService(toggle.NewEngineWithDeps(loader, log), loader, log)
+
+	m, err := NewTestModel(configService, "ghostty")
+	require.NoError(t, err)
+
+	require.NoError(t, os.WriteFile(target, []byte("font-size = 15\n"), 0o644))
+	msg, ok := m.resetField("ghostty", "font-size")().(core.ConfigResetMsg)
+	require.True(t, ok)
+	require.NoError(t, msg.Error)
+	assert.EqualValues(t, 13, msg.Value)
+	data, err := os.ReadFile(target)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "font-size = 13")
+
+	msg, ok = m.unsetField("ghostty", "font-size")().(core.ConfigResetMsg)
+	require.True(t, ok)
+	require.NoError(t, msg.Error)
+	assert.Nil(t, msg.Value, "an unset field shows its default")
+	data, err = os.ReadFile(target)
+	require.NoError(t, err)
+	assert.NotContains(t, string(data), "font-size")
+}
@@ -4,15 +4,14 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+
+	"github.com/mrtkrcm/ZeroUI/internal/paths"
 )
 
 // FindConfigPath attempts to find the configuration file for the given application.
 // It searches in common configuration locations.
 func FindConfigPath(appName string) string {
-	home, err := os.UserHomeDir()
-	if err != nil {
-		return ""
-	}
+	home := paths.Home()
 
 	possiblePaths := []string{
 		filepath.Join(home, ".config", appName, "config.yml"),
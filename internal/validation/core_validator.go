@@ -14,6 +14,7 @@ func NewValidator() *Validator {
 	v := &Validator{
 		schemas:  make(map[string]*Schema),
 		validate: validator.New(),
+		rules:    builtinRules(),
 	}
 
 	// Register custom validators
@@ -134,6 +135,7 @@ func (v *Validator) validateBasicConfig(configData map[string]interface{}) *Vali
 // validateConfigWithSchema validates config data against a schema
 func (v *Validator) validateConfigWithSchema(configData map[string]interface{}, schema *Schema) *ValidationResult {
 	result := &ValidationResult{Valid: true}
+	skipCustom := make(map[string]bool)
 
 	// Validate each field
 	for fieldName, value := range configData {
@@ -169,12 +171,15 @@ func (v *Validator) validateConfigWithSchema(configData map[string]interface{},
 			continue
 		}
 
-		// Validate with rule
-		fieldResult := v.validateFieldWithRule(fieldName, value, rule)
+		// Validate with rule; custom rules run below with the whole config
+		fieldResult, checked := v.validateFieldConstraints(fieldName, value, rule)
 		if !fieldResult.Valid {
 			result.Valid = false
 			result.Errors = append(result.Errors, fieldResult.Errors...)
 		}
+		if !checked {
+			skipCustom[fieldName] = true
+		}
 	}
 
 	// Check required fields
@@ -201,6 +206,7 @@ func (v *Validator) validateConfigWithSchema(configData map[string]interface{},
 						Field:   fieldName,
 						Message: fmt.Sprintf("depends on field: %s", dep),
 						Code:    "missing_dependency",
+						Related: dep,
 					})
 				}
 			}
@@ -213,9 +219,18 @@ func (v *Validator) validateConfigWithSchema(configData map[string]interface{},
 						Field:   fieldName,
 						Message: fmt.Sprintf("conflicts with field: %s", conflict),
 						Code:    "field_conflict",
+						Related: conflict,
 					})
 				}
 			}
+
+			// Check custom rules against the whole config
+			if rule.Custom != nil && !skipCustom[fieldName] {
+				if err := v.validateCustomRule(fieldName, value, configData, rule.Custom); err != nil {
+					result.Valid = false
+					result.Errors = append(result.Errors, customRuleError(fieldName, value, err))
+				}
+			}
 		}
 	}
 
@@ -0,0 +1,154 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// RuleFunc implements a named custom validation function
+type RuleFunc func(ctx *RuleContext) error
+
+// RuleContext is passed to custom rule functions
+type RuleContext struct {
+	Field  string
+	Value  interface{}
+	Config map[string]interface{} // Whole config; nil when a field is validated on its own
+	Args   map[string]interface{}
+
+	validator *Validator
+}
+
+// RegisterRule registers a named function that schemas can reference
+// through CustomRule.Function, replacing any existing function of that name
+func (v *Validator) RegisterRule(name string, fn RuleFunc) {
+	v.rules[name] = fn
+}
+
+// HasRule reports whether a custom rule function is registered
+func (v *Validator) HasRule(name string) bool {
+	_, ok := v.rules[name]
+	return ok
+}
+
+// builtinRules returns the custom rule functions available to every validator
+func builtinRules() map[string]RuleFunc {
+	return map[string]RuleFunc{
+		"validate_positive": func(ctx *RuleContext) error {
+			if num, err := convertToFloat64(ctx.Value); err == nil && num <= 0 {
+				return errors.New("value must be positive")
+			}
+			return nil
+		},
+		"validate_non_empty": func(ctx *RuleContext) error {
+			if str, ok := ctx.Value.(string); ok && strings.TrimSpace(str) == "" {
+				return errors.New("value cannot be empty")
+			}
+			return nil
+		},
+		"unique": func(ctx *RuleContext) error {
+			// Delegate to package-level uniqueness helper implemented in uniqueness.go
+			valStr := strings.TrimSpace(fmt.Sprintf("%v", ctx.Value))
+			return ctx.validator.validateUniqueness(ctx.Field, valStr, ctx.Args)
+		},
+		"range": func(ctx *RuleContext) error {
+			num, ok := toExprNumber(ctx.Value)
+			if !ok {
+				return fmt.Errorf("value %v is not a number", ctx.Value)
+			}
+			if min, ok := toExprNumber(ctx.Args["min"]); ok && num < min {
+				return fmt.Errorf("value must be at least %v", ctx.Args["min"])
+			}
+			if max, ok := toExprNumber(ctx.Args["max"]); ok && num > max {
+				return fmt.Errorf("value must be at most %v", ctx.Args["max"])
+			}
+			return nil
+		},
+	}
+}
+
+// validateCustomRule evaluates a custom rule for a field. config may be nil, in
+// which case expressions referencing other keys are treated as not applicable.
+func (v *Validator) validateCustomRule(fieldName string, value interface{}, config map[string]interface{}, custom *CustomRule) error {
+	if custom.Function == "" && custom.Expr == "" {
+		return errors.New("custom rule must define a function or an expression")
+	}
+
+	if custom.When != "" {
+		applies, err := evalRuleExpr(custom.When, value, config)
+		if errors.Is(err, errMissingKey) || (err == nil && !applies) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+
+	if custom.Function != "" {
+		fn, ok := v.rules[custom.Function]
+		if !ok {
+			return errors.New("unknown custom validation function: " + custom.Function)
+		}
+		ctx := &RuleContext{Field: fieldName, Value: value, Config: config, Args: custom.Args, validator: v}
+		if err := fn(ctx); err != nil {
+			if custom.Message != "" {
+				return errors.New(custom.Message)
+			}
+			return err
+		}
+	}
+
+	if custom.Expr != "" {
+		ok, err := evalRuleExpr(custom.Expr, value, config)
+		if errors.Is(err, errMissingKey) {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if !ok {
+			if custom.Message != "" {
+				return errors.New(custom.Message)
+			}
+			return fmt.Errorf("rule failed: %s", custom.Expr)
+		}
+	}
+
+	return nil
+}
+
+// evalRuleExpr compiles (cached) and evaluates a rule expression
+func evalRuleExpr(source string, value interface{}, config map[string]interface{}) (bool, error) {
+	expr, err := CompileExpr(source)
+	if err != nil {
+		return false, fmt.Errorf("invalid rule expression %q: %w", source, err)
+	}
+	ok, err := expr.EvalBool(value, config)
+	if err != nil && !errors.Is(err, errMissingKey) {
+		return false, fmt.Errorf("rule expression %q: %w", source, err)
+	}
+	return ok, err
+}
+
+// compileCustomRule checks a custom rule's expressions so schema errors surface at load time
+func compileCustomRule(custom *CustomRule) error {
+	for _, source := range []string{custom.When, custom.Expr} {
+		if source == "" {
+			continue
+		}
+		if _, err := CompileExpr(source); err != nil {
+			return fmt.Errorf("invalid rule expression %q: %w", source, err)
+		}
+	}
+	return nil
+}
+
+// customRuleError converts a custom rule failure into a validation error
+func customRuleError(fieldName string, value interface{}, err error) *ValidationError {
+	return &ValidationError{
+		Field:   fieldName,
+		Message: err.Error(),
+		Code:    "CUSTOM_VALIDATION",
+		Value:   value,
+	}
+}
@@ -0,0 +1,215 @@
+package validation
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExpr_Eval(t *testing.T) {
+	config := map[string]interface{}{
+		"font-family":   "Terminus",
+		"window-width":  "800",
+		"font.size":     12,
+		"keybind":       []string{"ctrl+a=copy", "ctrl+v=paste"},
+		"cursor-blink":  true,
+		"window-height": 600.0,
+	}
+
+	tests := []struct {
+		expr  string
+		value interface{}
+		want  bool
+	}{
+		{"value >= 6 && value <= 72", 12, true},
+		{"value >= 6 && value <= 72", 90, false},
+		{"font-family in ['Terminus', 'Tamsyn']", nil, true},
+		{"font-family not in ['Terminus', 'Tamsyn']", nil, false},
+		{"value <= window-width / 4", "200", true},
+		{"value <= window-width / 4", "201", false},
+		{"window-height - 100 == 500", nil, true},
+		{"font.size * 2 == 24", nil, true},
+		{"len(keybind) == 2 and contains(keybind, 'ctrl+v=paste')", nil, true},
+		{"!cursor-blink || exists(missing)", nil, false},
+		{"matches(value, '^#[0-9a-f]{6}$')", "#1e1e2e", true},
+		{"startswith(lower(font-family), 'term') && endswith(value, 'x')", "box", true},
+		{"field('font.size') % 5 == 2", nil, true},
+		{"-value < 0", 3, true},
+		{"upper(value) + '!' == 'HI!'", "hi", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			expr, err := CompileExpr(tt.expr)
+			if err != nil {
+				t.Fatalf("compile failed: %v", err)
+			}
+			got, err := expr.EvalBool(tt.value, config)
+			if err != nil {
+				t.Fatalf("eval failed: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestExpr_Errors(t *testing.T) {
+	invalid := []string{
+		"",
+		"value >",
+		"(value == 1",
+		"unknown(value)",
+		"len(a, b)",
+		"value == 'unterminated",
+		"value $ 1",
+		strings.Repeat("(", maxExprDepth+2) + "1" + strings.Repeat(")", maxExprDepth+2),
+		strings.Repeat("a", maxExprLength+1),
+	}
+	for _, source := range invalid {
+		if _, err := CompileExpr(source); err == nil {
+			t.Errorf("expected compile error for %q", source)
+		}
+	}
+
+	expr, err := CompileExpr("other > 1")
+	if err != nil {
+		t.Fatalf("compile failed: %v", err)
+	}
+	if _, err := expr.EvalBool(nil, map[string]interface{}{}); !errors.Is(err, errMissingKey) {
+		t.Errorf("expected missing key error, got %v", err)
+	}
+
+	expr, _ = CompileExpr("value / 0 > 1")
+	if _, err := expr.EvalBool(1, nil); err == nil {
+		t.Error("expected division by zero error")
+	}
+}
+
+func TestValidator_CustomRules(t *testing.T) {
+	v := NewValidator()
+	v.RegisterRule("even", func(ctx *RuleContext) error {
+		if n, ok := toExprNumber(ctx.Value); ok && int(n)%2 != 0 {
+			return errors.New("value must be even")
+		}
+		return nil
+	})
+
+	v.RegisterSchema(&Schema{
+		Name: "term",
+		Fields: map[string]*FieldRule{
+			"font-family": {Type: TypeString},
+			"font-size": {
+				Type: TypeNumber,
+				Custom: &CustomRule{
+					When:    "font-family in ['Terminus', 'Tamsyn']",
+					Expr:    "value >= 6 && value <= 72",
+					Message: "bitmap fonts support sizes 6-72",
+				},
+			},
+			"window-width": {Type: TypeNumber},
+			"window-padding-x": {
+				Type:   TypeNumber,
+				Custom: &CustomRule{Expr: "value <= window-width / 4"},
+			},
+			"tab-width": {
+				Type:   TypeNumber,
+				Custom: &CustomRule{Function: "even"},
+			},
+			"opacity": {
+				Type:   TypeNumber,
+				Custom: &CustomRule{Function: "range", Args: map[string]interface{}{"min": 0, "max": 1}},
+			},
+		},
+	})
+
+	tests := []struct {
+		name   string
+		config map[string]interface{}
+		errors []string
+	}{
+		{
+			name:   "valid",
+			config: map[string]interface{}{"font-family": "Terminus", "font-size": 12, "window-width": 800, "window-padding-x": 100, "tab-width": 4, "opacity": 0.9},
+		},
+		{
+			name:   "condition not met skips rule",
+			config: map[string]interface{}{"font-family": "Fira Code", "font-size": 96},
+		},
+		{
+			name:   "condition met enforces rule",
+			config: map[string]interface{}{"font-family": "Tamsyn", "font-size": 96},
+			errors: []string{"font-size"},
+		},
+		{
+			name:   "cross-field limit",
+			config: map[string]interface{}{"window-width": 800, "window-padding-x": 300},
+			errors: []string{"window-padding-x"},
+		},
+		{
+			name:   "missing referenced key skips rule",
+			config: map[string]interface{}{"window-padding-x": 300},
+		},
+		{
+			name:   "registered functions",
+			config: map[string]interface{}{"tab-width": 3, "opacity": 2},
+			errors: []string{"tab-width", "opacity"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := v.ValidateTargetConfig("term", tt.config)
+
+			var got []string
+			for _, e := range result.Errors {
+				if e.Code != "CUSTOM_VALIDATION" {
+					t.Errorf("unexpected error: %+v", e)
+					continue
+				}
+				got = append(got, e.Field)
+			}
+			if len(got) != len(tt.errors) {
+				t.Fatalf("expected errors on %v, got %v", tt.errors, got)
+			}
+			for _, field := range tt.errors {
+				if !strings.Contains(strings.Join(got, ","), field) {
+					t.Errorf("expected error on %s, got %v", field, got)
+				}
+			}
+			if result.Valid != (len(tt.errors) == 0) {
+				t.Errorf("expected valid=%v", len(tt.errors) == 0)
+			}
+		})
+	}
+
+	result := v.ValidateTargetConfig("term", map[string]interface{}{"font-family": "Terminus", "font-size": 80})
+	if len(result.Errors) != 1 || result.Errors[0].Message != "bitmap fonts support sizes 6-72" {
+		t.Errorf("expected custom message, got %+v", result.Errors)
+	}
+}
+
+func TestValidator_CustomRuleErrors(t *testing.T) {
+	v := NewValidator()
+
+	if err := v.validateCustom("x", 1, &CustomRule{Function: "missing"}); err == nil ||
+		!strings.Contains(err.Error(), "unknown custom validation function") {
+		t.Errorf("expected unknown function error, got %v", err)
+	}
+	if err := v.validateCustom("x", 1, &CustomRule{}); err == nil {
+		t.Error("expected error for empty custom rule")
+	}
+
+	dir := t.TempDir()
+	schemaPath := filepath.Join(dir, "bad.json")
+	schema := `{"name": "bad", "fields": {"size": {"type": "number", "custom": {"expr": "value >"}}}}`
+	if err := os.WriteFile(schemaPath, []byte(schema), 0o644); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+	if err := v.LoadSchema(schemaPath); err == nil || !strings.Contains(err.Error(), "size") {
+		t.Errorf("expected load error naming the field, got %v", err)
+	}
+}
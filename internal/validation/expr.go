@@ -0,0 +1,839 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+)
+
+// Custom rule expressions are a small, side-effect free language for cross-field
+// constraints. An expression can reference the validated field as `value` and any
+// other config key by name, e.g.:
+//
+//	value <= window-width / 4
+//	font-family in ["Terminus", "Tamsyn"] && value >= 6
+//	exists(theme) || !matches(value, "^#")
+//
+// Key names may contain '-' and '.', so binary minus must be surrounded by spaces.
+// Supported operators: || && ! == != < <= > >= in, not in, + - * / %.
+// Functions: exists, len, lower, upper, number, matches, startswith, endswith,
+// contains, field. Evaluation never loops and input size is bounded.
+
+const (
+	maxExprLength = 1024
+	maxExprDepth  = 64
+)
+
+// errMissingKey signals that an expression referenced a key absent from the config;
+// rules that depend on absent keys are not applicable and are skipped
+var errMissingKey = errors.New("referenced key is not set")
+
+// Expr is a compiled custom rule expression
+type Expr struct {
+	source string
+	root   exprNode
+}
+
+var exprCache sync.Map // source -> *Expr
+
+// CompileExpr parses an expression, caching the result for reuse
+func CompileExpr(source string) (*Expr, error) {
+	if cached, ok := exprCache.Load(source); ok {
+		return cached.(*Expr), nil
+	}
+
+	if len(source) > maxExprLength {
+		return nil, fmt.Errorf("expression exceeds %d characters", maxExprLength)
+	}
+
+	tokens, err := lexExpr(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &exprParser{tokens: tokens}
+	root, err := p.parseOr(0)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().kind != tokEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", p.peek().text, p.peek().pos)
+	}
+
+	expr := &Expr{source: source, root: root}
+	exprCache.Store(source, expr)
+	return expr, nil
+}
+
+// String returns the expression source
+func (e *Expr) String() string {
+	return e.source
+}
+
+// Eval evaluates the expression against a config. value is bound to the
+// identifier `value`. It returns errMissingKey when a referenced key is absent.
+func (e *Expr) Eval(value interface{}, config map[string]interface{}) (interface{}, error) {
+	return e.root.eval(&exprEnv{value: value, config: config})
+}
+
+// EvalBool evaluates the expression and reports its truthiness
+func (e *Expr) EvalBool(value interface{}, config map[string]interface{}) (bool, error) {
+	result, err := e.Eval(value, config)
+	if err != nil {
+		return false, err
+	}
+	return truthy(result), nil
+}
+
+type exprEnv struct {
+	value  interface{}
+	config map[string]interface{}
+}
+
+func (env *exprEnv) lookup(name string) (interface{}, bool) {
+	if name == "value" {
+		return env.value, env.value != nil
+	}
+	v, ok := env.config[name]
+	return v, ok && v != nil
+}
+
+// Lexer
+
+type tokenKind int
+
+const (
+	tokEOF tokenKind = iota
+	tokNumber
+	tokString
+	tokIdent
+	tokOp
+)
+
+type exprToken struct {
+	kind tokenKind
+	text string
+	num  float64
+	pos  int
+}
+
+func isIdentStart(r rune) bool {
+	return r == '_' || unicode.IsLetter(r)
+}
+
+func isIdentPart(r rune) bool {
+	return r == '_' || r == '-' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func lexExpr(src string) ([]exprToken, error) {
+	var tokens []exprToken
+	runes := []rune(src)
+
+	for i := 0; i < len(runes); {
+		r := runes[i]
+		switch {
+		case unicode.IsSpace(r):
+			i++
+		case unicode.IsDigit(r) || (r == '.' && i+1 < len(runes) && unicode.IsDigit(runes[i+1])):
+			start := i
+			for i < len(runes) && (unicode.IsDigit(runes[i]) || runes[i] == '.') {
+				i++
+			}
+			text := string(runes[start:i])
+			num, err := strconv.ParseFloat(text, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid number %q at position %d", text, start)
+			}
+			tokens = append(tokens, exprToken{kind: tokNumber, text: text, num: num, pos: start})
+		case r == '"' || r == '\'':
+			start := i
+			quote := r
+			i++
+			var sb strings.Builder
+			for i < len(runes) && runes[i] != quote {
+				if runes[i] == '\\' && i+1 < len(runes) {
+					i++
+				}
+				sb.WriteRune(runes[i])
+				i++
+			}
+			if i >= len(runes) {
+				return nil, fmt.Errorf("unterminated string at position %d", start)
+			}
+			i++
+			tokens = append(tokens, exprToken{kind: tokString, text: sb.String(), pos: start})
+		case isIdentStart(r):
+			start := i
+			for i < len(runes) && isIdentPart(runes[i]) {
+				i++
+			}
+			// A trailing '-' or '.' belongs to the next token, not the identifier
+			for i > start+1 && (runes[i-1] == '-' || runes[i-1] == '.') {
+				i--
+			}
+			tokens = append(tokens, exprToken{kind: tokIdent, text: string(runes[start:i]), pos: start})
+		default:
+			start := i
+			two := ""
+			if i+1 < len(runes) {
+				two = string(runes[i : i+2])
+			}
+			switch two {
+			case "==", "!=", "<=", ">=", "&&", "||":
+				tokens = append(tokens, exprToken{kind: tokOp, text: two, pos: start})
+				i += 2
+				continue
+			}
+			if strings.ContainsRune("<>!+-*/%()[],", r) {
+				tokens = append(tokens, exprToken{kind: tokOp, text: string(r), pos: start})
+				i++
+				continue
+			}
+			return nil, fmt.Errorf("unexpected character %q at position %d", r, start)
+		}
+	}
+
+	return append(tokens, exprToken{kind: tokEOF, pos: len(runes)}), nil
+}
+
+// Parser
+
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+}
+
+func (p *exprParser) peek() exprToken {
+	return p.tokens[p.pos]
+}
+
+func (p *exprParser) next() exprToken {
+	t := p.tokens[p.pos]
+	if t.kind != tokEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *exprParser) isOp(text string) bool {
+	t := p.peek()
+	return t.kind == tokOp && t.text == text
+}
+
+func (p *exprParser) isKeyword(text string) bool {
+	t := p.peek()
+	return t.kind == tokIdent && t.text == text
+}
+
+func (p *exprParser) expect(text string) error {
+	if !p.isOp(text) {
+		t := p.peek()
+		return fmt.Errorf("expected %q at position %d", text, t.pos)
+	}
+	p.next()
+	return nil
+}
+
+func (p *exprParser) parseOr(depth int) (exprNode, error) {
+	if depth > maxExprDepth {
+		return nil, errors.New("expression is nested too deeply")
+	}
+	left, err := p.parseAnd(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("||") || p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "||", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseAnd(depth int) (exprNode, error) {
+	left, err := p.parseNot(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("&&") || p.isKeyword("and") {
+		p.next()
+		right, err := p.parseNot(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &logicalNode{op: "&&", left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseNot(depth int) (exprNode, error) {
+	if p.isOp("!") || (p.isKeyword("not") && !p.nextIsKeyword("in")) {
+		p.next()
+		if depth > maxExprDepth {
+			return nil, errors.New("expression is nested too deeply")
+		}
+		operand, err := p.parseNot(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{operand: operand}, nil
+	}
+	return p.parseCompare(depth)
+}
+
+func (p *exprParser) nextIsKeyword(text string) bool {
+	if p.pos+1 >= len(p.tokens) {
+		return false
+	}
+	t := p.tokens[p.pos+1]
+	return t.kind == tokIdent && t.text == text
+}
+
+func (p *exprParser) parseCompare(depth int) (exprNode, error) {
+	left, err := p.parseAdd(depth)
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	var op string
+	switch {
+	case t.kind == tokOp && (t.text == "==" || t.text == "!=" || t.text == "<" || t.text == "<=" || t.text == ">" || t.text == ">="):
+		op = t.text
+		p.next()
+	case p.isKeyword("in"):
+		op = "in"
+		p.next()
+	case p.isKeyword("not") && p.nextIsKeyword("in"):
+		op = "not in"
+		p.next()
+		p.next()
+	default:
+		return left, nil
+	}
+
+	right, err := p.parseAdd(depth)
+	if err != nil {
+		return nil, err
+	}
+	return &compareNode{op: op, left: left, right: right}, nil
+}
+
+func (p *exprParser) parseAdd(depth int) (exprNode, error) {
+	left, err := p.parseMul(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("+") || p.isOp("-") {
+		op := p.next().text
+		right, err := p.parseMul(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &arithNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseMul(depth int) (exprNode, error) {
+	left, err := p.parseUnary(depth)
+	if err != nil {
+		return nil, err
+	}
+	for p.isOp("*") || p.isOp("/") || p.isOp("%") {
+		op := p.next().text
+		right, err := p.parseUnary(depth)
+		if err != nil {
+			return nil, err
+		}
+		left = &arithNode{op: op, left: left, right: right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) parseUnary(depth int) (exprNode, error) {
+	if p.isOp("-") {
+		p.next()
+		if depth > maxExprDepth {
+			return nil, errors.New("expression is nested too deeply")
+		}
+		operand, err := p.parseUnary(depth + 1)
+		if err != nil {
+			return nil, err
+		}
+		return &arithNode{op: "-", left: &literalNode{value: 0.0}, right: operand}, nil
+	}
+	return p.parsePrimary(depth)
+}
+
+func (p *exprParser) parsePrimary(depth int) (exprNode, error) {
+	t := p.next()
+	switch t.kind {
+	case tokNumber:
+		return &literalNode{value: t.num}, nil
+	case tokString:
+		return &literalNode{value: t.text}, nil
+	case tokIdent:
+		switch t.text {
+		case "true":
+			return &literalNode{value: true}, nil
+		case "false":
+			return &literalNode{value: false}, nil
+		case "null", "nil":
+			return &literalNode{value: nil}, nil
+		}
+		if p.isOp("(") {
+			p.next()
+			var args []exprNode
+			for !p.isOp(")") {
+				arg, err := p.parseOr(depth + 1)
+				if err != nil {
+					return nil, err
+				}
+				args = append(args, arg)
+				if !p.isOp(",") {
+					break
+				}
+				p.next()
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			fn, ok := exprFuncs[t.text]
+			if !ok {
+				return nil, fmt.Errorf("unknown function %q at position %d", t.text, t.pos)
+			}
+			if fn.arity >= 0 && len(args) != fn.arity {
+				return nil, fmt.Errorf("%s expects %d argument(s), got %d", t.text, fn.arity, len(args))
+			}
+			return &callNode{name: t.text, fn: fn, args: args}, nil
+		}
+		return &identNode{name: t.text}, nil
+	case tokOp:
+		switch t.text {
+		case "(":
+			inner, err := p.parseOr(depth + 1)
+			if err != nil {
+				return nil, err
+			}
+			if err := p.expect(")"); err != nil {
+				return nil, err
+			}
+			return inner, nil
+		case "[":
+			var items []exprNode
+			for !p.isOp("]") {
+				item, err := p.parseOr(depth + 1)
+				if err != nil {
+					return nil, err
+				}
+				items = append(items, item)
+				if !p.isOp(",") {
+					break
+				}
+				p.next()
+			}
+			if err := p.expect("]"); err != nil {
+				return nil, err
+			}
+			return &listNode{items: items}, nil
+		}
+	case tokEOF:
+		return nil, errors.New("unexpected end of expression")
+	}
+	return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+}
+
+// AST
+
+type exprNode interface {
+	eval(env *exprEnv) (interface{}, error)
+}
+
+type literalNode struct{ value interface{} }
+
+func (n *literalNode) eval(*exprEnv) (interface{}, error) { return n.value, nil }
+
+type identNode struct{ name string }
+
+func (n *identNode) eval(env *exprEnv) (interface{}, error) {
+	v, ok := env.lookup(n.name)
+	if !ok {
+		return nil, errMissingKey
+	}
+	return normalizeExprValue(v), nil
+}
+
+type listNode struct{ items []exprNode }
+
+func (n *listNode) eval(env *exprEnv) (interface{}, error) {
+	out := make([]interface{}, 0, len(n.items))
+	for _, item := range n.items {
+		v, err := item.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, v)
+	}
+	return out, nil
+}
+
+type notNode struct{ operand exprNode }
+
+func (n *notNode) eval(env *exprEnv) (interface{}, error) {
+	v, err := n.operand.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	return !truthy(v), nil
+}
+
+type logicalNode struct {
+	op          string
+	left, right exprNode
+}
+
+func (n *logicalNode) eval(env *exprEnv) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	if n.op == "&&" && !truthy(left) {
+		return false, nil
+	}
+	if n.op == "||" && truthy(left) {
+		return true, nil
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	return truthy(right), nil
+}
+
+type compareNode struct {
+	op          string
+	left, right exprNode
+}
+
+func (n *compareNode) eval(env *exprEnv) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	switch n.op {
+	case "==":
+		return exprEqual(left, right), nil
+	case "!=":
+		return !exprEqual(left, right), nil
+	case "in", "not in":
+		found, err := exprContains(right, left)
+		if err != nil {
+			return nil, err
+		}
+		return found == (n.op == "in"), nil
+	}
+
+	if lf, lok := toExprNumber(left); lok {
+		if rf, rok := toExprNumber(right); rok {
+			switch n.op {
+			case "<":
+				return lf < rf, nil
+			case "<=":
+				return lf <= rf, nil
+			case ">":
+				return lf > rf, nil
+			case ">=":
+				return lf >= rf, nil
+			}
+		}
+	}
+
+	ls, lok := left.(string)
+	rs, rok := right.(string)
+	if !lok || !rok {
+		return nil, fmt.Errorf("cannot compare %v %s %v", left, n.op, right)
+	}
+	switch n.op {
+	case "<":
+		return ls < rs, nil
+	case "<=":
+		return ls <= rs, nil
+	case ">":
+		return ls > rs, nil
+	default:
+		return ls >= rs, nil
+	}
+}
+
+type arithNode struct {
+	op          string
+	left, right exprNode
+}
+
+func (n *arithNode) eval(env *exprEnv) (interface{}, error) {
+	left, err := n.left.eval(env)
+	if err != nil {
+		return nil, err
+	}
+	right, err := n.right.eval(env)
+	if err != nil {
+		return nil, err
+	}
+
+	if n.op == "+" {
+		if ls, ok := left.(string); ok {
+			if _, isNum := toExprNumber(ls); !isNum {
+				return ls + fmt.Sprintf("%v", right), nil
+			}
+		}
+	}
+
+	lf, lok := toExprNumber(left)
+	rf, rok := toExprNumber(right)
+	if !lok || !rok {
+		return nil, fmt.Errorf("cannot apply %s to %v and %v", n.op, left, right)
+	}
+
+	switch n.op {
+	case "+":
+		return lf + rf, nil
+	case "-":
+		return lf - rf, nil
+	case "*":
+		return lf * rf, nil
+	case "/":
+		if rf == 0 {
+			return nil, errors.New("division by zero")
+		}
+		return lf / rf, nil
+	default:
+		if rf == 0 {
+			return nil, errors.New("division by zero")
+		}
+		return float64(int64(lf) % int64(rf)), nil
+	}
+}
+
+type exprFunc struct {
+	arity int // -1 for variadic
+	call  func(env *exprEnv, args []exprNode) (interface{}, error)
+}
+
+type callNode struct {
+	name string
+	fn   exprFunc
+	args []exprNode
+}
+
+func (n *callNode) eval(env *exprEnv) (interface{}, error) {
+	return n.fn.call(env, n.args)
+}
+
+// evalArgs evaluates all call arguments eagerly
+func evalArgs(env *exprEnv, args []exprNode) ([]interface{}, error) {
+	out := make([]interface{}, len(args))
+	for i, arg := range args {
+		v, err := arg.eval(env)
+		if err != nil {
+			return nil, err
+		}
+		out[i] = v
+	}
+	return out, nil
+}
+
+func stringFunc(fn func(s string) interface{}) exprFunc {
+	return exprFunc{arity: 1, call: func(env *exprEnv, args []exprNode) (interface{}, error) {
+		vals, err := evalArgs(env, args)
+		if err != nil {
+			return nil, err
+		}
+		return fn(fmt.Sprintf("%v", vals[0])), nil
+	}}
+}
+
+func stringPairFunc(fn func(a, b string) (interface{}, error)) exprFunc {
+	return exprFunc{arity: 2, call: func(env *exprEnv, args []exprNode) (interface{}, error) {
+		vals, err := evalArgs(env, args)
+		if err != nil {
+			return nil, err
+		}
+		return fn(fmt.Sprintf("%v", vals[0]), fmt.Sprintf("%v", vals[1]))
+	}}
+}
+
+var exprFuncs map[string]exprFunc
+
+func init() {
+	exprFuncs = map[string]exprFunc{
+		// exists(key) is true when key is set; key may be an identifier or a string
+		"exists": {arity: 1, call: func(env *exprEnv, args []exprNode) (interface{}, error) {
+			if ident, ok := args[0].(*identNode); ok {
+				_, set := env.lookup(ident.name)
+				return set, nil
+			}
+			v, err := args[0].eval(env)
+			if err != nil {
+				return nil, err
+			}
+			_, set := env.lookup(fmt.Sprintf("%v", v))
+			return set, nil
+		}},
+		// field(name) reads a key whose name is not a valid identifier
+		"field": {arity: 1, call: func(env *exprEnv, args []exprNode) (interface{}, error) {
+			v, err := args[0].eval(env)
+			if err != nil {
+				return nil, err
+			}
+			value, ok := env.lookup(fmt.Sprintf("%v", v))
+			if !ok {
+				return nil, errMissingKey
+			}
+			return normalizeExprValue(value), nil
+		}},
+		"len": {arity: 1, call: func(env *exprEnv, args []exprNode) (interface{}, error) {
+			vals, err := evalArgs(env, args)
+			if err != nil {
+				return nil, err
+			}
+			switch v := vals[0].(type) {
+			case []interface{}:
+				return float64(len(v)), nil
+			case string:
+				return float64(len([]rune(v))), nil
+			case nil:
+				return 0.0, nil
+			default:
+				return float64(len(fmt.Sprintf("%v", v))), nil
+			}
+		}},
+		"lower": stringFunc(func(s string) interface{} { return strings.ToLower(s) }),
+		"upper": stringFunc(func(s string) interface{} { return strings.ToUpper(s) }),
+		"number": {arity: 1, call: func(env *exprEnv, args []exprNode) (interface{}, error) {
+			vals, err := evalArgs(env, args)
+			if err != nil {
+				return nil, err
+			}
+			f, ok := toExprNumber(vals[0])
+			if !ok {
+				return nil, fmt.Errorf("%v is not a number", vals[0])
+			}
+			return f, nil
+		}},
+		"matches": stringPairFunc(func(s, pattern string) (interface{}, error) {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			return re.MatchString(s), nil
+		}),
+		"startswith": stringPairFunc(func(s, prefix string) (interface{}, error) {
+			return strings.HasPrefix(s, prefix), nil
+		}),
+		"endswith": stringPairFunc(func(s, suffix string) (interface{}, error) {
+			return strings.HasSuffix(s, suffix), nil
+		}),
+		"contains": {arity: 2, call: func(env *exprEnv, args []exprNode) (interface{}, error) {
+			vals, err := evalArgs(env, args)
+			if err != nil {
+				return nil, err
+			}
+			return exprContains(vals[0], vals[1])
+		}},
+	}
+}
+
+// Value helpers
+
+// normalizeExprValue converts config values into expression values
+func normalizeExprValue(v interface{}) interface{} {
+	switch val := v.(type) {
+	case int:
+		return float64(val)
+	case int64:
+		return float64(val)
+	case int32:
+		return float64(val)
+	case float32:
+		return float64(val)
+	case []string:
+		out := make([]interface{}, len(val))
+		for i, s := range val {
+			out[i] = s
+		}
+		return out
+	default:
+		return v
+	}
+}
+
+// toExprNumber converts numbers and numeric strings (common in text configs) to float64
+func toExprNumber(v interface{}) (float64, bool) {
+	switch val := normalizeExprValue(v).(type) {
+	case float64:
+		return val, true
+	case string:
+		f, err := strconv.ParseFloat(strings.TrimSpace(val), 64)
+		return f, err == nil
+	default:
+		return 0, false
+	}
+}
+
+func exprEqual(a, b interface{}) bool {
+	if af, ok := toExprNumber(a); ok {
+		if bf, ok := toExprNumber(b); ok {
+			return af == bf
+		}
+	}
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+	return fmt.Sprintf("%v", a) == fmt.Sprintf("%v", b)
+}
+
+func exprContains(container, item interface{}) (bool, error) {
+	switch c := normalizeExprValue(container).(type) {
+	case []interface{}:
+		for _, elem := range c {
+			if exprEqual(elem, item) {
+				return true, nil
+			}
+		}
+		return false, nil
+	case string:
+		return strings.Contains(c, fmt.Sprintf("%v", item)), nil
+	default:
+		return false, fmt.Errorf("cannot search in %v", container)
+	}
+}
+
+func truthy(v interface{}) bool {
+	switch val := v.(type) {
+	case nil:
+		return false
+	case bool:
+		return val
+	case float64:
+		return val != 0
+	case string:
+		return val != "" && val != "false"
+	case []interface{}:
+		return len(val) > 0
+	default:
+		return true
+	}
+}
@@ -80,6 +80,23 @@ func (v *Validator) validateFieldBasic(fieldName string, value interface{}) *Val
 
 // validateFieldWithRule validates a field against a specific rule
 func (v *Validator) validateFieldWithRule(fieldName string, value interface{}, rule *FieldRule) *ValidationResult {
+	result, checked := v.validateFieldConstraints(fieldName, value, rule)
+
+	// Custom validation without config context; rules that reference
+	// other keys are not applicable here and are skipped
+	if checked && rule.Custom != nil {
+		if err := v.validateCustom(fieldName, value, rule.Custom); err != nil {
+			result.Valid = false
+			result.Errors = append(result.Errors, customRuleError(fieldName, value, err))
+		}
+	}
+
+	return result
+}
+
+// validateFieldConstraints applies a rule's built-in constraints. checked reports
+// whether the value was present and of the right type, so further rules apply.
+func (v *Validator) validateFieldConstraints(fieldName string, value interface{}, rule *FieldRule) (*ValidationResult, bool) {
 	result := &ValidationResult{Valid: true}
 
 	// Check required
@@ -91,12 +108,12 @@ func (v *Validator) validateFieldWithRule(fieldName string, value interface{}, r
 			// normalized code
 			Code: "required",
 		})
-		return result
+		return result, false
 	}
 
 	// Allow nil for optional fields
 	if value == nil {
-		return result
+		return result, false
 	}
 
 	// Type validation
@@ -109,7 +126,7 @@ func (v *Validator) validateFieldWithRule(fieldName string, value interface{}, r
 			Code:  "type_mismatch",
 			Value: value,
 		})
-		return result
+		return result, false
 	}
 
 	// String-specific validations
@@ -136,20 +153,7 @@ func (v *Validator) validateFieldWithRule(fieldName string, value interface{}, r
 		}
 	}
 
-	// Custom validation
-	if rule.Custom != nil {
-		if err := v.validateCustom(fieldName, value, rule.Custom); err != nil {
-			result.Valid = false
-			result.Errors = append(result.Errors, &ValidationError{
-				Field:   fieldName,
-				Message: err.Error(),
-				Code:    "CUSTOM_VALIDATION",
-				Value:   value,
-			})
-		}
-	}
-
-	return result
+	return result, true
 }
 
 // validateStringField validates string-specific rules
@@ -349,41 +353,9 @@ func (v *Validator) validateFormat(value, format string) error {
 	}
 }
 
-// validateCustom executes custom validation logic
+// validateCustom executes a custom rule for a single field without config context
 func (v *Validator) validateCustom(fieldName string, value interface{}, custom *CustomRule) error {
-	// Custom validation functions are handled here
-	switch custom.Function {
-	case "validate_positive":
-		if num, err := convertToFloat64(value); err == nil {
-			if num <= 0 {
-				if custom.Message != "" {
-					return errors.New(custom.Message)
-				}
-				return errors.New("value must be positive")
-			}
-		}
-	case "validate_non_empty":
-		if str, ok := value.(string); ok && strings.TrimSpace(str) == "" {
-			if custom.Message != "" {
-				return errors.New(custom.Message)
-			}
-			return errors.New("value cannot be empty")
-		}
-	case "unique":
-		// Delegate to package-level uniqueness helper implemented in uniqueness.go
-		valStr := strings.TrimSpace(fmt.Sprintf("%v", value))
-		if err := v.validateUniqueness(fieldName, valStr, custom.Args); err != nil {
-			// If custom message is provided, prefer it
-			if custom.Message != "" {
-				return errors.New(custom.Message)
-			}
-			return err
-		}
-		return nil
-	default:
-		return errors.New("unknown custom validation function: " + custom.Function)
-	}
-	return nil
+	return v.validateCustomRule(fieldName, value, nil, custom)
 }
 
 // Helper validation functions
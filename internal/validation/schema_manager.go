@@ -25,6 +25,14 @@ func (v *Validator) LoadSchema(schemaPath string) error {
 		return fmt.Errorf("schema name is required")
 	}
 
+	for fieldName, rule := range schema.Fields {
+		if rule != nil && rule.Custom != nil {
+			if err := compileCustomRule(rule.Custom); err != nil {
+				return fmt.Errorf("field %s: %w", fieldName, err)
+			}
+		}
+	}
+
 	v.optimizeSchema(&schema)
 	v.RegisterSchema(&schema)
 	return nil
@@ -44,11 +44,15 @@ type GlobalRules struct {
 	ForbiddenFields []string `json:"forbidden_fields,omitempty"` // Forbidden field names
 }
 
-// CustomRule represents a custom validation rule
+// CustomRule represents a custom validation rule. A rule runs a registered
+// function, an expression, or both; When limits the rule to configs where the
+// condition holds (see expr.go for the expression language).
 type CustomRule struct {
-	Function string                 `json:"function"`          // Function name
-	Args     map[string]interface{} `json:"args,omitempty"`    // Function arguments
-	Message  string                 `json:"message,omitempty"` // Custom error message
+	Function string                 `json:"function,omitempty"` // Registered function name
+	Args     map[string]interface{} `json:"args,omitempty"`     // Function arguments
+	Expr     string                 `json:"expr,omitempty"`     // Expression that must evaluate to true
+	When     string                 `json:"when,omitempty"`     // Condition for the rule to apply
+	Message  string                 `json:"message,omitempty"`  // Custom error message
 }
 
 // ValidatedAppConfig represents an app config with validation tags
@@ -104,10 +108,12 @@ type ValidationError struct {
 	Message string      `json:"message"`
 	Code    string      `json:"code,omitempty"`
 	Value   interface{} `json:"value,omitempty"`
+	Related string      `json:"related,omitempty"` // Other field involved in dependency/conflict errors
 }
 
 // Validator provides configuration validation functionality
 type Validator struct {
 	schemas  map[string]*Schema
 	validate *validator.Validate
+	rules    map[string]RuleFunc
 }
@@ -4,6 +4,8 @@ package configextractor
 import (
 	"fmt"
 	"strings"
+
+	"github.com/mrtkrcm/ZeroUI/internal/secrets"
 )
 
 // ConfigDiff represents the differences between two configurations
@@ -88,35 +90,36 @@ func (d ConfigDiff) Summary() string {
 	return strings.Join(parts, ", ")
 }
 
-// FormatDiff returns a formatted string representation of the diff
+// FormatDiff returns a formatted string representation of the diff, with
+// secret values masked
 func (d ConfigDiff) FormatDiff() string {
 	var output strings.Builder
 
 	if len(d.Added) > 0 {
 		output.WriteString("Added:\n")
 		for key, value := range d.Added {
-			output.WriteString(fmt.Sprintf("  + %s = %v\n", key, value))
+			output.WriteString(fmt.Sprintf("  + %s = %v\n", key, secrets.Redact(key, value)))
 		}
 	}
 
 	if len(d.Modified) > 0 {
 		output.WriteString("Modified:\n")
 		for key, diff := range d.Modified {
-			output.WriteString(fmt.Sprintf("  ~ %s: %v → %v\n", key, diff.Old, diff.New))
+			output.WriteString(fmt.Sprintf("  ~ %s: %v → %v\n", key, secrets.Redact(key, diff.Old), secrets.Redact(key, diff.New)))
 		}
 	}
 
 	if len(d.Removed) > 0 {
 		output.WriteString("Removed:\n")
 		for key, value := range d.Removed {
-			output.WriteString(fmt.Sprintf("  - %s = %v\n", key, value))
+			output.WriteString(fmt.Sprintf("  - %s = %v\n", key, secrets.Redact(key, value)))
 		}
 	}
 
 	if len(d.Unchanged) > 0 {
 		output.WriteString("Unchanged:\n")
 		for key, value := range d.Unchanged {
-			output.WriteString(fmt.Sprintf("  = %s = %v\n", key, value))
+			output.WriteString(fmt.Sprintf("  = %s = %v\n", key, secrets.Redact(key, value)))
 		}
 	}
 
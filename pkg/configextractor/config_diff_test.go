@@ -297,6 +297,7 @@ func TestConfigDiff_FormatDiff(t *testing.T) {
 		},
 		Modified: map[string]ValueDiff{
 			"font-family": {Old: "JetBrains Mono", New: "Fira Code"},
+			"api-key":     {Old: "old-key", New: "new-key"},
 		},
 		Removed: map[string]interface{}{
 			"cursor-blink": true,
@@ -317,6 +318,7 @@ func TestConfigDiff_FormatDiff(t *testing.T) {
 		"  ~ font-family: JetBrains Mono → Fira Code",
 		"Removed:",
 		"  - cursor-blink = true",
+		"  ~ api-key: ******** → ********",
 	}
 
 	for _, expected := range expectedStrings {
@@ -324,6 +326,9 @@ func TestConfigDiff_FormatDiff(t *testing.T) {
 			t.Errorf("FormatDiff() missing expected string: %q", expected)
 		}
 	}
+	if containsString(result, "new-key") {
+		t.Error("FormatDiff() should mask secret values")
+	}
 }
 
 // Helper function to check if a string contains a substring
@@ -0,0 +1,420 @@
+package configextractor
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/knadh/koanf/parsers/toml"
+)
+
+// EffectiveConfig is the configuration a tool reports it resolved, after
+// defaults, includes, command-line flags and environment are applied
+type EffectiveConfig struct {
+	App     string
+	Command string // Command line that produced the values
+	Values  map[string]interface{}
+	// Origins records where the tool says a value came from, when it reports it
+	Origins map[string]string
+	// Complete is true when the tool lists every setting; otherwise it only
+	// lists settings that differ from its defaults
+	Complete  bool
+	Timestamp time.Time
+}
+
+// EffectiveCommand defines how to ask a tool for its effective configuration
+type EffectiveCommand struct {
+	Command string
+	Args    []string
+	// More lists further argument lists whose output is merged with that of
+	// Args, for tools that report each scope separately
+	More   [][]string
+	Parser func(output string) (values map[string]interface{}, origins map[string]string, err error)
+	// ConfigArgs returns the arguments that make the tool read the given
+	// config file instead of its default one; nil when it can't be told
+	ConfigArgs func(path string) []string
+	Timeout    time.Duration
+	Complete   bool
+}
+
+// EffectiveReader reads effective configuration by running the tool
+type EffectiveReader struct {
+	commands map[string]EffectiveCommand
+	// Runner executes the tool; tests may inject a fake runner
+	Runner Runner
+}
+
+// NewEffectiveReader creates a reader for the tools that can print their
+// effective configuration
+func NewEffectiveReader(runner Runner) *EffectiveReader {
+	if runner == nil {
+		runner = NewOSRunner()
+	}
+	return &EffectiveReader{
+		commands: map[string]EffectiveCommand{
+			"ghostty": {
+				Command: "ghostty",
+				Args:    []string{"+show-config"},
+				Parser:  parseEffectiveKeyValues,
+				ConfigArgs: func(path string) []string {
+					return []string{"--config-default-files=false", "--config-file=" + path}
+				},
+				Timeout: 10 * time.Second,
+			},
+			"git": {
+				Command:  "git",
+				Args:     []string{"config", "--list", "--show-origin"},
+				Parser:   parseEffectiveGit,
+				Timeout:  3 * time.Second,
+				Complete: true,
+			},
+			"tmux": {
+				Command: "tmux",
+				// Session, window and server options are listed separately
+				Args:     []string{"show-options", "-g"},
+				More:     [][]string{{"show-options", "-gw"}, {"show-options", "-s"}},
+				Parser:   parseEffectiveTmux,
+				Timeout:  3 * time.Second,
+				Complete: true,
+			},
+			"starship": {
+				Command:  "starship",
+				Args:     []string{"print-config"},
+				Parser:   parseEffectiveTOML,
+				Timeout:  5 * time.Second,
+				Complete: true,
+			},
+		},
+		Runner: runner,
+	}
+}
+
+// CanRead reports whether the effective configuration of app can be read
+func (r *EffectiveReader) CanRead(app string) bool {
+	_, ok := r.commands[app]
+	return ok
+}
+
+// Apps returns the apps whose effective configuration can be read, sorted
+func (r *EffectiveReader) Apps() []string {
+	apps := make([]string, 0, len(r.commands))
+	for app := range r.commands {
+		apps = append(apps, app)
+	}
+	sort.Strings(apps)
+	return apps
+}
+
+// Read runs the app's tool and parses the configuration it reports
+func (r *EffectiveReader) Read(ctx context.Context, app string) (*EffectiveConfig, error) {
+	return r.ReadConfig(ctx, app, "")
+}
+
+// ReadConfig is Read for the config file at configPath, for tools that can
+// be pointed at one; others read their default config. An empty path
+// always means the default.
+func (r *EffectiveReader) ReadConfig(ctx context.Context, app, configPath string) (*EffectiveConfig, error) {
+	cmd, ok := r.commands[app]
+	if !ok {
+		return nil, fmt.Errorf("no effective-config command for %s (supported: %s)", app, strings.Join(r.Apps(), ", "))
+	}
+
+	execCtx, cancel := context.WithTimeout(ctx, cmd.Timeout)
+	defer cancel()
+
+	var configArgs []string
+	if configPath != "" && cmd.ConfigArgs != nil {
+		configArgs = cmd.ConfigArgs(configPath)
+	}
+
+	values := make(map[string]interface{})
+	origins := make(map[string]string)
+	var commandLines []string
+	for _, args := range append([][]string{cmd.Args}, cmd.More...) {
+		args = append(append([]string{}, args...), configArgs...)
+		commandLine := strings.Join(append([]string{cmd.Command}, args...), " ")
+		commandLines = append(commandLines, commandLine)
+
+		stdout, stderr, err := r.Runner.Run(execCtx, cmd.Command, args...)
+		if err != nil {
+			if msg := strings.TrimSpace(string(stderr)); msg != "" {
+				return nil, fmt.Errorf("%s failed: %w: %s", commandLine, err, msg)
+			}
+			return nil, fmt.Errorf("%s failed: %w", commandLine, err)
+		}
+
+		v, o, err := cmd.Parser(string(stdout))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse %s output: %w", commandLine, err)
+		}
+		for key, value := range v {
+			values[key] = value
+		}
+		for key, origin := range o {
+			origins[key] = origin
+		}
+	}
+
+	return &EffectiveConfig{
+		App:       app,
+		Command:   strings.Join(commandLines, "; "),
+		Values:    values,
+		Origins:   origins,
+		Complete:  cmd.Complete,
+		Timestamp: time.Now(),
+	}, nil
+}
+
+// EffectiveStatus classifies a setting when comparing a config file with
+// the effective configuration
+type EffectiveStatus string
+
+const (
+	// EffectiveMatch: the tool uses the value set in the file
+	EffectiveMatch EffectiveStatus = "match"
+	// EffectiveDiffers: the tool uses a different value than the file sets
+	EffectiveDiffers EffectiveStatus = "differs"
+	// EffectiveIgnored: the file sets a key the tool doesn't report at all
+	EffectiveIgnored EffectiveStatus = "ignored"
+	// EffectiveDefault: the file sets a key the tool only omits because it
+	// equals the default
+	EffectiveDefault EffectiveStatus = "default"
+	// EffectiveExternal: the tool reports a value the file doesn't set
+	EffectiveExternal EffectiveStatus = "external"
+)
+
+// EffectiveComparison is one setting of a file/effective comparison
+type EffectiveComparison struct {
+	Key       string
+	File      interface{} // nil when the file doesn't set the key
+	Effective interface{} // nil when the tool doesn't report the key
+	Origin    string
+	Status    EffectiveStatus
+}
+
+// Mismatch reports whether the tool doesn't use the value the file sets
+func (c EffectiveComparison) Mismatch() bool {
+	return c.Status == EffectiveDiffers || c.Status == EffectiveIgnored
+}
+
+// CompareEffective compares values read from a config file with the
+// effective configuration. Keys only the tool reports are included when it
+// lists just non-default settings; complete listings would bury the file's
+// keys among every default.
+func CompareEffective(file map[string]interface{}, effective *EffectiveConfig) []EffectiveComparison {
+	var result []EffectiveComparison
+
+	for key, fileValue := range file {
+		c := EffectiveComparison{Key: key, File: fileValue, Origin: effective.Origins[key]}
+		value, ok := effective.Values[key]
+		switch {
+		case ok:
+			c.Effective = value
+			c.Status = EffectiveDiffers
+			if EffectiveValuesEqual(fileValue, value) {
+				c.Status = EffectiveMatch
+			}
+		case effective.Complete:
+			c.Status = EffectiveIgnored
+		default:
+			c.Status = EffectiveDefault
+		}
+		result = append(result, c)
+	}
+
+	if !effective.Complete {
+		for key, value := range effective.Values {
+			if _, ok := file[key]; !ok {
+				result = append(result, EffectiveComparison{
+					Key:       key,
+					Effective: value,
+					Origin:    effective.Origins[key],
+					Status:    EffectiveExternal,
+				})
+			}
+		}
+	}
+
+	sort.Slice(result, func(i, j int) bool { return result[i].Key < result[j].Key })
+	return result
+}
+
+// EffectiveValuesEqual compares a file value with a reported value, ignoring
+// quoting and number formatting
+func EffectiveValuesEqual(a, b interface{}) bool {
+	return normalizeEffective(a) == normalizeEffective(b)
+}
+
+func normalizeEffective(value interface{}) string {
+	switch v := value.(type) {
+	case []interface{}:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = normalizeEffective(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case []string:
+		items := make([]string, len(v))
+		for i, item := range v {
+			items[i] = normalizeEffective(item)
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	}
+
+	s := strings.TrimSpace(fmt.Sprintf("%v", value))
+	if len(s) >= 2 && (s[0] == '"' || s[0] == '\'') && s[len(s)-1] == s[0] {
+		s = s[1 : len(s)-1]
+	}
+	if f, err := strconv.ParseFloat(s, 64); err == nil {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	if b, err := strconv.ParseBool(s); err == nil {
+		return strconv.FormatBool(b)
+	}
+	return s
+}
+
+// addEffective records a value, turning repeated keys into lists
+func addEffective(values map[string]interface{}, key string, value interface{}) {
+	existing, ok := values[key]
+	if !ok {
+		values[key] = value
+		return
+	}
+	if list, ok := existing.([]interface{}); ok {
+		values[key] = append(list, value)
+		return
+	}
+	values[key] = []interface{}{existing, value}
+}
+
+// parseEffectiveKeyValues parses "key = value" lines (Ghostty +show-config)
+func parseEffectiveKeyValues(output string) (map[string]interface{}, map[string]string, error) {
+	values := make(map[string]interface{})
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+		addEffective(values, strings.TrimSpace(key), strings.TrimSpace(value))
+	}
+	return values, nil, scanner.Err()
+}
+
+// gitMultiValued lists the git variables, as "section.variable" without any
+// subsection, that take every value given rather than the last one. Those
+// marked true start over at an empty value, as credential.helper does.
+var gitMultiValued = map[string]bool{
+	"branch.merge":          false,
+	"credential.helper":     true,
+	"http.extraheader":      true,
+	"include.path":          false,
+	"includeif.path":        false,
+	"log.excludedecoration": false,
+	"maintenance.repo":      false,
+	"push.pushoption":       true,
+	"receive.hiderefs":      false,
+	"remote.fetch":          false,
+	"remote.push":           false,
+	"remote.pushurl":        false,
+	"remote.url":            false,
+	"safe.directory":        true,
+	"transfer.hiderefs":     false,
+	"uploadpack.hiderefs":   false,
+	"url.insteadof":         false,
+	"url.pushinsteadof":     false,
+}
+
+// gitVariable returns a git key's "section.variable", dropping the subsection
+func gitVariable(key string) string {
+	section, rest, _ := strings.Cut(key, ".")
+	return section + "." + rest[strings.LastIndex(rest, ".")+1:]
+}
+
+// parseEffectiveGit parses "origin<TAB>key=value" lines (git config --list
+// --show-origin). Later values override earlier ones, as in git; the values
+// of multi-valued keys such as remote.<name>.fetch become lists.
+func parseEffectiveGit(output string) (map[string]interface{}, map[string]string, error) {
+	values := make(map[string]interface{})
+	origins := make(map[string]string)
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		origin, entry, ok := strings.Cut(line, "\t")
+		if !ok {
+			origin, entry = "", line
+		}
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			// A key without "=" is a boolean set to true
+			value = "true"
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		origins[key] = strings.TrimPrefix(origin, "file:")
+
+		resets, multi := gitMultiValued[gitVariable(key)]
+		switch {
+		case !multi:
+			values[key] = value
+		case resets && value == "":
+			delete(values, key)
+			delete(origins, key)
+		default:
+			addEffective(values, key, value)
+		}
+	}
+	return values, origins, scanner.Err()
+}
+
+// parseEffectiveTmux parses "option value" lines (tmux show-options)
+func parseEffectiveTmux(output string) (map[string]interface{}, map[string]string, error) {
+	values := make(map[string]interface{})
+	scanner := bufio.NewScanner(strings.NewReader(output))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(line, " ")
+		value = strings.TrimSpace(value)
+		if unquoted, err := strconv.Unquote(value); err == nil {
+			value = unquoted
+		}
+		values[key] = value
+	}
+	return values, nil, scanner.Err()
+}
+
+// parseEffectiveTOML parses TOML output (starship print-config) into dotted keys
+func parseEffectiveTOML(output string) (map[string]interface{}, map[string]string, error) {
+	data, err := toml.Parser().Unmarshal([]byte(output))
+	if err != nil {
+		return nil, nil, err
+	}
+	values := make(map[string]interface{})
+	flattenEffective(data, "", values)
+	return values, nil, nil
+}
+
+// flattenEffective stores nested tables under dotted keys
+func flattenEffective(data map[string]interface{}, prefix string, values map[string]interface{}) {
+	for key, value := range data {
+		if table, ok := value.(map[string]interface{}); ok && len(table) > 0 {
+			flattenEffective(table, prefix+key+".", values)
+			continue
+		}
+		values[prefix+key] = value
+	}
+}
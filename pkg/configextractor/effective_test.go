@@ -0,0 +1,167 @@
+package configextractor_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mrtkrcm/ZeroUI/pkg/configextractor"
+	"github.com/mrtkrcm/ZeroUI/pkg/configextractor/testhelpers"
+)
+
+func TestEffectiveReader_Read(t *testing.T) {
+	runner := testhelpers.New()
+	runner.RegisterString("ghostty", "font-size = 15\nkeybind = ctrl+a=copy\nkeybind = ctrl+v=paste\ntheme = \"Dracula\"\n", "", nil, 0, "+show-config")
+	runner.RegisterString("git", "file:/home/u/.gitconfig\tuser.name=Jo\ncommand line:\tcore.Editor=vim\nfile:/home/u/.gitconfig\tcore.bare\n"+
+		"file:/etc/gitconfig\tcore.autocrlf=true\nfile:/home/u/.gitconfig\tcore.autocrlf=input\n"+
+		"file:.git/config\tremote.origin.fetch=+refs/heads/*:refs/remotes/origin/*\nfile:.git/config\tremote.origin.fetch=+refs/tags/*:refs/tags/*\n"+
+		"file:/etc/gitconfig\tcredential.helper=osxkeychain\nfile:/home/u/.gitconfig\tcredential.helper=\nfile:/home/u/.gitconfig\tcredential.helper=store\n",
+		"", nil, 0, "config", "--list", "--show-origin")
+	runner.RegisterString("tmux", "mouse on\nstatus-left \"[#S] \"\n", "", nil, 0, "show-options", "-g")
+	runner.RegisterString("tmux", "mode-keys vi\n", "", nil, 0, "show-options", "-gw")
+	runner.RegisterString("tmux", "escape-time 10\n", "", nil, 0, "show-options", "-s")
+	runner.RegisterString("ghostty", "font-size = 12\n", "", nil, 0, "+show-config", "--config-default-files=false", "--config-file=/work/ghostty.conf")
+	runner.RegisterString("starship", "add_newline = false\n[character]\nsuccess_symbol = \"➜\"\n", "", nil, 0, "print-config")
+
+	reader := configextractor.NewEffectiveReader(runner)
+	ctx := context.Background()
+
+	ghostty, err := reader.Read(ctx, "ghostty")
+	if err != nil {
+		t.Fatalf("Read(ghostty) failed: %v", err)
+	}
+	if ghostty.Complete || ghostty.Command != "ghostty +show-config" {
+		t.Errorf("Unexpected ghostty snapshot: %+v", ghostty)
+	}
+	if keybinds, ok := ghostty.Values["keybind"].([]interface{}); !ok || len(keybinds) != 2 {
+		t.Errorf("Expected repeated keybind values as a list, got %#v", ghostty.Values["keybind"])
+	}
+
+	git, err := reader.Read(ctx, "git")
+	if err != nil {
+		t.Fatalf("Read(git) failed: %v", err)
+	}
+	if git.Values["core.editor"] != "vim" || git.Origins["core.editor"] != "command line:" {
+		t.Errorf("Expected lower-cased key with its origin, got %v / %v", git.Values, git.Origins)
+	}
+	if git.Values["core.bare"] != "true" || git.Origins["user.name"] != "/home/u/.gitconfig" {
+		t.Errorf("Unexpected git values: %v / %v", git.Values, git.Origins)
+	}
+	// Scalar keys take the last value, as in git
+	if git.Values["core.autocrlf"] != "input" || git.Origins["core.autocrlf"] != "/home/u/.gitconfig" {
+		t.Errorf("Expected the last core.autocrlf to win, got %#v", git.Values["core.autocrlf"])
+	}
+	if fetch, ok := git.Values["remote.origin.fetch"].([]interface{}); !ok || len(fetch) != 2 {
+		t.Errorf("Expected both fetch refspecs, got %#v", git.Values["remote.origin.fetch"])
+	}
+	// An empty credential.helper drops the helpers set before it
+	if git.Values["credential.helper"] != "store" {
+		t.Errorf("Expected the helper list to start over, got %#v", git.Values["credential.helper"])
+	}
+
+	tmux, err := reader.Read(ctx, "tmux")
+	if err != nil {
+		t.Fatalf("Read(tmux) failed: %v", err)
+	}
+	if tmux.Values["status-left"] != "[#S] " || tmux.Values["mouse"] != "on" {
+		t.Errorf("Unexpected tmux values: %#v", tmux.Values)
+	}
+	if tmux.Values["mode-keys"] != "vi" || tmux.Values["escape-time"] != "10" {
+		t.Errorf("Expected window and server options to be merged in, got %#v", tmux.Values)
+	}
+
+	configured, err := reader.ReadConfig(ctx, "ghostty", "/work/ghostty.conf")
+	if err != nil {
+		t.Fatalf("ReadConfig(ghostty) failed: %v", err)
+	}
+	if configured.Values["font-size"] != "12" || !strings.Contains(configured.Command, "--config-file=/work/ghostty.conf") {
+		t.Errorf("Expected ghostty to read the given config, got %+v", configured)
+	}
+	if _, err := reader.ReadConfig(ctx, "starship", "/work/starship.toml"); err != nil {
+		t.Errorf("Expected tools without a config flag to read their default config, got %v", err)
+	}
+
+	starship, err := reader.Read(ctx, "starship")
+	if err != nil {
+		t.Fatalf("Read(starship) failed: %v", err)
+	}
+	if starship.Values["character.success_symbol"] != "➜" || starship.Values["add_newline"] != false {
+		t.Errorf("Unexpected starship values: %#v", starship.Values)
+	}
+}
+
+func TestEffectiveReader_Errors(t *testing.T) {
+	runner := testhelpers.New()
+	runner.RegisterString("tmux", "", "no server running on /tmp/tmux-0/default", errors.New("exit status 1"), 0, "show-options", "-g")
+	runner.RegisterString("ghostty", "", "", nil, time.Second, "+show-config")
+
+	reader := configextractor.NewEffectiveReader(runner)
+
+	if _, err := reader.Read(context.Background(), "zed"); err == nil || !strings.Contains(err.Error(), "supported") {
+		t.Errorf("Expected an unsupported-app error, got %v", err)
+	}
+	if _, err := reader.Read(context.Background(), "tmux"); err == nil || !strings.Contains(err.Error(), "no server running") {
+		t.Errorf("Expected the tool's stderr in the error, got %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := reader.Read(ctx, "ghostty"); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("Expected the context deadline to stop the tool, got %v", err)
+	}
+}
+
+func TestCompareEffective(t *testing.T) {
+	file := map[string]interface{}{
+		"font-size":  "13",
+		"opacity":    "0.90",
+		"theme":      "Dracula",
+		"cursor":     "block",
+		"keybind":    []interface{}{"ctrl+a=copy", "ctrl+v=paste"},
+		"typo-field": "x",
+	}
+
+	partial := &configextractor.EffectiveConfig{
+		Values: map[string]interface{}{
+			"font-size": "15",
+			"opacity":   "0.9",
+			"theme":     `"Dracula"`,
+			"keybind":   []interface{}{"ctrl+a=copy", "ctrl+v=paste"},
+			"command":   "/bin/fish",
+		},
+	}
+
+	want := map[string]configextractor.EffectiveStatus{
+		"font-size":  configextractor.EffectiveDiffers,
+		"opacity":    configextractor.EffectiveMatch,
+		"theme":      configextractor.EffectiveMatch,
+		"keybind":    configextractor.EffectiveMatch,
+		"cursor":     configextractor.EffectiveDefault,
+		"typo-field": configextractor.EffectiveDefault,
+		"command":    configextractor.EffectiveExternal,
+	}
+	rows := configextractor.CompareEffective(file, partial)
+	if len(rows) != len(want) {
+		t.Fatalf("Expected %d rows, got %+v", len(want), rows)
+	}
+	for _, row := range rows {
+		if row.Status != want[row.Key] {
+			t.Errorf("%s: expected %s, got %s", row.Key, want[row.Key], row.Status)
+		}
+	}
+
+	// A complete listing reports every key it knows, so absent keys are ignored
+	partial.Complete = true
+	for _, row := range configextractor.CompareEffective(file, partial) {
+		switch row.Key {
+		case "command":
+			t.Errorf("Complete listings should not add keys missing from the file")
+		case "typo-field", "cursor":
+			if row.Status != configextractor.EffectiveIgnored || !row.Mismatch() {
+				t.Errorf("%s: expected an ignored mismatch, got %s", row.Key, row.Status)
+			}
+		}
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"time"
 
 	"github.com/mrtkrcm/ZeroUI/internal/performance"
+	"github.com/mrtkrcm/ZeroUI/pkg/configextractor/source"
 )
 
 // min returns the smaller of two integers
@@ -24,6 +25,7 @@ type Extractor struct {
 	parsers    map[string]Parser
 	cache      Cache
 	registry   AppRegistry
+	source     source.Source // Backend for remote files; nil means live HTTP
 
 	// Performance optimizations
 	timeout          time.Duration
@@ -31,6 +33,11 @@ type Extractor struct {
 	concurrentLoader *performance.ConcurrentConfigLoader
 }
 
+// sourceSetter is implemented by strategies that fetch remote files
+type sourceSetter interface {
+	SetSource(src source.Source)
+}
+
 // New creates a new extractor with sensible defaults
 func New(opts ...Option) *Extractor {
 	e := &Extractor{
@@ -47,6 +54,16 @@ func New(opts ...Option) *Extractor {
 		opt(e)
 	}
 
+	// Strategies added with WithStrategy fetch through the same source,
+	// whichever order the options came in
+	if e.source != nil {
+		for _, strategy := range e.strategies {
+			if s, ok := strategy.(sourceSetter); ok {
+				s.SetSource(e.source)
+			}
+		}
+	}
+
 	// Register default strategies (sorted by priority)
 	e.registerDefaultStrategies()
 
@@ -241,11 +258,18 @@ func (e *Extractor) getStrategiesForApp(app string) []Strategy {
 // registerDefaultStrategies adds built-in extraction strategies
 func (e *Extractor) registerDefaultStrategies() {
 	// Add default strategies (preserving any custom ones)
+	schema, github := NewJSONSchema(), NewGitHub()
+	if e.source != nil {
+		schema.SetSource(e.source)
+		github = NewGitHubWithSource(e.source)
+	}
+
 	defaultStrategies := []Strategy{
+		schema,   // Published schemas carry types, enums and defaults
 		NewCLI(), // Fastest, most reliable
 		// NewLocal(),    // Fast, cached locally
 		// NewBuiltin(),  // Always available fallback
-		NewGitHub(), // Network dependent, slower
+		github, // Network dependent, slower
 	}
 
 	// Append default strategies to existing ones
@@ -342,6 +366,15 @@ func WithRegistry(registry AppRegistry) Option {
 	}
 }
 
+// WithSource sets the backend strategies use to fetch remote files, e.g. a
+// local mirror or a record/replay cassette. It applies to strategies added
+// with WithStrategy as well as the default ones.
+func WithSource(src source.Source) Option {
+	return func(e *Extractor) {
+		e.source = src
+	}
+}
+
 // WithStrategy adds a custom extraction strategy
 func WithStrategy(strategy Strategy) Option {
 	return func(e *Extractor) {
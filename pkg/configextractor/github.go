@@ -7,11 +7,13 @@ import (
 	"net/http"
 	"strings"
 	"time"
+
+	"github.com/mrtkrcm/ZeroUI/pkg/configextractor/source"
 )
 
 // GitHub strategy extracts configuration from GitHub repositories
 type GitHub struct {
-	client *http.Client
+	source source.Source
 	repos  map[string]RepoInfo
 }
 
@@ -25,17 +27,23 @@ type RepoInfo struct {
 	Confidence float64  // Confidence score for this source
 }
 
-// NewGitHub creates a new GitHub extraction strategy
+// NewGitHub creates a new GitHub extraction strategy fetching over HTTP
 func NewGitHub() *GitHub {
-	return &GitHub{
-		client: &http.Client{
-			Timeout: 15 * time.Second,
-			Transport: &http.Transport{
-				MaxIdleConns:        20,
-				MaxIdleConnsPerHost: 5,
-				IdleConnTimeout:     30 * time.Second,
-			},
+	return NewGitHubWithSource(source.NewHTTP(&http.Client{
+		Timeout: 15 * time.Second,
+		Transport: &http.Transport{
+			MaxIdleConns:        20,
+			MaxIdleConnsPerHost: 5,
+			IdleConnTimeout:     30 * time.Second,
 		},
+	}))
+}
+
+// NewGitHubWithSource creates a GitHub strategy that fetches files through src,
+// e.g. a local mirror or a replay cassette
+func NewGitHubWithSource(src source.Source) *GitHub {
+	return &GitHub{
+		source: src,
 		repos: map[string]RepoInfo{
 			"zed": {
 				Owner:      "zed-industries",
@@ -130,44 +138,10 @@ func (g *GitHub) extractFromPath(ctx context.Context, app string, repo RepoInfo,
 	return nil, fmt.Errorf("file not found: %s", path)
 }
 
-// fetchFile retrieves a file from GitHub's raw content API
+// fetchFile retrieves a file from GitHub's raw content API through the configured source
 func (g *GitHub) fetchFile(ctx context.Context, owner, repo, branch, path string) ([]byte, error) {
 	url := fmt.Sprintf("https://raw.githubusercontent.com/%s/%s/%s/%s", owner, repo, branch, path)
-
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("failed to create request: %w", err)
-	}
-
-	// Set user agent for better rate limiting
-	req.Header.Set("User-Agent", "ConfigToggle-Extractor/1.0")
-
-	resp, err := g.client.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("failed to fetch file: %w", err)
-	}
-	defer func() { _ = resp.Body.Close() }()
-
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("HTTP %d: %s", resp.StatusCode, resp.Status)
-	}
-
-	// Read response body with size limit for safety
-	const maxSize = 10 << 20 // 10MB limit
-	body := make([]byte, 0, 8192)
-	buf := make([]byte, 8192)
-
-	for len(body) < maxSize {
-		n, err := resp.Body.Read(buf)
-		if n > 0 {
-			body = append(body, buf[:n]...)
-		}
-		if err != nil {
-			break
-		}
-	}
-
-	return body, nil
+	return g.source.Fetch(ctx, url)
 }
 
 // parseContent parses the fetched content based on format and path
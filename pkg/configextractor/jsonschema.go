@@ -0,0 +1,156 @@
+package configextractor
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/mrtkrcm/ZeroUI/pkg/configextractor/source"
+	"github.com/mrtkrcm/ZeroUI/pkg/reference"
+)
+
+// JSONSchema strategy imports settings metadata from published JSON Schemas.
+// Schemas carry types, enums, defaults and descriptions, so this strategy is
+// preferred over scraping source files.
+type JSONSchema struct {
+	fetcher source.Source
+	sources map[string]SchemaSource
+}
+
+// SchemaSource describes where an app's settings schema lives
+type SchemaSource struct {
+	Location   string  // http(s) URL or local file path
+	ConfigPath string  // Config file the schema describes
+	Format     string  // Config format (defaults to json)
+	Confidence float64 // Confidence score for this source
+}
+
+// NewJSONSchema creates a JSON Schema extraction strategy with known published schemas
+func NewJSONSchema() *JSONSchema {
+	return &JSONSchema{
+		fetcher: source.NewHTTP(nil),
+		sources: map[string]SchemaSource{
+			"starship": {
+				Location:   "https://starship.rs/config-schema.json",
+				ConfigPath: "~/.config/starship.toml",
+				Format:     "toml",
+				Confidence: 0.95,
+			},
+		},
+	}
+}
+
+// NewJSONSchemaWithSource creates a strategy for a single app and schema location
+func NewJSONSchemaWithSource(app string, schema SchemaSource) *JSONSchema {
+	s := &JSONSchema{
+		fetcher: source.NewHTTP(nil),
+		sources: make(map[string]SchemaSource),
+	}
+	s.AddSource(app, schema)
+	return s
+}
+
+// SetSource sets the backend used to fetch schemas published at URLs
+func (s *JSONSchema) SetSource(src source.Source) {
+	s.fetcher = src
+}
+
+// AddSource registers or replaces the schema source for an app
+func (s *JSONSchema) AddSource(app string, schema SchemaSource) {
+	if schema.Format == "" {
+		schema.Format = "json"
+	}
+	if schema.Confidence == 0 {
+		schema.Confidence = 0.95
+	}
+	s.sources[app] = schema
+}
+
+// Name returns strategy identifier
+func (s *JSONSchema) Name() string {
+	return "jsonschema"
+}
+
+// CanExtract checks if a schema is known for the app
+func (s *JSONSchema) CanExtract(app string) bool {
+	_, exists := s.sources[app]
+	return exists
+}
+
+// Priority returns strategy priority (schemas are authoritative metadata)
+func (s *JSONSchema) Priority() int {
+	return 110
+}
+
+// Extract fetches the schema and converts it into settings with dotted paths
+func (s *JSONSchema) Extract(ctx context.Context, app string) (*Config, error) {
+	schema, exists := s.sources[app]
+	if !exists {
+		return nil, fmt.Errorf("no JSON schema configured for %s", app)
+	}
+
+	data, err := s.fetch(ctx, schema.Location)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schema for %s: %w", app, err)
+	}
+
+	flattened, err := reference.FlattenJSONSchema(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema for %s: %w", app, err)
+	}
+
+	config := &Config{
+		App:        app,
+		ConfigPath: schema.ConfigPath,
+		Format:     schema.Format,
+		Settings:   make(map[string]Setting, len(flattened)),
+		Source: ExtractionSource{
+			Method:     "jsonschema",
+			Location:   schema.Location,
+			Confidence: schema.Confidence,
+		},
+		Timestamp: time.Now(),
+	}
+
+	for key, setting := range flattened {
+		config.Settings[key] = Setting{
+			Name:    key,
+			Type:    fromReferenceType(setting.Type, setting.ValidValues),
+			Default: setting.DefaultValue,
+			Values:  setting.ValidValues,
+			Desc:    setting.Description,
+			Cat:     setting.Category,
+		}
+	}
+
+	return config, nil
+}
+
+// fetch reads a schema from a URL or local path
+func (s *JSONSchema) fetch(ctx context.Context, location string) ([]byte, error) {
+	if !strings.HasPrefix(location, "http://") && !strings.HasPrefix(location, "https://") {
+		return os.ReadFile(location)
+	}
+
+	return s.fetcher.Fetch(ctx, location)
+}
+
+// fromReferenceType maps reference types back onto extractor types;
+// strings with enumerated values become choices
+func fromReferenceType(t reference.SettingType, values []string) SettingType {
+	switch t {
+	case reference.TypeNumber:
+		return TypeNumber
+	case reference.TypeBoolean:
+		return TypeBoolean
+	case reference.TypeArray:
+		return TypeArray
+	default:
+		if len(values) > 0 {
+			return TypeChoice
+		}
+		return TypeString
+	}
+}
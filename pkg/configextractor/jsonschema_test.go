@@ -0,0 +1,84 @@
+package configextractor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJSONSchema_Extract(t *testing.T) {
+	schemaPath := filepath.Join(t.TempDir(), "settings.schema.json")
+	schema := `{
+  "type": "object",
+  "properties": {
+    "format": { "type": "string", "description": "Prompt format", "default": "$all" },
+    "add_newline": { "type": "boolean", "default": true },
+    "character": {
+      "type": "object",
+      "properties": {
+        "vimcmd_symbol": { "type": "string", "enum": ["❮", "<"] }
+      }
+    }
+  }
+}`
+	if err := os.WriteFile(schemaPath, []byte(schema), 0o644); err != nil {
+		t.Fatalf("failed to write schema: %v", err)
+	}
+
+	strategy := NewJSONSchemaWithSource("test", SchemaSource{Location: schemaPath, Format: "toml"})
+
+	if !strategy.CanExtract("test") || strategy.CanExtract("other") {
+		t.Fatal("expected strategy to handle only the configured app")
+	}
+
+	config, err := strategy.Extract(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if config.Source.Method != "jsonschema" || config.Source.Confidence != 0.95 {
+		t.Errorf("unexpected source: %+v", config.Source)
+	}
+	if config.Format != "toml" {
+		t.Errorf("expected format toml, got %s", config.Format)
+	}
+
+	if s := config.Settings["add_newline"]; s.Type != TypeBoolean || s.Default != true {
+		t.Errorf("unexpected add_newline setting: %+v", s)
+	}
+	if s := config.Settings["character.vimcmd_symbol"]; s.Type != TypeChoice || len(s.Values) != 2 {
+		t.Errorf("expected enum to become a choice, got %+v", s)
+	}
+	if s := config.Settings["format"]; s.Desc != "Prompt format" || s.Type != TypeString {
+		t.Errorf("unexpected format setting: %+v", s)
+	}
+
+	missing := NewJSONSchemaWithSource("test", SchemaSource{Location: filepath.Join(t.TempDir(), "missing.json")})
+	if _, err := missing.Extract(context.Background(), "test"); err == nil {
+		t.Error("expected error for missing schema file")
+	}
+}
+
+// staticSource serves the same body for every URL
+type staticSource []byte
+
+func (s staticSource) Fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	return s, nil
+}
+
+func TestJSONSchema_AddedStrategyUsesSource(t *testing.T) {
+	strategy := NewJSONSchemaWithSource("test", SchemaSource{Location: "https://example.invalid/schema.json"})
+	schema := staticSource(`{"type": "object", "properties": {"add_newline": {"type": "boolean"}}}`)
+
+	// The source applies whichever order the options come in
+	New(WithStrategy(strategy), WithSource(schema))
+
+	config, err := strategy.Extract(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("expected the schema to come from the source, got %v", err)
+	}
+	if _, ok := config.Settings["add_newline"]; !ok {
+		t.Errorf("expected add_newline from the source's schema, got %v", config.Settings)
+	}
+}
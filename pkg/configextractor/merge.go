@@ -0,0 +1,293 @@
+package configextractor
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MergeReport describes how a merged config was assembled
+type MergeReport struct {
+	App       string             `json:"app"`
+	Sources   []ExtractionSource `json:"sources"`
+	Failures  map[string]string  `json:"failures,omitempty"` // strategy name -> error
+	Conflicts []SettingConflict  `json:"conflicts,omitempty"`
+}
+
+// SettingConflict lists the disagreeing claims strategies made about one setting field
+type SettingConflict struct {
+	Key    string          `json:"key"`
+	Field  string          `json:"field"` // type or default
+	Claims []SettingSource `json:"claims"`
+}
+
+// ExtractMerged runs every applicable strategy and merges their results instead
+// of returning the first winner. Each setting records the claims of every strategy
+// that reported it and a combined confidence; disagreements are reported as conflicts.
+func (e *Extractor) ExtractMerged(ctx context.Context, app string) (*Config, *MergeReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	strategies := e.getStrategiesForApp(app)
+	if len(strategies) == 0 {
+		return nil, nil, fmt.Errorf("no extraction strategies available for app: %s", app)
+	}
+
+	type result struct {
+		config *Config
+		err    error
+		name   string
+	}
+
+	results := make([]result, len(strategies))
+	workerPool := make(chan struct{}, min(len(strategies), 5))
+	var wg sync.WaitGroup
+
+	for i, strategy := range strategies {
+		wg.Add(1)
+		go func(i int, s Strategy) {
+			defer wg.Done()
+
+			workerPool <- struct{}{}
+			defer func() { <-workerPool }()
+
+			if err := ctx.Err(); err != nil {
+				results[i] = result{err: err, name: s.Name()}
+				return
+			}
+
+			config, err := s.Extract(ctx, app)
+			results[i] = result{config: config, err: err, name: s.Name()}
+		}(i, strategy)
+	}
+	wg.Wait()
+
+	var configs []*Config
+	failures := make(map[string]string)
+	for _, res := range results {
+		switch {
+		case res.err != nil:
+			failures[res.name] = res.err.Error()
+		case res.config == nil:
+			failures[res.name] = "no configuration returned"
+		default:
+			configs = append(configs, res.config)
+		}
+	}
+
+	if len(configs) == 0 {
+		return nil, nil, fmt.Errorf("all extraction strategies failed for %s", app)
+	}
+
+	merged, report := MergeConfigs(app, configs)
+	if len(failures) > 0 {
+		report.Failures = failures
+	}
+
+	return merged, report, nil
+}
+
+// MergeConfigs combines configs produced by different strategies. The type and
+// default of each setting are chosen by confidence-weighted vote; descriptions,
+// values and categories come from the most confident source that has them.
+func MergeConfigs(app string, configs []*Config) (*Config, *MergeReport) {
+	ordered := append([]*Config(nil), configs...)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		if ordered[i].Source.Confidence != ordered[j].Source.Confidence {
+			return ordered[i].Source.Confidence > ordered[j].Source.Confidence
+		}
+		return ordered[i].Source.Method < ordered[j].Source.Method
+	})
+
+	report := &MergeReport{App: app}
+	merged := &Config{
+		App:       app,
+		Settings:  make(map[string]Setting),
+		Timestamp: time.Now(),
+	}
+
+	var methods []string
+	for _, cfg := range ordered {
+		report.Sources = append(report.Sources, cfg.Source)
+		methods = append(methods, cfg.Source.Method)
+		if merged.ConfigPath == "" {
+			merged.ConfigPath = cfg.ConfigPath
+		}
+		if merged.Format == "" {
+			merged.Format = cfg.Format
+		}
+	}
+
+	merged.Source = ExtractionSource{
+		Method:     "merged",
+		Location:   strings.Join(methods, ","),
+		Confidence: ordered[0].Source.Confidence,
+	}
+
+	keys := make(map[string]bool)
+	for _, cfg := range ordered {
+		for key := range cfg.Settings {
+			keys[key] = true
+		}
+	}
+
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	for _, key := range sortedKeys {
+		setting, conflicts := mergeSetting(key, ordered)
+		merged.Settings[key] = setting
+		report.Conflicts = append(report.Conflicts, conflicts...)
+	}
+
+	return merged, report
+}
+
+// mergeSetting merges the claims for a single key; configs must be ordered by confidence
+func mergeSetting(key string, configs []*Config) (Setting, []SettingConflict) {
+	var out Setting
+	out.Name = key
+
+	var claims []SettingSource
+	for _, cfg := range configs {
+		s, ok := cfg.Settings[key]
+		if !ok {
+			continue
+		}
+
+		claims = append(claims, SettingSource{
+			Method:     cfg.Source.Method,
+			Location:   cfg.Source.Location,
+			Type:       s.Type,
+			Default:    s.Default,
+			Confidence: cfg.Source.Confidence,
+		})
+
+		if out.Desc == "" {
+			out.Desc = s.Desc
+		}
+		if len(out.Values) == 0 {
+			out.Values = s.Values
+		}
+		if out.Cat == "" {
+			out.Cat = s.Cat
+		}
+	}
+	out.Provenance = claims
+
+	votes := make([]Claim, len(claims))
+	for i, c := range claims {
+		votes[i] = Claim{Type: string(c.Type), Default: c.Default, Confidence: c.Confidence}
+	}
+	vote := VoteClaims(votes)
+	out.Type = SettingType(vote.Type)
+	out.Default = vote.Default
+	out.Confidence = vote.Confidence
+
+	var conflicts []SettingConflict
+	if vote.TypeConflict {
+		out.Conflicts = append(out.Conflicts, "type")
+		conflicts = append(conflicts, SettingConflict{Key: key, Field: "type", Claims: claims})
+	}
+	if vote.DefaultConflict {
+		out.Conflicts = append(out.Conflicts, "default")
+		conflicts = append(conflicts, SettingConflict{Key: key, Field: "default", Claims: claims})
+	}
+
+	return out, conflicts
+}
+
+// Claim is what one source says about a setting's type and default
+type Claim struct {
+	Type       string
+	Default    interface{}
+	Confidence float64
+}
+
+// Vote is the outcome of weighing the claims made about one setting
+type Vote struct {
+	Type    string
+	Default interface{}
+	// Confidence combines the claims that agree on Type, diluted by disagreement
+	Confidence float64
+	// TypeConflict and DefaultConflict report claims that disagreed
+	TypeConflict    bool
+	DefaultConflict bool
+}
+
+// VoteClaims chooses a setting's type and default by confidence-weighted vote.
+// Claims must be ordered by confidence; a tied type vote goes to the first.
+func VoteClaims(claims []Claim) Vote {
+	typeVotes := make(map[string]float64)
+	defaultVotes := make(map[string]float64)
+	defaults := make(map[string]interface{})
+	var total, totalWithDefault float64
+
+	for _, c := range claims {
+		total += c.Confidence
+		typeVotes[c.Type] += c.Confidence
+		if c.Default != nil && fmt.Sprintf("%v", c.Default) != "" {
+			k := fmt.Sprintf("%v", c.Default)
+			defaultVotes[k] += c.Confidence
+			totalWithDefault += c.Confidence
+			if _, seen := defaults[k]; !seen {
+				defaults[k] = c.Default
+			}
+		}
+	}
+
+	var vote Vote
+	if len(claims) == 0 {
+		return vote
+	}
+	vote.Type = pickVote(typeVotes, claims[0].Type)
+	vote.TypeConflict = len(typeVotes) > 1
+
+	defaultAgreement := 1.0
+	if len(defaultVotes) > 0 {
+		chosenDefault := pickVote(defaultVotes, "")
+		vote.Default = defaults[chosenDefault]
+		defaultAgreement = defaultVotes[chosenDefault] / totalWithDefault
+		vote.DefaultConflict = len(defaultVotes) > 1
+	}
+
+	// Independent agreeing sources reinforce each other; disagreement dilutes the result
+	miss := 1.0
+	for _, c := range claims {
+		if c.Type == vote.Type {
+			miss *= 1 - c.Confidence
+		}
+	}
+	typeAgreement := 1.0
+	if total > 0 {
+		typeAgreement = typeVotes[vote.Type] / total
+	}
+	vote.Confidence = math.Round((1-miss)*typeAgreement*defaultAgreement*1000) / 1000
+
+	return vote
+}
+
+// pickVote returns the key with the highest weight, preferring fallback on ties
+// and otherwise the lexically smallest key for deterministic output
+func pickVote(votes map[string]float64, fallback string) string {
+	best := ""
+	bestWeight := -1.0
+	for k, w := range votes {
+		switch {
+		case w > bestWeight:
+			best, bestWeight = k, w
+		case w == bestWeight:
+			if k == fallback || (best != fallback && k < best) {
+				best = k
+			}
+		}
+	}
+	return best
+}
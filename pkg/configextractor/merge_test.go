@@ -0,0 +1,114 @@
+package configextractor
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+// stubStrategy returns a fixed config for merge tests
+type stubStrategy struct {
+	name     string
+	priority int
+	config   *Config
+	err      error
+}
+
+func (s *stubStrategy) Name() string               { return s.name }
+func (s *stubStrategy) CanExtract(app string) bool { return app == "test" }
+func (s *stubStrategy) Priority() int              { return s.priority }
+func (s *stubStrategy) Extract(ctx context.Context, app string) (*Config, error) {
+	return s.config, s.err
+}
+
+func stubConfig(method string, confidence float64, settings map[string]Setting) *Config {
+	return &Config{
+		App:      "test",
+		Format:   "custom",
+		Settings: settings,
+		Source:   ExtractionSource{Method: method, Confidence: confidence},
+	}
+}
+
+func TestMergeConfigs(t *testing.T) {
+	cli := stubConfig("cli", 0.9, map[string]Setting{
+		"font-size": {Name: "font-size", Type: TypeNumber, Default: 13},
+		"theme":     {Name: "theme", Type: TypeString, Default: "auto"},
+	})
+	github := stubConfig("github", 0.8, map[string]Setting{
+		"font-size": {Name: "font-size", Type: TypeNumber, Default: 13, Desc: "Font size in points"},
+		"theme":     {Name: "theme", Type: TypeChoice, Default: "dark"},
+		"opacity":   {Name: "opacity", Type: TypeNumber},
+	})
+
+	merged, report := MergeConfigs("test", []*Config{github, cli})
+
+	if merged.Source.Method != "merged" || merged.Source.Location != "cli,github" {
+		t.Errorf("unexpected merged source: %+v", merged.Source)
+	}
+
+	fontSize := merged.Settings["font-size"]
+	if len(fontSize.Provenance) != 2 {
+		t.Fatalf("expected 2 provenance entries, got %d", len(fontSize.Provenance))
+	}
+	if fontSize.Desc != "Font size in points" {
+		t.Errorf("expected description from github, got %q", fontSize.Desc)
+	}
+	if len(fontSize.Conflicts) != 0 {
+		t.Errorf("expected no conflicts, got %v", fontSize.Conflicts)
+	}
+	if fontSize.Confidence != 0.98 {
+		t.Errorf("expected agreeing sources to reinforce confidence to 0.98, got %v", fontSize.Confidence)
+	}
+
+	theme := merged.Settings["theme"]
+	if theme.Type != TypeString || theme.Default != "auto" {
+		t.Errorf("expected most confident claims to win, got %s/%v", theme.Type, theme.Default)
+	}
+	if len(theme.Conflicts) != 2 {
+		t.Errorf("expected type and default conflicts, got %v", theme.Conflicts)
+	}
+	if theme.Confidence >= 0.9 {
+		t.Errorf("expected conflicting setting to lose confidence, got %v", theme.Confidence)
+	}
+
+	opacity := merged.Settings["opacity"]
+	if opacity.Confidence != 0.8 || len(opacity.Provenance) != 1 {
+		t.Errorf("expected single-source confidence 0.8, got %v", opacity.Confidence)
+	}
+
+	if len(report.Conflicts) != 2 {
+		t.Errorf("expected 2 conflicts in report, got %d", len(report.Conflicts))
+	}
+}
+
+func TestExtractor_ExtractMerged(t *testing.T) {
+	extractor := New(
+		WithStrategy(&stubStrategy{name: "one", priority: 300, config: stubConfig("one", 0.9, map[string]Setting{
+			"a": {Name: "a", Type: TypeBoolean, Default: true},
+		})}),
+		WithStrategy(&stubStrategy{name: "two", priority: 200, config: stubConfig("two", 0.7, map[string]Setting{
+			"b": {Name: "b", Type: TypeString},
+		})}),
+		WithStrategy(&stubStrategy{name: "broken", priority: 100, err: errors.New("boom")}),
+	)
+
+	config, report, err := extractor.ExtractMerged(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(config.Settings) != 2 {
+		t.Errorf("expected settings from both strategies, got %d", len(config.Settings))
+	}
+	if report.Failures["broken"] != "boom" {
+		t.Errorf("expected failure to be reported, got %v", report.Failures)
+	}
+	if len(report.Sources) != 2 {
+		t.Errorf("expected 2 sources, got %d", len(report.Sources))
+	}
+
+	if _, _, err := extractor.ExtractMerged(context.Background(), "unknown"); err == nil {
+		t.Error("expected error for unknown app")
+	}
+}
@@ -0,0 +1,50 @@
+package configextractor
+
+import (
+	"github.com/mrtkrcm/ZeroUI/pkg/reference"
+)
+
+// ToReference converts an extracted config into a reference.ConfigReference.
+// Choice settings become strings with ValidValues, matching the curated
+// reference format consumed by ReferenceConfigMapper.
+func ToReference(cfg *Config) *reference.ConfigReference {
+	ref := &reference.ConfigReference{
+		AppName:     cfg.App,
+		ConfigPath:  cfg.ConfigPath,
+		ConfigType:  cfg.Format,
+		LastUpdated: cfg.Timestamp,
+		Settings:    make(map[string]reference.ConfigSetting, len(cfg.Settings)),
+	}
+
+	for key, setting := range cfg.Settings {
+		name := setting.Name
+		if name == "" {
+			name = key
+		}
+
+		ref.Settings[key] = reference.ConfigSetting{
+			Name:         name,
+			Type:         toReferenceType(setting.Type),
+			Description:  setting.Desc,
+			DefaultValue: setting.Default,
+			ValidValues:  setting.Values,
+			Category:     setting.Cat,
+		}
+	}
+
+	return ref
+}
+
+// toReferenceType maps extractor setting types onto reference setting types
+func toReferenceType(t SettingType) reference.SettingType {
+	switch t {
+	case TypeNumber:
+		return reference.TypeNumber
+	case TypeBoolean:
+		return reference.TypeBoolean
+	case TypeArray:
+		return reference.TypeArray
+	default:
+		return reference.TypeString
+	}
+}
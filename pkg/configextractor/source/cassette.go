@@ -0,0 +1,159 @@
+package source
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// cassetteVersion is bumped when the cassette file format changes
+const cassetteVersion = 1
+
+// Interaction is one recorded response
+type Interaction struct {
+	URL    string `json:"url"`
+	Status int    `json:"status"`
+	Body   string `json:"body,omitempty"`
+	Error  string `json:"error,omitempty"`
+}
+
+type cassetteFile struct {
+	Version      int           `json:"version"`
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Cassette records responses from an inner source, or replays recorded ones.
+// Failed fetches are recorded too, so fallbacks (main, then master) replay identically.
+type Cassette struct {
+	path      string
+	inner     Source // nil when replaying
+	mu        sync.Mutex
+	responses map[string]Interaction
+}
+
+// NewRecorder creates a cassette that fetches through inner and records every response.
+// Existing recordings in path are kept and updated.
+func NewRecorder(path string, inner Source) *Cassette {
+	c := &Cassette{path: path, inner: inner, responses: make(map[string]Interaction)}
+	_ = c.load() // start fresh if the cassette does not exist yet
+	return c
+}
+
+// NewReplayer creates a cassette that serves only recorded responses
+func NewReplayer(path string) (*Cassette, error) {
+	c := &Cassette{path: path, responses: make(map[string]Interaction)}
+	if err := c.load(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// Fetch returns the recorded response for rawURL, recording it first when in record mode
+func (c *Cassette) Fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	if c.inner == nil {
+		c.mu.Lock()
+		rec, ok := c.responses[rawURL]
+		c.mu.Unlock()
+		if !ok {
+			return nil, fmt.Errorf("no recorded response for %s in %s", rawURL, c.path)
+		}
+		return rec.response()
+	}
+
+	data, err := c.inner.Fetch(ctx, rawURL)
+
+	// Context cancellation is not a property of the remote file; don't record it
+	if err != nil && ctx.Err() != nil {
+		return nil, err
+	}
+
+	rec := Interaction{URL: rawURL, Status: http.StatusOK, Body: string(data)}
+	if err != nil {
+		var statusErr *StatusError
+		if errors.As(err, &statusErr) {
+			rec = Interaction{URL: rawURL, Status: statusErr.Code}
+		} else {
+			rec = Interaction{URL: rawURL, Error: err.Error()}
+		}
+	}
+
+	c.mu.Lock()
+	c.responses[rawURL] = rec
+	c.mu.Unlock()
+
+	return data, err
+}
+
+// Close writes recorded interactions to the cassette file; replay cassettes are unchanged
+func (c *Cassette) Close() error {
+	if c.inner == nil {
+		return nil
+	}
+
+	c.mu.Lock()
+	file := cassetteFile{Version: cassetteVersion}
+	for _, rec := range c.responses {
+		file.Interactions = append(file.Interactions, rec)
+	}
+	c.mu.Unlock()
+
+	sort.Slice(file.Interactions, func(i, j int) bool {
+		return file.Interactions[i].URL < file.Interactions[j].URL
+	})
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode cassette: %w", err)
+	}
+
+	if dir := filepath.Dir(c.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("failed to create cassette directory: %w", err)
+		}
+	}
+
+	return os.WriteFile(c.path, append(data, '\n'), 0o644)
+}
+
+// Len returns the number of recorded interactions
+func (c *Cassette) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.responses)
+}
+
+func (c *Cassette) load() error {
+	data, err := os.ReadFile(c.path)
+	if err != nil {
+		return fmt.Errorf("failed to read cassette: %w", err)
+	}
+
+	var file cassetteFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return fmt.Errorf("failed to parse cassette %s: %w", c.path, err)
+	}
+	if file.Version != cassetteVersion {
+		return fmt.Errorf("unsupported cassette version %d in %s", file.Version, c.path)
+	}
+
+	for _, rec := range file.Interactions {
+		c.responses[rec.URL] = rec
+	}
+	return nil
+}
+
+func (i Interaction) response() ([]byte, error) {
+	if i.Error != "" {
+		return nil, errors.New(i.Error)
+	}
+	if i.Status != http.StatusOK {
+		return nil, &StatusError{URL: i.URL, Code: i.Status}
+	}
+	return []byte(i.Body), nil
+}
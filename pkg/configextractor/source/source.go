@@ -0,0 +1,156 @@
+// Package source provides pluggable backends for fetching remote files used by
+// extraction strategies: live HTTP, a local mirror directory, and record/replay
+// cassettes for reproducible offline runs.
+package source
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Mode selects a source backend
+type Mode string
+
+const (
+	ModeLive   Mode = "live"   // Fetch over HTTP
+	ModeMirror Mode = "mirror" // Read from a local mirror directory
+	ModeRecord Mode = "record" // Fetch over HTTP and record responses to a cassette
+	ModeReplay Mode = "replay" // Serve responses from a cassette only
+)
+
+// maxBodySize limits fetched file size for safety
+const maxBodySize = 10 << 20 // 10MB
+
+// Source fetches the content of a URL
+type Source interface {
+	Fetch(ctx context.Context, rawURL string) ([]byte, error)
+}
+
+// StatusError reports a non-200 HTTP response
+type StatusError struct {
+	URL  string
+	Code int
+}
+
+func (e *StatusError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.Code, http.StatusText(e.Code))
+}
+
+// New creates a source for the given mode. path is the mirror root for
+// ModeMirror and the cassette file for ModeRecord and ModeReplay.
+// Callers must call Close when done so recordings are written.
+func New(mode Mode, path string) (Source, error) {
+	switch mode {
+	case "", ModeLive:
+		return NewHTTP(nil), nil
+	case ModeMirror:
+		if path == "" {
+			return nil, fmt.Errorf("mirror mode requires a directory")
+		}
+		return NewMirror(path), nil
+	case ModeRecord:
+		if path == "" {
+			return nil, fmt.Errorf("record mode requires a cassette file")
+		}
+		return NewRecorder(path, NewHTTP(nil)), nil
+	case ModeReplay:
+		if path == "" {
+			return nil, fmt.Errorf("replay mode requires a cassette file")
+		}
+		return NewReplayer(path)
+	default:
+		return nil, fmt.Errorf("unknown source mode %q (expected live, mirror, record or replay)", mode)
+	}
+}
+
+// Close flushes sources that hold state, such as recording cassettes
+func Close(src Source) error {
+	if c, ok := src.(io.Closer); ok {
+		return c.Close()
+	}
+	return nil
+}
+
+// HTTP fetches files over the network
+type HTTP struct {
+	client *http.Client
+}
+
+// NewHTTP creates a live HTTP source; a nil client uses a default with a 15s timeout
+func NewHTTP(client *http.Client) *HTTP {
+	if client == nil {
+		client = &http.Client{Timeout: 15 * time.Second}
+	}
+	return &HTTP{client: client}
+}
+
+// Fetch performs a GET request and returns the body
+func (h *HTTP) Fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", rawURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "ZeroUI-Extractor/1.0")
+
+	resp, err := h.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch %s: %w", rawURL, err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, &StatusError{URL: rawURL, Code: resp.StatusCode}
+	}
+
+	return io.ReadAll(io.LimitReader(resp.Body, maxBodySize))
+}
+
+// Mirror serves files from a local directory. GitHub raw URLs map to
+// <root>/<owner>/<repo>/<branch>/<path>; other URLs map to <root>/<host>/<path>.
+type Mirror struct {
+	root string
+}
+
+// NewMirror creates a mirror source rooted at dir
+func NewMirror(dir string) *Mirror {
+	return &Mirror{root: dir}
+}
+
+// Fetch reads the mirrored file for rawURL; missing files report HTTP 404
+func (m *Mirror) Fetch(ctx context.Context, rawURL string) ([]byte, error) {
+	rel, err := MirrorPath(rawURL)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := os.ReadFile(filepath.Join(m.root, rel))
+	if os.IsNotExist(err) {
+		return nil, &StatusError{URL: rawURL, Code: http.StatusNotFound}
+	}
+	return data, err
+}
+
+// MirrorPath returns the mirror-relative path for a URL
+func MirrorPath(rawURL string) (string, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", fmt.Errorf("invalid URL %s: %w", rawURL, err)
+	}
+
+	clean := filepath.Clean("/" + u.Path)
+	if strings.Contains(clean, "..") {
+		return "", fmt.Errorf("invalid URL path: %s", rawURL)
+	}
+
+	if u.Host == "raw.githubusercontent.com" {
+		return filepath.FromSlash(strings.TrimPrefix(clean, "/")), nil
+	}
+	return filepath.Join(u.Host, filepath.FromSlash(strings.TrimPrefix(clean, "/"))), nil
+}
@@ -0,0 +1,122 @@
+package source
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMirrorPath(t *testing.T) {
+	tests := []struct {
+		url     string
+		want    string
+		wantErr bool
+	}{
+		{"https://raw.githubusercontent.com/zed-industries/zed/main/assets/settings/default.json", "zed-industries/zed/main/assets/settings/default.json", false},
+		{"https://starship.rs/config-schema.json", "starship.rs/config-schema.json", false},
+		{"https://raw.githubusercontent.com/a/b/main/../../../etc/passwd", "etc/passwd", false},
+		{"://bad", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.url, func(t *testing.T) {
+			got, err := MirrorPath(tt.url)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MirrorPath() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != filepath.FromSlash(tt.want) {
+				t.Errorf("MirrorPath() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMirror_Fetch(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "alacritty", "alacritty", "master", "alacritty.yml")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("font:\n  size: 11\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	mirror := NewMirror(root)
+
+	data, err := mirror.Fetch(context.Background(), "https://raw.githubusercontent.com/alacritty/alacritty/master/alacritty.yml")
+	if err != nil || string(data) != "font:\n  size: 11\n" {
+		t.Fatalf("unexpected mirror result: %q, %v", data, err)
+	}
+
+	_, err = mirror.Fetch(context.Background(), "https://raw.githubusercontent.com/alacritty/alacritty/main/alacritty.yml")
+	var statusErr *StatusError
+	if !errors.As(err, &statusErr) || statusErr.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for missing mirror file, got %v", err)
+	}
+}
+
+func TestCassette_RecordReplay(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path == "/missing" {
+			http.NotFound(w, r)
+			return
+		}
+		_, _ = w.Write([]byte("body for " + r.URL.Path))
+	}))
+	defer server.Close()
+
+	cassettePath := filepath.Join(t.TempDir(), "fixtures", "extract.cassette.json")
+
+	recorder, err := New(ModeRecord, cassettePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, err := recorder.Fetch(context.Background(), server.URL+"/config"); err != nil || string(data) != "body for /config" {
+		t.Fatalf("unexpected recorded fetch: %q, %v", data, err)
+	}
+	if _, err := recorder.Fetch(context.Background(), server.URL+"/missing"); err == nil {
+		t.Fatal("expected 404 while recording")
+	}
+	if err := Close(recorder); err != nil {
+		t.Fatalf("failed to save cassette: %v", err)
+	}
+
+	server.Close()
+
+	replayer, err := New(ModeReplay, cassettePath)
+	if err != nil {
+		t.Fatalf("failed to open cassette: %v", err)
+	}
+	if data, err := replayer.Fetch(context.Background(), server.URL+"/config"); err != nil || string(data) != "body for /config" {
+		t.Errorf("unexpected replayed fetch: %q, %v", data, err)
+	}
+
+	var statusErr *StatusError
+	if _, err := replayer.Fetch(context.Background(), server.URL+"/missing"); !errors.As(err, &statusErr) || statusErr.Code != http.StatusNotFound {
+		t.Errorf("expected recorded 404 to replay, got %v", err)
+	}
+	if _, err := replayer.Fetch(context.Background(), server.URL+"/unrecorded"); err == nil {
+		t.Error("expected error for unrecorded URL")
+	}
+	if requests != 2 {
+		t.Errorf("expected replay to make no requests, got %d total", requests)
+	}
+}
+
+func TestNew_InvalidMode(t *testing.T) {
+	if _, err := New("ftp", ""); err == nil {
+		t.Error("expected error for unknown mode")
+	}
+	if _, err := New(ModeMirror, ""); err == nil {
+		t.Error("expected error for mirror without directory")
+	}
+	if _, err := New(ModeReplay, filepath.Join(t.TempDir(), "missing.json")); err == nil {
+		t.Error("expected error for missing cassette")
+	}
+}
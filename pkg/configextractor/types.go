@@ -35,6 +35,20 @@ type Setting struct {
 	Values  []string    `json:"values,omitempty"` // For enum/choice types
 	Desc    string      `json:"description,omitempty"`
 	Cat     string      `json:"category,omitempty"`
+
+	// Populated by ExtractMerged only
+	Provenance []SettingSource `json:"provenance,omitempty"` // What each strategy reported
+	Confidence float64         `json:"confidence,omitempty"` // Combined confidence (0.0-1.0)
+	Conflicts  []string        `json:"conflicts,omitempty"`  // Fields strategies disagree on
+}
+
+// SettingSource records what a single strategy reported for a setting
+type SettingSource struct {
+	Method     string      `json:"method"`
+	Location   string      `json:"location,omitempty"`
+	Type       SettingType `json:"type"`
+	Default    interface{} `json:"default,omitempty"`
+	Confidence float64     `json:"confidence"`
 }
 
 // SettingType simplified enum
@@ -0,0 +1,66 @@
+package configmerge
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Labels name the three inputs in conflict markers
+type Labels struct {
+	Ours   string
+	Base   string
+	Theirs string
+}
+
+// LineFunc renders one config line for a key and value, such as
+// "font-size = 13" for Ghostty or "font-size: 13" for YAML
+type LineFunc func(key string, value interface{}) string
+
+// KeyValueLine renders "key = value" lines, the Ghostty and kitty style
+func KeyValueLine(key string, value interface{}) string {
+	return fmt.Sprintf("%s = %v", key, value)
+}
+
+// FormatConflicts renders conflicts as diff3-style conflict markers, one block
+// per conflict, with the ours, base and theirs sections in that order. List
+// values are written one line per entry; a missing value leaves its section
+// empty.
+func FormatConflicts(conflicts []Conflict, labels Labels, line LineFunc) string {
+	if line == nil {
+		line = KeyValueLine
+	}
+	if labels.Ours == "" {
+		labels.Ours = string(Ours)
+	}
+	if labels.Base == "" {
+		labels.Base = "base"
+	}
+	if labels.Theirs == "" {
+		labels.Theirs = string(Theirs)
+	}
+
+	var b strings.Builder
+	for i, c := range conflicts {
+		if i > 0 {
+			b.WriteString("\n")
+		}
+		fmt.Fprintf(&b, "<<<<<<< %s\n", labels.Ours)
+		writeLines(&b, c.Key, c.Ours, line)
+		fmt.Fprintf(&b, "||||||| %s\n", labels.Base)
+		writeLines(&b, c.Key, c.Base, line)
+		b.WriteString("=======\n")
+		writeLines(&b, c.Key, c.Theirs, line)
+		fmt.Fprintf(&b, ">>>>>>> %s\n", labels.Theirs)
+	}
+	return b.String()
+}
+
+func writeLines(b *strings.Builder, key string, value interface{}, line LineFunc) {
+	if value == nil {
+		return
+	}
+	for _, entry := range listOf(value, true) {
+		b.WriteString(line(key, entry))
+		b.WriteString("\n")
+	}
+}
@@ -0,0 +1,313 @@
+// Package configmerge merges configuration maps three ways: the changes made
+// from a common base to "ours" and to "theirs" are combined key by key, and
+// keys both sides changed differently are reported as typed conflicts.
+//
+// Maps are flat, keyed by dotted paths as koanf's All returns them. List
+// values, such as Ghostty's repeated keybind lines, are merged entry by entry,
+// so adding different keybinds on each side does not conflict.
+package configmerge
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// ConflictKind describes how the two sides disagree about a key
+type ConflictKind string
+
+const (
+	// BothModified means both sides changed the base value, differently
+	BothModified ConflictKind = "both-modified"
+	// BothAdded means the key is new on both sides, with different values
+	BothAdded ConflictKind = "both-added"
+	// ModifiedDeleted means ours changed the value and theirs deleted it
+	ModifiedDeleted ConflictKind = "modified-deleted"
+	// DeletedModified means ours deleted the value and theirs changed it
+	DeletedModified ConflictKind = "deleted-modified"
+)
+
+// Side names one input of a merge
+type Side string
+
+const (
+	Ours   Side = "ours"
+	Theirs Side = "theirs"
+)
+
+// Conflict is a key, or one entry of a list key, that both sides changed
+// differently. Values are nil where the side doesn't have the key.
+type Conflict struct {
+	Key string
+	// Entry identifies the list entry in conflict, for list keys merged by
+	// identity (the trigger of a keybind); empty for whole values
+	Entry  string
+	Kind   ConflictKind
+	Base   interface{}
+	Ours   interface{}
+	Theirs interface{}
+}
+
+// Options adjusts a merge
+type Options struct {
+	// ListIdentity names the entries of list keys. Entries with the same
+	// identity are the same setting, so changing one on both sides is a
+	// conflict. Lists without an identity function are merged as sets.
+	ListIdentity map[string]func(entry string) string
+	// Prefer resolves every conflict by taking that side's value. When empty,
+	// conflicts are left in Result.Conflicts.
+	Prefer Side
+}
+
+// Result is the outcome of a merge
+type Result struct {
+	// Merged holds every key of the result. Conflicting keys and list entries
+	// keep ours unless Options.Prefer says otherwise.
+	Merged map[string]interface{}
+	// Conflicts lists unresolved conflicts, sorted by key and entry
+	Conflicts []Conflict
+	// Resolved lists conflicts settled by Options.Prefer
+	Resolved []Conflict
+	// Changed lists the keys whose merged value differs from ours, the
+	// changes the merge brings in from theirs
+	Changed []string
+}
+
+// HasConflicts reports whether the merge left conflicts to resolve
+func (r *Result) HasConflicts() bool {
+	return len(r.Conflicts) > 0
+}
+
+// ConflictKeys returns the keys with unresolved conflicts, without duplicates
+func (r *Result) ConflictKeys() []string {
+	var keys []string
+	seen := make(map[string]bool)
+	for _, c := range r.Conflicts {
+		if !seen[c.Key] {
+			seen[c.Key] = true
+			keys = append(keys, c.Key)
+		}
+	}
+	return keys
+}
+
+// Merge combines the changes from base to ours and from base to theirs. A
+// key changed on one side only takes that side's value; a key changed on both
+// sides to the same value takes it; anything else is a conflict.
+func Merge(base, ours, theirs map[string]interface{}, opts Options) *Result {
+	result := &Result{Merged: make(map[string]interface{})}
+
+	keys := make(map[string]bool)
+	for _, m := range []map[string]interface{}{base, ours, theirs} {
+		for key := range m {
+			keys[key] = true
+		}
+	}
+
+	for key := range keys {
+		bv, inBase := base[key]
+		ov, inOurs := ours[key]
+		tv, inTheirs := theirs[key]
+
+		value, present, conflict := mergeValue(bv, inBase, ov, inOurs, tv, inTheirs)
+		switch {
+		case conflict == nil:
+		case isList(bv) || isList(ov) || isList(tv) || opts.ListIdentity[key] != nil:
+			var conflicts []Conflict
+			value, present, conflicts = mergeList(key, bv, inBase, ov, inOurs, tv, inTheirs, opts)
+			for _, c := range conflicts {
+				result.addConflict(c, opts.Prefer)
+			}
+		default:
+			conflict.Key = key
+			result.addConflict(*conflict, opts.Prefer)
+			value, present = pick(opts.Prefer, ov, inOurs, tv, inTheirs)
+		}
+
+		if present {
+			result.Merged[key] = value
+		}
+		if !sameEntry(value, present, ov, inOurs) {
+			result.Changed = append(result.Changed, key)
+		}
+	}
+
+	sort.Strings(result.Changed)
+	sortConflicts(result.Conflicts)
+	sortConflicts(result.Resolved)
+	return result
+}
+
+// pick settles a conflict: theirs when preferred, ours otherwise
+func pick(prefer Side, ov interface{}, inOurs bool, tv interface{}, inTheirs bool) (interface{}, bool) {
+	if prefer == Theirs {
+		return tv, inTheirs
+	}
+	return ov, inOurs
+}
+
+func (r *Result) addConflict(c Conflict, prefer Side) {
+	if prefer == "" {
+		r.Conflicts = append(r.Conflicts, c)
+	} else {
+		r.Resolved = append(r.Resolved, c)
+	}
+}
+
+// mergeValue merges one value three ways. The conflict it returns has no key.
+func mergeValue(bv interface{}, inBase bool, ov interface{}, inOurs bool, tv interface{}, inTheirs bool) (interface{}, bool, *Conflict) {
+	switch {
+	case sameEntry(bv, inBase, ov, inOurs):
+		return tv, inTheirs, nil
+	case sameEntry(bv, inBase, tv, inTheirs), sameEntry(ov, inOurs, tv, inTheirs):
+		return ov, inOurs, nil
+	}
+
+	conflict := &Conflict{Kind: conflictKind(inBase, inOurs, inTheirs)}
+	if inBase {
+		conflict.Base = bv
+	}
+	if inOurs {
+		conflict.Ours = ov
+	}
+	if inTheirs {
+		conflict.Theirs = tv
+	}
+	return nil, false, conflict
+}
+
+func conflictKind(inBase, inOurs, inTheirs bool) ConflictKind {
+	switch {
+	case !inBase:
+		return BothAdded
+	case !inOurs:
+		return DeletedModified
+	case !inTheirs:
+		return ModifiedDeleted
+	}
+	return BothModified
+}
+
+// mergeList merges list values entry by entry. The result follows theirs'
+// order, with entries only ours has appended in ours' order.
+func mergeList(key string, bv interface{}, inBase bool, ov interface{}, inOurs bool, tv interface{}, inTheirs bool, opts Options) (interface{}, bool, []Conflict) {
+	identity := opts.ListIdentity[key]
+	if identity == nil {
+		identity = func(entry string) string { return entry }
+	}
+	base := indexEntries(listOf(bv, inBase), identity)
+	ours := indexEntries(listOf(ov, inOurs), identity)
+	theirs := indexEntries(listOf(tv, inTheirs), identity)
+
+	order := append([]string(nil), theirs.order...)
+	for _, id := range ours.order {
+		if _, ok := theirs.entries[id]; !ok {
+			order = append(order, id)
+		}
+	}
+
+	var merged []interface{}
+	var conflicts []Conflict
+	for _, id := range order {
+		b, inB := base.entries[id]
+		o, inO := ours.entries[id]
+		t, inT := theirs.entries[id]
+
+		value, present, conflict := mergeValue(b, inB, o, inO, t, inT)
+		if conflict != nil {
+			conflict.Key = key
+			conflict.Entry = id
+			conflicts = append(conflicts, *conflict)
+			value, present = pick(opts.Prefer, o, inO, t, inT)
+		}
+		if present {
+			merged = append(merged, value)
+		}
+	}
+
+	switch {
+	case len(merged) == 0:
+		return nil, false, conflicts
+	case len(merged) == 1 && !isList(ov) && !(!inOurs && isList(tv)):
+		return merged[0], true, conflicts
+	}
+	return merged, true, conflicts
+}
+
+type entryIndex struct {
+	order   []string
+	entries map[string]interface{}
+}
+
+// indexEntries keys list entries by identity; a later entry with the same
+// identity replaces an earlier one, as repeated config lines do
+func indexEntries(list []interface{}, identity func(string) string) entryIndex {
+	index := entryIndex{entries: make(map[string]interface{})}
+	for _, entry := range list {
+		id := identity(fmt.Sprintf("%v", entry))
+		if _, ok := index.entries[id]; !ok {
+			index.order = append(index.order, id)
+		}
+		index.entries[id] = entry
+	}
+	return index
+}
+
+func listOf(value interface{}, present bool) []interface{} {
+	if !present {
+		return nil
+	}
+	switch v := value.(type) {
+	case []interface{}:
+		return v
+	case []string:
+		list := make([]interface{}, len(v))
+		for i, s := range v {
+			list[i] = s
+		}
+		return list
+	}
+	return []interface{}{value}
+}
+
+func isList(value interface{}) bool {
+	switch value.(type) {
+	case []interface{}, []string:
+		return true
+	}
+	return false
+}
+
+// sameEntry compares two optional values by their printed form, the way the
+// rest of ZeroUI compares config values: parsed files hold strings where
+// callers may set numbers
+func sameEntry(a interface{}, aOK bool, b interface{}, bOK bool) bool {
+	if aOK != bOK {
+		return false
+	}
+	return !aOK || fmt.Sprintf("%v", a) == fmt.Sprintf("%v", b)
+}
+
+func sortConflicts(conflicts []Conflict) {
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].Key != conflicts[j].Key {
+			return conflicts[i].Key < conflicts[j].Key
+		}
+		return conflicts[i].Entry < conflicts[j].Entry
+	})
+}
+
+// KnownLists identifies the entries of list keys used by supported apps, for
+// Options.ListIdentity
+var KnownLists = map[string]func(entry string) string{
+	"keybind": KeybindTrigger,
+}
+
+// KeybindTrigger identifies a Ghostty keybind entry by its trigger, the part
+// before the first "=", so rebinding the same keys on both sides conflicts
+func KeybindTrigger(entry string) string {
+	if i := strings.Index(entry, "="); i > 0 {
+		return strings.TrimSpace(entry[:i])
+	}
+	return strings.TrimSpace(entry)
+}
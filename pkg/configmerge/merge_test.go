@@ -0,0 +1,135 @@
+package configmerge
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMerge(t *testing.T) {
+	base := map[string]interface{}{
+		"font-size":   "13",
+		"theme":       "light",
+		"cursor":      "block",
+		"opacity":     "1.0",
+		"window.size": "80x24",
+	}
+	ours := map[string]interface{}{
+		"font-size":   16,
+		"theme":       "light",
+		"cursor":      "bar",
+		"window.size": "80x24",
+		"shell":       "fish",
+	}
+	theirs := map[string]interface{}{
+		"font-size":   "13",
+		"theme":       "dark",
+		"cursor":      "underline",
+		"opacity":     "0.9",
+		"window.size": "100x30",
+		"shell":       "zsh",
+	}
+
+	result := Merge(base, ours, theirs, Options{})
+
+	wantMerged := map[string]interface{}{
+		"font-size":   16,
+		"theme":       "dark",
+		"cursor":      "bar",
+		"window.size": "100x30",
+		"shell":       "fish",
+	}
+	if !reflect.DeepEqual(result.Merged, wantMerged) {
+		t.Errorf("Merged = %v, want %v", result.Merged, wantMerged)
+	}
+
+	wantConflicts := []Conflict{
+		{Key: "cursor", Kind: BothModified, Base: "block", Ours: "bar", Theirs: "underline"},
+		{Key: "opacity", Kind: DeletedModified, Base: "1.0", Theirs: "0.9"},
+		{Key: "shell", Kind: BothAdded, Ours: "fish", Theirs: "zsh"},
+	}
+	if !reflect.DeepEqual(result.Conflicts, wantConflicts) {
+		t.Errorf("Conflicts = %+v, want %+v", result.Conflicts, wantConflicts)
+	}
+	if got := result.Changed; !reflect.DeepEqual(got, []string{"theme", "window.size"}) {
+		t.Errorf("Changed = %v", got)
+	}
+
+	resolved := Merge(base, ours, theirs, Options{Prefer: Theirs})
+	if resolved.HasConflicts() || len(resolved.Resolved) != 3 {
+		t.Fatalf("Expected every conflict resolved, got %+v", resolved)
+	}
+	if resolved.Merged["cursor"] != "underline" || resolved.Merged["opacity"] != "0.9" {
+		t.Errorf("Expected theirs to win conflicts, got %v", resolved.Merged)
+	}
+}
+
+func TestMerge_Lists(t *testing.T) {
+	base := map[string]interface{}{
+		"keybind": []interface{}{"ctrl+a=select_all", "ctrl+c=copy"},
+		"palette": "0=#000000",
+	}
+	ours := map[string]interface{}{
+		"keybind": []interface{}{"ctrl+a=select_all", "ctrl+c=copy", "ctrl+t=new_tab"},
+		"palette": []interface{}{"0=#000000", "1=#ff0000"},
+	}
+	theirs := map[string]interface{}{
+		"keybind": []interface{}{"ctrl+c=copy", "ctrl+w=close_surface"},
+		"palette": []interface{}{"0=#000000", "2=#00ff00"},
+	}
+	opts := Options{ListIdentity: map[string]func(string) string{"keybind": KeybindTrigger}}
+
+	result := Merge(base, ours, theirs, opts)
+	if result.HasConflicts() {
+		t.Fatalf("Expected independent list edits to merge, got %+v", result.Conflicts)
+	}
+	wantKeybind := []interface{}{"ctrl+c=copy", "ctrl+w=close_surface", "ctrl+t=new_tab"}
+	if !reflect.DeepEqual(result.Merged["keybind"], wantKeybind) {
+		t.Errorf("keybind = %v, want %v", result.Merged["keybind"], wantKeybind)
+	}
+	wantPalette := []interface{}{"0=#000000", "2=#00ff00", "1=#ff0000"}
+	if !reflect.DeepEqual(result.Merged["palette"], wantPalette) {
+		t.Errorf("palette = %v, want %v", result.Merged["palette"], wantPalette)
+	}
+
+	// Rebinding the same trigger differently on both sides conflicts
+	ours["keybind"] = []interface{}{"ctrl+a=select_all", "ctrl+c=copy_to_clipboard"}
+	theirs["keybind"] = []interface{}{"ctrl+a=select_all", "ctrl+c=copy:plain"}
+	result = Merge(base, ours, theirs, opts)
+	want := []Conflict{{Key: "keybind", Entry: "ctrl+c", Kind: BothModified, Base: "ctrl+c=copy", Ours: "ctrl+c=copy_to_clipboard", Theirs: "ctrl+c=copy:plain"}}
+	if !reflect.DeepEqual(result.Conflicts, want) {
+		t.Errorf("Conflicts = %+v, want %+v", result.Conflicts, want)
+	}
+	if keys := result.ConflictKeys(); !reflect.DeepEqual(keys, []string{"keybind"}) {
+		t.Errorf("ConflictKeys = %v", keys)
+	}
+}
+
+func TestFormatConflicts(t *testing.T) {
+	conflicts := []Conflict{
+		{Key: "font-size", Kind: ModifiedDeleted, Base: "13", Ours: "16"},
+		{Key: "keybind", Entry: "ctrl+c", Kind: BothModified, Base: "ctrl+c=copy", Ours: "ctrl+c=paste", Theirs: "ctrl+c=copy:plain"},
+	}
+
+	got := FormatConflicts(conflicts, Labels{Ours: "config", Theirs: "team.conf"}, nil)
+	want := strings.Join([]string{
+		"<<<<<<< config",
+		"font-size = 16",
+		"||||||| base",
+		"font-size = 13",
+		"=======",
+		">>>>>>> team.conf",
+		"",
+		"<<<<<<< config",
+		"keybind = ctrl+c=paste",
+		"||||||| base",
+		"keybind = ctrl+c=copy",
+		"=======",
+		"keybind = ctrl+c=copy:plain",
+		">>>>>>> team.conf",
+		"",
+	}, "\n")
+	if got != want {
+		t.Errorf("FormatConflicts =\n%s\nwant\n%s", got, want)
+	}
+}
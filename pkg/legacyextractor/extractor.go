@@ -1,6 +1,7 @@
 package legacyextractor
 
 import (
+	"bytes"
 	"context"
 	"fmt"
 	"io"
@@ -8,6 +9,8 @@ import (
 	"strings"
 	"sync"
 	"time"
+
+	"github.com/mrtkrcm/ZeroUI/pkg/configextractor/source"
 )
 
 // Extractor is the main configuration extraction orchestrator
@@ -35,6 +38,19 @@ type Setting struct {
 	Values  []string    `yaml:"valid_values,omitempty"`
 	Desc    string      `yaml:"description,omitempty"`
 	Cat     string      `yaml:"category,omitempty"`
+
+	// Populated by ExtractMerged only
+	Sources    []SettingSource `yaml:"sources,omitempty"`
+	Confidence float64         `yaml:"confidence,omitempty"`
+	Conflicts  []string        `yaml:"conflicts,omitempty"`
+}
+
+// SettingSource records what a single strategy reported for a setting
+type SettingSource struct {
+	Strategy   string      `yaml:"strategy"`
+	Type       string      `yaml:"type"`
+	Default    interface{} `yaml:"default_value,omitempty"`
+	Confidence float64     `yaml:"confidence"`
 }
 
 // HTTPClient interface for HTTP operations
@@ -66,6 +82,19 @@ func (c *DefaultHTTPClient) Get(ctx context.Context, url string) (io.ReadCloser,
 	return resp.Body, nil
 }
 
+// SourceClient adapts a source.Source (live, mirror or cassette) to HTTPClient
+type SourceClient struct {
+	Source source.Source
+}
+
+func (c *SourceClient) Get(ctx context.Context, url string) (io.ReadCloser, error) {
+	data, err := c.Source.Fetch(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	return io.NopCloser(bytes.NewReader(data)), nil
+}
+
 // Option configures the extractor
 type Option func(*Extractor)
 
@@ -90,6 +119,18 @@ func WithConcurrency(n int) Option {
 	}
 }
 
+// WithHTTPClient sets the client used by the default GitHub strategy
+func WithHTTPClient(client HTTPClient) Option {
+	return func(e *Extractor) {
+		e.client = client
+	}
+}
+
+// WithSource fetches remote files through src, e.g. a local mirror or a cassette
+func WithSource(src source.Source) Option {
+	return WithHTTPClient(&SourceClient{Source: src})
+}
+
 // WithStrategy adds a custom strategy
 func WithStrategy(strategy Strategy) Option {
 	return func(e *Extractor) {
@@ -136,7 +177,7 @@ func New(opts ...Option) *Extractor {
 			NewCLIStrategy(),
 			NewLocalStrategy("configs"),
 			NewBuiltinStrategy(),
-			NewGitHubStrategy(httpClient),
+			NewGitHubStrategy(e.client),
 		}
 	}
 
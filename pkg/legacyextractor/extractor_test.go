@@ -3,8 +3,12 @@ package legacyextractor
 import (
 	"context"
 	"fmt"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
+
+	"github.com/mrtkrcm/ZeroUI/pkg/configextractor/source"
 )
 
 // Mock strategy for testing
@@ -134,6 +138,104 @@ func TestExtractor_ExtractBatch(t *testing.T) {
 	}
 }
 
+func TestExtractor_ExtractMerged(t *testing.T) {
+	extractor := New(
+		WithStrategy(&mockStrategy{
+			name:       "cli",
+			confidence: 0.95,
+			config: &Config{
+				App: "test",
+				Settings: map[string]Setting{
+					"font-size": {Name: "font-size", Type: "number", Default: 13},
+					"theme":     {Name: "theme", Type: "string", Default: "auto"},
+				},
+			},
+		}),
+		WithStrategy(&mockStrategy{
+			name:       "builtin",
+			confidence: 0.6,
+			config: &Config{
+				App: "test",
+				Settings: map[string]Setting{
+					"font-size": {Name: "font-size", Type: "string", Default: 13, Desc: "Font size"},
+				},
+			},
+		}),
+		WithStrategy(&mockStrategy{name: "broken", confidence: 0.8, err: fmt.Errorf("unavailable")}),
+	)
+
+	cfg, report, err := extractor.ExtractMerged(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("ExtractMerged() error = %v", err)
+	}
+
+	fontSize := cfg.Settings["font-size"]
+	if fontSize.Type != "number" {
+		t.Errorf("expected most confident type to win, got %s", fontSize.Type)
+	}
+	if fontSize.Desc != "Font size" {
+		t.Errorf("expected description to be filled from builtin, got %q", fontSize.Desc)
+	}
+	if len(fontSize.Sources) != 2 || len(fontSize.Conflicts) != 1 {
+		t.Errorf("expected 2 sources and a type conflict, got %+v", fontSize)
+	}
+	if fontSize.Confidence >= cfg.Settings["theme"].Confidence {
+		t.Errorf("expected conflicting setting to have lower confidence: %v vs %v",
+			fontSize.Confidence, cfg.Settings["theme"].Confidence)
+	}
+
+	if len(report.Conflicts) != 1 || report.Failures["broken"] != "unavailable" {
+		t.Errorf("unexpected report: %+v", report)
+	}
+}
+
+func TestExtractor_ExtractMergedKeepsFinishedOnTimeout(t *testing.T) {
+	extractor := New(
+		WithTimeout(50*time.Millisecond),
+		WithStrategy(&mockStrategy{
+			name:       "cli",
+			confidence: 0.95,
+			config: &Config{
+				App:      "test",
+				Settings: map[string]Setting{"font-size": {Name: "font-size", Type: "number"}},
+			},
+		}),
+		WithStrategy(&mockStrategy{name: "github", confidence: 0.8, delay: time.Second}),
+	)
+
+	cfg, report, err := extractor.ExtractMerged(context.Background(), "test")
+	if err != nil {
+		t.Fatalf("expected the finished strategy to be merged, got %v", err)
+	}
+	if _, ok := cfg.Settings["font-size"]; !ok {
+		t.Errorf("expected font-size from cli, got %+v", cfg.Settings)
+	}
+	if report.Failures["github"] != context.DeadlineExceeded.Error() {
+		t.Errorf("expected the hung strategy to be reported, got %+v", report.Failures)
+	}
+}
+
+func TestGitHubStrategy_MirrorSource(t *testing.T) {
+	root := t.TempDir()
+	path := filepath.Join(root, "zed-industries", "zed", "master", "assets", "settings", "default.json")
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte("theme = \"One Dark\"\nbuffer_font_size = 15\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	// main is missing from the mirror, so the strategy must fall back to master
+	strategy := NewGitHubStrategy(&SourceClient{Source: source.NewMirror(root)})
+	cfg, err := strategy.Extract(context.Background(), "zed")
+	if err != nil {
+		t.Fatalf("Extract() error = %v", err)
+	}
+	if len(cfg.Settings) == 0 {
+		t.Error("expected settings from mirrored file")
+	}
+}
+
 func TestLRUCache(t *testing.T) {
 	cache := NewLRUCache(2, 1*time.Hour)
 
@@ -0,0 +1,174 @@
+package legacyextractor
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/mrtkrcm/ZeroUI/pkg/configextractor"
+)
+
+// MergeReport describes how a merged config was assembled
+type MergeReport struct {
+	App       string            `yaml:"app_name"`
+	Sources   []string          `yaml:"sources"`
+	Failures  map[string]string `yaml:"failures,omitempty"`
+	Conflicts []SettingConflict `yaml:"conflicts,omitempty"`
+}
+
+// SettingConflict lists disagreeing strategy claims for one setting field
+type SettingConflict struct {
+	Key    string          `yaml:"key"`
+	Field  string          `yaml:"field"`
+	Claims []SettingSource `yaml:"claims"`
+}
+
+// ExtractMerged runs all strategies and merges every successful result, recording
+// per-setting provenance and a combined confidence instead of picking one winner
+func (e *Extractor) ExtractMerged(ctx context.Context, app string) (*Config, *MergeReport, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.timeout)
+	defer cancel()
+
+	type result struct {
+		config     *Config
+		name       string
+		confidence float64
+		err        error
+	}
+
+	results := make(chan result, len(e.strategies))
+
+	for _, strategy := range e.strategies {
+		go func(s Strategy) {
+			cfg, err := s.Extract(ctx, app)
+			results <- result{config: cfg, name: s.Name(), confidence: s.Confidence(), err: err}
+		}(strategy)
+	}
+
+	var claims []strategyResult
+	failures := make(map[string]string)
+	finished := make(map[string]bool)
+
+	// Strategies still running at the deadline are failures; the results
+	// that came in are merged
+collect:
+	for i := 0; i < len(e.strategies); i++ {
+		select {
+		case <-ctx.Done():
+			for _, s := range e.strategies {
+				if !finished[s.Name()] {
+					failures[s.Name()] = ctx.Err().Error()
+				}
+			}
+			break collect
+		case r := <-results:
+			finished[r.name] = true
+			switch {
+			case r.err != nil:
+				failures[r.name] = r.err.Error()
+			case r.config == nil:
+				failures[r.name] = "no configuration returned"
+			default:
+				claims = append(claims, strategyResult{name: r.name, confidence: r.confidence, config: r.config})
+			}
+		}
+	}
+
+	if len(claims) == 0 {
+		return nil, nil, fmt.Errorf("no extraction method succeeded for %s", app)
+	}
+
+	merged, report := mergeResults(app, claims)
+	if len(failures) > 0 {
+		report.Failures = failures
+	}
+
+	return merged, report, nil
+}
+
+type strategyResult struct {
+	name       string
+	confidence float64
+	config     *Config
+}
+
+// mergeResults combines strategy results by confidence-weighted vote
+func mergeResults(app string, results []strategyResult) (*Config, *MergeReport) {
+	sort.SliceStable(results, func(i, j int) bool {
+		if results[i].confidence != results[j].confidence {
+			return results[i].confidence > results[j].confidence
+		}
+		return results[i].name < results[j].name
+	})
+
+	report := &MergeReport{App: app}
+	merged := &Config{App: app, Settings: make(map[string]Setting)}
+
+	keys := make(map[string]bool)
+	for _, r := range results {
+		report.Sources = append(report.Sources, r.name)
+		if merged.Path == "" {
+			merged.Path = r.config.Path
+		}
+		if merged.Type == "" {
+			merged.Type = r.config.Type
+		}
+		for key := range r.config.Settings {
+			keys[key] = true
+		}
+	}
+
+	sortedKeys := make([]string, 0, len(keys))
+	for key := range keys {
+		sortedKeys = append(sortedKeys, key)
+	}
+	sort.Strings(sortedKeys)
+
+	for _, key := range sortedKeys {
+		var out Setting
+		out.Name = key
+
+		for _, r := range results {
+			s, ok := r.config.Settings[key]
+			if !ok {
+				continue
+			}
+			out.Sources = append(out.Sources, SettingSource{
+				Strategy:   r.name,
+				Type:       s.Type,
+				Default:    s.Default,
+				Confidence: r.confidence,
+			})
+			if out.Desc == "" {
+				out.Desc = s.Desc
+			}
+			if len(out.Values) == 0 {
+				out.Values = s.Values
+			}
+			if out.Cat == "" {
+				out.Cat = s.Cat
+			}
+		}
+
+		claims := make([]configextractor.Claim, len(out.Sources))
+		for i, c := range out.Sources {
+			claims[i] = configextractor.Claim{Type: c.Type, Default: c.Default, Confidence: c.Confidence}
+		}
+		vote := configextractor.VoteClaims(claims)
+		out.Type = vote.Type
+		out.Default = vote.Default
+		out.Confidence = vote.Confidence
+		if vote.TypeConflict {
+			out.Conflicts = append(out.Conflicts, "type")
+			report.Conflicts = append(report.Conflicts, SettingConflict{Key: key, Field: "type", Claims: out.Sources})
+		}
+		if vote.DefaultConflict {
+			out.Conflicts = append(out.Conflicts, "default")
+			report.Conflicts = append(report.Conflicts, SettingConflict{Key: key, Field: "default", Claims: out.Sources})
+		}
+
+		merged.Settings[key] = out
+	}
+
+	return merged, report
+}
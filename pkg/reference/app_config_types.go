@@ -18,7 +18,9 @@ type FieldConfig struct {
 	Values      []string    `yaml:"values,omitempty"`
 	Default     interface{} `yaml:"default,omitempty"`
 	Description string      `yaml:"description,omitempty"`
-	Path        string      `yaml:"path,omitempty"` // JSON path for nested values
+	Path        string      `yaml:"path,omitempty"`         // JSON path for nested values
+	Required    bool        `yaml:"required,omitempty"`     // Must stay present; reset writes the default
+	EnvOverride string      `yaml:"env_override,omitempty"` // Environment variable that overrides the value at runtime
 }
 
 // PresetConfig represents a preset configuration (copy from config package to avoid import cycle)
@@ -0,0 +1,184 @@
+package reference
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// Deprecation describes a setting that an application renamed or dropped.
+// Deprecations are keyed by the old setting name in ConfigReference.
+type Deprecation struct {
+	ReplacedBy   string            `json:"replaced_by,omitempty" yaml:"replaced_by,omitempty"`
+	Values       map[string]string `json:"values,omitempty" yaml:"values,omitempty"` // Old value -> new value
+	DeprecatedIn string            `json:"deprecated_in,omitempty" yaml:"deprecated_in,omitempty"`
+	RemovedIn    string            `json:"removed_in,omitempty" yaml:"removed_in,omitempty"`
+	Note         string            `json:"note,omitempty" yaml:"note,omitempty"`
+}
+
+// Removed reports whether the setting was dropped without a replacement
+func (d Deprecation) Removed() bool {
+	return d.ReplacedBy == "" && len(d.Values) == 0
+}
+
+// Applies reports whether a configured value is affected. A deprecation
+// without a replacement key but with value transforms only covers the listed
+// values, e.g. an option that kept its name but changed its accepted values.
+func (d Deprecation) Applies(value interface{}) bool {
+	if d.ReplacedBy != "" || len(d.Values) == 0 {
+		return true
+	}
+	for _, item := range deprecationItems(value) {
+		if _, ok := d.Values[fmt.Sprintf("%v", item)]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// TransformValue maps a value through the value transforms. Values without a
+// transform are returned unchanged; lists are transformed element-wise.
+func (d Deprecation) TransformValue(value interface{}) interface{} {
+	if len(d.Values) == 0 {
+		return value
+	}
+
+	switch v := value.(type) {
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = d.TransformValue(item)
+		}
+		return out
+	case []string:
+		out := make([]string, len(v))
+		for i, item := range v {
+			out[i] = fmt.Sprintf("%v", d.TransformValue(item))
+		}
+		return out
+	}
+
+	if replacement, ok := d.Values[fmt.Sprintf("%v", value)]; ok {
+		return replacement
+	}
+	return value
+}
+
+// Message describes the deprecation of key for users
+func (d Deprecation) Message(key string) string {
+	var msg string
+	switch {
+	case d.ReplacedBy != "":
+		msg = fmt.Sprintf("%s is deprecated, use %s instead", key, d.ReplacedBy)
+	case len(d.Values) > 0:
+		olds := make([]string, 0, len(d.Values))
+		for old := range d.Values {
+			olds = append(olds, old)
+		}
+		sort.Strings(olds)
+		msg = fmt.Sprintf("%s values %s are deprecated", key, strings.Join(olds, ", "))
+	default:
+		msg = fmt.Sprintf("%s is no longer supported", key)
+	}
+
+	var versions []string
+	if d.DeprecatedIn != "" {
+		versions = append(versions, "deprecated in "+d.DeprecatedIn)
+	}
+	if d.RemovedIn != "" {
+		versions = append(versions, "removed in "+d.RemovedIn)
+	}
+	if len(versions) > 0 {
+		msg += " (" + strings.Join(versions, ", ") + ")"
+	}
+	if d.Note != "" {
+		msg += ": " + d.Note
+	}
+	return msg
+}
+
+// Deprecation returns the deprecation recorded for a key
+func (r *ConfigReference) Deprecation(key string) (Deprecation, bool) {
+	if r == nil || r.Deprecations == nil {
+		return Deprecation{}, false
+	}
+	d, ok := r.Deprecations[key]
+	return d, ok
+}
+
+// Migration is a single rewrite needed to move a config off a deprecated setting
+type Migration struct {
+	Key      string      `json:"key"`               // Deprecated key as found in the config
+	NewKey   string      `json:"new_key,omitempty"` // Key to write, empty when the setting is dropped
+	OldValue interface{} `json:"old_value"`
+	NewValue interface{} `json:"new_value,omitempty"`
+	// Conflict is set when the replacement key is already configured; the
+	// existing replacement value is kept and the deprecated key is dropped
+	Conflict    bool        `json:"conflict,omitempty"`
+	Deprecation Deprecation `json:"deprecation"`
+}
+
+// Renamed reports whether the migration moves the value to another key
+func (m Migration) Renamed() bool {
+	return m.NewKey != "" && m.NewKey != m.Key
+}
+
+// Dropped reports whether the migration deletes the key without writing a value
+func (m Migration) Dropped() bool {
+	return m.NewKey == "" || m.Conflict
+}
+
+// PlanMigrations lists the rewrites needed to migrate config values off
+// deprecated settings, ordered by key
+func (r *ConfigReference) PlanMigrations(values map[string]interface{}) []Migration {
+	if r == nil || len(r.Deprecations) == 0 {
+		return nil
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var migrations []Migration
+	for _, key := range keys {
+		d, ok := r.Deprecations[key]
+		if !ok || !d.Applies(values[key]) {
+			continue
+		}
+
+		m := Migration{Key: key, OldValue: values[key], Deprecation: d}
+		switch {
+		case d.ReplacedBy != "":
+			m.NewKey = d.ReplacedBy
+			m.NewValue = d.TransformValue(values[key])
+			if _, exists := values[d.ReplacedBy]; exists {
+				m.Conflict = true
+				m.NewValue = values[d.ReplacedBy]
+			}
+		case len(d.Values) > 0:
+			m.NewKey = key
+			m.NewValue = d.TransformValue(values[key])
+		}
+		migrations = append(migrations, m)
+	}
+
+	return migrations
+}
+
+// deprecationItems splits list values so each element can be matched
+func deprecationItems(value interface{}) []interface{} {
+	switch v := value.(type) {
+	case []interface{}:
+		return v
+	case []string:
+		items := make([]interface{}, len(v))
+		for i, s := range v {
+			items[i] = s
+		}
+		return items
+	default:
+		return []interface{}{value}
+	}
+}
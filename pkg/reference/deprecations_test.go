@@ -0,0 +1,123 @@
+package reference
+
+import (
+	"strings"
+	"testing"
+)
+
+func testDeprecationReference() *ConfigReference {
+	return &ConfigReference{
+		AppName: "term",
+		Settings: map[string]ConfigSetting{
+			"background-blur":   {Name: "background-blur", Type: TypeString},
+			"window-decoration": {Name: "window-decoration", Type: TypeString, ValidValues: []string{"auto", "none"}},
+			"font-size":         {Name: "font-size", Type: TypeNumber},
+		},
+		Deprecations: map[string]Deprecation{
+			"background-blur-radius": {ReplacedBy: "background-blur", DeprecatedIn: "1.1.0"},
+			"window-decoration":      {Values: map[string]string{"true": "auto", "false": "none"}},
+			"font-size-old":          {ReplacedBy: "font-size"},
+			"legacy-mode":            {RemovedIn: "2.0"},
+			"palette-old":            {ReplacedBy: "palette", Values: map[string]string{"red": "#ff0000"}},
+		},
+	}
+}
+
+func TestPlanMigrations(t *testing.T) {
+	ref := testDeprecationReference()
+
+	migrations := ref.PlanMigrations(map[string]interface{}{
+		"background-blur-radius": "20",
+		"window-decoration":      "true",
+		"font-size-old":          11,
+		"font-size":              12,
+		"legacy-mode":            "on",
+		"palette-old":            []interface{}{"red", "#00ff00"},
+		"theme":                  "dark",
+	})
+
+	byKey := make(map[string]Migration)
+	for _, m := range migrations {
+		byKey[m.Key] = m
+	}
+	if len(migrations) != 5 || migrations[0].Key != "background-blur-radius" {
+		t.Fatalf("expected 5 migrations ordered by key, got %+v", migrations)
+	}
+
+	if m := byKey["background-blur-radius"]; !m.Renamed() || m.NewKey != "background-blur" || m.NewValue != "20" {
+		t.Errorf("unexpected rename: %+v", m)
+	}
+	if m := byKey["window-decoration"]; m.Renamed() || m.Dropped() || m.NewValue != "auto" {
+		t.Errorf("unexpected value transform: %+v", m)
+	}
+	if m := byKey["font-size-old"]; !m.Conflict || !m.Dropped() || m.NewValue != 12 {
+		t.Errorf("expected existing replacement to win: %+v", m)
+	}
+	if m := byKey["legacy-mode"]; !m.Dropped() || m.NewKey != "" {
+		t.Errorf("expected removed setting to be dropped: %+v", m)
+	}
+	if m := byKey["palette-old"]; m.NewKey != "palette" {
+		t.Errorf("unexpected list migration: %+v", m)
+	} else if items, ok := m.NewValue.([]interface{}); !ok || items[0] != "#ff0000" || items[1] != "#00ff00" {
+		t.Errorf("expected list transformed element-wise, got %v", m.NewValue)
+	}
+
+	// Values without a transform aren't deprecated
+	if got := ref.PlanMigrations(map[string]interface{}{"window-decoration": "auto"}); len(got) != 0 {
+		t.Errorf("expected no migrations for current values, got %+v", got)
+	}
+}
+
+func TestDeprecation_Message(t *testing.T) {
+	ref := testDeprecationReference()
+
+	tests := map[string]string{
+		"background-blur-radius": "background-blur-radius is deprecated, use background-blur instead (deprecated in 1.1.0)",
+		"window-decoration":      "window-decoration values false, true are deprecated",
+		"legacy-mode":            "legacy-mode is no longer supported (removed in 2.0)",
+	}
+	for key, want := range tests {
+		d, ok := ref.Deprecation(key)
+		if !ok {
+			t.Fatalf("expected deprecation for %s", key)
+		}
+		if got := d.Message(key); got != want {
+			t.Errorf("Message(%q) = %q, want %q", key, got, want)
+		}
+	}
+}
+
+func TestValidateConfiguration_Deprecated(t *testing.T) {
+	manager := NewReferenceManager(stubLoader{ref: testDeprecationReference()})
+
+	result, err := manager.ValidateConfiguration("term", "background-blur-radius", "20")
+	if err != nil {
+		t.Fatalf("ValidateConfiguration failed: %v", err)
+	}
+	if !result.Valid || len(result.Warnings) != 1 || len(result.Suggestions) != 1 || result.Suggestions[0] != "background-blur" {
+		t.Errorf("expected valid result with deprecation warning, got %+v", result)
+	}
+
+	result, _ = manager.ValidateConfiguration("term", "window-decoration", "true")
+	if !result.Valid || len(result.Warnings) != 1 {
+		t.Errorf("expected transformed value to validate with a warning, got %+v", result)
+	}
+
+	result, _ = manager.ValidateConfiguration("term", "window-decoration", "none")
+	if !result.Valid || len(result.Warnings) != 0 {
+		t.Errorf("expected current value without warnings, got %+v", result)
+	}
+
+	result, _ = manager.ValidateConfiguration("term", "legacy-mode", "on")
+	if result.Valid || len(result.Errors) != 1 || !strings.Contains(result.Errors[0], "Removed setting") {
+		t.Errorf("expected removed setting to be invalid, got %+v", result)
+	}
+}
+
+type stubLoader struct {
+	ref *ConfigReference
+}
+
+func (s stubLoader) LoadReference(string) (*ConfigReference, error) {
+	return s.ref, nil
+}
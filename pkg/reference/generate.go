@@ -0,0 +1,222 @@
+package reference
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// MergeOptions controls how generated settings are merged into a curated reference
+type MergeOptions struct {
+	// Prune drops settings that exist in the curated reference but were not
+	// reported by extraction. By default they are kept and only reported.
+	Prune bool
+}
+
+// SettingChange describes a curated value that differs from what extraction reported
+type SettingChange struct {
+	Key       string      `json:"key" yaml:"key"`
+	Field     string      `json:"field" yaml:"field"`
+	Curated   interface{} `json:"curated" yaml:"curated"`
+	Generated interface{} `json:"generated" yaml:"generated"`
+}
+
+// GenerateReport summarizes the result of merging generated settings
+type GenerateReport struct {
+	Added     []string        `json:"added,omitempty" yaml:"added,omitempty"`
+	Removed   []string        `json:"removed,omitempty" yaml:"removed,omitempty"`
+	Changed   []SettingChange `json:"changed,omitempty" yaml:"changed,omitempty"`
+	Unchanged int             `json:"unchanged" yaml:"unchanged"`
+	Pruned    bool            `json:"pruned" yaml:"pruned"`
+	// Modified is false when the merged reference is the curated one as it
+	// was, in which case it keeps the curated LastUpdated
+	Modified bool `json:"modified" yaml:"modified"`
+}
+
+// HasChanges reports whether the merge added, removed or flagged any setting
+func (r *GenerateReport) HasChanges() bool {
+	return len(r.Added) > 0 || len(r.Removed) > 0 || len(r.Changed) > 0
+}
+
+// Summary returns a one-line summary of the report
+func (r *GenerateReport) Summary() string {
+	return fmt.Sprintf("%d added, %d removed, %d changed, %d unchanged",
+		len(r.Added), len(r.Removed), len(r.Changed), r.Unchanged)
+}
+
+// MergeGenerated merges extracted settings into an existing curated reference.
+// Curated values win: descriptions, categories, examples and any type or default
+// that a human has filled in are kept, and generated data only fills gaps.
+// Disagreements are reported as changes so they can be reviewed.
+// A nil curated reference produces a reference built entirely from generated.
+func MergeGenerated(curated, generated *ConfigReference, opts MergeOptions) (*ConfigReference, *GenerateReport) {
+	report := &GenerateReport{Pruned: opts.Prune}
+
+	merged := &ConfigReference{
+		AppName:     generated.AppName,
+		ConfigPath:  generated.ConfigPath,
+		ConfigType:  generated.ConfigType,
+		LastUpdated: time.Now().UTC().Truncate(time.Second),
+		Settings:    make(map[string]ConfigSetting, len(generated.Settings)),
+	}
+
+	if curated == nil {
+		for key, setting := range generated.Settings {
+			if setting.Name == "" {
+				setting.Name = key
+			}
+			merged.Settings[key] = setting
+			report.Added = append(report.Added, key)
+		}
+		sort.Strings(report.Added)
+		report.Modified = true
+		return merged, report
+	}
+
+	if curated.AppName != "" {
+		merged.AppName = curated.AppName
+	}
+	if curated.ConfigPath != "" {
+		merged.ConfigPath = curated.ConfigPath
+	}
+	if curated.ConfigType != "" {
+		merged.ConfigType = curated.ConfigType
+	}
+	// Deprecations are curated by hand; extraction never produces them
+	merged.Deprecations = curated.Deprecations
+
+	for key, gen := range generated.Settings {
+		cur, exists := curated.Settings[key]
+		if !exists {
+			if gen.Name == "" {
+				gen.Name = key
+			}
+			merged.Settings[key] = gen
+			report.Added = append(report.Added, key)
+			continue
+		}
+
+		setting, changes := mergeSetting(key, cur, gen)
+		merged.Settings[key] = setting
+		if len(changes) == 0 {
+			report.Unchanged++
+		}
+		report.Changed = append(report.Changed, changes...)
+	}
+
+	for key, cur := range curated.Settings {
+		if _, exists := generated.Settings[key]; exists {
+			continue
+		}
+		report.Removed = append(report.Removed, key)
+		if !opts.Prune {
+			merged.Settings[key] = cur
+		}
+	}
+
+	sort.Strings(report.Added)
+	sort.Strings(report.Removed)
+	sort.Slice(report.Changed, func(i, j int) bool {
+		if report.Changed[i].Key != report.Changed[j].Key {
+			return report.Changed[i].Key < report.Changed[j].Key
+		}
+		return report.Changed[i].Field < report.Changed[j].Field
+	})
+
+	// When extraction adds nothing, regenerating mustn't bump the curated date
+	unchanged := *curated
+	unchanged.LastUpdated = merged.LastUpdated
+	report.Modified = !reflect.DeepEqual(&unchanged, merged)
+	if !report.Modified {
+		merged.LastUpdated = curated.LastUpdated
+	}
+
+	return merged, report
+}
+
+// mergeSetting fills empty curated fields from the generated setting and
+// records every field where both sides hold different non-empty values
+func mergeSetting(key string, cur, gen ConfigSetting) (ConfigSetting, []SettingChange) {
+	var changes []SettingChange
+	out := cur
+
+	if out.Name == "" {
+		out.Name = key
+	}
+
+	if out.Type == "" {
+		out.Type = gen.Type
+	} else if gen.Type != "" && gen.Type != out.Type {
+		changes = append(changes, SettingChange{Key: key, Field: "type", Curated: out.Type, Generated: gen.Type})
+	}
+
+	if out.Description == "" {
+		out.Description = gen.Description
+	}
+
+	if out.Category == "" {
+		out.Category = gen.Category
+	}
+
+	if isEmptyValue(out.DefaultValue) {
+		out.DefaultValue = gen.DefaultValue
+	} else if !isEmptyValue(gen.DefaultValue) && fmt.Sprintf("%v", gen.DefaultValue) != fmt.Sprintf("%v", out.DefaultValue) {
+		changes = append(changes, SettingChange{Key: key, Field: "default_value", Curated: out.DefaultValue, Generated: gen.DefaultValue})
+	}
+
+	if len(out.ValidValues) == 0 {
+		out.ValidValues = gen.ValidValues
+	} else if len(gen.ValidValues) > 0 && !reflect.DeepEqual(sortedCopy(out.ValidValues), sortedCopy(gen.ValidValues)) {
+		changes = append(changes, SettingChange{Key: key, Field: "valid_values", Curated: out.ValidValues, Generated: gen.ValidValues})
+	}
+
+	return out, changes
+}
+
+func isEmptyValue(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	if s, ok := v.(string); ok {
+		return s == ""
+	}
+	return false
+}
+
+func sortedCopy(values []string) []string {
+	out := append([]string(nil), values...)
+	sort.Strings(out)
+	return out
+}
+
+// WriteReferenceFile writes a reference as YAML in the format read by StaticConfigLoader
+func WriteReferenceFile(path string, ref *ConfigReference) error {
+	data, err := MarshalReference(ref)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create reference directory: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write reference %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// MarshalReference encodes a reference as YAML with stable key ordering
+func MarshalReference(ref *ConfigReference) ([]byte, error) {
+	data, err := yaml.Marshal(ref)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal reference for %s: %w", ref.AppName, err)
+	}
+	return data, nil
+}
@@ -0,0 +1,192 @@
+package reference
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestMergeGenerated(t *testing.T) {
+	curated := &ConfigReference{
+		AppName:    "test_app",
+		ConfigPath: "~/.test/config",
+		ConfigType: "custom",
+		Settings: map[string]ConfigSetting{
+			"font-size": {
+				Name:         "font-size",
+				Type:         TypeNumber,
+				Description:  "Hand-written description",
+				DefaultValue: 13,
+				Category:     "font",
+			},
+			"theme": {
+				Name: "theme",
+				Type: TypeString,
+			},
+			"legacy-option": {
+				Name: "legacy-option",
+				Type: TypeBoolean,
+			},
+		},
+	}
+
+	generated := &ConfigReference{
+		AppName:    "test_app",
+		ConfigPath: "~/.generated/config",
+		ConfigType: "custom",
+		Settings: map[string]ConfigSetting{
+			"font-size": {
+				Name:         "font-size",
+				Type:         TypeString,
+				Description:  "Scraped description",
+				DefaultValue: 12,
+			},
+			"theme": {
+				Name:         "theme",
+				Type:         TypeString,
+				Description:  "Color theme",
+				DefaultValue: "auto",
+				ValidValues:  []string{"auto", "dark"},
+			},
+			"cursor-style": {
+				Name: "cursor-style",
+				Type: TypeString,
+			},
+		},
+	}
+
+	t.Run("keeps curated data and reports differences", func(t *testing.T) {
+		merged, report := MergeGenerated(curated, generated, MergeOptions{})
+
+		if merged.ConfigPath != "~/.test/config" {
+			t.Errorf("Expected curated config path, got %q", merged.ConfigPath)
+		}
+
+		fontSize := merged.Settings["font-size"]
+		if fontSize.Description != "Hand-written description" {
+			t.Errorf("Expected curated description to be kept, got %q", fontSize.Description)
+		}
+		if fontSize.Type != TypeNumber || fontSize.DefaultValue != 13 {
+			t.Errorf("Expected curated type and default to be kept, got %s/%v", fontSize.Type, fontSize.DefaultValue)
+		}
+
+		theme := merged.Settings["theme"]
+		if theme.Description != "Color theme" || theme.DefaultValue != "auto" || len(theme.ValidValues) != 2 {
+			t.Errorf("Expected empty curated fields to be filled, got %+v", theme)
+		}
+
+		if !reflect.DeepEqual(report.Added, []string{"cursor-style"}) {
+			t.Errorf("Expected cursor-style to be added, got %v", report.Added)
+		}
+		if !reflect.DeepEqual(report.Removed, []string{"legacy-option"}) {
+			t.Errorf("Expected legacy-option to be reported as removed, got %v", report.Removed)
+		}
+		if _, kept := merged.Settings["legacy-option"]; !kept {
+			t.Error("Expected legacy-option to be kept without pruning")
+		}
+
+		if len(report.Changed) != 2 {
+			t.Fatalf("Expected 2 changes, got %d: %+v", len(report.Changed), report.Changed)
+		}
+		if report.Changed[0].Field != "default_value" || report.Changed[1].Field != "type" {
+			t.Errorf("Unexpected change fields: %+v", report.Changed)
+		}
+		if report.Unchanged != 1 {
+			t.Errorf("Expected 1 unchanged setting, got %d", report.Unchanged)
+		}
+	})
+
+	t.Run("prune drops unreported settings", func(t *testing.T) {
+		merged, report := MergeGenerated(curated, generated, MergeOptions{Prune: true})
+
+		if _, kept := merged.Settings["legacy-option"]; kept {
+			t.Error("Expected legacy-option to be pruned")
+		}
+		if !report.Pruned || len(report.Removed) != 1 {
+			t.Errorf("Expected pruned report with one removal, got %+v", report)
+		}
+	})
+
+	t.Run("unchanged reference keeps its date", func(t *testing.T) {
+		updated := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+		current := &ConfigReference{
+			AppName:     "test_app",
+			ConfigPath:  "~/.test/config",
+			ConfigType:  "custom",
+			LastUpdated: updated,
+			Settings: map[string]ConfigSetting{
+				"theme": {Name: "theme", Type: TypeString, Description: "Color theme"},
+			},
+		}
+		same := &ConfigReference{
+			AppName: "test_app",
+			Settings: map[string]ConfigSetting{
+				"theme": {Name: "theme", Type: TypeString},
+			},
+		}
+
+		merged, report := MergeGenerated(current, same, MergeOptions{})
+		if report.Modified || !merged.LastUpdated.Equal(updated) {
+			t.Errorf("Expected the curated date to be kept, got modified=%v %v", report.Modified, merged.LastUpdated)
+		}
+
+		merged, report = MergeGenerated(current, generated, MergeOptions{})
+		if !report.Modified || merged.LastUpdated.Equal(updated) {
+			t.Errorf("Expected a new date for an added setting, got modified=%v %v", report.Modified, merged.LastUpdated)
+		}
+	})
+
+	t.Run("no curated reference", func(t *testing.T) {
+		merged, report := MergeGenerated(nil, generated, MergeOptions{})
+
+		if len(merged.Settings) != 3 || len(report.Added) != 3 {
+			t.Errorf("Expected all generated settings to be added, got %d/%d", len(merged.Settings), len(report.Added))
+		}
+	})
+}
+
+func TestWriteReferenceFileRoundTrip(t *testing.T) {
+	tempDir := t.TempDir()
+
+	ref := &ConfigReference{
+		AppName:    "test_app",
+		ConfigPath: "~/.test/config",
+		ConfigType: "json",
+		Settings: map[string]ConfigSetting{
+			"enabled": {Name: "enabled", Type: TypeBoolean, DefaultValue: true},
+		},
+	}
+
+	if err := WriteReferenceFile(filepath.Join(tempDir, "test_app.yaml"), ref); err != nil {
+		t.Fatalf("Failed to write reference: %v", err)
+	}
+
+	loaded, err := NewStaticConfigLoader(tempDir).LoadReference("test_app")
+	if err != nil {
+		t.Fatalf("Failed to load written reference: %v", err)
+	}
+
+	if loaded.Settings["enabled"].Type != TypeBoolean || loaded.Settings["enabled"].DefaultValue != true {
+		t.Errorf("Unexpected round-tripped setting: %+v", loaded.Settings["enabled"])
+	}
+}
+
+func TestStaticConfigLoaderMissingAndMalformed(t *testing.T) {
+	dir := t.TempDir()
+	loader := NewStaticConfigLoader(dir)
+
+	if _, err := loader.LoadReference("ghostty"); !errors.Is(err, ErrReferenceNotFound) {
+		t.Errorf("Expected ErrReferenceNotFound for a missing file, got %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(dir, "ghostty.yaml"), []byte("app_name: ghostty\nsettings: [\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := loader.LoadReference("ghostty")
+	if err == nil || errors.Is(err, ErrReferenceNotFound) {
+		t.Errorf("Expected a parse error for a malformed file, got %v", err)
+	}
+}
@@ -0,0 +1,367 @@
+package reference
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// maxSchemaDepth bounds $ref expansion so recursive schemas terminate
+const maxSchemaDepth = 16
+
+// JSONSchemaLoader loads references from JSON Schema files named <app>.schema.json
+type JSONSchemaLoader struct {
+	schemaDir string
+}
+
+// NewJSONSchemaLoader creates a loader for JSON Schema files in schemaDir
+func NewJSONSchemaLoader(schemaDir string) *JSONSchemaLoader {
+	return &JSONSchemaLoader{schemaDir: schemaDir}
+}
+
+// LoadReference loads and flattens <schemaDir>/<app>.schema.json
+func (l *JSONSchemaLoader) LoadReference(appName string) (*ConfigReference, error) {
+	return LoadJSONSchemaFile(appName, filepath.Join(l.schemaDir, appName+".schema.json"))
+}
+
+// LoadJSONSchemaFile reads a JSON Schema file and converts it into a reference
+func LoadJSONSchemaFile(appName, path string) (*ConfigReference, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema %s: %w", path, err)
+	}
+
+	settings, err := FlattenJSONSchema(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse schema %s: %w", path, err)
+	}
+
+	return &ConfigReference{
+		AppName:     appName,
+		ConfigType:  "json",
+		LastUpdated: time.Now(),
+		Settings:    settings,
+	}, nil
+}
+
+// FlattenJSONSchema converts a JSON Schema document into settings keyed by
+// dotted path. Local $ref pointers are resolved, enum and oneOf/anyOf constants
+// become ValidValues, and nested object properties are flattened into
+// "parent.child" keys. Objects without declared properties become object settings.
+func FlattenJSONSchema(data []byte) (map[string]ConfigSetting, error) {
+	var root map[string]interface{}
+	if err := json.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	f := &schemaFlattener{
+		root:     root,
+		settings: make(map[string]ConfigSetting),
+		refs:     make(map[string]map[string]interface{}),
+	}
+	f.walk("", root, 0)
+
+	if len(f.settings) == 0 {
+		return nil, fmt.Errorf("schema declares no properties")
+	}
+
+	return f.settings, nil
+}
+
+type schemaFlattener struct {
+	root     map[string]interface{}
+	settings map[string]ConfigSetting
+	// refs caches each $ref's resolved schema; definitions are shared by many
+	// properties and branches, and resolving them again at every use grows
+	// exponentially with nesting. A nil entry marks a ref being resolved.
+	refs map[string]map[string]interface{}
+}
+
+func (f *schemaFlattener) walk(path string, node map[string]interface{}, depth int) {
+	if depth > maxSchemaDepth {
+		return
+	}
+
+	node = f.resolve(node, depth)
+	if node == nil {
+		return
+	}
+
+	if props := schemaProperties(node); len(props) > 0 {
+		names := make([]string, 0, len(props))
+		for name := range props {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			child, ok := props[name].(map[string]interface{})
+			if !ok {
+				continue
+			}
+			f.walk(joinSchemaPath(path, name), f.inherit(child, node, name), depth+1)
+		}
+		return
+	}
+
+	// Root schemas without properties have nothing to describe
+	if path == "" {
+		return
+	}
+
+	f.settings[path] = f.toSetting(path, node)
+}
+
+// resolve follows $ref and folds allOf/oneOf/anyOf branches that declare
+// properties into a single node, so nested objects flatten the same way
+func (f *schemaFlattener) resolve(node map[string]interface{}, depth int) map[string]interface{} {
+	if depth > maxSchemaDepth {
+		return node
+	}
+
+	// A resolved ref has folded its own branches already
+	own := node
+	if ref, ok := node["$ref"].(string); ok {
+		if target := f.resolveRef(ref); target != nil {
+			// Sibling keywords (description, default) override the referenced schema
+			merged := make(map[string]interface{}, len(target)+len(node))
+			for k, v := range target {
+				merged[k] = v
+			}
+			for k, v := range node {
+				if k != "$ref" {
+					merged[k] = v
+				}
+			}
+			node = merged
+		}
+	}
+
+	props := make(map[string]interface{})
+	for _, keyword := range []string{"allOf", "oneOf", "anyOf"} {
+		branches, _ := own[keyword].([]interface{})
+		for _, b := range branches {
+			branch, ok := b.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			branch = f.resolve(branch, depth+1)
+			for name, p := range schemaProperties(branch) {
+				props[name] = p
+			}
+		}
+	}
+
+	if len(props) > 0 {
+		merged := make(map[string]interface{}, len(node)+1)
+		for k, v := range node {
+			merged[k] = v
+		}
+		existing := schemaProperties(node)
+		for name, p := range existing {
+			props[name] = p
+		}
+		merged["properties"] = props
+		node = merged
+	}
+
+	return node
+}
+
+// resolveRef returns the resolved schema a $ref points to, or nil when it
+// can't be found. A ref met again while it is being resolved is a cycle and
+// yields the target unresolved.
+func (f *schemaFlattener) resolveRef(ref string) map[string]interface{} {
+	resolved, seen := f.refs[ref]
+	if seen && resolved != nil {
+		return resolved
+	}
+
+	target := f.lookup(ref)
+	if target == nil || seen {
+		return target
+	}
+
+	f.refs[ref] = nil
+	resolved = f.resolve(target, 0)
+	f.refs[ref] = resolved
+	return resolved
+}
+
+// inherit copies the parent's default for a property when the property has none
+func (f *schemaFlattener) inherit(child, parent map[string]interface{}, name string) map[string]interface{} {
+	if _, ok := child["default"]; ok {
+		return child
+	}
+	parentDefault, ok := parent["default"].(map[string]interface{})
+	if !ok {
+		return child
+	}
+	value, ok := parentDefault[name]
+	if !ok {
+		return child
+	}
+	out := make(map[string]interface{}, len(child)+1)
+	for k, v := range child {
+		out[k] = v
+	}
+	out["default"] = value
+	return out
+}
+
+// lookup resolves a local JSON pointer such as #/definitions/Foo or #/$defs/Foo
+func (f *schemaFlattener) lookup(ref string) map[string]interface{} {
+	if !strings.HasPrefix(ref, "#/") {
+		return nil
+	}
+
+	var current interface{} = f.root
+	for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		part = strings.ReplaceAll(strings.ReplaceAll(part, "~1", "/"), "~0", "~")
+		m, ok := current.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		current = m[part]
+	}
+
+	m, _ := current.(map[string]interface{})
+	return m
+}
+
+func (f *schemaFlattener) toSetting(path string, node map[string]interface{}) ConfigSetting {
+	setting := ConfigSetting{
+		Name:         path,
+		Type:         schemaType(node),
+		Description:  schemaDescription(node),
+		DefaultValue: node["default"],
+		ValidValues:  f.schemaEnum(node),
+		Category:     strings.SplitN(path, ".", 2)[0],
+	}
+
+	if examples, ok := node["examples"].([]interface{}); ok && len(examples) > 0 {
+		setting.Example = examples[0]
+	}
+
+	if setting.Type == "" {
+		setting.Type = TypeString
+		for _, keyword := range []string{"oneOf", "anyOf"} {
+			branches, _ := node[keyword].([]interface{})
+			for _, b := range branches {
+				if branch, ok := b.(map[string]interface{}); ok {
+					if t := schemaType(f.resolve(branch, 0)); t != "" {
+						setting.Type = t
+						return setting
+					}
+				}
+			}
+		}
+	}
+
+	return setting
+}
+
+// schemaEnum collects enum values, including const values of oneOf/anyOf branches
+func (f *schemaFlattener) schemaEnum(node map[string]interface{}) []string {
+	var values []string
+	seen := make(map[string]bool)
+	add := func(v interface{}) {
+		if v == nil {
+			return
+		}
+		s := fmt.Sprintf("%v", v)
+		if !seen[s] {
+			seen[s] = true
+			values = append(values, s)
+		}
+	}
+
+	if enum, ok := node["enum"].([]interface{}); ok {
+		for _, v := range enum {
+			add(v)
+		}
+	}
+
+	for _, keyword := range []string{"oneOf", "anyOf"} {
+		branches, _ := node[keyword].([]interface{})
+		for _, b := range branches {
+			branch, ok := b.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			branch = f.resolve(branch, 0)
+			if c, ok := branch["const"]; ok {
+				add(c)
+			}
+			if enum, ok := branch["enum"].([]interface{}); ok {
+				for _, v := range enum {
+					add(v)
+				}
+			}
+		}
+	}
+
+	return values
+}
+
+func schemaProperties(node map[string]interface{}) map[string]interface{} {
+	props, _ := node["properties"].(map[string]interface{})
+	return props
+}
+
+func schemaDescription(node map[string]interface{}) string {
+	if desc, ok := node["description"].(string); ok && desc != "" {
+		return strings.TrimSpace(desc)
+	}
+	if desc, ok := node["markdownDescription"].(string); ok {
+		return strings.TrimSpace(desc)
+	}
+	return ""
+}
+
+// schemaType maps JSON Schema types onto reference types, ignoring "null"
+func schemaType(node map[string]interface{}) SettingType {
+	var types []string
+	switch t := node["type"].(type) {
+	case string:
+		types = []string{t}
+	case []interface{}:
+		for _, v := range t {
+			if s, ok := v.(string); ok {
+				types = append(types, s)
+			}
+		}
+	}
+
+	for _, t := range types {
+		switch t {
+		case "string":
+			return TypeString
+		case "integer", "number":
+			return TypeNumber
+		case "boolean":
+			return TypeBoolean
+		case "array":
+			return TypeArray
+		case "object":
+			return TypeObject
+		}
+	}
+
+	if _, ok := node["enum"]; ok {
+		return TypeString
+	}
+
+	return ""
+}
+
+func joinSchemaPath(parent, name string) string {
+	if parent == "" {
+		return name
+	}
+	return parent + "." + name
+}
@@ -0,0 +1,206 @@
+package reference
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+const testSchema = `{
+  "$schema": "http://json-schema.org/draft-07/schema#",
+  "$ref": "#/definitions/Settings",
+  "definitions": {
+    "Settings": {
+      "type": "object",
+      "properties": {
+        "buffer_font_size": {
+          "type": "number",
+          "description": "Font size for editor buffers",
+          "default": 15
+        },
+        "theme": { "$ref": "#/definitions/Theme" },
+        "cursor_shape": {
+          "description": "Cursor shape",
+          "oneOf": [
+            { "const": "bar" },
+            { "const": "block" },
+            { "const": "underline" }
+          ]
+        },
+        "terminal": {
+          "type": "object",
+          "default": { "blinking": "off" },
+          "properties": {
+            "blinking": { "type": "string", "enum": ["off", "on", "terminal_controlled"] },
+            "font": {
+              "type": "object",
+              "properties": {
+                "family": { "type": ["string", "null"], "markdownDescription": "Terminal font family" }
+              }
+            }
+          }
+        },
+        "languages": {
+          "type": "object",
+          "additionalProperties": true
+        }
+      }
+    },
+    "Theme": {
+      "type": "string",
+      "description": "Color theme",
+      "default": "One Dark"
+    }
+  }
+}`
+
+func TestFlattenJSONSchema(t *testing.T) {
+	settings, err := FlattenJSONSchema([]byte(testSchema))
+	if err != nil {
+		t.Fatalf("Failed to flatten schema: %v", err)
+	}
+
+	tests := []struct {
+		key          string
+		wantType     SettingType
+		wantDefault  interface{}
+		wantValues   []string
+		wantDesc     string
+		wantCategory string
+	}{
+		{"buffer_font_size", TypeNumber, 15.0, nil, "Font size for editor buffers", "buffer_font_size"},
+		{"theme", TypeString, "One Dark", nil, "Color theme", "theme"},
+		{"cursor_shape", TypeString, nil, []string{"bar", "block", "underline"}, "Cursor shape", "cursor_shape"},
+		{"terminal.blinking", TypeString, "off", []string{"off", "on", "terminal_controlled"}, "", "terminal"},
+		{"terminal.font.family", TypeString, nil, nil, "Terminal font family", "terminal"},
+		{"languages", TypeObject, nil, nil, "", "languages"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.key, func(t *testing.T) {
+			setting, exists := settings[tt.key]
+			if !exists {
+				t.Fatalf("Expected setting %q to exist; got keys %v", tt.key, keysOf(settings))
+			}
+			if setting.Type != tt.wantType {
+				t.Errorf("Expected type %s, got %s", tt.wantType, setting.Type)
+			}
+			if !reflect.DeepEqual(setting.DefaultValue, tt.wantDefault) {
+				t.Errorf("Expected default %v, got %v", tt.wantDefault, setting.DefaultValue)
+			}
+			if !reflect.DeepEqual(setting.ValidValues, tt.wantValues) {
+				t.Errorf("Expected values %v, got %v", tt.wantValues, setting.ValidValues)
+			}
+			if setting.Description != tt.wantDesc {
+				t.Errorf("Expected description %q, got %q", tt.wantDesc, setting.Description)
+			}
+			if setting.Category != tt.wantCategory {
+				t.Errorf("Expected category %q, got %q", tt.wantCategory, setting.Category)
+			}
+		})
+	}
+
+	if _, exists := settings["terminal"]; exists {
+		t.Error("Expected object with properties to be flattened, not kept as a setting")
+	}
+}
+
+func TestFlattenJSONSchemaRecursiveRef(t *testing.T) {
+	schema := `{
+  "type": "object",
+  "properties": {
+    "node": { "$ref": "#/definitions/Node" }
+  },
+  "definitions": {
+    "Node": {
+      "type": "object",
+      "properties": {
+        "name": { "type": "string" },
+        "child": { "$ref": "#/definitions/Node" }
+      }
+    }
+  }
+}`
+
+	settings, err := FlattenJSONSchema([]byte(schema))
+	if err != nil {
+		t.Fatalf("Failed to flatten recursive schema: %v", err)
+	}
+	if _, exists := settings["node.child.name"]; !exists {
+		t.Error("Expected recursive properties to be flattened to a bounded depth")
+	}
+}
+
+func TestFlattenJSONSchemaSharedRefs(t *testing.T) {
+	// Every level combines the next one three times over; resolving each use
+	// separately would take 3^15 steps
+	const levels = 16
+	defs := make([]string, 0, levels+1)
+	for i := 0; i < levels; i++ {
+		next := fmt.Sprintf(`{"$ref": "#/definitions/L%d"}`, i+1)
+		defs = append(defs, fmt.Sprintf(`"L%d": {"allOf": [%s, %s, %s], "properties": {"p%d": {"type": "string"}}}`, i, next, next, next, i))
+	}
+	defs = append(defs, fmt.Sprintf(`"L%d": {"properties": {"leaf": {"type": "boolean"}}}`, levels))
+	schema := `{"properties": {"settings": {"$ref": "#/definitions/L0"}}, "definitions": {` + strings.Join(defs, ",") + `}}`
+
+	done := make(chan map[string]ConfigSetting, 1)
+	go func() {
+		settings, err := FlattenJSONSchema([]byte(schema))
+		if err != nil {
+			t.Errorf("Failed to flatten schema: %v", err)
+		}
+		done <- settings
+	}()
+
+	select {
+	case settings := <-done:
+		for _, key := range []string{"settings.p0", "settings.p15", "settings.leaf"} {
+			if _, ok := settings[key]; !ok {
+				t.Errorf("Expected %s to be flattened", key)
+			}
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Flattening a schema with shared definitions took too long")
+	}
+}
+
+func TestJSONSchemaLoader(t *testing.T) {
+	tempDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(tempDir, "zed.schema.json"), []byte(testSchema), 0o644); err != nil {
+		t.Fatalf("Failed to write schema: %v", err)
+	}
+
+	manager := NewReferenceManager(NewJSONSchemaLoader(tempDir))
+	ref, err := manager.GetReference("zed")
+	if err != nil {
+		t.Fatalf("Failed to load schema reference: %v", err)
+	}
+
+	if ref.AppName != "zed" || ref.ConfigType != "json" {
+		t.Errorf("Unexpected reference metadata: %s/%s", ref.AppName, ref.ConfigType)
+	}
+
+	result, err := manager.ValidateConfiguration("zed", "terminal.blinking", "sometimes")
+	if err != nil {
+		t.Fatalf("Validation failed: %v", err)
+	}
+	if result.Valid {
+		t.Error("Expected value outside the schema enum to be invalid")
+	}
+
+	if _, err := NewJSONSchemaLoader(tempDir).LoadReference("missing"); err == nil {
+		t.Error("Expected error for missing schema file")
+	}
+}
+
+func keysOf(settings map[string]ConfigSetting) []string {
+	keys := make([]string, 0, len(settings))
+	for k := range settings {
+		keys = append(keys, k)
+	}
+	return keys
+}
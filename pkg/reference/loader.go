@@ -2,6 +2,7 @@ package reference
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -11,6 +12,10 @@ import (
 	"gopkg.in/yaml.v3"
 )
 
+// ErrReferenceNotFound is returned by StaticConfigLoader.LoadReference when an
+// app has no reference file
+var ErrReferenceNotFound = errors.New("no configuration file found")
+
 // StaticConfigLoader loads from embedded YAML/JSON files
 type StaticConfigLoader struct {
 	configDir string
@@ -33,7 +38,7 @@ func (s *StaticConfigLoader) LoadReference(appName string) (*ConfigReference, er
 		}
 	}
 
-	return nil, fmt.Errorf("no configuration file found for %s", appName)
+	return nil, fmt.Errorf("%w for %s", ErrReferenceNotFound, appName)
 }
 
 // parseConfigFile parses configuration from different file formats
@@ -30,6 +30,10 @@ func (rm *ReferenceManager) ValidateConfiguration(appName, settingName string, v
 		return nil, err
 	}
 
+	if d, deprecated := ref.Deprecation(settingName); deprecated && d.Applies(value) {
+		return validateDeprecated(ref, settingName, d, value), nil
+	}
+
 	setting, exists := ref.Settings[settingName]
 	if !exists {
 		return &ValidationResult{
@@ -42,6 +46,37 @@ func (rm *ReferenceManager) ValidateConfiguration(appName, settingName string, v
 	return validateSetting(setting, value), nil
 }
 
+// validateDeprecated warns about a deprecated setting and validates the
+// migrated value against its replacement when one is documented
+func validateDeprecated(ref *ConfigReference, settingName string, d Deprecation, value interface{}) *ValidationResult {
+	result := &ValidationResult{Valid: true}
+
+	target := settingName
+	if d.ReplacedBy != "" {
+		target = d.ReplacedBy
+		result.Suggestions = append(result.Suggestions, d.ReplacedBy)
+	}
+
+	if setting, exists := ref.Settings[target]; exists {
+		result = mergeValidation(result, validateSetting(setting, d.TransformValue(value)))
+	} else if d.Removed() {
+		result.Valid = false
+		result.Errors = append(result.Errors, fmt.Sprintf("Removed setting: %s", settingName))
+	}
+
+	result.Warnings = append(result.Warnings, d.Message(settingName))
+	return result
+}
+
+// mergeValidation folds the findings of other into result
+func mergeValidation(result, other *ValidationResult) *ValidationResult {
+	result.Valid = result.Valid && other.Valid
+	result.Errors = append(result.Errors, other.Errors...)
+	result.Warnings = append(result.Warnings, other.Warnings...)
+	result.Suggestions = append(result.Suggestions, other.Suggestions...)
+	return result
+}
+
 // validateSetting validates a value against a setting definition
 func validateSetting(setting ConfigSetting, value interface{}) *ValidationResult {
 	result := &ValidationResult{Valid: true}
@@ -40,6 +40,7 @@ func (m *ReferenceConfigMapper) MapReferenceToAppConfig(appName string) (*AppCon
 			Type:        convertSettingTypeToFieldType(setting.Type),
 			Description: setting.Description,
 			Default:     setting.DefaultValue,
+			Required:    setting.Required,
 		}
 
 		// Handle valid values
@@ -97,6 +98,7 @@ func (m *ReferenceConfigMapper) MergeWithAppConfig(appName string, existingAppCo
 				Type:        convertSettingTypeToFieldType(setting.Type),
 				Description: setting.Description,
 				Default:     setting.DefaultValue,
+				Required:    setting.Required,
 			}
 
 			// Handle valid values
@@ -0,0 +1,215 @@
+package reference
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// JSONSchemaDraft is the JSON Schema dialect emitted by the exporters
+const JSONSchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// ExportJSONSchema builds a JSON Schema describing an application's config file
+// from its reference settings and app definition fields. Either may be nil.
+// Field definitions take precedence for enums, defaults and descriptions since
+// they are what ZeroUI actually toggles. Dotted keys are nested into objects for
+// structured formats (json, yaml, toml) and kept flat otherwise.
+func ExportJSONSchema(ref *ConfigReference, app *AppConfig) map[string]interface{} {
+	name, format := "", ""
+	if ref != nil {
+		name, format = ref.AppName, ref.ConfigType
+	}
+	if app != nil {
+		if app.Name != "" {
+			name = app.Name
+		}
+		if app.Format != "" {
+			format = app.Format
+		}
+	}
+
+	properties := make(map[string]map[string]interface{})
+
+	if ref != nil {
+		for key, setting := range ref.Settings {
+			properties[key] = settingSchema(setting)
+		}
+	}
+
+	if app != nil {
+		for key, field := range app.Fields {
+			prop, exists := properties[key]
+			if !exists {
+				prop = make(map[string]interface{})
+				properties[key] = prop
+			}
+			applyFieldSchema(prop, field)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"$schema": JSONSchemaDraft,
+		"title":   name + " configuration",
+		"type":    "object",
+	}
+	if app != nil && app.Description != "" {
+		schema["description"] = app.Description
+	}
+
+	// Sorted order guarantees a parent key is placed before its dotted children
+	nested := format == "json" || format == "yaml" || format == "toml"
+	root := make(map[string]interface{})
+	for _, key := range sortedSchemaKeys(properties) {
+		if nested && strings.Contains(key, ".") {
+			insertNestedProperty(root, strings.Split(key, "."), properties[key])
+			continue
+		}
+		root[key] = properties[key]
+	}
+	schema["properties"] = root
+
+	return schema
+}
+
+func settingSchema(setting ConfigSetting) map[string]interface{} {
+	prop := make(map[string]interface{})
+
+	switch setting.Type {
+	case TypeNumber:
+		prop["type"] = "number"
+	case TypeBoolean:
+		prop["type"] = "boolean"
+	case TypeArray:
+		prop["type"] = "array"
+	case TypeObject:
+		prop["type"] = "object"
+	default:
+		prop["type"] = "string"
+	}
+
+	if setting.Description != "" {
+		prop["description"] = setting.Description
+	}
+	if !isEmptyValue(setting.DefaultValue) {
+		prop["default"] = setting.DefaultValue
+	}
+	if setting.Example != nil {
+		prop["examples"] = []interface{}{setting.Example}
+	}
+	if len(setting.ValidValues) > 0 {
+		prop["enum"] = stringsToInterfaces(setting.ValidValues)
+	}
+
+	return prop
+}
+
+func applyFieldSchema(prop map[string]interface{}, field FieldConfig) {
+	switch field.Type {
+	case "number":
+		prop["type"] = "number"
+	case "boolean":
+		prop["type"] = "boolean"
+	case "choice", "string":
+		prop["type"] = "string"
+	default:
+		if _, ok := prop["type"]; !ok {
+			prop["type"] = "string"
+		}
+	}
+
+	if field.Description != "" {
+		prop["description"] = field.Description
+	}
+	if !isEmptyValue(field.Default) {
+		prop["default"] = field.Default
+	}
+	if len(field.Values) > 0 {
+		prop["enum"] = stringsToInterfaces(field.Values)
+	}
+}
+
+func insertNestedProperty(root map[string]interface{}, parts []string, prop map[string]interface{}) {
+	current := root
+	for _, part := range parts[:len(parts)-1] {
+		child, ok := current[part].(map[string]interface{})
+		if !ok {
+			child = map[string]interface{}{"type": "object"}
+			current[part] = child
+		}
+		if child["type"] != "object" {
+			child["type"] = "object"
+		}
+		props, ok := child["properties"].(map[string]interface{})
+		if !ok {
+			props = make(map[string]interface{})
+			child["properties"] = props
+		}
+		current = props
+	}
+	current[parts[len(parts)-1]] = prop
+}
+
+func sortedSchemaKeys(properties map[string]map[string]interface{}) []string {
+	keys := make([]string, 0, len(properties))
+	for key := range properties {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func stringsToInterfaces(values []string) []interface{} {
+	out := make([]interface{}, len(values))
+	for i, v := range values {
+		out[i] = v
+	}
+	return out
+}
+
+// SchemaForType derives a JSON Schema from a Go type using its yaml struct tags.
+// It is used to describe ZeroUI's own file formats so the schemas track the structs.
+func SchemaForType(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": SchemaForType(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": SchemaForType(t.Elem())}
+	case reflect.Struct:
+		props := make(map[string]interface{})
+		for i := 0; i < t.NumField(); i++ {
+			field := t.Field(i)
+			if !field.IsExported() {
+				continue
+			}
+			name := strings.Split(field.Tag.Get("yaml"), ",")[0]
+			if name == "-" {
+				continue
+			}
+			if name == "" {
+				name = strings.ToLower(field.Name)
+			}
+			props[name] = SchemaForType(field.Type)
+		}
+		return map[string]interface{}{
+			"type":                 "object",
+			"properties":           props,
+			"additionalProperties": false,
+		}
+	default:
+		// interface{} and anything else accepts any value
+		return map[string]interface{}{}
+	}
+}
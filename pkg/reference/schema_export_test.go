@@ -0,0 +1,102 @@
+package reference
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestExportJSONSchema(t *testing.T) {
+	ref := &ConfigReference{
+		AppName:    "test_app",
+		ConfigType: "json",
+		Settings: map[string]ConfigSetting{
+			"theme":             {Name: "theme", Type: TypeString, Description: "Color theme", ValidValues: []string{"dark", "light"}},
+			"terminal.blinking": {Name: "terminal.blinking", Type: TypeBoolean, DefaultValue: false},
+			"buffer_font_size":  {Name: "buffer_font_size", Type: TypeNumber, DefaultValue: 15},
+		},
+	}
+	app := &AppConfig{
+		Name:   "test_app",
+		Format: "json",
+		Fields: map[string]FieldConfig{
+			"theme":     {Type: "choice", Values: []string{"dark", "light", "auto"}, Default: "auto"},
+			"ui_layout": {Type: "string", Description: "Layout"},
+		},
+	}
+
+	schema := ExportJSONSchema(ref, app)
+
+	if schema["$schema"] != JSONSchemaDraft || schema["type"] != "object" {
+		t.Fatalf("Unexpected schema header: %v", schema)
+	}
+
+	props := schema["properties"].(map[string]interface{})
+
+	theme := props["theme"].(map[string]interface{})
+	if !reflect.DeepEqual(theme["enum"], []interface{}{"dark", "light", "auto"}) {
+		t.Errorf("Expected field values to override reference enum, got %v", theme["enum"])
+	}
+	if theme["default"] != "auto" || theme["description"] != "Color theme" {
+		t.Errorf("Expected merged default and description, got %v", theme)
+	}
+
+	terminal, ok := props["terminal"].(map[string]interface{})
+	if !ok || terminal["type"] != "object" {
+		t.Fatalf("Expected dotted key to be nested for json format, got %v", props["terminal"])
+	}
+	blinking := terminal["properties"].(map[string]interface{})["blinking"].(map[string]interface{})
+	if blinking["type"] != "boolean" || blinking["default"] != false {
+		t.Errorf("Unexpected nested property: %v", blinking)
+	}
+
+	if props["ui_layout"].(map[string]interface{})["type"] != "string" {
+		t.Errorf("Expected field-only key to be exported")
+	}
+	if props["buffer_font_size"].(map[string]interface{})["type"] != "number" {
+		t.Errorf("Expected number type for buffer_font_size")
+	}
+
+	// Flat formats keep dotted keys as-is
+	ref.ConfigType = "custom"
+	flat := ExportJSONSchema(ref, nil)["properties"].(map[string]interface{})
+	if _, ok := flat["terminal.blinking"]; !ok {
+		t.Error("Expected dotted key to stay flat for custom format")
+	}
+}
+
+func TestSchemaForType(t *testing.T) {
+	type inner struct {
+		Values []string `yaml:"values,omitempty"`
+	}
+	type outer struct {
+		Name    string            `yaml:"name"`
+		Count   int               `yaml:"count"`
+		Items   map[string]inner  `yaml:"items"`
+		Any     interface{}       `yaml:"any"`
+		Skipped string            `yaml:"-"`
+		hidden  string            //nolint:unused
+		Labels  map[string]string `yaml:"labels,omitempty"`
+	}
+
+	schema := SchemaForType(reflect.TypeOf(outer{}))
+	props := schema["properties"].(map[string]interface{})
+
+	if _, ok := props["-"]; ok {
+		t.Error("Expected yaml:\"-\" fields to be skipped")
+	}
+	if _, ok := props["hidden"]; ok {
+		t.Error("Expected unexported fields to be skipped")
+	}
+	if props["count"].(map[string]interface{})["type"] != "integer" {
+		t.Errorf("Expected integer count, got %v", props["count"])
+	}
+
+	items := props["items"].(map[string]interface{})["additionalProperties"].(map[string]interface{})
+	values := items["properties"].(map[string]interface{})["values"].(map[string]interface{})
+	if values["type"] != "array" {
+		t.Errorf("Expected nested array, got %v", values)
+	}
+	if len(props["any"].(map[string]interface{})) != 0 {
+		t.Errorf("Expected interface{} to accept any value")
+	}
+}
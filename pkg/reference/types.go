@@ -9,6 +9,8 @@ type ConfigReference struct {
 	ConfigType  string                   `json:"config_type" yaml:"config_type"` // json, toml, yaml, ini
 	LastUpdated time.Time                `json:"last_updated" yaml:"last_updated"`
 	Settings    map[string]ConfigSetting `json:"settings" yaml:"settings"`
+	// Deprecations maps renamed or removed setting names to their migration
+	Deprecations map[string]Deprecation `json:"deprecations,omitempty" yaml:"deprecations,omitempty"`
 }
 
 // ConfigSetting represents a single configuration option
@@ -38,6 +40,7 @@ const (
 type ValidationResult struct {
 	Valid       bool     `json:"valid"`
 	Errors      []string `json:"errors,omitempty"`
+	Warnings    []string `json:"warnings,omitempty"`
 	Suggestions []string `json:"suggestions,omitempty"`
 }
 
@@ -139,6 +139,10 @@ func SetupTestEnvWithHome(t *testing.T, homeDir string) {
 // that need deterministic PATH and HOME setup for tests. This eliminates code
 // duplication across multiple TestMain implementations.
 //
+// HOME points at a temporary directory and the XDG base directory variables are
+// unset, so the backups and audit log entries written by tests that change
+// configs stay out of the developer's state directory.
+//
 // Parameters:
 //   - packageName: Name of the package (for logging)
 //   - tempDirPrefix: Prefix for temporary directory name
@@ -173,6 +177,10 @@ func RunTestMainWithCleanup(m *testing.M, packageName string, tempDirPrefix stri
 			if err := os.Setenv("HOME", tmpHome); err != nil {
 				log.Printf("%s: TestMain: failed to set HOME: %v", packageName, err)
 			} else {
+				// XDG variables would otherwise keep zeroui's directories outside it
+				for _, v := range []string{"XDG_CONFIG_HOME", "XDG_STATE_HOME", "XDG_CACHE_HOME"} {
+					os.Unsetenv(v)
+				}
 				// Clear any caches that might be affected by HOME change
 				if clearCache != nil {
 					clearCache()